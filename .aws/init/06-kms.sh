@@ -0,0 +1,8 @@
+#!/bin/bash
+set -x
+
+# Key encryption key for the PII data keys (DATA_KEY_PROVIDER=kms, DATA_KEY_KMS_KEY_ID=alias/fuvekon-pii)
+KEY_ID=$(awslocal kms create-key --description "fuvekon PII data keys" --query KeyMetadata.KeyId --output text)
+awslocal kms create-alias --alias-name alias/fuvekon-pii --target-key-id "$KEY_ID"
+
+set +x
@@ -16,7 +16,14 @@ locals {
   redis_url = local.secrets.REDIS_URL
 
   jwt_secret                      = local.secrets.JWT_SECRET
+  jwt_signing_keys                = local.secrets.JWT_SIGNING_KEYS # "kid:base64 PKCS#8 key,..." (Ed25519 or RSA)
+  jwt_signing_key_id              = lookup(local.secrets, "JWT_SIGNING_KEY_ID", "")
+  jwt_hs256_cutoff                = lookup(local.secrets, "JWT_HS256_CUTOFF", "") # RFC 3339 time JWT_SIGNING_KEYS was first deployed
   user_pii_aes_key                = local.secrets.USER_PII_AES_KEY
+  user_pii_index_key              = local.secrets.USER_PII_INDEX_KEY
+  user_pii_aes_keys               = lookup(local.secrets, "USER_PII_AES_KEYS", "")
+  user_pii_aes_key_id             = lookup(local.secrets, "USER_PII_AES_KEY_ID", "")
+  data_key_provider               = lookup(local.secrets, "DATA_KEY_PROVIDER", "env") # "kms" once the USER_PII_* keys are wrapped (cmd/datakey)
   jwt_access_token_expiry_minutes = local.secrets.JWT_ACCESS_TOKEN_EXPIRY_MINUTES
   jwt_refresh_token_expiry_days   = local.secrets.JWT_REFRESH_TOKEN_EXPIRY_DAYS
   login_max_fail                  = local.secrets.LOGIN_MAX_FAIL
@@ -34,4 +41,8 @@ locals {
   google_client_id     = local.secrets.GOOGLE_CLIENT_ID
   google_client_secret = local.secrets.GOOGLE_CLIENT_SECRET
   internal_api_key     = local.secrets.INTERNAL_API_KEY
+
+  # HMAC keys for SQS job messages and /internal/jobs/* requests ("kid:secret,...")
+  job_signing_keys   = local.secrets.JOB_SIGNING_KEYS
+  job_signing_key_id = lookup(local.secrets, "JOB_SIGNING_KEY_ID", "")
 }
@@ -26,6 +26,11 @@ module "sqs" {
   project_name = var.project_name
 }
 
+module "kms" {
+  source       = "./modules/kms"
+  project_name = var.project_name
+}
+
 module "iam_role" {
   source                   = "./modules/iam_role"
   project_name             = var.project_name
@@ -34,6 +39,7 @@ module "iam_role" {
   ses_identity_arn         = module.ses.sender_identity_arn
   sqs_queue_arn            = module.sqs.queue_arn
   sqs_dlq_arn              = module.sqs.dead_letter_queue_arn
+  pii_kms_key_arn          = module.kms.pii_key_arn
 }
 
 module "lambda" {
@@ -52,7 +58,15 @@ module "lambda" {
   redis_url                       = local.redis_url
   aws_region                      = var.aws_region
   jwt_secret                      = local.jwt_secret
+  jwt_signing_keys                = local.jwt_signing_keys
+  jwt_signing_key_id              = local.jwt_signing_key_id
+  jwt_hs256_cutoff                = local.jwt_hs256_cutoff
   user_pii_aes_key                = local.user_pii_aes_key
+  user_pii_index_key              = local.user_pii_index_key
+  user_pii_aes_keys               = local.user_pii_aes_keys
+  user_pii_aes_key_id             = local.user_pii_aes_key_id
+  data_key_provider               = local.data_key_provider
+  data_key_kms_key_id             = module.kms.pii_key_arn
   jwt_access_token_expiry_minutes = local.jwt_access_token_expiry_minutes
   jwt_refresh_token_expiry_days   = local.jwt_refresh_token_expiry_days
   login_max_fail                  = local.login_max_fail
@@ -63,9 +77,12 @@ module "lambda" {
   ses_sender_email                = module.ses.sender_email
   sqs_queue_url                   = module.sqs.queue_url
   sqs_queue_arn                   = module.sqs.queue_arn
+  sqs_dlq_url                     = module.sqs.dead_letter_queue_url
   cors_allowed_origins            = var.s3_cors_allowed_origins
   general_service_url             = var.general_service_url
   internal_api_key                = local.internal_api_key
+  job_signing_keys                = local.job_signing_keys
+  job_signing_key_id              = local.job_signing_key_id
   mail_provider                   = local.mail_provider
   sendgrid_api_key                = local.sendgrid_api_key
   mail_from_name                  = local.mail_from_name
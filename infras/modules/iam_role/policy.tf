@@ -27,6 +27,7 @@ data "aws_iam_policy_document" "lambda_app_policy" {
       "sqs:SendMessage",
       "sqs:ReceiveMessage",
       "sqs:DeleteMessage",
+      "sqs:ChangeMessageVisibility",
       "sqs:GetQueueAttributes"
     ]
     resources = [
@@ -35,6 +36,12 @@ data "aws_iam_policy_document" "lambda_app_policy" {
     ]
   }
 
+  statement {
+    sid       = "KMSDataKeyAccess"
+    actions   = ["kms:Decrypt"]
+    resources = [var.pii_kms_key_arn]
+  }
+
   statement {
     sid = "CloudWatchLogsAccess"
     actions = [
@@ -23,6 +23,11 @@ variable "sqs_queue_arn" {
   type        = string
 }
 
+variable "pii_kms_key_arn" {
+  description = "ARN of the KMS key that wraps the PII data keys"
+  type        = string
+}
+
 variable "sqs_dlq_arn" {
   description = "ARN of the SQS dead letter queue"
   type        = string
@@ -0,0 +1,16 @@
+# Key encryption key for the PII data keys of general-service (DATA_KEY_PROVIDER=kms)
+resource "aws_kms_key" "pii" {
+  description             = "${var.project_name} PII data keys"
+  deletion_window_in_days = 30
+  enable_key_rotation     = true
+
+  tags = {
+    Name        = var.project_name
+    Environment = "Production"
+  }
+}
+
+resource "aws_kms_alias" "pii" {
+  name          = "alias/${var.project_name}-pii"
+  target_key_id = aws_kms_key.pii.key_id
+}
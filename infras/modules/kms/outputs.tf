@@ -0,0 +1,4 @@
+output "pii_key_arn" {
+  description = "ARN of the KMS key that wraps the PII data keys"
+  value       = aws_kms_key.pii.arn
+}
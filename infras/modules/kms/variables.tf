@@ -0,0 +1,4 @@
+variable "project_name" {
+  description = "The name of the project"
+  type        = string
+}
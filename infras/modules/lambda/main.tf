@@ -21,7 +21,15 @@ resource "aws_lambda_function" "general_service" {
       REDIS_URL                       = var.redis_url
       REDIS_TLS                       = "true"
       JWT_SECRET                      = var.jwt_secret
+      JWT_SIGNING_KEYS                = var.jwt_signing_keys
+      JWT_SIGNING_KEY_ID              = var.jwt_signing_key_id
+      JWT_HS256_CUTOFF                = var.jwt_hs256_cutoff
       USER_PII_AES_KEY                = var.user_pii_aes_key
+      USER_PII_INDEX_KEY              = var.user_pii_index_key
+      USER_PII_AES_KEYS               = var.user_pii_aes_keys
+      USER_PII_AES_KEY_ID             = var.user_pii_aes_key_id
+      DATA_KEY_PROVIDER               = var.data_key_provider
+      DATA_KEY_KMS_KEY_ID             = var.data_key_kms_key_id
       JWT_ACCESS_TOKEN_EXPIRY_MINUTES = var.jwt_access_token_expiry_minutes
       JWT_REFRESH_TOKEN_EXPIRY_DAYS   = var.jwt_refresh_token_expiry_days
       LOGIN_MAX_FAIL                  = var.login_max_fail
@@ -34,6 +42,8 @@ resource "aws_lambda_function" "general_service" {
       SES_EMAIL_IDENTITY              = var.ses_sender_email
       SQS_QUEUE                       = var.sqs_queue_url
       INTERNAL_API_KEY                = var.internal_api_key
+      JOB_SIGNING_KEYS                = var.job_signing_keys
+      JOB_SIGNING_KEY_ID              = var.job_signing_key_id
       COOKIE_DOMAIN                   = ""
       COOKIE_SECURE                   = "true"
       COOKIE_SAMESITE                 = "None"
@@ -142,8 +152,18 @@ resource "aws_lambda_function" "sqs_worker" {
       DB_SSLMODE          = var.db_sslmode
       SES_SENDER          = var.ses_sender_email
       SQS_QUEUE           = var.sqs_queue_url
+      SQS_DLQ_URL         = var.sqs_dlq_url
       GENERAL_SERVICE_URL = var.general_service_url
       INTERNAL_API_KEY    = var.internal_api_key
+      JOB_SIGNING_KEYS    = var.job_signing_keys
+      JOB_SIGNING_KEY_ID  = var.job_signing_key_id
+      # Remembers delivered job signatures so a captured message cannot be enqueued again.
+      REDIS_URL = var.redis_url
+      REDIS_TLS = "true"
+
+      TICKET_PENDING_EXPIRY_HOURS = var.ticket_pending_expiry_hours
+      # EventBridge rules trigger jobs in AWS; the internal ticker is for Local() only.
+      SCHEDULER_ENABLED = "false"
     }
   }
 
@@ -178,3 +198,37 @@ resource "aws_lambda_event_source_mapping" "sqs_worker" {
     maximum_concurrency = 2
   }
 }
+
+# Scheduled jobs - EventBridge rules invoke the SQS worker with {"scheduled_job": "<name>"}.
+# Keep in sync with the default SCHEDULE_* crons in sqs-worker/scheduler/jobs.go (times are UTC).
+resource "aws_cloudwatch_event_rule" "sqs_worker_schedule" {
+  for_each = var.sqs_worker_schedules
+
+  name                = "${var.project_name}-job-${replace(each.key, "_", "-")}"
+  description         = "Run sqs-worker scheduled job ${each.key}"
+  schedule_expression = each.value
+
+  tags = {
+    Name        = var.project_name
+    Environment = "Production"
+    Service     = "sqs-worker"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "sqs_worker_schedule" {
+  for_each = var.sqs_worker_schedules
+
+  rule  = aws_cloudwatch_event_rule.sqs_worker_schedule[each.key].name
+  arn   = aws_lambda_function.sqs_worker.arn
+  input = jsonencode({ scheduled_job = each.key })
+}
+
+resource "aws_lambda_permission" "sqs_worker_schedule" {
+  for_each = var.sqs_worker_schedules
+
+  statement_id  = "AllowEventBridge-${replace(each.key, "_", "-")}"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.sqs_worker.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.sqs_worker_schedule[each.key].arn
+}
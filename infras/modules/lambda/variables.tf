@@ -67,12 +67,61 @@ variable "jwt_secret" {
   sensitive   = true
 }
 
+variable "jwt_signing_keys" {
+  description = "Comma-separated kid:key JWT signing keys (base64 PKCS#8 Ed25519 or RSA); public keys are served as JWKS (general-service)"
+  type        = string
+  sensitive   = true
+}
+
+variable "jwt_signing_key_id" {
+  description = "Key ID from jwt_signing_keys used to sign (empty = first key)"
+  type        = string
+  default     = ""
+}
+
+variable "jwt_hs256_cutoff" {
+  description = "RFC 3339 time jwt_signing_keys was first deployed; HS256 tokens issued before it are accepted until they expire"
+  type        = string
+  default     = ""
+}
+
 variable "user_pii_aes_key" {
   description = "Base64-encoded AES key for encrypting user PII (general-service)"
   type        = string
   sensitive   = true
 }
 
+variable "user_pii_aes_keys" {
+  description = "Comma-separated kid:key AES key ring for user PII; USER_PII_AES_KEY still decrypts older values (general-service)"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "user_pii_aes_key_id" {
+  description = "ID of the key in user_pii_aes_keys that encrypts new PII (default: first)"
+  type        = string
+  default     = ""
+}
+
+variable "data_key_provider" {
+  description = "How the USER_PII_* keys are wrapped: env (plaintext) or kms"
+  type        = string
+  default     = "env"
+}
+
+variable "data_key_kms_key_id" {
+  description = "ARN of the KMS key that wraps the USER_PII_* keys when data_key_provider is kms"
+  type        = string
+  default     = ""
+}
+
+variable "user_pii_index_key" {
+  description = "Base64-encoded HMAC key for the blind indexes used to search encrypted user PII (general-service)"
+  type        = string
+  sensitive   = true
+}
+
 variable "jwt_access_token_expiry_minutes" {
   description = "JWT access token expiry in minutes"
   type        = string
@@ -134,24 +183,41 @@ variable "cors_allowed_origins" {
   default     = []
 }
 
+variable "sqs_dlq_url" {
+  description = "SQS dead-letter queue URL (sqs-worker moves messages here when their retry budget is spent)"
+  type        = string
+}
+
 variable "sqs_queue_arn" {
   description = "SQS queue ARN for event source mapping"
   type        = string
 }
 
 variable "general_service_url" {
-  description = "Base URL of general-service API (for sqs-worker to call /internal/jobs/ticket)"
+  description = "Base URL of general-service API (for sqs-worker to call /internal/jobs/*)"
   type        = string
   default     = ""
 }
 
 variable "internal_api_key" {
-  description = "Internal API key for general-service /internal/jobs/ticket (must match general-service INTERNAL_API_KEY)"
+  description = "Internal API key for general-service /internal/jobs/* (must match general-service INTERNAL_API_KEY)"
   type        = string
   default     = ""
   sensitive   = true
 }
 
+variable "job_signing_keys" {
+  description = "HMAC keys (kid:secret,...) signing SQS job messages and /internal/jobs/* requests; same value for general-service and sqs-worker"
+  type        = string
+  sensitive   = true
+}
+
+variable "job_signing_key_id" {
+  description = "Key ID from job_signing_keys used to sign (empty = first key)"
+  type        = string
+  default     = ""
+}
+
 variable "mail_provider" {
   description = "Mail provider: ses (default) or sendgrid"
   type        = string
@@ -183,3 +249,24 @@ variable "google_client_secret" {
   default     = ""
   sensitive   = true
 }
+
+variable "sqs_worker_schedules" {
+  description = "Scheduled jobs run by the sqs-worker: job name => EventBridge schedule expression (UTC)"
+  type        = map(string)
+  default = {
+    expire_stale_tickets  = "cron(0 * * * ? *)"
+    payment_reminders     = "cron(0 2 * * ? *)"
+    reconcile_stock       = "cron(30 19 * * ? *)"
+    weekly_stats          = "cron(0 1 ? * MON *)"
+    purge_deleted_users   = "cron(15 * * * ? *)"
+    retention_purge       = "cron(0 20 * * ? *)"
+    impersonation_notices = "cron(0/10 * * * ? *)"
+    fraud_screening       = "cron(0/5 * * * ? *)"
+  }
+}
+
+variable "ticket_pending_expiry_hours" {
+  description = "Hours a ticket may stay pending (unpaid) before the sqs-worker releases it"
+  type        = number
+  default     = 48
+}
@@ -32,7 +32,9 @@ resource "aws_sqs_queue_redrive_policy" "main" {
   queue_url = aws_sqs_queue.main.id
   redrive_policy = jsonencode({
     deadLetterTargetArn = aws_sqs_queue.dead_letter.arn
-    maxReceiveCount     = 3
+    # Safety net only: the sqs-worker moves messages to the DLQ itself once an action's retry budget
+    # (at most 8 attempts) is spent. Keep this above the largest budget in processor.DefaultRegistry.
+    maxReceiveCount = 10
   })
 }
 
@@ -0,0 +1,52 @@
+// Command datakey prints data keys for configuration, wrapped with the DATA_KEY_PROVIDER key
+// provider: a new random AES-256 key by default, or an existing base64 key with -wrap (e.g. to
+// move a plaintext USER_PII_AES_KEY to KMS). -kek prints a new key for DATA_KEY_FILE instead.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log"
+
+	"general-service/internal/config"
+	"general-service/internal/security"
+)
+
+func main() {
+	wrap := flag.String("wrap", "", "base64 data key to wrap instead of generating one")
+	kek := flag.Bool("kek", false, "print a new key encryption key for DATA_KEY_FILE")
+	flag.Parse()
+
+	config.LoadEnv()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	if *kek {
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return
+	}
+	if *wrap != "" {
+		existing, err := security.DecodeBase64Key(*wrap)
+		if err != nil {
+			log.Fatal("❌ -wrap: ", err)
+		}
+		key = existing
+	}
+
+	ctx := context.Background()
+	provider, err := security.NewKeyProviderFromEnv(ctx)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	wrapped, err := provider.WrapKey(ctx, key)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	log.Printf("Data key wrapped with the %s key provider", provider.Name())
+	fmt.Println(base64.StdEncoding.EncodeToString(wrapped))
+}
@@ -18,6 +18,10 @@
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and your JWT token.
+// @securityDefinitions.apikey ServiceAPIKey
+// @in header
+// @name X-Api-Key
+// @description Service account API key (fvk_...). Only the admin routes of the key's scopes accept it.
 package main
 
 import (
@@ -32,12 +36,14 @@ import (
 	"gorm.io/gorm"
 
 	_ "general-service/docs"
+	"general-service/internal/common/utils"
 	"general-service/internal/config"
 	"general-service/internal/database"
 	"general-service/internal/handlers"
 	"general-service/internal/middlewares"
 	"general-service/internal/queue"
 	"general-service/internal/repositories"
+	"general-service/internal/security"
 	"general-service/internal/services"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -63,7 +69,6 @@ func validateRequiredEnvVars() error {
 		"DB_PASSWORD",
 		"DB_NAME",
 		"JWT_SECRET",
-		"USER_PII_AES_KEY",
 		// Redis is optional - only warn if missing
 	}
 
@@ -74,6 +79,32 @@ func validateRequiredEnvVars() error {
 		}
 	}
 
+	// PII keys are plaintext with the env key provider, and wrapped data keys (which need the
+	// provider's own setting to unwrap) with kms or file
+	keyForm := "base64 key"
+	switch provider := strings.ToLower(strings.TrimSpace(os.Getenv("DATA_KEY_PROVIDER"))); provider {
+	case "", security.KeyProviderEnv:
+	case security.KeyProviderKMS:
+		keyForm = "wrapped by " + provider
+		if os.Getenv("DATA_KEY_KMS_KEY_ID") == "" {
+			missing = append(missing, "DATA_KEY_KMS_KEY_ID")
+		}
+	case security.KeyProviderFile:
+		keyForm = "wrapped by " + provider
+		if os.Getenv("DATA_KEY_FILE") == "" {
+			missing = append(missing, "DATA_KEY_FILE")
+		}
+	default:
+		return fmt.Errorf("DATA_KEY_PROVIDER %q is not one of env, kms, file", provider)
+	}
+	// PII keys: the key ring, or the single key used before key IDs
+	if os.Getenv("USER_PII_AES_KEYS") == "" && os.Getenv("USER_PII_AES_KEY") == "" {
+		missing = append(missing, fmt.Sprintf("USER_PII_AES_KEYS or USER_PII_AES_KEY (%s)", keyForm))
+	}
+	if os.Getenv("USER_PII_INDEX_KEY") == "" {
+		missing = append(missing, fmt.Sprintf("USER_PII_INDEX_KEY (%s)", keyForm))
+	}
+
 	// Check Redis separately - just warn, don't fail
 	if os.Getenv("REDIS_HOST") == "" && os.Getenv("REDIS_URL") == "" {
 		log.Println("WARNING: Neither REDIS_HOST nor REDIS_URL is set. Rate limiting may not work properly.")
@@ -133,6 +164,18 @@ func setupRouter(db *gorm.DB) (*gin.Engine, error) {
 	// Set the global DB reference
 	database.GlobalDB = db
 
+	// Asymmetric JWT signing keys (optional; HS256 with JWT_SECRET when not set)
+	if ring, err := utils.LoadJWTKeyRing(); err != nil {
+		if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+			return nil, fmt.Errorf("configuration error: %w", err)
+		}
+		log.Fatalf("Configuration error: %v", err)
+	} else if ring != nil {
+		log.Printf("JWT signing with key %q (%s)", ring.Active().ID, ring.Active().Algorithm)
+	} else {
+		log.Println("WARNING: JWT_SIGNING_KEYS not set; tokens are signed HS256 with JWT_SECRET")
+	}
+
 	// Initialize Redis
 	setupRedis()
 
@@ -156,27 +199,51 @@ func setupRouter(db *gorm.DB) (*gin.Engine, error) {
 	}
 
 	// Initialize repositories and services
-	repos := repositories.NewRepositories(db)
-	svc := services.NewServices(repos, database.RedisClient, loginMaxFail, loginFailBlockMinutes)
-	h := handlers.NewHandlers(svc, queuePublisher)
+	piiIndex, err := database.LoadUserPIIBlindIndex()
+	if err != nil {
+		if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+			return nil, fmt.Errorf("configuration error: %w", err)
+		}
+		log.Fatalf("Configuration error: %v", err)
+	}
+	repos := repositories.NewRepositories(db, piiIndex)
+	svc := services.NewServices(repos, database.RedisClient, loginMaxFail, loginFailBlockMinutes, config.GetMFARequiredRoles())
+	h := handlers.NewHandlers(svc, queuePublisher, config.GetCookieConfig())
+	middlewares.SetTokenRevocationChecker(svc.Session)
+	middlewares.SetAuditLogWriter(svc.Audit)
+	middlewares.SetAPIKeyAuthenticator(svc.ServiceAccount)
+	middlewares.SetupRateLimiting(database.RedisClient, config.GetRateLimitAllowlist())
+	middlewares.SetupInternalJobReplayGuard(database.RedisClient)
 
 	// Setup router with middleware
 	router := gin.Default()
+	if err := router.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		log.Fatalf("Configuration error: TRUSTED_PROXIES: %v", err)
+	}
 	allowedOrigins := config.GetEnvOr("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
 
+	router.Use(middlewares.RequestID())
 	router.Use(middlewares.CorsMiddleware(allowedOrigins))
 	log.Println("CORS middleware configured with allowed origins:", allowedOrigins)
 
 	// Setup Swagger (disabled in production)
 	setupSwagger(router)
 
+	// Check if running in Lambda - if so, API Gateway includes /api/general in the path
+	isLambda := os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != ""
+
+	// JWKS is public: other services verify access tokens with it
+	if isLambda {
+		config.SetupWellKnownRoutes(router.Group(config.LambdaRoutePrefix), h)
+	} else {
+		config.SetupWellKnownRoutes(router, h)
+	}
+
 	// Every API requires X-Internal-Api-Key header (INTERNAL_API_KEY env)
 	router.Use(middlewares.InternalAPIKeyMiddleware())
 
-	// Check if running in Lambda - if so, API Gateway includes /api/general in the path
-	isLambda := os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != ""
 	if isLambda {
-		generalGroup := router.Group("/api/general")
+		generalGroup := router.Group(config.LambdaRoutePrefix)
 		config.SetupAPIRoutes(generalGroup, h, db, repos, database.SetWithExpiration)
 		log.Println("Routes configured with /api/general prefix for Lambda deployment")
 	} else {
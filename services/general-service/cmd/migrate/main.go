@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"general-service/internal/config"
 	"general-service/internal/database"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
+	reencryptPII := flag.Bool("reencrypt-pii", false, "re-encrypt user PII with the active USER_PII_AES_KEY_ID instead of migrating (resumable; stop with Ctrl-C)")
+	batchSize := flag.Int("batch-size", 500, "rows per batch for -reencrypt-pii")
+	flag.Parse()
+
 	config.LoadEnv()
 
 	db, err := database.ConnectWithEnv()
@@ -20,6 +29,18 @@ func main() {
 	}
 	defer sqlDB.Close()
 
+	if *reencryptPII {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		log.Println("🔄 Starting PII re-encryption...")
+		if err := database.ReencryptUserPII(ctx, db, *batchSize); err != nil {
+			log.Fatal("❌ PII re-encryption failed: ", err)
+		}
+		log.Println("✅ PII re-encryption completed successfully!")
+		return
+	}
+
 	log.Println("🔄 Starting database migration...")
 
 	if err := database.MigrateAndSeed(db); err != nil {
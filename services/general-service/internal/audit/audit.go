@@ -0,0 +1,94 @@
+// Package audit collects what a privileged action changed, so the action can be written to the
+// admin audit log with the request that made it. The admin audit middleware starts a Recorder
+// for every mutating admin request; services report the entities they change with Snapshot and
+// Record, which do nothing outside an audited action.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Change is one entity a privileged action changed. Before and After are JSON snapshots of the
+// entity (nil when it was created or deleted); they are diffed, with PII redacted, when the
+// action is logged.
+type Change struct {
+	EntityType string
+	EntityID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+}
+
+// ActorRoleServiceAccount is the ActorRole of actions made with a service account API key; the
+// ActorID is then the service account's.
+const ActorRoleServiceAccount = -1
+
+// Action is a privileged action and the changes it made.
+type Action struct {
+	ActorID    string // user ID of the admin or staff member, or the service account ID
+	ActorRole  int    // constants.UserRole, or ActorRoleServiceAccount
+	Name       string // e.g. "tickets.approve"
+	EntityType string // e.g. "tickets", "users.deletions"
+	EntityID   string
+	IPAddress  string
+	UserAgent  string
+	RequestID  string
+	Status     int // HTTP status of the response
+	Changes    []Change
+}
+
+// Recorder collects the changes of one action.
+type Recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+// Changes returns the changes recorded so far.
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change(nil), r.changes...)
+}
+
+type recorderKey struct{}
+
+// NewContext returns a context that collects changes into r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder of the audited action, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Snapshot captures v as it is now, to be passed to Record as the state before a change. It
+// returns nil outside an audited action, so callers can take snapshots unconditionally.
+func Snapshot(ctx context.Context, v any) json.RawMessage {
+	if FromContext(ctx) == nil || v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Record adds a change made by the current audited action. before is a Snapshot taken before the
+// change (nil for a created entity) and after the entity now (nil for a deleted one).
+func Record(ctx context.Context, entityType, entityID string, before json.RawMessage, after any) {
+	r := FromContext(ctx)
+	if r == nil {
+		return
+	}
+	change := Change{EntityType: entityType, EntityID: entityID, Before: before}
+	if after != nil {
+		change.After = Snapshot(ctx, after)
+	}
+	r.mu.Lock()
+	r.changes = append(r.changes, change)
+	r.mu.Unlock()
+}
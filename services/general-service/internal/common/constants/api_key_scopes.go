@@ -0,0 +1,25 @@
+package constants
+
+// APIKeyScope is a permission granted to a service account API key. Each scope opens a fixed set
+// of admin routes (see config.APIKeyScopeRoutes); a key can call nothing else.
+type APIKeyScope string
+
+const (
+	ScopeTicketsRead  APIKeyScope = "tickets:read"  // list and look up tickets
+	ScopeCheckinWrite APIKeyScope = "checkin:write" // confirm ticket check-in
+	ScopeDealersRead  APIKeyScope = "dealers:read"  // list and look up dealer booths
+	ScopeUsersRead    APIKeyScope = "users:read"    // list and look up users
+)
+
+// APIKeyScopes lists every scope a key can be granted.
+var APIKeyScopes = []APIKeyScope{ScopeTicketsRead, ScopeCheckinWrite, ScopeDealersRead, ScopeUsersRead}
+
+// IsValid checks if the scope is one of APIKeyScopes
+func (s APIKeyScope) IsValid() bool {
+	for _, scope := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,43 @@ var (
 	ErrAgeRequirement                    = errors.New("must be at least 16 years old")
 	ErrInvalidDateOfBirth                = errors.New("invalid date of birth format")
 	ErrInternalServer                    = errors.New("internal server error")
+	ErrInvalidRefreshToken               = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused                = errors.New("refresh token reuse detected")
+	ErrInvalidMagicLink                  = errors.New("sign-in link is invalid, expired or already used")
+
+	// Email change errors
+	ErrEmailAlreadyInUse       = errors.New("email address is already in use")
+	ErrSameEmail               = errors.New("new email is the same as the current email")
+	ErrInvalidEmailChangeCode  = errors.New("invalid or expired verification code")
+	ErrInvalidEmailRevertToken = errors.New("email change revert link is invalid or expired")
+
+	// Two-factor authentication errors
+	ErrInvalidMFAToken    = errors.New("invalid or expired two-factor challenge")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMFASetupNotStarted = errors.New("two-factor setup has not been started")
+	ErrMFARequiredForRole = errors.New("two-factor authentication is required for this role")
+
+	// Passkey errors
+	ErrInvalidPasskey           = errors.New("passkey verification failed")
+	ErrPasskeyNotFound          = errors.New("passkey not found")
+	ErrPasskeyAlreadyRegistered = errors.New("passkey is already registered")
+	ErrPasskeyChallengeExpired  = errors.New("passkey challenge expired or already used")
+
+	// External sign-in (OAuth/OIDC) errors
+	ErrOAuthProviderNotConfigured       = errors.New("sign-in provider is not configured")
+	ErrInvalidOAuthState                = errors.New("sign-in request expired or already used")
+	ErrInvalidOAuthLogin                = errors.New("sign-in provider rejected the login")
+	ErrOAuthVerifiedEmailRequired       = errors.New("sign-in provider did not share a verified email address")
+	ErrOAuthRegistrationDetailsRequired = errors.New("fullName, nickname, dateOfBirth, and country are required to complete sign-up")
+	ErrOAuthRegistrationExpired         = errors.New("sign-up session expired")
+	ErrOAuthAccountExists               = errors.New("an account with this email already exists; sign in and link this provider from account settings")
+	ErrIdentityAlreadyLinked            = errors.New("this sign-in account is linked to another user")
+	ErrProviderAlreadyLinked            = errors.New("a login from this provider is already linked")
+	ErrIdentityNotFound                 = errors.New("linked login not found")
+	ErrLastLoginMethod                  = errors.New("cannot remove the only remaining login method")
+	ErrPasswordAlreadySet               = errors.New("account already has a password")
 
 	// Ticket errors
 	ErrInvalidTierID       = errors.New("invalid tier ID format")
@@ -24,6 +61,36 @@ var (
 	ErrInvalidUserID       = errors.New("invalid user ID format")
 	ErrNoTicketFound       = errors.New("no ticket found for this user")
 	ErrInvalidTicketStatus = errors.New("invalid ticket status")
+
+	// Personal data export errors
+	ErrDataExportInProgress  = errors.New("a data export is already being prepared")
+	ErrDataExportNotFound    = errors.New("data export not found")
+	ErrInvalidDataExportLink = errors.New("data export link is invalid or expired")
+
+	// Account deletion errors
+	ErrAccountDeletionScheduled     = errors.New("account deletion is already scheduled")
+	ErrAccountDeletionNotFound      = errors.New("account deletion request not found")
+	ErrAccountDeletionInvalidStatus = errors.New("account deletion request cannot be changed in its current state")
+
+	// Impersonation errors
+	ErrImpersonationNotAllowed = errors.New("only attendee accounts can be impersonated")
+	ErrImpersonationActive     = errors.New("an impersonation is already active; end it first")
+	ErrImpersonationNotFound   = errors.New("impersonation not found")
+	ErrImpersonationEnded      = errors.New("impersonation has already ended")
+	ErrImpersonationForbidden  = errors.New("this action is not allowed while impersonating a user")
+
+	// Service account and API key errors
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrServiceAccountExists   = errors.New("a service account with this name already exists")
+	ErrServiceAccountDisabled = errors.New("service account is disabled")
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+	ErrAPIKeyRevoked          = errors.New("API key has been revoked or has expired")
+	ErrInvalidAPIKeyScope     = errors.New("invalid API key scope")
+	ErrInvalidAPIKey          = errors.New("invalid or expired API key")
+
+	// Fraud review errors
+	ErrFraudAssessmentNotFound = errors.New("fraud assessment not found")
+	ErrFraudAssessmentReviewed = errors.New("fraud assessment has already been reviewed")
 )
 
 const (
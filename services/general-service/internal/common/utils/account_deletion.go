@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetAccountDeletionGracePeriod retrieves how long (days) a self-service account deletion can be
+// cancelled before the user is anonymised, from env
+func GetAccountDeletionGracePeriod() time.Duration {
+	dayStr := os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")
+	if dayStr == "" {
+		return 30 * 24 * time.Hour
+	}
+	days, err := strconv.Atoi(dayStr)
+	if err != nil || days < 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"general-service/internal/common/constants"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyTag starts every service account API key, so keys are recognisable (and can be told
+	// apart from JWTs in the Authorization header).
+	APIKeyTag = "fvk_"
+
+	apiKeyIDBytes     = 6  // 12 hex characters after the tag
+	apiKeySecretBytes = 32 // 43 base64url characters
+	apiKeyPrefixLen   = len(APIKeyTag) + 2*apiKeyIDBytes
+)
+
+// APIKeyPrincipal is the service account authenticated by an API key.
+type APIKeyPrincipal struct {
+	ServiceAccountID string
+	ServiceAccount   string // name
+	KeyID            string
+	Scopes           []constants.APIKeyScope
+}
+
+// GenerateAPIKey returns a new API key "fvk_<id>_<secret>" and its prefix "fvk_<id>", which
+// identifies the key and may be shown again later; the key itself is shown once.
+func GenerateAPIKey() (key, prefix string, err error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyTag + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// APIKeyPrefix returns the prefix of key, or false when key is not shaped like an API key
+func APIKeyPrefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, APIKeyTag) || len(key) <= apiKeyPrefixLen+1 || key[apiKeyPrefixLen] != '_' {
+		return "", false
+	}
+	return key[:apiKeyPrefixLen], true
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key; only the hash is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyMatches compares key with a stored hash in constant time
+func APIKeyMatches(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// GetAPIKeyPrincipalFromContext returns the service account of a request authenticated with an
+// API key, or nil for requests made by a user
+func GetAPIKeyPrincipalFromContext(c *gin.Context) *APIKeyPrincipal {
+	principal, _ := c.Get("api_key_principal")
+	p, _ := principal.(*APIKeyPrincipal)
+	return p
+}
@@ -6,12 +6,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CookieConfig holds cookie configuration
+// RefreshCookieName is the httpOnly cookie carrying the refresh token.
+const RefreshCookieName = "refresh_token"
+
+// CookieConfig holds cookie configuration. MaxAge applies to the access token cookie; the refresh
+// cookie uses RefreshMaxAge and is only sent to RefreshPath (the auth routes).
 type CookieConfig struct {
-	Domain   string
-	Secure   bool
-	SameSite string
-	MaxAge   int
+	Domain        string
+	Secure        bool
+	SameSite      string
+	MaxAge        int
+	RefreshMaxAge int
+	RefreshPath   string
 }
 
 // SetAuthCookie sets the authentication cookie with configured settings
@@ -44,6 +50,34 @@ func ClearAuthCookie(c *gin.Context, cookieConfig CookieConfig) {
 	)
 }
 
+// SetRefreshCookie sets the refresh token cookie, scoped to the auth routes
+func SetRefreshCookie(c *gin.Context, token string, cookieConfig CookieConfig) {
+	c.SetSameSite(parseSameSite(cookieConfig.SameSite))
+	c.SetCookie(
+		RefreshCookieName,
+		token,
+		cookieConfig.RefreshMaxAge,
+		cookieConfig.RefreshPath,
+		cookieConfig.Domain,
+		cookieConfig.Secure,
+		true, // httpOnly
+	)
+}
+
+// ClearRefreshCookie removes the refresh token cookie
+func ClearRefreshCookie(c *gin.Context, cookieConfig CookieConfig) {
+	c.SetSameSite(parseSameSite(cookieConfig.SameSite))
+	c.SetCookie(
+		RefreshCookieName,
+		"",
+		-1,
+		cookieConfig.RefreshPath,
+		cookieConfig.Domain,
+		cookieConfig.Secure,
+		true, // httpOnly
+	)
+}
+
 // parseSameSite converts string to http.SameSite constant
 func parseSameSite(sameSite string) http.SameSite {
 	switch sameSite {
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetDataExportLinkExpiry retrieves how long (hours) a personal data export can be downloaded from env
+func GetDataExportLinkExpiry() time.Duration {
+	hourStr := os.Getenv("DATA_EXPORT_LINK_EXPIRY_HOURS")
+	if hourStr == "" {
+		return 72 * time.Hour
+	}
+	hours, err := strconv.Atoi(hourStr)
+	if err != nil || hours <= 0 {
+		return 72 * time.Hour
+	}
+	return time.Duration(hours) * time.Hour
+}
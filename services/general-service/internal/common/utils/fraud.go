@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetFraudReviewScore retrieves the risk score (0-100) from which a ticket purchase is put in the
+// fraud review queue, from env (FRAUD_REVIEW_SCORE, default 40)
+func GetFraudReviewScore() int {
+	if score, err := strconv.Atoi(os.Getenv("FRAUD_REVIEW_SCORE")); err == nil && score > 0 && score <= 100 {
+		return score
+	}
+	return 40
+}
+
+// GetFraudHoldScore retrieves the risk score (0-100) from which a purchased ticket is held until
+// the review is cleared, from env (FRAUD_HOLD_SCORE). It is never below the review score; 0 or
+// unset turns automatic holds off.
+func GetFraudHoldScore() int {
+	score, err := strconv.Atoi(os.Getenv("FRAUD_HOLD_SCORE"))
+	if err != nil || score <= 0 || score > 100 {
+		return 0
+	}
+	return max(score, GetFraudReviewScore())
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetImpersonationTokenExpiry retrieves how long (minutes) an impersonation token is valid, from
+// env. It is capped at the access token expiry, which is how long ending an impersonation denies
+// its token for.
+func GetImpersonationTokenExpiry() time.Duration {
+	expiry := 15 * time.Minute
+	if minutes, err := strconv.Atoi(os.Getenv("IMPERSONATION_TOKEN_EXPIRY_MINUTES")); err == nil && minutes > 0 {
+		expiry = time.Duration(minutes) * time.Minute
+	}
+	return min(expiry, GetAccessTokenExpiry())
+}
+
+// GetImpersonatorIDFromContext returns the ID of the admin acting as the current user, or "" when
+// the request is not made with an impersonation token
+func GetImpersonatorIDFromContext(c *gin.Context) string {
+	return c.GetString("impersonator_id")
+}
@@ -2,31 +2,46 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"general-service/internal/security"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 // JWTClaims represents the claims stored in JWT token
 type JWTClaims struct {
-	UserID      string `json:"user_id"`
-	Email       string `json:"email"`
-	FursonaName string `json:"fursona_name"`
-	Role        string `json:"role"`
-	TokenType   string `json:"token_type"` // "access" or "refresh"
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	FursonaName string    `json:"fursona_name"`
+	Role        string    `json:"role"`
+	TokenType   string    `json:"token_type"`          // "access" or "refresh"
+	SessionID   string    `json:"sid,omitempty"`       // UserSession ID; empty for tokens issued before sessions existed
+	NewEmail    string    `json:"new_email,omitempty"` // email change revert tokens: the address Email was changed to
+	Actor       *JWTActor `json:"act,omitempty"`       // impersonation tokens: the admin acting as the user
 	jwt.RegisteredClaims
 }
 
+// JWTActor is the act claim (RFC 8693) of an impersonation token: who is acting as the subject
+type JWTActor struct {
+	Subject string `json:"sub"` // admin user ID
+}
+
 // TokenPair holds both access and refresh tokens
 type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken   string `json:"access_token"`
+	AccessTokenID string `json:"-"` // jti of AccessToken
+	RefreshToken  string `json:"refresh_token"`
 }
 
 // GetJWTSecret retrieves JWT secret from environment variable
@@ -39,6 +54,104 @@ func GetJWTSecret() string {
 	return secret
 }
 
+var (
+	jwtKeyRingOnce sync.Once
+	jwtKeyRing     *security.JWTKeyRing
+	jwtKeyRingErr  error
+)
+
+// LoadJWTKeyRing returns the asymmetric signing keys from JWT_SIGNING_KEYS, loaded on first use.
+// It returns nil if none are configured, in which case tokens are signed HS256 with JWT_SECRET.
+func LoadJWTKeyRing() (*security.JWTKeyRing, error) {
+	jwtKeyRingOnce.Do(func() {
+		jwtKeyRing, jwtKeyRingErr = security.NewJWTKeyRingFromEnv()
+	})
+	return jwtKeyRing, jwtKeyRingErr
+}
+
+// acceptHS256Token reports whether an HS256 token is accepted once signing keys are configured:
+// only if it was issued before JWT_HS256_CUTOFF (RFC 3339, when JWT_SIGNING_KEYS was enabled) and
+// the longest-lived token issued then could still be valid, so tokens from before the switch work
+// until they expire. JWT_SECRET (not its development fallback) must still be set to verify them.
+func acceptHS256Token(claims *JWTClaims, now time.Time) bool {
+	if os.Getenv("JWT_SECRET") == "" || claims == nil || claims.IssuedAt == nil {
+		return false
+	}
+	cutoff, err := time.Parse(time.RFC3339, strings.TrimSpace(os.Getenv("JWT_HS256_CUTOFF")))
+	if err != nil {
+		return false
+	}
+	return claims.IssuedAt.Before(cutoff) && now.Before(cutoff.Add(hs256GracePeriod()))
+}
+
+// hs256GracePeriod is the lifetime of the longest-lived JWT (refresh tokens are not JWTs).
+func hs256GracePeriod() time.Duration {
+	return max(GetAccessTokenExpiry(), GetForgotPasswordTokenExpiry(), GetEmailRevertTokenExpiry(), GetMFAChallengeTokenExpiry())
+}
+
+// GetJWKS returns the public keys other services use to verify tokens (empty while tokens are
+// still signed HS256).
+func GetJWKS() (security.JWKSet, error) {
+	ring, err := LoadJWTKeyRing()
+	if err != nil {
+		return security.JWKSet{}, err
+	}
+	if ring == nil {
+		return security.JWKSet{Keys: []security.JWK{}}, nil
+	}
+	return ring.JWKS(), nil
+}
+
+// signToken signs claims with the active key of the key ring, naming it in the kid header, or
+// HS256 with JWT_SECRET if no key ring is configured.
+func signToken(claims JWTClaims) (string, error) {
+	ring, err := LoadJWTKeyRing()
+	if err != nil {
+		return "", err
+	}
+	if ring == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(GetJWTSecret()))
+	}
+	key := ring.Active()
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported signing algorithm %q", key.Algorithm)
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Signer)
+}
+
+// tokenKey resolves the verification key of a token: the key ring entry named by its kid header,
+// or JWT_SECRET for HS256 tokens.
+func tokenKey(token *jwt.Token) (interface{}, error) {
+	ring, err := LoadJWTKeyRing()
+	if err != nil {
+		return nil, err
+	}
+	if token.Method == jwt.SigningMethodHS256 {
+		if ring != nil {
+			claims, _ := token.Claims.(*JWTClaims)
+			if !acceptHS256Token(claims, time.Now()) {
+				return nil, errors.New("HS256 tokens are no longer accepted")
+			}
+		}
+		return []byte(GetJWTSecret()), nil
+	}
+	if ring == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, err := ring.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("signing method %v does not match key %q", token.Header["alg"], kid)
+	}
+	return key.PublicKey(), nil
+}
+
 // GetAccessTokenExpiry retrieves access token expiry duration from environment
 func GetAccessTokenExpiry() time.Duration {
 	expiryMinutes := os.Getenv("JWT_ACCESS_TOKEN_EXPIRY_MINUTES")
@@ -75,32 +188,68 @@ func GetRefreshTokenExpiry() time.Duration {
 
 // CreateAccessToken generates a new access token for the user
 func CreateAccessToken(userID uuid.UUID, email, fursonaName, role string) (string, error) {
+	token, _, err := createAccessToken(userID, email, fursonaName, role, "")
+	return token, err
+}
+
+// createAccessToken signs an access token bound to sessionID and returns it with its jti
+func createAccessToken(userID uuid.UUID, email, fursonaName, role, sessionID string) (string, string, error) {
+	jti := uuid.New().String()
 	claims := JWTClaims{
 		UserID:      userID.String(),
 		Email:       email,
 		FursonaName: fursonaName,
 		Role:        role,
 		TokenType:   "access",
+		SessionID:   sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(GetAccessTokenExpiry())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "general-service",
 			Subject:   userID.String(),
-			ID:        uuid.New().String(),
+			ID:        jti,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(GetJWTSecret()))
+	tokenString, err := signToken(claims)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign access token: %w", err)
+		return "", "", fmt.Errorf("failed to sign access token: %w", err)
 	}
 
-	return tokenString, nil
+	return tokenString, jti, nil
 }
 
-// CreateRefreshToken generates a new refresh token for the user
+// CreateImpersonationToken signs an access token for userID carrying an act claim naming the admin.
+// sessionID is the impersonation ID, so ending the impersonation denies the token. It returns the
+// token, its jti and its expiry.
+func CreateImpersonationToken(userID uuid.UUID, email, fursonaName, role, sessionID, actorID string) (string, string, time.Time, error) {
+	jti := uuid.New().String()
+	now := time.Now()
+	expiresAt := now.Add(GetImpersonationTokenExpiry())
+	claims := JWTClaims{
+		UserID:      userID.String(),
+		Email:       email,
+		FursonaName: fursonaName,
+		Role:        role,
+		TokenType:   "access",
+		SessionID:   sessionID,
+		Actor:       &JWTActor{Subject: actorID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "general-service",
+			Subject:   userID.String(),
+			ID:        jti,
+		},
+	}
+	signed, err := signToken(claims)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to sign impersonation token: %w", err)
+	}
+	return signed, jti, expiresAt, nil
+}
 
 // CreateRefreshToken generates a random string as refresh token (no user info)
 func CreateRefreshToken(_ uuid.UUID, _ string, _ string, _ string) (string, error) {
@@ -111,9 +260,15 @@ func CreateRefreshToken(_ uuid.UUID, _ string, _ string, _ string) (string, erro
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
-// CreateTokenPair generates both access and refresh tokens
-func CreateTokenPair(userID uuid.UUID, email, fursonaName, role string) (*TokenPair, error) {
-	accessToken, err := CreateAccessToken(userID, email, fursonaName, role)
+// HashRefreshToken returns the hex SHA-256 of a refresh token; only the hash is stored
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateTokenPair generates both access and refresh tokens for a session
+func CreateTokenPair(userID uuid.UUID, email, fursonaName, role, sessionID string) (*TokenPair, error) {
+	accessToken, jti, err := createAccessToken(userID, email, fursonaName, role, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create access token: %w", err)
 	}
@@ -124,20 +279,17 @@ func CreateTokenPair(userID uuid.UUID, email, fursonaName, role string) (*TokenP
 	}
 
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:   accessToken,
+		AccessTokenID: jti,
+		RefreshToken:  refreshToken,
 	}, nil
 }
 
-// ValidateToken validates the JWT token and returns the claims
+// ValidateToken validates the JWT token and returns the claims. Tokens signed with the key ring
+// are verified with the key named by their kid header.
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(GetJWTSecret()), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, tokenKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), security.JWTAlgEdDSA, security.JWTAlgRS256}))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -319,8 +471,7 @@ func CreateForgotPasswordToken(userID uuid.UUID, email, fursonaName, role string
 			ID:        uuid.New().String(),
 		},
 	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString([]byte(GetJWTSecret()))
+	signed, err := signToken(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign password token: %w", err)
 	}
@@ -338,3 +489,108 @@ func ValidateForgotPasswordToken(tokenString string) (*JWTClaims, error) {
 	}
 	return claims, nil
 }
+
+// GetEmailRevertTokenExpiry retrieves how long (hours) the link to undo an email change stays valid
+func GetEmailRevertTokenExpiry() time.Duration {
+	hourStr := os.Getenv("JWT_EMAIL_REVERT_EXPIRY_HOURS")
+	if hourStr == "" {
+		return 7 * 24 * time.Hour
+	}
+	hours, err := strconv.Atoi(hourStr)
+	if err != nil || hours <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// CreateEmailRevertToken creates a signed JWT, sent to the previous address, that changes the
+// user's email from newEmail back to oldEmail
+func CreateEmailRevertToken(userID uuid.UUID, oldEmail, newEmail string) (string, error) {
+	claims := JWTClaims{
+		UserID:    userID.String(),
+		Email:     oldEmail,
+		NewEmail:  newEmail,
+		TokenType: "email_revert",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(GetEmailRevertTokenExpiry())),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "general-service",
+			Subject:   userID.String(),
+			ID:        uuid.New().String(),
+		},
+	}
+	signed, err := signToken(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign email revert token: %w", err)
+	}
+	return signed, nil
+}
+
+// ValidateEmailRevertToken validates a JWT created by CreateEmailRevertToken
+func ValidateEmailRevertToken(tokenString string) (*JWTClaims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email revert token: %w", err)
+	}
+	if claims.TokenType != "email_revert" {
+		return nil, errors.New("token is not an email revert token")
+	}
+	return claims, nil
+}
+
+// Token types of the short-lived tokens returned by login when a second factor is needed
+const (
+	MFAChallengeTokenType  = "mfa_challenge"  // user must enter a TOTP or recovery code
+	MFAEnrollmentTokenType = "mfa_enrollment" // user's role requires 2FA but no authenticator is set up yet
+)
+
+// GetMFAChallengeTokenExpiry retrieves the MFA challenge token expiry (minutes) from env
+func GetMFAChallengeTokenExpiry() time.Duration {
+	minStr := os.Getenv("JWT_MFA_CHALLENGE_EXPIRY_MINUTES")
+	if minStr == "" {
+		return 5 * time.Minute
+	}
+	minutes, err := strconv.Atoi(minStr)
+	if err != nil || minutes <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// CreateMFAChallengeToken creates a signed JWT proving the password step of a login succeeded.
+// tokenType is MFAChallengeTokenType or MFAEnrollmentTokenType.
+func CreateMFAChallengeToken(userID uuid.UUID, email, fursonaName, role, tokenType string) (string, error) {
+	claims := JWTClaims{
+		UserID:      userID.String(),
+		Email:       email,
+		FursonaName: fursonaName,
+		Role:        role,
+		TokenType:   tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(GetMFAChallengeTokenExpiry())),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "general-service",
+			Subject:   userID.String(),
+			ID:        uuid.New().String(),
+		},
+	}
+	signed, err := signToken(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign MFA challenge token: %w", err)
+	}
+	return signed, nil
+}
+
+// ValidateMFAChallengeToken validates a JWT created by CreateMFAChallengeToken with the given type
+func ValidateMFAChallengeToken(tokenString, tokenType string) (*JWTClaims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA challenge token: %w", err)
+	}
+	if claims.TokenType != tokenType {
+		return nil, errors.New("token is not an MFA challenge token")
+	}
+	return claims, nil
+}
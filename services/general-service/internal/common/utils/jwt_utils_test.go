@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAcceptHS256Token(t *testing.T) {
+	cutoff := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	grace := 7 * 24 * time.Hour // JWT_EMAIL_REVERT_EXPIRY_HOURS default, the longest-lived token
+
+	tests := []struct {
+		name     string
+		secret   string
+		cutoff   string
+		issuedAt time.Time
+		now      time.Time
+		want     bool
+	}{
+		{"issued before cutoff", "secret", cutoff.Format(time.RFC3339), cutoff.Add(-time.Minute), cutoff.Add(time.Minute), true},
+		{"issued after cutoff", "secret", cutoff.Format(time.RFC3339), cutoff.Add(time.Minute), cutoff.Add(2 * time.Minute), false},
+		{"grace period over", "secret", cutoff.Format(time.RFC3339), cutoff.Add(-time.Minute), cutoff.Add(grace + time.Second), false},
+		{"no cutoff configured", "secret", "", cutoff.Add(-time.Minute), cutoff.Add(time.Minute), false},
+		{"invalid cutoff", "secret", "yesterday", cutoff.Add(-time.Minute), cutoff.Add(time.Minute), false},
+		{"development fallback secret", "", cutoff.Format(time.RFC3339), cutoff.Add(-time.Minute), cutoff.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", tt.secret)
+			t.Setenv("JWT_HS256_CUTOFF", tt.cutoff)
+			claims := &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(tt.issuedAt)}}
+			if got := acceptHS256Token(claims, tt.now); got != tt.want {
+				t.Errorf("acceptHS256Token = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// magicLinkKeyPrefix keys pending magic-link logins by the SHA-256 of their token, so a Redis dump
+// does not contain usable links.
+const magicLinkKeyPrefix = "magic_link:"
+
+// magicLinkCooldownKeyPrefix keys the per-address resend cooldown by the SHA-256 of the email.
+const magicLinkCooldownKeyPrefix = "magic_link_cooldown:"
+
+// GetMagicLinkExpiry retrieves how long (minutes) a magic login link stays valid from env
+func GetMagicLinkExpiry() time.Duration {
+	minStr := os.Getenv("MAGIC_LINK_EXPIRY_MINUTES")
+	if minStr == "" {
+		return 15 * time.Minute
+	}
+	minutes, err := strconv.Atoi(minStr)
+	if err != nil || minutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetMagicLinkCooldown retrieves how long (seconds) to wait before another magic login link is
+// sent to the same email from env
+func GetMagicLinkCooldown() time.Duration {
+	secStr := os.Getenv("MAGIC_LINK_COOLDOWN_SECONDS")
+	if secStr == "" {
+		return 60 * time.Second
+	}
+	seconds, err := strconv.Atoi(secStr)
+	if err != nil || seconds < 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func magicLinkKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return magicLinkKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// StoreMagicLink stores the user a magic login token signs in until expiration
+// Returns an error if Redis is not available (magic links cannot be used without it)
+func StoreMagicLink(ctx context.Context, redisClient *redis.Client, token, userID string, expiration time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not available: cannot store magic link")
+	}
+	return redisClient.Set(ctx, magicLinkKey(token), userID, expiration).Err()
+}
+
+// TakeMagicLink returns and deletes the user ID stored for a magic login token, so each link
+// works once. Returns "" if the token is unknown, expired or already used.
+func TakeMagicLink(ctx context.Context, redisClient *redis.Client, token string) (string, error) {
+	if redisClient == nil {
+		return "", fmt.Errorf("redis client not available: cannot read magic link")
+	}
+	userID, err := redisClient.GetDel(ctx, magicLinkKey(token)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return userID, err
+}
+
+// StartMagicLinkCooldown starts the resend cooldown for email (already normalized). Returns false
+// if a link was requested for it within the cooldown, in which case no new link should be sent.
+// Returns an error if Redis is not available (magic links cannot be used without it)
+func StartMagicLinkCooldown(ctx context.Context, redisClient *redis.Client, email string, cooldown time.Duration) (bool, error) {
+	if redisClient == nil {
+		return false, fmt.Errorf("redis client not available: cannot check magic link cooldown")
+	}
+	if cooldown <= 0 {
+		return true, nil
+	}
+	sum := sha256.Sum256([]byte(email))
+	return redisClient.SetNX(ctx, magicLinkCooldownKeyPrefix+hex.EncodeToString(sum[:]), 1, cooldown).Result()
+}
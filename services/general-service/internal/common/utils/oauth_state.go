@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Key prefixes for external sign-in state: pending authorization requests (by OAuth state) and
+// verified provider profiles waiting for sign-up details (by registration token).
+const (
+	OAuthStateKeyPrefix        = "oauth:state:"
+	OAuthRegistrationKeyPrefix = "oauth:registration:"
+)
+
+// StoreOAuthState stores value under key until expiration
+// Returns an error if Redis is not available (provider logins cannot be completed without it)
+func StoreOAuthState(ctx context.Context, redisClient *redis.Client, key string, value []byte, expiration time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not available: cannot store sign-in state")
+	}
+	return redisClient.Set(ctx, key, value, expiration).Err()
+}
+
+// GetOAuthState returns the value stored under key. Returns nil if it is unknown or expired.
+func GetOAuthState(ctx context.Context, redisClient *redis.Client, key string) ([]byte, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not available: cannot read sign-in state")
+	}
+	value, err := redisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
+// TakeOAuthState returns and deletes the value stored under key, so it is used once. Returns nil
+// if it is unknown or expired.
+func TakeOAuthState(ctx context.Context, redisClient *redis.Client, key string) ([]byte, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not available: cannot read sign-in state")
+	}
+	value, err := redisClient.GetDel(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
+// DeleteOAuthState removes the value stored under key (no-op without Redis)
+func DeleteOAuthState(ctx context.Context, redisClient *redis.Client, key string) error {
+	if redisClient == nil {
+		return nil
+	}
+	return redisClient.Del(ctx, key).Err()
+}
@@ -4,6 +4,9 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 func GenerateOtp() (string, error) {
@@ -20,3 +23,9 @@ func GenerateOtp() (string, error) {
 	otp := randomUint32 % 1000000
 	return fmt.Sprintf("%06d", otp), nil
 }
+
+// EmailChangeOTPKey is the key (in place of an email in StoreOTP / VerifyAndDeleteOTP) of the code
+// confirming that userID receives mail at newEmail. It is separate from registration codes.
+func EmailChangeOTPKey(userID uuid.UUID, newEmail string) string {
+	return "email_change:" + userID.String() + ":" + strings.ToLower(newEmail)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEventEndDate retrieves the last day of the most recent convention (EVENT_END_DATE, YYYY-MM-DD,
+// ICT) from env. ok is false when it is unset or invalid; rules anchored on the event end then do
+// not run.
+func GetEventEndDate() (end time.Time, ok bool) {
+	value := strings.TrimSpace(os.Getenv("EVENT_END_DATE"))
+	if value == "" {
+		return time.Time{}, false
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.FixedZone("ICT", 7*60*60))
+	if err != nil {
+		return time.Time{}, false
+	}
+	// The whole last day belongs to the event.
+	return day.AddDate(0, 0, 1), true
+}
+
+// GetRetentionPeriod retrieves how long (days) data of a retention rule is kept from
+// RETENTION_<RULE> (e.g. RETENTION_USER_ID_CARD=60). "off" disables the rule (ok is false).
+func GetRetentionPeriod(rule string, defaultDays int) (days int, ok bool) {
+	value := strings.TrimSpace(os.Getenv("RETENTION_" + strings.ToUpper(rule)))
+	if value == "" {
+		return defaultDays, true
+	}
+	if strings.EqualFold(value, "off") {
+		return 0, false
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return defaultDays, true
+	}
+	return days, true
+}
+
+// IsRetentionPurgeEnabled reports whether the scheduled retention purge deletes data
+// (RETENTION_PURGE_ENABLED=true). Until then every run is a dry run.
+func IsRetentionPurgeEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("RETENTION_PURGE_ENABLED"))
+	return enabled
+}
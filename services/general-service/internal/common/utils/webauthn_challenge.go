@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WebAuthn ceremonies are keyed by their challenge, which the browser echoes back inside
+// clientDataJSON, so the finish request needs no separate ceremony ID.
+const webAuthnChallengeKeyPrefix = "webauthn:challenge:%s"
+
+// StoreWebAuthnChallenge stores the state of a pending ceremony under its challenge
+// Returns an error if Redis is not available (ceremonies cannot be verified without it)
+func StoreWebAuthnChallenge(ctx context.Context, redisClient *redis.Client, challenge string, state []byte, expiration time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not available: cannot store WebAuthn challenge")
+	}
+	return redisClient.Set(ctx, fmt.Sprintf(webAuthnChallengeKeyPrefix, challenge), state, expiration).Err()
+}
+
+// TakeWebAuthnChallenge returns and deletes the state stored for challenge, so each challenge is
+// used once. Returns nil state if it is unknown or expired.
+func TakeWebAuthnChallenge(ctx context.Context, redisClient *redis.Client, challenge string) ([]byte, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not available: cannot verify WebAuthn challenge")
+	}
+	state, err := redisClient.GetDel(ctx, fmt.Sprintf(webAuthnChallengeKeyPrefix, challenge)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return state, err
+}
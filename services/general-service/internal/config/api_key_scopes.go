@@ -0,0 +1,16 @@
+package config
+
+import "general-service/internal/common/constants"
+
+// APIKeyScopeRoutes maps each admin route a service account API key may call ("METHOD /path",
+// path after /admin as registered) to the scope it needs. Routes not listed here are refused to
+// API keys whatever their scopes; add a route only once it is safe for partner integrations.
+var APIKeyScopeRoutes = map[string]constants.APIKeyScope{
+	"GET /tickets":                constants.ScopeTicketsRead,
+	"GET /tickets/:id":            constants.ScopeTicketsRead,
+	"PATCH /tickets/:id/check-in": constants.ScopeCheckinWrite,
+	"GET /dealers":                constants.ScopeDealersRead,
+	"GET /dealers/:id":            constants.ScopeDealersRead,
+	"GET /users":                  constants.ScopeUsersRead,
+	"GET /users/:id":              constants.ScopeUsersRead,
+}
@@ -1,22 +1,23 @@
 package config
 
-// Cookie configuration
-type CookieConfig struct {
-	Domain   string
-	Secure   bool
-	SameSite string
-	MaxAge   int
-}
+import "general-service/internal/common/utils"
+
+// CookieConfig is the auth cookie configuration shared by the access and refresh cookies
+type CookieConfig = utils.CookieConfig
 
-// GetCookieConfig returns cookie configuration from environment
+// GetCookieConfig returns cookie configuration from environment. Cookie lifetimes follow the
+// JWT access and refresh token expiries. The refresh cookie path defaults to /v1/auth under the
+// route prefix, so behind API Gateway it is /api/general/v1/auth.
 func GetCookieConfig() CookieConfig {
-	secure := GetEnvOr("COOKIE_SECURE", "true") == "true"
-	sameSite := GetEnvOr("COOKIE_SAMESITE", "Strict") // Strict, Lax, or None
+	secure := GetEnvOr("COOKIE_SECURE", "true") != "false" // Default to true
+	sameSite := GetEnvOr("COOKIE_SAMESITE", "Strict")      // Strict, Lax, or None
 
 	return CookieConfig{
-		Domain:   GetEnvOr("COOKIE_DOMAIN", ""),
-		Secure:   secure,
-		SameSite: sameSite,
-		MaxAge:   3600, // 1 hour default
+		Domain:        GetEnvOr("COOKIE_DOMAIN", ""),
+		Secure:        secure,
+		SameSite:      sameSite,
+		MaxAge:        int(utils.GetAccessTokenExpiry().Seconds()),
+		RefreshMaxAge: int(utils.GetRefreshTokenExpiry().Seconds()),
+		RefreshPath:   GetEnvOr("COOKIE_REFRESH_PATH", RoutePrefix()+"/v1/auth"),
 	}
 }
@@ -2,13 +2,26 @@ package config
 
 import (
 	"fmt"
+	"general-service/internal/common/constants"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// LambdaRoutePrefix is the path API Gateway forwards to the Lambda deployment (ANY /api/general/{proxy+}).
+const LambdaRoutePrefix = "/api/general"
+
+// RoutePrefix returns the path the routes are mounted under: LambdaRoutePrefix in Lambda, none locally.
+func RoutePrefix() string {
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		return LambdaRoutePrefix
+	}
+	return ""
+}
+
 func LoadEnv() error {
 	// In AWS Lambda, environment variables are already set by the Lambda runtime
 	// Check if we're running in Lambda by looking for AWS_LAMBDA_FUNCTION_NAME
@@ -63,3 +76,23 @@ func GetLoginFailBlockMinutes() int {
 	}
 	return blockMinutes
 }
+
+// GetMFARequiredRoles returns the roles that must use two-factor authentication, from the
+// comma-separated MFA_REQUIRED_ROLES (default "admin,staff"). Unknown names are skipped.
+func GetMFARequiredRoles() []constants.UserRole {
+	value := GetEnvOr("MFA_REQUIRED_ROLES", "admin,staff")
+	var roles []constants.UserRole
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "none") {
+			continue
+		}
+		role, err := constants.ParseUserRole(name)
+		if err != nil {
+			fmt.Printf("WARNING: MFA_REQUIRED_ROLES: %v\n", err)
+			continue
+		}
+		roles = append(roles, role)
+	}
+	return roles
+}
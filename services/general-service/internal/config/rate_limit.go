@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"general-service/internal/middlewares"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate limit policy names. Routes using the same policy share its counters.
+const (
+	RateLimitAPI            = "api"             // every /v1 request, per IP
+	RateLimitLogin          = "login"           // password, passkey, provider and 2FA logins, per IP
+	RateLimitTokenRefresh   = "token-refresh"   // access token refresh, per IP
+	RateLimitRegister       = "register"        // account sign-up, per IP
+	RateLimitOTPSend        = "otp-send"        // requests that email a code or sign-in link, per IP
+	RateLimitOTPVerify      = "otp-verify"      // code checks, per IP
+	RateLimitPasswordReset  = "password-reset"  // forgot password and reset confirmation, per IP
+	RateLimitEmailChange    = "email-change"    // email change request and confirmation, per user
+	RateLimitTicketPurchase = "ticket-purchase" // ticket purchase, per user
+	RateLimitDataExport     = "data-export"     // personal data export requests, per user
+)
+
+// defaultRateLimitPolicies are used unless overridden with RATE_LIMIT_<NAME> (see GetRateLimitPolicy).
+var defaultRateLimitPolicies = map[string]middlewares.RateLimitPolicy{
+	RateLimitAPI:            {Limit: 300, Window: time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitLogin:          {Limit: 20, Window: 5 * time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitTokenRefresh:   {Limit: 60, Window: 5 * time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitRegister:       {Limit: 5, Window: time.Hour, Key: middlewares.RateLimitByIP},
+	RateLimitOTPSend:        {Limit: 5, Window: 15 * time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitOTPVerify:      {Limit: 10, Window: 10 * time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitPasswordReset:  {Limit: 5, Window: 15 * time.Minute, Key: middlewares.RateLimitByIP},
+	RateLimitEmailChange:    {Limit: 10, Window: time.Hour, Key: middlewares.RateLimitByUser},
+	RateLimitTicketPurchase: {Limit: 5, Window: time.Minute, Key: middlewares.RateLimitByUser},
+	RateLimitDataExport:     {Limit: 3, Window: 24 * time.Hour, Key: middlewares.RateLimitByUser},
+}
+
+// GetRateLimitPolicy returns the named policy. RATE_LIMIT_<NAME> (name upper-cased, "-" as "_")
+// overrides its limit as "<requests>/<window>", e.g. RATE_LIMIT_REGISTER=10/1h; "off" disables it.
+func GetRateLimitPolicy(name string) middlewares.RateLimitPolicy {
+	policy, ok := defaultRateLimitPolicies[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown rate limit policy %q", name))
+	}
+	policy.Name = name
+
+	envKey := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	value := strings.TrimSpace(GetEnvOr(envKey, ""))
+	if value == "" {
+		return policy
+	}
+	if strings.EqualFold(value, "off") {
+		policy.Limit = 0
+		return policy
+	}
+	limitStr, windowStr, found := strings.Cut(value, "/")
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	window, werr := time.ParseDuration(strings.TrimSpace(windowStr))
+	if !found || err != nil || werr != nil || limit <= 0 || window <= 0 {
+		fmt.Printf("WARNING: %s: expected <requests>/<window> like 10/1m, using %d/%s\n", envKey, policy.Limit, policy.Window)
+		return policy
+	}
+	policy.Limit = limit
+	policy.Window = window
+	return policy
+}
+
+// GetRateLimitAllowlist returns the networks that are never rate limited, from the
+// comma-separated IPs or CIDRs in RATE_LIMIT_ALLOWLIST (e.g. the venue network of the staff
+// check-in devices). Invalid entries are skipped.
+func GetRateLimitAllowlist() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(GetEnvOr("RATE_LIMIT_ALLOWLIST", ""), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			fmt.Printf("WARNING: RATE_LIMIT_ALLOWLIST: %v\n", err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// GetTrustedProxies returns the proxies (IPs or CIDRs, TRUSTED_PROXIES) whose X-Forwarded-For
+// and X-Real-IP headers gin uses for the client IP. None by default: in Lambda the request's
+// remote address is already API Gateway's sourceIp, the real client, and locally clients connect
+// directly, so a forwarded header can only be forged. The client IP keys the per-IP rate limits
+// and RATE_LIMIT_ALLOWLIST, and is recorded in sessions, audit logs and fraud fingerprints.
+func GetTrustedProxies() []string {
+	var proxies []string
+	for _, entry := range strings.Split(GetEnvOr("TRUSTED_PROXIES", ""), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			proxies = append(proxies, entry)
+		}
+	}
+	return proxies
+}
@@ -29,30 +29,70 @@ func CheckHealth(c *gin.Context) {
 	c.JSON(200, common.SuccessResponse(&healthData, "Service is healthy", 200))
 }
 
+// rateLimit returns the middleware enforcing the named rate limit policy
+func rateLimit(name string) gin.HandlerFunc {
+	return middlewares.RateLimit(GetRateLimitPolicy(name))
+}
+
 func SetupAuthRoutes(router *gin.RouterGroup, h *handlers.Handlers) {
 	auth := router.Group("/auth")
 	{
-		auth.POST("/register", h.Auth.Register)
-		auth.POST("/login", h.Auth.Login)
-		auth.POST("/google", h.Auth.GoogleLogin)
+		auth.POST("/register", rateLimit(RateLimitRegister), h.Auth.Register)
+		auth.POST("/login", rateLimit(RateLimitLogin), h.Auth.Login)
+		auth.POST("/google", rateLimit(RateLimitLogin), h.Auth.GoogleLogin)
+		auth.POST("/telegram", rateLimit(RateLimitLogin), h.Auth.TelegramLogin)
+		auth.GET("/oauth/providers", h.Auth.GetOAuthProviders)
+		auth.POST("/oauth/register", rateLimit(RateLimitRegister), h.Auth.CompleteOAuthRegistration)
+		auth.POST("/oauth/:provider/authorize", h.Auth.BeginOAuthLogin)
+		auth.POST("/oauth/:provider/callback", rateLimit(RateLimitLogin), h.Auth.OAuthCallback)
+		auth.POST("/refresh", rateLimit(RateLimitTokenRefresh), h.Auth.Refresh)
+		auth.POST("/mfa/verify", rateLimit(RateLimitLogin), h.Auth.VerifyMFA)
+		auth.POST("/mfa/enroll", h.Auth.EnrollMFA)
+		auth.POST("/mfa/enroll/confirm", rateLimit(RateLimitLogin), h.Auth.ConfirmMFAEnrollment)
+		auth.POST("/mfa/passkey/begin", h.Auth.BeginPasskeyMFA)
+		auth.POST("/mfa/passkey/finish", rateLimit(RateLimitLogin), h.Auth.FinishPasskeyMFA)
+		auth.POST("/passkey/login/begin", h.Auth.BeginPasskeyLogin)
+		auth.POST("/passkey/login/finish", rateLimit(RateLimitLogin), h.Auth.FinishPasskeyLogin)
 		auth.POST("/logout", middlewares.JWTAuthMiddleware(), h.Auth.Logout)
 
 		//add jwt auth
-		auth.POST("/reset-password", middlewares.JWTAuthMiddleware(), h.Auth.ResetPassword)
-		auth.POST("/verify-otp", h.Auth.VerifyOtp)
-		auth.POST("/resend-otp", h.Auth.ResendOtp)
+		auth.POST("/reset-password", middlewares.JWTAuthMiddleware(), middlewares.DenyWhileImpersonating(), h.Auth.ResetPassword)
+		auth.POST("/verify-otp", rateLimit(RateLimitOTPVerify), h.Auth.VerifyOtp)
+		auth.POST("/resend-otp", rateLimit(RateLimitOTPSend), h.Auth.ResendOtp)
+
+		auth.POST("/magic-link", rateLimit(RateLimitOTPSend), h.Auth.RequestMagicLink)
+		auth.POST("/magic-link/verify", rateLimit(RateLimitLogin), h.Auth.MagicLinkLogin)
 
-		auth.POST("/forgot-password", h.Auth.ForgotPassword)
-		auth.POST("/reset-password/confirm", h.Auth.ResetPasswordConfirm)
+		auth.POST("/forgot-password", rateLimit(RateLimitPasswordReset), h.Auth.ForgotPassword)
+		auth.POST("/reset-password/confirm", rateLimit(RateLimitPasswordReset), h.Auth.ResetPasswordConfirm)
+		auth.POST("/email/revert", rateLimit(RateLimitPasswordReset), h.Auth.RevertEmailChange)
 	}
 }
 
+// SetupWellKnownRoutes registers public discovery documents. They are registered before the
+// internal API key middleware so other services and JWT libraries can fetch them without it.
+func SetupWellKnownRoutes(router gin.IRouter, h *handlers.Handlers) {
+	router.GET("/.well-known/jwks.json", h.Auth.GetJWKS)
+}
+
 func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos *repositories.Repositories, redisSetFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error) {
 	// Internal job endpoint (called by SQS worker) - no /v1 prefix for clarity
-	// INTERNAL_API_KEY is enforced at router level in main.go for all APIs
+	// INTERNAL_API_KEY is enforced at router level in main.go for all APIs; internal jobs must
+	// also carry an HMAC signature (JOB_SIGNING_KEYS)
 	internal := router.Group("/internal")
+	internal.Use(middlewares.InternalJobSignatureMiddleware())
 	{
 		internal.POST("/jobs/ticket", h.Ticket.ProcessTicketJob)
+		internal.POST("/jobs/bulk-approve", h.Ticket.ProcessBulkApproveJob)
+		internal.POST("/jobs/blacklist-sign-out", h.Ticket.ProcessBlacklistSignOutJob)
+		// Scheduled jobs that need mail (triggered by the sqs-worker scheduler)
+		internal.POST("/jobs/payment-reminders", h.Ticket.ProcessPaymentRemindersJob)
+		internal.POST("/jobs/weekly-stats", h.Analytics.ProcessWeeklyStatsJob)
+		internal.POST("/jobs/account-deletions", h.User.ProcessAccountDeletionsJob)
+		internal.POST("/jobs/data-export", h.User.ProcessDataExportJob)
+		internal.POST("/jobs/retention-purge", h.Retention.ProcessRetentionPurgeJob)
+		internal.POST("/jobs/impersonation-notices", h.Impersonation.ProcessImpersonationNoticesJob)
+		internal.POST("/jobs/fraud-screening", h.Fraud.ProcessFraudScreeningJob)
 	}
 
 	// Root endpoint
@@ -92,10 +132,14 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 	})
 
 	v1 := router.Group("/v1")
+	v1.Use(rateLimit(RateLimitAPI))
 	{
 		v1.GET("/ping", CheckHealth)
 		SetupAuthRoutes(v1, h)
 
+		// Personal data export download - the emailed token is the credential
+		v1.GET("/users/export/download", h.User.DownloadDataExport)
+
 		// Public ticket routes (optional JWT so admins can get normalized tier payloads, e.g. is_active)
 		tickets := v1.Group("/tickets")
 		tickets.Use(middlewares.OptionalJWTAuthMiddleware())
@@ -108,6 +152,8 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 		protected := v1.Group("")
 		protected.Use(middlewares.JWTAuthMiddleware())
 		protected.Use(middlewares.RequireVerifiedOrWhitelist(repos.User))
+		// Sensitive actions an admin impersonating the user must not take
+		noImpersonation := middlewares.DenyWhileImpersonating()
 		{
 			// User routes
 			users := protected.Group("/users")
@@ -115,6 +161,32 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 				users.GET("/me", h.User.GetMe)
 				users.PUT("/me", h.User.UpdateProfile)
 				users.PATCH("/me/avatar", h.User.UpdateAvatar)
+				users.GET("/me/sessions", h.User.GetMySessions)
+				users.DELETE("/me/sessions", noImpersonation, h.User.RevokeMyOtherSessions)
+				users.DELETE("/me/sessions/:id", noImpersonation, h.User.RevokeMySession)
+				users.GET("/me/mfa", h.User.GetMyMFA)
+				users.DELETE("/me/mfa", noImpersonation, h.User.DisableMyMFA)
+				users.POST("/me/mfa/totp", noImpersonation, h.User.SetupMyTOTP)
+				users.POST("/me/mfa/totp/confirm", noImpersonation, h.User.ConfirmMyTOTP)
+				users.POST("/me/mfa/recovery-codes", noImpersonation, h.User.RegenerateMyRecoveryCodes)
+				users.GET("/me/passkeys", h.User.GetMyPasskeys)
+				users.POST("/me/passkeys/register/begin", noImpersonation, h.User.BeginMyPasskeyRegistration)
+				users.POST("/me/passkeys/register/finish", noImpersonation, h.User.FinishMyPasskeyRegistration)
+				users.DELETE("/me/passkeys/:id", noImpersonation, h.User.DeleteMyPasskey)
+				users.GET("/me/login-methods", h.User.GetMyLoginMethods)
+				users.POST("/me/password", noImpersonation, h.User.SetMyPassword)
+				users.POST("/me/email", noImpersonation, rateLimit(RateLimitEmailChange), h.User.RequestMyEmailChange)
+				users.POST("/me/email/confirm", noImpersonation, rateLimit(RateLimitEmailChange), h.User.ConfirmMyEmailChange)
+				users.POST("/me/identities/google", noImpersonation, h.User.LinkMyGoogle)
+				users.POST("/me/identities/telegram", noImpersonation, h.User.LinkMyTelegram)
+				users.POST("/me/identities/:provider/authorize", noImpersonation, h.User.BeginMyIdentityLink)
+				users.POST("/me/identities/:provider/callback", noImpersonation, h.User.FinishMyIdentityLink)
+				users.DELETE("/me/identities/:id", noImpersonation, h.User.UnlinkMyIdentity)
+				users.GET("/me/export", noImpersonation, h.User.GetMyDataExport)
+				users.POST("/me/export", noImpersonation, rateLimit(RateLimitDataExport), h.User.RequestMyDataExport)
+				users.GET("/me/deletion", h.User.GetMyAccountDeletion)
+				users.POST("/me/deletion", noImpersonation, h.User.RequestMyAccountDeletion)
+				users.DELETE("/me/deletion", noImpersonation, h.User.CancelMyAccountDeletion)
 			}
 
 			// Dealer routes
@@ -132,11 +204,11 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 			protectedTickets := protected.Group("/tickets")
 			{
 				protectedTickets.GET("/me", h.Ticket.GetMyTicket)
-				protectedTickets.POST("/purchase", h.Ticket.PurchaseTicket)
-				protectedTickets.PATCH("/me/confirm", h.Ticket.ConfirmPayment)
-				protectedTickets.DELETE("/me/cancel", h.Ticket.CancelTicket)
+				protectedTickets.POST("/purchase", noImpersonation, rateLimit(RateLimitTicketPurchase), h.Ticket.PurchaseTicket)
+				protectedTickets.PATCH("/me/confirm", noImpersonation, h.Ticket.ConfirmPayment)
+				protectedTickets.DELETE("/me/cancel", noImpersonation, h.Ticket.CancelTicket)
 				protectedTickets.PATCH("/me/badge", h.Ticket.UpdateBadgeDetails)
-				protectedTickets.PATCH("/me/upgrade", h.Ticket.UpgradeTicket)
+				protectedTickets.PATCH("/me/upgrade", noImpersonation, h.Ticket.UpgradeTicket)
 			}
 
 			// Protected conbook routes (require auth)
@@ -168,9 +240,11 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 			}
 		}
 
-		// Admin routes - require JWT; role enforced per subgroup (admin, or admin+staff for ticket get/approve)
+		// Admin routes - require JWT; role enforced per subgroup (admin, or admin+staff for ticket get/approve).
+		// Service account API keys may call the routes of their scopes (APIKeyScopeRoutes) instead.
+		// Every mutating request is written to the admin audit log.
 		admin := v1.Group("/admin")
-		admin.Use(middlewares.JWTAuthMiddleware())
+		admin.Use(middlewares.JWTOrAPIKeyAuthMiddleware(APIKeyScopeRoutes), middlewares.AdminAudit())
 		{
 			// Admin-only user management
 			adminUsers := admin.Group("/users")
@@ -179,9 +253,14 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 				adminUsers.GET("", h.User.GetAllUsers)
 				adminUsers.GET("/statistics/count-by-country", h.User.GetUserCountByCountry)
 				adminUsers.GET("/statistics/count-by-age-range", h.User.GetUserCountByAgeRange)
+				adminUsers.GET("/deletions", h.User.GetAccountDeletions)
+				adminUsers.PATCH("/deletions/:id/hold", h.User.HoldAccountDeletion)
+				adminUsers.PATCH("/deletions/:id/release", h.User.ReleaseAccountDeletion)
 				adminUsers.GET("/:id", h.User.GetUserByIDForAdmin)
 				adminUsers.PUT("/:id", h.User.UpdateUserByAdmin)
 				adminUsers.DELETE("/:id", h.User.DeleteUser)
+				adminUsers.DELETE("/:id/sessions", h.User.RevokeUserSessions)
+				adminUsers.DELETE("/:id/mfa", h.User.ResetUserMFA)
 				adminUsers.PATCH("/:id/verify", h.User.VerifyUser)
 				adminUsers.GET("/blacklisted", h.Ticket.GetBlacklistedUsers)
 				adminUsers.PATCH("/:id/blacklist", h.Ticket.BlacklistUser)
@@ -204,6 +283,7 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 				adminTickets.PATCH("/tiers/:id/activate", h.Ticket.ActivateTierForAdmin)
 				adminTickets.PATCH("/tiers/:id/deactivate", h.Ticket.DeactivateTierForAdmin)
 				adminTickets.PATCH("/tiers/:id/visibility", h.Ticket.SetTierVisibleForAdmin)
+				adminTickets.POST("/bulk-approve", middlewares.AuditAction("tickets.bulk_approve"), h.Ticket.BulkApproveTickets)
 				adminTickets.PATCH("/:id/deny", h.Ticket.DenyTicket)
 				adminTickets.PATCH("/:id", h.Ticket.UpdateTicketForAdmin)
 				adminTickets.DELETE("/:id", h.Ticket.DeleteTicketForAdmin)
@@ -270,6 +350,63 @@ func SetupAPIRoutes(router gin.IRouter, h *handlers.Handlers, db *gorm.DB, repos
 			{
 				adminAnalytics.GET("/dashboard", h.Analytics.GetDashboard)
 			}
+
+			// Admin-only status of sqs-worker scheduled jobs
+			adminScheduledJobs := admin.Group("/scheduled-jobs")
+			adminScheduledJobs.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminScheduledJobs.GET("", h.Analytics.GetScheduledJobs)
+			}
+
+			// Admin-only data retention policy: rules, dry-run report and purge audit log
+			adminRetention := admin.Group("/retention")
+			adminRetention.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminRetention.GET("/rules", h.Retention.GetRetentionRules)
+				adminRetention.POST("/dry-run", middlewares.AuditAction("retention.dry_run"), h.Retention.RunRetentionDryRun)
+				adminRetention.GET("/logs", h.Retention.GetRetentionLogs)
+			}
+
+			// Admin-only impersonation sessions: list, start and end
+			adminImpersonations := admin.Group("/impersonations")
+			adminImpersonations.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminImpersonations.GET("", h.Impersonation.GetImpersonations)
+				adminImpersonations.POST("", h.Impersonation.StartImpersonation)
+				adminImpersonations.POST("/:id/end", h.Impersonation.EndImpersonation)
+			}
+
+			// Admin-only service accounts and their scoped API keys: create, disable, issue, rotate and revoke
+			adminServiceAccounts := admin.Group("/service-accounts")
+			adminServiceAccounts.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminServiceAccounts.GET("", h.ServiceAccount.GetServiceAccounts)
+				adminServiceAccounts.POST("", h.ServiceAccount.CreateServiceAccount)
+				adminServiceAccounts.GET("/:id", h.ServiceAccount.GetServiceAccount)
+				adminServiceAccounts.POST("/:id/disable", h.ServiceAccount.DisableServiceAccount)
+				adminServiceAccounts.POST("/:id/keys", middlewares.AuditAction("service_accounts.keys.create"), h.ServiceAccount.CreateAPIKey)
+				adminServiceAccounts.POST("/:id/keys/:keyId/rotate", h.ServiceAccount.RotateAPIKey)
+				adminServiceAccounts.DELETE("/:id/keys/:keyId", middlewares.AuditAction("service_accounts.keys.revoke"), h.ServiceAccount.RevokeAPIKey)
+			}
+
+			// Admin-only fraud review: flagged purchase assessments, clear or confirm (optionally blacklisting)
+			adminFraud := admin.Group("/fraud")
+			adminFraud.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminFraud.GET("/assessments", h.Fraud.GetFraudAssessments)
+				adminFraud.GET("/assessments/:id", h.Fraud.GetFraudAssessment)
+				adminFraud.PATCH("/assessments/:id/clear", h.Fraud.ClearFraudAssessment)
+				adminFraud.PATCH("/assessments/:id/confirm", h.Fraud.ConfirmFraudAssessment)
+			}
+
+			// Admin-only audit log of privileged actions: query, CSV export and hash chain check
+			adminAuditLogs := admin.Group("/audit-logs")
+			adminAuditLogs.Use(middlewares.RequireRole(role.RoleAdmin))
+			{
+				adminAuditLogs.GET("", h.Audit.GetAuditLogs)
+				adminAuditLogs.GET("/export", h.Audit.ExportAuditLogs)
+				adminAuditLogs.GET("/verify", h.Audit.VerifyAuditLogChain)
+			}
 		}
 
 		// Dev-only: send a test email (OTP / dealer approved / ticket+QR) without going through auth flows
@@ -3,16 +3,12 @@ package database
 import (
 	"fmt"
 	"log"
-	"os"
-	"strings"
 
 	"general-service/internal/security"
 
 	"gorm.io/gorm"
 )
 
-const encryptedValuePrefix = "v1:"
-
 type userPIIRow struct {
 	Id        string `gorm:"column:id"`
 	FirstName string `gorm:"column:first_name"`
@@ -21,21 +17,16 @@ type userPIIRow struct {
 }
 
 // migrateEncryptExistingUserPII encrypts legacy plaintext PII values already stored in users.
-// It is idempotent: values with prefix "v1:" are considered already encrypted.
+// It is idempotent: values with prefix "v1:" or "v2:" are considered already encrypted.
 func migrateEncryptExistingUserPII(db *gorm.DB) error {
 	if !db.Migrator().HasTable("users") {
 		log.Println("users table doesn't exist yet, skipping user PII encryption migration")
 		return nil
 	}
 
-	keyB64 := os.Getenv(userPIIKeyEnv)
-	key, err := security.DecodeBase64Key(keyB64)
-	if err != nil {
-		return fmt.Errorf("failed to decode %s for migration: %w", userPIIKeyEnv, err)
-	}
-	c, err := security.NewAESCipher(key)
+	c, err := LoadUserPIIKeyRing()
 	if err != nil {
-		return fmt.Errorf("failed to initialize AES cipher for migration: %w", err)
+		return err
 	}
 
 	rawDB := db.Session(&gorm.Session{SkipHooks: true})
@@ -95,8 +86,8 @@ func migrateEncryptExistingUserPII(db *gorm.DB) error {
 	return nil
 }
 
-func encryptIfPlain(c *security.AESCipher, value string) (string, bool, error) {
-	if value == "" || strings.HasPrefix(value, encryptedValuePrefix) {
+func encryptIfPlain(c *security.AESKeyRing, value string) (string, bool, error) {
+	if value == "" || security.IsEncrypted(value) {
 		return value, false, nil
 	}
 	encrypted, err := c.EncryptString(value)
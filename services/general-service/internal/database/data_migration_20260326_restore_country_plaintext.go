@@ -3,8 +3,6 @@ package database
 import (
 	"fmt"
 	"log"
-	"os"
-	"strings"
 
 	"general-service/internal/security"
 
@@ -17,21 +15,16 @@ type userCountryRow struct {
 }
 
 // migrateRestoreCountryPlaintext decrypts users.country back to plaintext.
-// It is safe to run multiple times: only values prefixed with "v1:" are decrypted.
+// It is safe to run multiple times: only encrypted values ("v1:"/"v2:" prefix) are decrypted.
 func migrateRestoreCountryPlaintext(db *gorm.DB) error {
 	if !db.Migrator().HasTable("users") {
 		log.Println("users table doesn't exist yet, skipping country restore migration")
 		return nil
 	}
 
-	keyB64 := os.Getenv(userPIIKeyEnv)
-	key, err := security.DecodeBase64Key(keyB64)
+	c, err := LoadUserPIIKeyRing()
 	if err != nil {
-		return fmt.Errorf("failed to decode %s for country restore migration: %w", userPIIKeyEnv, err)
-	}
-	c, err := security.NewAESCipher(key)
-	if err != nil {
-		return fmt.Errorf("failed to initialize AES cipher for country restore migration: %w", err)
+		return err
 	}
 
 	rawDB := db.Session(&gorm.Session{SkipHooks: true})
@@ -44,7 +37,7 @@ func migrateRestoreCountryPlaintext(db *gorm.DB) error {
 		}
 
 		for _, row := range rows {
-			if row.Country == "" || !strings.HasPrefix(row.Country, encryptedValuePrefix) {
+			if row.Country == "" || !security.IsEncrypted(row.Country) {
 				continue
 			}
 
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// migrateGoogleIdsToUserIdentities copies users.google_id into user_identities, idempotently.
+// It must run before dropUnusedColumns, which removes google_id now that User no longer has it.
+func migrateGoogleIdsToUserIdentities(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("users") || !migrator.HasColumn("users", "google_id") {
+		return nil
+	}
+
+	result := db.Exec(`
+		INSERT INTO user_identities (id, user_id, provider, subject, email, linked_at)
+		SELECT gen_random_uuid(), id, 'google', google_id, email, COALESCE(modified_at, created_at, NOW())
+		FROM users
+		WHERE google_id IS NOT NULL AND google_id <> ''
+		ON CONFLICT (provider, subject) DO NOTHING;
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to copy users.google_id into user_identities: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Copied %d Google IDs from users.google_id into user_identities", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"general-service/internal/security"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// migratePasskeyKeysToCOSE rewrites passkey public keys stored as PKIX DER into the COSE_Key
+// go-webauthn verifies assertions with. A DER key starts with a SEQUENCE tag (0x30) and a COSE_Key
+// with a CBOR map, so converted rows are skipped and it only does work once.
+func migratePasskeyKeysToCOSE(db *gorm.DB) error {
+	if !db.Migrator().HasTable("web_authn_credentials") {
+		return nil
+	}
+
+	var rows []struct {
+		Id        uuid.UUID
+		PublicKey []byte
+		Algorithm int
+	}
+	if err := db.Table("web_authn_credentials").Select("id", "public_key", "algorithm").Find(&rows).Error; err != nil {
+		return err
+	}
+	var converted int
+	for _, row := range rows {
+		if len(row.PublicKey) == 0 || row.PublicKey[0] != 0x30 {
+			continue
+		}
+		coseKey, err := security.COSEKeyFromPKIX(row.PublicKey, row.Algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to convert the public key of passkey %s: %w", row.Id, err)
+		}
+		if err := db.Table("web_authn_credentials").Where("id = ?", row.Id).Update("public_key", coseKey).Error; err != nil {
+			return fmt.Errorf("failed to update the public key of passkey %s: %w", row.Id, err)
+		}
+		converted++
+	}
+
+	if converted > 0 {
+		log.Printf("Converted %d passkey public keys to COSE", converted)
+	}
+	return nil
+}
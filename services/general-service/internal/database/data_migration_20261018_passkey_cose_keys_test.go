@@ -0,0 +1,60 @@
+package database
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	"general-service/internal/models"
+	"general-service/internal/security"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestMigratePasskeyKeysToCOSE(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.WebAuthnCredential{}); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := security.COSEKeyFromPKIX(der, security.COSEAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := models.WebAuthnCredential{Id: uuid.New(), UserId: uuid.New(), CredentialId: "legacy", PublicKey: der, Algorithm: security.COSEAlgES256}
+	current := models.WebAuthnCredential{Id: uuid.New(), UserId: uuid.New(), CredentialId: "current", PublicKey: want, Algorithm: security.COSEAlgES256}
+	if err := db.Create([]*models.WebAuthnCredential{&legacy, &current}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := migratePasskeyKeysToCOSE(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []uuid.UUID{legacy.Id, current.Id} {
+		var got models.WebAuthnCredential
+		if err := db.First(&got, "id = ?", id).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.PublicKey, want) {
+			t.Errorf("passkey %s: public key %x, want the COSE key %x", got.CredentialId, got.PublicKey, want)
+		}
+	}
+}
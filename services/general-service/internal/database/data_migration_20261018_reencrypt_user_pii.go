@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"general-service/internal/models"
+	"general-service/internal/security"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PIIReencryptionJobName is the scheduled_job_runs row ReencryptUserPII reports its progress in,
+// so it shows up in the admin scheduled jobs list.
+const PIIReencryptionJobName = "pii-reencryption"
+
+// piiKeyColumns is the primary key of each table with encrypted columns (see piiColumns).
+var piiKeyColumns = map[string]string{
+	"users":        "id",
+	"user_mfa":     "user_id",
+	"data_exports": "id",
+}
+
+// piiReencryptionTableProgress counts the rows of one table. Skipped rows changed while they were
+// re-encrypted and are picked up by the next run.
+type piiReencryptionTableProgress struct {
+	Total   int64 `json:"total"`
+	Done    int64 `json:"done"`
+	Skipped int64 `json:"skipped"`
+}
+
+type piiReencryptionProgress struct {
+	ActiveKeyID  string                                   `json:"active_key_id"`
+	Tables       map[string]*piiReencryptionTableProgress `json:"tables"`
+	KeyIDsInUse  []string                                 `json:"key_ids_in_use,omitempty"`
+	StartedAt    time.Time                                `json:"-"`
+	lastReported time.Time
+}
+
+// ReencryptUserPII re-encrypts every stored PII value that is not encrypted with the active key
+// of the user PII key ring, batchSize rows at a time. Each row is updated only if it is unchanged
+// since it was read, so it can run next to the API. It is resumable: rows already on the active
+// key are never selected again, so a stopped run (ctx cancelled) just continues where it was.
+// Progress is logged and stored in the PIIReencryptionJobName scheduled job run.
+func ReencryptUserPII(ctx context.Context, db *gorm.DB, batchSize int) error {
+	ring, err := LoadUserPIIKeyRing()
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	rawDB := db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true})
+	progress := &piiReencryptionProgress{
+		ActiveKeyID: ring.ActiveKeyID(),
+		Tables:      make(map[string]*piiReencryptionTableProgress),
+		StartedAt:   time.Now(),
+	}
+	log.Printf("PII re-encryption: encrypting with key %q", ring.ActiveKeyID())
+	recordPIIReencryption(db, progress, models.ScheduledJobStatusRunning, nil)
+
+	for _, table := range piiTables {
+		if err := reencryptPIITable(ctx, rawDB, ring, table, batchSize, progress); err != nil {
+			recordPIIReencryption(db, progress, models.ScheduledJobStatusFailed, err)
+			return err
+		}
+	}
+
+	for _, table := range piiTables {
+		ids, err := piiKeyIDsInUse(rawDB, table)
+		if err != nil {
+			recordPIIReencryption(db, progress, models.ScheduledJobStatusFailed, err)
+			return fmt.Errorf("failed to list PII key IDs in %s: %w", table, err)
+		}
+		for _, id := range ids {
+			if !slices.Contains(progress.KeyIDsInUse, id) {
+				progress.KeyIDsInUse = append(progress.KeyIDsInUse, id)
+			}
+		}
+	}
+	recordPIIReencryption(db, progress, models.ScheduledJobStatusSucceeded, nil)
+
+	var skipped int64
+	for _, p := range progress.Tables {
+		skipped += p.Skipped
+	}
+	log.Printf("PII re-encryption finished; keys in use: %s", strings.Join(progress.KeyIDsInUse, ", "))
+	if skipped > 0 {
+		log.Printf("PII re-encryption: %d rows changed while running, run it again to finish them", skipped)
+	}
+	return nil
+}
+
+func reencryptPIITable(ctx context.Context, db *gorm.DB, ring *security.AESKeyRing, table string, batchSize int, progress *piiReencryptionProgress) error {
+	if !db.Migrator().HasTable(table) {
+		return nil
+	}
+	keyColumn := piiKeyColumns[table]
+	columns := piiColumns[table]
+	stale := staleEncryptionCondition(ring, columns)
+
+	tableProgress := &piiReencryptionTableProgress{}
+	progress.Tables[table] = tableProgress
+	if err := db.Table(table).Where(stale).Count(&tableProgress.Total).Error; err != nil {
+		return fmt.Errorf("failed to count %s rows to re-encrypt: %w", table, err)
+	}
+	if tableProgress.Total == 0 {
+		return nil
+	}
+
+	lastKey := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("PII re-encryption stopped at %s %d/%d: %w", table, tableProgress.Done, tableProgress.Total, err)
+		}
+
+		query := db.Table(table).Select(append([]string{keyColumn + "::text AS row_key"}, columns...)).Where(stale)
+		if lastKey != "" {
+			query = query.Where(keyColumn+" > ?", lastKey)
+		}
+		var rows []map[string]any
+		if err := query.Order(keyColumn).Limit(batchSize).Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to load %s rows to re-encrypt: %w", table, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			lastKey, _ = row["row_key"].(string)
+			update := db.Table(table).Where(keyColumn+" = ?", lastKey)
+			updates := map[string]any{}
+			for _, column := range columns {
+				value, ok := row[column].(string)
+				if !ok {
+					update = update.Where(column + " IS NULL")
+					continue
+				}
+				update = update.Where(column+" = ?", value)
+				if ring.IsCurrent(value) {
+					continue
+				}
+				plaintext, err := ring.DecryptString(value)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt %s.%s of %s: %w", table, column, lastKey, err)
+				}
+				if updates[column], err = ring.EncryptString(plaintext); err != nil {
+					return err
+				}
+			}
+
+			result := update.Updates(updates)
+			if result.Error != nil {
+				return fmt.Errorf("failed to re-encrypt %s row %s: %w", table, lastKey, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				tableProgress.Skipped++
+			} else {
+				tableProgress.Done++
+			}
+		}
+
+		log.Printf("PII re-encryption: %s %d/%d", table, tableProgress.Done+tableProgress.Skipped, tableProgress.Total)
+		if time.Since(progress.lastReported) > 10*time.Second {
+			recordPIIReencryption(db, progress, models.ScheduledJobStatusRunning, nil)
+		}
+	}
+	return nil
+}
+
+// staleEncryptionCondition matches rows with a value in columns encrypted with a key other than
+// the active one.
+func staleEncryptionCondition(ring *security.AESKeyRing, columns []string) clause.Expr {
+	current := "v2:" + ring.ActiveKeyID() + ":%"
+	if ring.ActiveKeyID() == security.LegacyAESKeyID {
+		current = "v1:%"
+	}
+	conditions := make([]string, len(columns))
+	vars := make([]any, 0, len(columns))
+	for i, column := range columns {
+		conditions[i] = "((" + column + " LIKE 'v1:%' OR " + column + " LIKE 'v2:%') AND " + column + " NOT LIKE ?)"
+		vars = append(vars, current)
+	}
+	return gorm.Expr("("+strings.Join(conditions, " OR ")+")", vars...)
+}
+
+// recordPIIReencryption upserts the job run row. Failing to record progress does not stop the
+// re-encryption.
+func recordPIIReencryption(db *gorm.DB, progress *piiReencryptionProgress, status models.ScheduledJobStatus, runErr error) {
+	now := time.Now()
+	progress.lastReported = now
+	result, _ := json.Marshal(progress)
+	run := models.ScheduledJobRun{
+		Name:           PIIReencryptionJobName,
+		Schedule:       "manual",
+		LastStatus:     status,
+		LastStartedAt:  &progress.StartedAt,
+		LastDurationMs: now.Sub(progress.StartedAt).Milliseconds(),
+		LastResult:     string(result),
+	}
+	columns := []string{"schedule", "last_status", "last_started_at", "last_duration_ms", "last_result", "last_error", "last_finished_at", "modified_at"}
+	switch status {
+	case models.ScheduledJobStatusSucceeded:
+		run.LastFinishedAt = &now
+		run.LastSuccessAt = &now
+		columns = append(columns, "last_success_at")
+	case models.ScheduledJobStatusFailed:
+		run.LastFinishedAt = &now
+		run.LastError = runErr.Error()
+	}
+	err := db.Session(&gorm.Session{Context: context.Background()}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&run).Error
+	if err != nil {
+		log.Printf("[WARN] Failed to record PII re-encryption progress: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"general-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const blindIndexBackfillBatchSize = 500
+
+// migrateBackfillUserPIIBlindIndex fills id_card_index and name_index for users written before
+// the blind indexes existed. Rows with name_index set are skipped, so it only does work once.
+func migrateBackfillUserPIIBlindIndex(db *gorm.DB) error {
+	if !db.Migrator().HasTable("users") {
+		return nil
+	}
+
+	c, err := LoadUserPIIKeyRing()
+	if err != nil {
+		return err
+	}
+	index, err := LoadUserPIIBlindIndex()
+	if err != nil {
+		return err
+	}
+
+	rawDB := db.Session(&gorm.Session{SkipHooks: true})
+	var totalUpdated int64
+	var rows []userPIIRow
+	result := rawDB.Table("users").Select("id", "first_name", "last_name", "id_card").
+		Where("name_index IS NULL").
+		FindInBatches(&rows, blindIndexBackfillBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, row := range rows {
+				firstName, err := c.DecryptString(row.FirstName)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt first name of user %s: %w", row.Id, err)
+				}
+				lastName, err := c.DecryptString(row.LastName)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt last name of user %s: %w", row.Id, err)
+				}
+				idCard, err := c.DecryptString(row.IdCard)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt ID card of user %s: %w", row.Id, err)
+				}
+
+				err = rawDB.Table("users").Where("id = ?", row.Id).Updates(map[string]any{
+					"id_card_index": index.IDCard(idCard),
+					"name_index":    models.BlindIndexTokens(index.NameTokens(firstName, lastName)),
+				}).Error
+				if err != nil {
+					return fmt.Errorf("failed to update blind index for user %s: %w", row.Id, err)
+				}
+				totalUpdated++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if totalUpdated > 0 {
+		log.Printf("Built PII blind indexes for %d existing users", totalUpdated)
+	}
+	return nil
+}
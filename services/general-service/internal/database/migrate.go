@@ -20,6 +20,23 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.PerformancePanel{},
 		&models.PerformanceTalent{},
 		&models.Payment{},
+		&models.ScheduledJobRun{},
+		&models.RefreshToken{},
+		&models.UserSession{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.UserIdentity{},
+		&models.DataExport{},
+		&models.EmailLog{},
+		&models.AccountDeletion{},
+		&models.RetentionPurgeLog{},
+		&models.AdminAuditLog{},
+		&models.Impersonation{},
+		&models.ServiceAccount{},
+		&models.APIKey{},
+		&models.UserFingerprint{},
+		&models.FraudAssessment{},
 	}
 
 	// AutoMigrate (creates tables, adds columns, indexes)
@@ -28,9 +45,19 @@ func AutoMigrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to auto-migrate base tables: %w", err)
 	}
 
-	// Ensure google_id exists on users (handles DBs created before this column was added, e.g. CI)
-	if err := ensureUsersGoogleIdColumn(db); err != nil {
-		return fmt.Errorf("failed to ensure users.google_id column: %w", err)
+	// Move Google sign-in links into user_identities before google_id is dropped below
+	if err := migrateGoogleIdsToUserIdentities(db); err != nil {
+		return err
+	}
+
+	// Build the search indexes of users encrypted before they existed
+	if err := migrateBackfillUserPIIBlindIndex(db); err != nil {
+		return fmt.Errorf("failed to backfill user PII blind indexes: %w", err)
+	}
+
+	// Store passkey public keys registered before go-webauthn verified them as COSE keys
+	if err := migratePasskeyKeysToCOSE(db); err != nil {
+		return fmt.Errorf("failed to convert passkey public keys: %w", err)
 	}
 
 	// Ensure price_usd exists on ticket_tiers (handles DBs created before PriceUsd was added)
@@ -48,6 +75,16 @@ func AutoMigrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to ensure performance_panels.nickname column: %w", err)
 	}
 
+	// Reject updates and deletes of the admin audit log
+	if err := ensureAdminAuditLogAppendOnly(db); err != nil {
+		return fmt.Errorf("failed to make admin_audit_logs append-only: %w", err)
+	}
+
+	// Index the lookups the fraud screening payment memo check makes
+	if err := ensurePaymentMemoIndexes(db); err != nil {
+		return fmt.Errorf("failed to create payment memo indexes: %w", err)
+	}
+
 	// Drop columns that are no longer in the model
 	// WARNING: This will permanently DELETE DATA!
 	if err := dropUnusedColumns(db, allModels); err != nil {
@@ -63,19 +100,6 @@ func AutoMigrate(db *gorm.DB) error {
 	return nil
 }
 
-// ensureUsersGoogleIdColumn adds the google_id column to users if it does not exist.
-// This covers databases created before GoogleId was added to the User model (e.g. CI).
-func ensureUsersGoogleIdColumn(db *gorm.DB) error {
-	migrator := db.Migrator()
-	if migrator.HasTable("users") && !migrator.HasColumn("users", "google_id") {
-		if err := migrator.AddColumn(&models.User{}, "GoogleId"); err != nil {
-			return err
-		}
-		log.Println("Added users.google_id column (migration)")
-	}
-	return nil
-}
-
 // ensureTicketTiersPriceUsdColumn adds the price_usd column to ticket_tiers if it does not exist.
 // This covers databases created before PriceUsd was added to the TicketTier model.
 func ensureTicketTiersPriceUsdColumn(db *gorm.DB) error {
@@ -114,6 +138,43 @@ func ensurePerformancePanelsNicknameColumn(db *gorm.DB) error {
 	return nil
 }
 
+// ensureAdminAuditLogAppendOnly installs a trigger that rejects UPDATE and DELETE on
+// admin_audit_logs, so entries can only be appended. The hash chain still shows tampering by
+// anyone able to drop the trigger.
+func ensureAdminAuditLogAppendOnly(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION admin_audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'admin_audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS admin_audit_logs_append_only ON admin_audit_logs`,
+		`CREATE TRIGGER admin_audit_logs_append_only BEFORE UPDATE OR DELETE ON admin_audit_logs
+	FOR EACH ROW EXECUTE FUNCTION admin_audit_logs_append_only()`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensurePaymentMemoIndexes indexes payments by their normalized memo and user_tickets by their
+// upper-cased reference code, so memo matches are equality lookups.
+func ensurePaymentMemoIndexes(db *gorm.DB) error {
+	statements := []string{
+		`CREATE INDEX IF NOT EXISTS idx_payments_memo_key ON payments ((` + models.PaymentMemoKeySQL + `))`,
+		`CREATE INDEX IF NOT EXISTS idx_user_tickets_reference_code_upper ON user_tickets (upper(reference_code))`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // dropUnusedColumns removes columns that are no longer in the model
 func dropUnusedColumns(db *gorm.DB, models []interface{}) error {
 	migrator := db.Migrator()
@@ -1,9 +1,13 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"general-service/internal/models"
 	"general-service/internal/security"
@@ -11,37 +15,156 @@ import (
 	"gorm.io/gorm"
 )
 
-const userPIIKeyEnv = "USER_PII_AES_KEY"
+const (
+	userPIIKeyEnv      = "USER_PII_AES_KEY"
+	userPIIKeysEnv     = "USER_PII_AES_KEYS"
+	userPIIKeyIDEnv    = "USER_PII_AES_KEY_ID"
+	userPIIIndexKeyEnv = "USER_PII_INDEX_KEY"
+)
+
+// dataKeyProvider unwraps the data keys in configuration (see security.NewKeyProviderFromEnv).
+// It is created once, so unwrapped keys are cached across loads.
+var dataKeyProvider = sync.OnceValues(func() (security.KeyProvider, error) {
+	return security.NewKeyProviderFromEnv(context.Background())
+})
+
+// dataKeyUnwrapTimeout bounds the provider calls of one key load.
+const dataKeyUnwrapTimeout = 10 * time.Second
+
+// loadDataKey returns the data key in env (base64, wrapped unless DATA_KEY_PROVIDER is "env").
+func loadDataKey(ctx context.Context, provider security.KeyProvider, env string) ([]byte, error) {
+	wrapped, err := security.DecodeBase64Key(os.Getenv(env))
+	if err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", env, err)
+	}
+	key, err := provider.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be unwrapped with the %s key provider: %w", env, provider.Name(), err)
+	}
+	return key, nil
+}
+
+// LoadUserPIIKeyRing returns the keys that encrypt user PII: the named keys of USER_PII_AES_KEYS
+// ("kid:key,kid:key", each a base64 AES key) plus USER_PII_AES_KEY, the key of values written
+// before key IDs existed. New values are encrypted with USER_PII_AES_KEY_ID (default: the first
+// key of USER_PII_AES_KEYS, or USER_PII_AES_KEY when that is the only key). Unless
+// DATA_KEY_PROVIDER is "env", the configured keys are wrapped and unwrapped here.
+//
+// To rotate, add the new key to USER_PII_AES_KEYS and make it active, run
+// `go run ./cmd/migrate -reencrypt-pii`, then remove the old key once it reports nothing left.
+func LoadUserPIIKeyRing() (*security.AESKeyRing, error) {
+	provider, err := dataKeyProvider()
+	if err != nil {
+		return nil, fmt.Errorf("data key provider: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dataKeyUnwrapTimeout)
+	defer cancel()
+
+	keys, err := security.ParseKeySpec(userPIIKeysEnv, os.Getenv(userPIIKeysEnv))
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Key, err = provider.UnwrapKey(ctx, keys[i].Key); err != nil {
+			return nil, fmt.Errorf("%s: key %q could not be unwrapped with the %s key provider: %w", userPIIKeysEnv, keys[i].ID, provider.Name(), err)
+		}
+	}
+	var legacyKey []byte
+	if strings.TrimSpace(os.Getenv(userPIIKeyEnv)) != "" {
+		if legacyKey, err = loadDataKey(ctx, provider, userPIIKeyEnv); err != nil {
+			return nil, err
+		}
+	}
+	ring, err := security.NewAESKeyRing(userPIIKeysEnv, keys, os.Getenv(userPIIKeyIDEnv), legacyKey)
+	if err != nil {
+		return nil, fmt.Errorf("user PII keys are invalid: %w", err)
+	}
+	return ring, nil
+}
+
+// LoadUserPIIBlindIndex returns the blind index keyed by USER_PII_INDEX_KEY (wrapped like the
+// encryption keys), used to search the encrypted user PII. The key is separate from the
+// encryption keys so those can be rotated without rebuilding the indexes.
+func LoadUserPIIBlindIndex() (*security.BlindIndex, error) {
+	provider, err := dataKeyProvider()
+	if err != nil {
+		return nil, fmt.Errorf("data key provider: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dataKeyUnwrapTimeout)
+	defer cancel()
+
+	key, err := loadDataKey(ctx, provider, userPIIIndexKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	index, err := security.NewBlindIndex(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", userPIIIndexKeyEnv, err)
+	}
+	return index, nil
+}
 
 // RegisterUserPIIEncryption installs GORM callbacks that transparently encrypt/decrypt
-// selected PII fields for models.User.
+// selected PII fields for models.User, the TOTP secret of models.UserMFA and the archive of
+// models.DataExport. Before encrypting a User they also refresh its blind indexes (IdCardIndex,
+// NameIndex) from the plaintext, so only writes of the whole user (Create/Save) keep them correct.
+//
+// It fails if any stored value is encrypted with a key that is not in the key ring. A write whose
+// values cannot be encrypted fails rather than storing them in plaintext.
 func RegisterUserPIIEncryption(db *gorm.DB) error {
-	keyB64 := os.Getenv(userPIIKeyEnv)
-	key, err := security.DecodeBase64Key(keyB64)
+	c, err := LoadUserPIIKeyRing()
 	if err != nil {
-		return fmt.Errorf("%s is invalid: %w", userPIIKeyEnv, err)
+		return err
 	}
-	c, err := security.NewAESCipher(key)
+	if err := validateUserPIIKeyIDs(db, c); err != nil {
+		return err
+	}
+	index, err := LoadUserPIIBlindIndex()
 	if err != nil {
-		return fmt.Errorf("%s is invalid: %w", userPIIKeyEnv, err)
+		return err
 	}
+	registerUserPIICallbacks(db, c, index)
+	return nil
+}
 
+// registerUserPIICallbacks installs the callbacks of RegisterUserPIIEncryption.
+func registerUserPIICallbacks(db *gorm.DB, c *security.AESKeyRing, index *security.BlindIndex) {
 	encryptDest := func(tx *gorm.DB) {
 		if tx.Statement == nil {
 			return
 		}
-		_ = walkAndApplyUsers(tx.Statement.Dest, func(u *models.User) error {
+		err := walkAndApply(tx.Statement.Dest, func(u *models.User) error {
+			indexUserPII(index, u)
 			return encryptUserPII(c, u)
 		})
+		if err == nil {
+			err = walkAndApply(tx.Statement.Dest, func(m *models.UserMFA) error {
+				return encryptUserMFA(c, m)
+			})
+		}
+		if err == nil {
+			err = walkAndApply(tx.Statement.Dest, func(e *models.DataExport) error {
+				return encryptDataExport(c, e)
+			})
+		}
+		if err != nil {
+			_ = tx.AddError(fmt.Errorf("failed to encrypt PII: %w", err))
+		}
 	}
 
 	decryptDest := func(tx *gorm.DB) {
 		if tx.Statement == nil {
 			return
 		}
-		_ = walkAndApplyUsers(tx.Statement.Dest, func(u *models.User) error {
+		_ = walkAndApply(tx.Statement.Dest, func(u *models.User) error {
 			return decryptUserPII(c, u)
 		})
+		_ = walkAndApply(tx.Statement.Dest, func(m *models.UserMFA) error {
+			return decryptUserMFA(c, m)
+		})
+		_ = walkAndApply(tx.Statement.Dest, func(e *models.DataExport) error {
+			return decryptDataExport(c, e)
+		})
 	}
 
 	// Create / Update: encrypt before writing.
@@ -50,11 +173,72 @@ func RegisterUserPIIEncryption(db *gorm.DB) error {
 
 	// Query: decrypt after scanning into destination.
 	db.Callback().Query().After("gorm:query").Register("user_pii_decrypt_query", decryptDest)
+}
+
+// indexUserPII sets the blind indexes of u from its plaintext PII. Values that are still
+// encrypted (e.g. a user that failed to decrypt) are left alone.
+func indexUserPII(index *security.BlindIndex, u *models.User) {
+	if u == nil || isEncryptedValue(u.FirstName) || isEncryptedValue(u.LastName) || isEncryptedValue(u.IdCard) {
+		return
+	}
+	u.IdCardIndex = index.IDCard(u.IdCard)
+	u.NameIndex = index.NameTokens(u.FirstName, u.LastName)
+}
+
+func isEncryptedValue(value string) bool {
+	return security.IsEncrypted(value)
+}
+
+// piiTables are the tables with columns encrypted with the user PII keys.
+var piiTables = []string{"users", "user_mfa", "data_exports"}
+
+// piiColumns lists the encrypted columns of each table.
+var piiColumns = map[string][]string{
+	"users":        {"first_name", "last_name", "id_card"},
+	"user_mfa":     {"secret"},
+	"data_exports": {"archive"},
+}
+
+// piiKeyIDsInUse returns the IDs of the keys the stored values of table are encrypted with.
+func piiKeyIDsInUse(db *gorm.DB, table string) ([]string, error) {
+	if !db.Migrator().HasTable(table) {
+		return nil, nil
+	}
+	columns := piiColumns[table]
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = "(" + column + ")"
+	}
+	var ids []string
+	err := db.Raw(`SELECT DISTINCT CASE WHEN v LIKE 'v1:%' THEN ? ELSE split_part(v, ':', 2) END
+		FROM `+table+`, LATERAL (VALUES `+strings.Join(values, ", ")+`) AS pii(v)
+		WHERE v LIKE 'v1:%' OR v LIKE 'v2:%'`, security.LegacyAESKeyID).Scan(&ids).Error
+	return ids, err
+}
 
+// validateUserPIIKeyIDs checks that every key the stored PII is encrypted with is in ring, so a
+// key removed too early is noticed at startup rather than on the first read of an affected user.
+func validateUserPIIKeyIDs(db *gorm.DB, ring *security.AESKeyRing) error {
+	for _, table := range piiTables {
+		ids, err := piiKeyIDsInUse(db, table)
+		if err != nil {
+			return fmt.Errorf("failed to check PII key IDs in %s: %w", table, err)
+		}
+		var missing []string
+		for _, id := range ids {
+			if !ring.HasKey(id) {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%s has values encrypted with keys missing from %s/%s: %s",
+				table, userPIIKeysEnv, userPIIKeyEnv, strings.Join(missing, ", "))
+		}
+	}
 	return nil
 }
 
-func encryptUserPII(c *security.AESCipher, u *models.User) error {
+func encryptUserPII(c *security.AESKeyRing, u *models.User) error {
 	var err error
 	if u == nil {
 		return nil
@@ -73,7 +257,7 @@ func encryptUserPII(c *security.AESCipher, u *models.User) error {
 	return nil
 }
 
-func decryptUserPII(c *security.AESCipher, u *models.User) error {
+func decryptUserPII(c *security.AESKeyRing, u *models.User) error {
 	var err error
 	if u == nil {
 		return nil
@@ -90,7 +274,43 @@ func decryptUserPII(c *security.AESCipher, u *models.User) error {
 	return nil
 }
 
-func walkAndApplyUsers(dest any, fn func(*models.User) error) error {
+func encryptUserMFA(c *security.AESKeyRing, m *models.UserMFA) error {
+	var err error
+	if m == nil {
+		return nil
+	}
+	m.Secret, err = c.EncryptString(m.Secret)
+	return err
+}
+
+func decryptUserMFA(c *security.AESKeyRing, m *models.UserMFA) error {
+	var err error
+	if m == nil {
+		return nil
+	}
+	m.Secret, err = c.DecryptString(m.Secret)
+	return err
+}
+
+func encryptDataExport(c *security.AESKeyRing, e *models.DataExport) error {
+	var err error
+	if e == nil {
+		return nil
+	}
+	e.Archive, err = c.EncryptString(e.Archive)
+	return err
+}
+
+func decryptDataExport(c *security.AESKeyRing, e *models.DataExport) error {
+	var err error
+	if e == nil {
+		return nil
+	}
+	e.Archive, err = c.DecryptString(e.Archive)
+	return err
+}
+
+func walkAndApply[T any](dest any, fn func(*T) error) error {
 	if dest == nil {
 		return nil
 	}
@@ -98,7 +318,7 @@ func walkAndApplyUsers(dest any, fn func(*models.User) error) error {
 	return walkValue(v, fn)
 }
 
-func walkValue(v reflect.Value, fn func(*models.User) error) error {
+func walkValue[T any](v reflect.Value, fn func(*T) error) error {
 	if !v.IsValid() {
 		return nil
 	}
@@ -111,8 +331,8 @@ func walkValue(v reflect.Value, fn func(*models.User) error) error {
 
 	switch v.Kind() {
 	case reflect.Struct:
-		// Handle models.User or embedded.
-		if u, ok := v.Addr().Interface().(*models.User); ok {
+		// Handle the target model or embedded.
+		if u, ok := v.Addr().Interface().(*T); ok {
 			return fn(u)
 		}
 		return nil
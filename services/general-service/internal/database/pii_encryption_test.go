@@ -0,0 +1,60 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+
+	"general-service/internal/models"
+	"general-service/internal/security"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestDataExportArchiveEncryptedAtRest(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.DataExport{}); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := security.NewAESKeyRing("TEST_KEYS", []security.NamedKey{{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)}}, "k1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := security.NewBlindIndex(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerUserPIICallbacks(db, ring, index)
+
+	archive := base64.StdEncoding.EncodeToString([]byte("PK\x03\x04 data.json: {\"email\":\"user@example.com\"}"))
+	export := &models.DataExport{Id: uuid.New(), UserId: uuid.New(), Status: models.DataExportStatusReady, Archive: archive}
+	if err := db.Create(export).Error; err != nil {
+		t.Fatal(err)
+	}
+	export.Archive = archive
+	if err := db.Save(export).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	if err := db.Raw("SELECT archive FROM data_exports WHERE id = ?", export.Id).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := security.EncryptionKeyID(stored); !ok || id != "k1" {
+		t.Fatalf("stored archive is not encrypted with the active key: %.40q", stored)
+	}
+
+	var loaded models.DataExport
+	if err := db.First(&loaded, "id = ?", export.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Archive != archive {
+		t.Errorf("loaded archive %.40q, want the saved archive", loaded.Archive)
+	}
+}
@@ -0,0 +1,36 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogResponse is one entry of the admin audit log
+type AuditLogResponse struct {
+	Seq        int64           `json:"seq"`
+	ActorId    *uuid.UUID      `json:"actor_id,omitempty"`
+	ActorRole  int             `json:"actor_role"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityId   string          `json:"entity_id,omitempty"`
+	Changes    json.RawMessage `json:"changes,omitempty"` // field => {before, after}, PII shown as "[redacted]"
+	Status     int             `json:"status"`
+	IpAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	RequestId  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// AuditChainVerificationResponse reports a check of the audit log hash chain
+type AuditChainVerificationResponse struct {
+	Valid       bool   `json:"valid"`
+	Checked     int64  `json:"checked"`  // entries verified
+	HeadSeq     int64  `json:"head_seq"` // last entry; keep it (with head_hash) elsewhere to detect truncation
+	HeadHash    string `json:"head_hash"`
+	BrokenAtSeq *int64 `json:"broken_at_seq,omitempty"` // first entry that does not verify
+	Problem     string `json:"problem,omitempty"`
+}
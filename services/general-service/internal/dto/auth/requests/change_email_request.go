@@ -0,0 +1,18 @@
+package requests
+
+// ChangeEmailRequest starts changing the account email; a code is sent to the new address
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email" example:"new@example.com"`
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123"`
+}
+
+// ConfirmEmailChangeRequest finishes an email change with the code sent to the new address
+type ConfirmEmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"new@example.com"`
+	Otp      string `json:"otp" binding:"required,len=6" example:"123456"`
+}
+
+// RevertEmailChangeRequest restores the previous email with the link sent to it
+type RevertEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -1,15 +1,9 @@
 package requests
 
 // GoogleLoginRequest is the body for Google Sign-In. Credential is required.
-// When the user is not yet registered, FullName, Nickname, DateOfBirth, and Country are required (same as normal register).
-// Password and ConfirmPassword are optional; if provided and matching, the user can also log in with email/password.
+// When the user is not yet registered, the registration details are required (same as normal
+// register), except that FullName and Nickname default to the Google profile name.
 type GoogleLoginRequest struct {
-	Credential      string `json:"credential" binding:"required"`
-	FullName        string `json:"fullName"`
-	Nickname        string `json:"nickname"`
-	DateOfBirth     string `json:"dateOfBirth"`
-	Country         string `json:"country"`
-	IdCard          string `json:"idCard"`
-	Password        string `json:"password"`
-	ConfirmPassword string `json:"confirmPassword"`
+	Credential string `json:"credential" binding:"required"`
+	OAuthRegistrationDetails
 }
@@ -0,0 +1,11 @@
+package requests
+
+// MagicLinkRequest asks for a sign-in link by email
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// MagicLinkLoginRequest signs in with the token from a magic link
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
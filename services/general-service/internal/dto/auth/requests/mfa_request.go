@@ -0,0 +1,25 @@
+package requests
+
+// MFAVerifyRequest completes a login that returned an MFA challenge. Exactly one of Code and
+// RecoveryCode is required.
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required" example:"<mfa-token>"`
+	Code         string `json:"code" binding:"omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode string `json:"recovery_code" binding:"omitempty,max=32" example:"ABCD-EFGH-JKLM"`
+}
+
+// MFAEnrollRequest starts authenticator setup during a login whose role requires 2FA
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"<mfa-token>"`
+}
+
+// MFAEnrollConfirmRequest confirms authenticator setup during login and signs the user in
+type MFAEnrollConfirmRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"<mfa-token>"`
+	Code     string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+// TOTPCodeRequest carries a code from the user's authenticator app
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
@@ -0,0 +1,58 @@
+package requests
+
+// OAuthRegistrationDetails completes sign-up for a new account created through an external
+// provider. FullName and Nickname default to what the provider shares; DateOfBirth and Country
+// are always needed. Password and ConfirmPassword are optional; if provided and matching, the user
+// can also log in with email/password.
+type OAuthRegistrationDetails struct {
+	FullName        string `json:"fullName"`
+	Nickname        string `json:"nickname"`
+	DateOfBirth     string `json:"dateOfBirth"`
+	Country         string `json:"country"`
+	IdCard          string `json:"idCard"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+// OAuthCallbackRequest finishes an authorization code login with the code and state the provider
+// redirected back with
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+	OAuthRegistrationDetails
+}
+
+// OAuthRegisterRequest completes sign-up after a callback answered with registration_required
+type OAuthRegisterRequest struct {
+	RegistrationToken string `json:"registration_token" binding:"required"`
+	OAuthRegistrationDetails
+}
+
+// TelegramLoginRequest is the user data signed by the Telegram Login Widget
+type TelegramLoginRequest struct {
+	ID        int64  `json:"id" binding:"required"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	PhotoURL  string `json:"photo_url"`
+	AuthDate  int64  `json:"auth_date" binding:"required"`
+	Hash      string `json:"hash" binding:"required"`
+}
+
+// OAuthLinkRequest finishes linking a provider account to the current user with the code and
+// state the provider redirected back with
+type OAuthLinkRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// GoogleLinkRequest links the Google account of a Sign-In credential to the current user
+type GoogleLinkRequest struct {
+	Credential string `json:"credential" binding:"required"`
+}
+
+// SetPasswordRequest sets the first password of an account created through a provider
+type SetPasswordRequest struct {
+	Password        string `json:"password" binding:"required,min=6"`
+	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+}
@@ -0,0 +1,37 @@
+package requests
+
+// PasskeyCredential is a PublicKeyCredential as serialised by the browser (PublicKeyCredential.toJSON()).
+// Binary fields are base64url.
+type PasskeyCredential struct {
+	ID       string                    `json:"id" binding:"required"`
+	RawID    string                    `json:"rawId"`
+	Type     string                    `json:"type" binding:"required,eq=public-key"`
+	Response PasskeyCredentialResponse `json:"response" binding:"required"`
+}
+
+// PasskeyCredentialResponse holds the attestation (registration) or assertion (login) response
+type PasskeyCredentialResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+	AttestationObject string   `json:"attestationObject"` // registration
+	Transports        []string `json:"transports"`        // registration
+	AuthenticatorData string   `json:"authenticatorData"` // login
+	Signature         string   `json:"signature"`         // login
+	UserHandle        string   `json:"userHandle"`        // login
+}
+
+// PasskeyRegisterRequest finishes adding a passkey to the current account
+type PasskeyRegisterRequest struct {
+	Name       string            `json:"name" binding:"omitempty,max=100" example:"My phone"`
+	Credential PasskeyCredential `json:"credential" binding:"required"`
+}
+
+// PasskeyLoginRequest finishes a passwordless passkey login
+type PasskeyLoginRequest struct {
+	Credential PasskeyCredential `json:"credential" binding:"required"`
+}
+
+// PasskeyMFARequest finishes an MFA challenge with a passkey
+type PasskeyMFARequest struct {
+	MFAToken   string            `json:"mfa_token" binding:"required" example:"<mfa-token>"`
+	Credential PasskeyCredential `json:"credential" binding:"required"`
+}
@@ -0,0 +1,22 @@
+package requests
+
+// CreateServiceAccountRequest represents an admin registering a partner integration
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Badge printer station"`
+	Description string `json:"description" binding:"max=500" example:"Prints badges at the registration desk"`
+}
+
+// CreateAPIKeyRequest represents an admin issuing an API key to a service account. The key never
+// expires when ExpiresInDays is omitted.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"max=100" example:"Registration desk 1"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=tickets:read checkin:write dealers:read users:read" example:"tickets:read,checkin:write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=730" example:"90"`
+}
+
+// RotateAPIKeyRequest represents an admin replacing an API key with a new one of the same scopes
+// and expiry period. The old key keeps working for GracePeriodMinutes (revoked at once when 0), so
+// the integration can switch over without downtime.
+type RotateAPIKeyRequest struct {
+	GracePeriodMinutes int `json:"grace_period_minutes" binding:"omitempty,min=0,max=10080" example:"60"`
+}
@@ -1,5 +1,12 @@
 package responses
 
 type LoginResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+
+	// MFA is set instead of the tokens when the password step succeeded but a second factor is needed
+	MFA *MFAChallengeResponse `json:"mfa,omitempty"`
+
+	// Registration is set instead of the tokens when a provider login needs sign-up details first
+	Registration *OAuthRegistrationResponse `json:"registration,omitempty"`
 }
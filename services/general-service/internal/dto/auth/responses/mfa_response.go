@@ -0,0 +1,32 @@
+package responses
+
+// MFAChallengeResponse is returned by login instead of setting session cookies when a second
+// factor is needed. MFAToken is short-lived and only accepted by the /auth/mfa endpoints.
+type MFAChallengeResponse struct {
+	MFARequired        bool     `json:"mfa_required"`
+	EnrollmentRequired bool     `json:"enrollment_required"` // role requires 2FA; set up an authenticator first
+	Methods            []string `json:"methods"`             // "totp", "passkey"
+	MFAToken           string   `json:"mfa_token"`
+	ExpiresIn          int      `json:"expires_in"` // seconds
+}
+
+// TOTPSetupResponse holds a new, unconfirmed authenticator secret
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"` // PNG data URL of OtpauthURL
+}
+
+// RecoveryCodesResponse lists single-use recovery codes; they are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse describes the current user's two-factor setup
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Pending                bool  `json:"pending"`  // setup started but not confirmed
+	Required               bool  `json:"required"` // enforced for the user's role
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+	Passkeys               int64 `json:"passkeys"` // passkeys can also answer MFA challenges
+}
@@ -0,0 +1,52 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthProvidersResponse lists the external sign-in providers that are configured
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers"` // e.g. "google", "discord", "facebook", "telegram"
+}
+
+// OAuthAuthorizationResponse starts an authorization code login: send the user to
+// AuthorizationURL, then post the code and state it redirects back with to the callback endpoint.
+type OAuthAuthorizationResponse struct {
+	Provider         string `json:"provider"`
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int    `json:"expires_in"` // seconds
+}
+
+// OAuthRegistrationResponse is returned by a provider login when no account exists yet and the
+// provider did not share everything sign-up needs. Profile fields are prefilled from the provider;
+// post them with the missing ones and RegistrationToken to /auth/oauth/register.
+type OAuthRegistrationResponse struct {
+	RegistrationRequired bool     `json:"registration_required"`
+	RegistrationToken    string   `json:"registration_token"`
+	Provider             string   `json:"provider"`
+	Email                string   `json:"email"`
+	FullName             string   `json:"fullName"`
+	Nickname             string   `json:"nickname"`
+	AvatarURL            string   `json:"avatar_url,omitempty"`
+	MissingFields        []string `json:"missing_fields"` // "fullName", "nickname", "dateOfBirth", "country"
+	ExpiresIn            int      `json:"expires_in"`     // seconds
+}
+
+// LinkedIdentityResponse is a provider account linked to the current user
+type LinkedIdentityResponse struct {
+	Id          uuid.UUID  `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email,omitempty"`
+	LinkedAt    time.Time  `json:"linked_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// LoginMethodsResponse lists the ways the current user can sign in
+type LoginMethodsResponse struct {
+	HasPassword bool                     `json:"has_password"`
+	Passkeys    int64                    `json:"passkeys"`
+	Identities  []LinkedIdentityResponse `json:"identities"`
+}
@@ -0,0 +1,68 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The options below are passed to navigator.credentials.create()/get() after decoding the base64url
+// fields (or directly via PublicKeyCredential.parseCreationOptionsFromJSON/parseRequestOptionsFromJSON).
+
+type PasskeyRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PasskeyUser struct {
+	ID          string `json:"id"` // base64url user handle
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type PasskeyCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type PasskeyCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"` // base64url
+	Transports []string `json:"transports,omitempty"`
+}
+
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PasskeyCreationOptions are the PublicKeyCredentialCreationOptions for registering a passkey
+type PasskeyCreationOptions struct {
+	Challenge              string                        `json:"challenge"`
+	RP                     PasskeyRelyingParty           `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                           `json:"timeout"` // milliseconds
+	Attestation            string                        `json:"attestation"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+}
+
+// PasskeyRequestOptions are the PublicKeyCredentialRequestOptions for signing in with a passkey
+type PasskeyRequestOptions struct {
+	Challenge        string                        `json:"challenge"`
+	RPID             string                        `json:"rpId"`
+	Timeout          int                           `json:"timeout"` // milliseconds
+	UserVerification string                        `json:"userVerification"`
+	AllowCredentials []PasskeyCredentialDescriptor `json:"allowCredentials"`
+}
+
+// PasskeyResponse describes one of the current user's passkeys
+type PasskeyResponse struct {
+	Id         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	BackedUp   bool       `json:"backed_up"` // synced passkey (e.g. iCloud Keychain, Google Password Manager)
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
@@ -0,0 +1,40 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceAccountResponse is a service account and its keys as admins see them
+type ServiceAccountResponse struct {
+	Id          uuid.UUID         `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	CreatedBy   uuid.UUID         `json:"created_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DisabledAt  *time.Time        `json:"disabled_at,omitempty"`
+	Keys        []*APIKeyResponse `json:"keys"`
+}
+
+// APIKeyResponse is an API key without its secret
+type APIKeyResponse struct {
+	Id            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	Scopes        []string   `json:"scopes"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIp    string     `json:"last_used_ip,omitempty"`
+	RotatedFromId *uuid.UUID `json:"rotated_from_id,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	IsActive      bool       `json:"is_active"`
+}
+
+// IssuedAPIKeyResponse is a newly created or rotated key. Key is only ever returned here: store it
+// now, it cannot be shown again.
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -12,3 +12,10 @@ type HealthResponse struct {
 	Message string `json:"message" example:"pong"`
 	Status  string `json:"status"  example:"healthy"`
 }
+
+// EmailJobResult summarises a batch email job triggered by the sqs-worker scheduler.
+type EmailJobResult struct {
+	Candidates int `json:"candidates"`
+	Sent       int `json:"sent"`
+	Failed     int `json:"failed"`
+}
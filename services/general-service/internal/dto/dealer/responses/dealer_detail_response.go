@@ -23,6 +23,8 @@ type DealerBoothDetailResponse struct {
 	BoothNumber string                 `json:"booth_number"`
 	PriceSheets []string               `json:"price_sheets"`
 	IsVerified  bool                   `json:"is_verified"`
+	FlaggedAt   *time.Time             `json:"flagged_at,omitempty"`
+	FlagReason  string                 `json:"flag_reason,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	ModifiedAt  time.Time              `json:"modified_at"`
 	Staffs      []*DealerStaffResponse `json:"staffs,omitempty"`
@@ -83,3 +83,28 @@ type UpgradeTicketRequest struct {
 type BlacklistUserRequest struct {
 	Reason string `json:"reason" binding:"omitempty,max=500"`
 }
+
+// BulkApproveTicketsRequest is the request body for approving many tickets at once (admin)
+type BulkApproveTicketsRequest struct {
+	TicketIDs []string `json:"ticket_ids" binding:"required,min=1,max=200,dive,uuid"`
+}
+
+// BlacklistSignOutJobRequest is sent by the sqs-worker after a queued denial blacklisted the user.
+type BlacklistSignOutJobRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+}
+
+// FraudScreeningJobRequest is the body of the fraud screening job. The scheduler sends no ticket
+// to screen the backlog; the sqs-worker sends TicketID right after a queued purchase.
+type FraudScreeningJobRequest struct {
+	TicketID string `json:"ticket_id" binding:"omitempty,uuid"`
+}
+
+// PaymentReminderJobRequest is the body the sqs-worker scheduler sends to trigger payment reminders.
+// Tickets left pending between OlderThanHours and OlderThanHours+WindowHours ago are reminded, so a
+// daily schedule with a 24h window reminds each ticket once.
+type PaymentReminderJobRequest struct {
+	OlderThanHours   int `json:"older_than_hours" binding:"required,min=1,max=720"`
+	WindowHours      int `json:"window_hours" binding:"omitempty,min=1,max=720"`
+	ExpireAfterHours int `json:"expire_after_hours" binding:"omitempty,min=1,max=720"` // Mentioned in the email when set
+}
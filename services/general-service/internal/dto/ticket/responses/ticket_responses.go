@@ -40,6 +40,8 @@ type UserTicketResponse struct {
 	UpgradedFromTierID    *uuid.UUID `json:"upgraded_from_tier_id,omitempty"`
 	PreviousReferenceCode string     `json:"previous_reference_code,omitempty"`
 	UpgradeDenialReason   string     `json:"upgrade_denial_reason,omitempty"`
+	RiskScore             *int       `json:"risk_score,omitempty"` // admin view: duplicate-account risk 0-100
+	FraudHold             bool       `json:"fraud_hold,omitempty"` // admin view: held for fraud review
 
 	// Tier info
 	Tier *TicketTierResponse `json:"tier,omitempty"`
@@ -131,3 +133,15 @@ type BlacklistedUserResponse struct {
 	BlacklistedAt   *time.Time `json:"blacklisted_at"`
 	BlacklistReason string     `json:"blacklist_reason"`
 }
+
+// BulkApproveFailure is one ticket that could not be approved in a bulk request
+type BulkApproveFailure struct {
+	TicketID string `json:"ticket_id"`
+	Error    string `json:"error"`
+}
+
+// BulkApproveResponse summarises a bulk approval (or one chunk of a queued one)
+type BulkApproveResponse struct {
+	Approved []string             `json:"approved"`
+	Failed   []BulkApproveFailure `json:"failed"`
+}
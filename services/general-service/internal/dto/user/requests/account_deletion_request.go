@@ -0,0 +1,13 @@
+package requests
+
+// RequestAccountDeletionRequest represents the request to delete the current user's account.
+// Password is required when the account has one.
+type RequestAccountDeletionRequest struct {
+	Password string `json:"password" binding:"omitempty,max=128"`
+	Reason   string `json:"reason" binding:"omitempty,max=500" example:"No longer attending"`
+}
+
+// HoldAccountDeletionRequest represents an admin putting an account deletion on hold
+type HoldAccountDeletionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Open payment dispute"`
+}
@@ -0,0 +1,14 @@
+package requests
+
+// ClearFraudAssessmentRequest represents an admin deciding a flagged purchase is not a duplicate
+// account; the ticket's hold is released
+type ClearFraudAssessmentRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Siblings sharing a home network"`
+}
+
+// ConfirmFraudAssessmentRequest represents an admin confirming a flagged purchase was made by a
+// duplicate account. The ticket stays held so it can be denied; Blacklist also bans the account.
+type ConfirmFraudAssessmentRequest struct {
+	Note      string `json:"note" binding:"max=500" example:"Same ID card as a blacklisted account"`
+	Blacklist bool   `json:"blacklist"`
+}
@@ -0,0 +1,7 @@
+package requests
+
+// StartImpersonationRequest represents an admin signing in as an attendee for support
+type StartImpersonationRequest struct {
+	UserId string `json:"user_id" binding:"required,uuid"`
+	Reason string `json:"reason" binding:"required,max=500" example:"Support request: ticket not showing"`
+}
@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountDeletionResponse describes a self-service account deletion request
+type AccountDeletionResponse struct {
+	Id           uuid.UUID  `json:"id"`
+	Status       string     `json:"status"` // pending, held, cancelled, completed
+	Reason       string     `json:"reason,omitempty"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	IsHeld       bool       `json:"is_held"`
+}
+
+// AdminAccountDeletionResponse is an account deletion request as admins see it
+type AdminAccountDeletionResponse struct {
+	AccountDeletionResponse
+	UserId      uuid.UUID                      `json:"user_id"`
+	UserEmail   string                         `json:"user_email"`
+	FursonaName string                         `json:"fursona_name"`
+	HeldBy      *uuid.UUID                     `json:"held_by,omitempty"`
+	HeldAt      *time.Time                     `json:"held_at,omitempty"`
+	HoldReason  string                         `json:"hold_reason,omitempty"`
+	Summary     *models.AccountDeletionSummary `json:"summary,omitempty"`
+}
+
+// ProcessAccountDeletionsResponse reports one run of the account deletion purge job
+type ProcessAccountDeletionsResponse struct {
+	Completed int      `json:"completed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
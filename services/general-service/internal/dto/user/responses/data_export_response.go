@@ -0,0 +1,18 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportResponse describes a personal data export request of the current user. The download
+// link itself is only sent by email.
+type DataExportResponse struct {
+	Id          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"` // pending, processing, ready, failed
+	ArchiveSize int64      `json:"archive_size,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,46 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FraudAssessmentResponse is a flagged ticket purchase in the fraud review queue
+type FraudAssessmentResponse struct {
+	Id            uuid.UUID              `json:"id"`
+	UserId        uuid.UUID              `json:"user_id"`
+	UserEmail     string                 `json:"user_email"`
+	FursonaName   string                 `json:"fursona_name"`
+	IsBlacklisted bool                   `json:"is_blacklisted"`
+	UserTicketId  *uuid.UUID             `json:"user_ticket_id,omitempty"`
+	ReferenceCode string                 `json:"reference_code,omitempty"`
+	TicketStatus  string                 `json:"ticket_status,omitempty"`
+	FraudHold     bool                   `json:"fraud_hold"` // the ticket currently cannot be approved
+	Score         int                    `json:"score"`
+	Signals       []*FraudSignalResponse `json:"signals"`
+	Status        string                 `json:"status"`
+	TicketHeld    bool                   `json:"ticket_held"` // the ticket was held automatically
+	ReviewedBy    *uuid.UUID             `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time             `json:"reviewed_at,omitempty"`
+	ReviewNote    string                 `json:"review_note,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// FraudSignalResponse is one reason a purchase was flagged
+type FraudSignalResponse struct {
+	Kind               string    `json:"kind"` // id_card, dob_name, identity, device, ip, payment_memo
+	MatchedUserId      uuid.UUID `json:"matched_user_id"`
+	MatchedBlacklisted bool      `json:"matched_blacklisted"`
+	Weight             int       `json:"weight"`
+	Detail             string    `json:"detail"`
+}
+
+// ScreenPurchasesResponse reports one run of the fraud screening job
+type ScreenPurchasesResponse struct {
+	Screened int      `json:"screened"`
+	Flagged  int      `json:"flagged"`
+	Held     int      `json:"held"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationTokenResponse is the token an admin uses to act as a user. There is no refresh
+// token: a new impersonation must be started once it expires.
+type ImpersonationTokenResponse struct {
+	Id           uuid.UUID `json:"id"`
+	TargetUserId uuid.UUID `json:"target_user_id"`
+	AccessToken  string    `json:"access_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// AdminImpersonationResponse is an impersonation as admins see it
+type AdminImpersonationResponse struct {
+	Id           uuid.UUID  `json:"id"`
+	AdminId      uuid.UUID  `json:"admin_id"`
+	AdminEmail   string     `json:"admin_email"`
+	TargetUserId uuid.UUID  `json:"target_user_id"`
+	UserEmail    string     `json:"user_email"`
+	FursonaName  string     `json:"fursona_name"`
+	Reason       string     `json:"reason"`
+	StartedAt    time.Time  `json:"started_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	NotifiedAt   *time.Time `json:"notified_at,omitempty"`
+	IsActive     bool       `json:"is_active"`
+}
+
+// ProcessImpersonationNoticesResponse reports one run of the impersonation notice job
+type ProcessImpersonationNoticesResponse struct {
+	Notified int      `json:"notified"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,37 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RetentionRuleResponse is a data retention rule with its effective configuration
+type RetentionRuleResponse struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Action      string     `json:"action"` // clear, delete
+	Anchor      string     `json:"anchor"` // what the period counts from, e.g. "event_end" or "sent_at"
+	Days        int        `json:"days"`
+	Enabled     bool       `json:"enabled"`
+	Cutoff      *time.Time `json:"cutoff,omitempty"`  // rows anchored at or before this are due; nil when the rule cannot run now
+	Skipped     string     `json:"skipped,omitempty"` // why the rule does not run now
+}
+
+// RetentionRuleResult reports what one rule purged, or would purge, in a run
+type RetentionRuleResult struct {
+	Rule     string     `json:"rule"`
+	Action   string     `json:"action"`
+	Cutoff   *time.Time `json:"cutoff,omitempty"`
+	Affected int64      `json:"affected"`
+	Skipped  string     `json:"skipped,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// RetentionRunResponse reports one run of the retention purge
+type RetentionRunResponse struct {
+	RunId  uuid.UUID              `json:"run_id"`
+	DryRun bool                   `json:"dry_run"`
+	Rules  []*RetentionRuleResult `json:"rules"`
+	Failed int                    `json:"failed"`
+}
@@ -0,0 +1,23 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionResponse describes one signed-in device of the current user
+type SessionResponse struct {
+	Id         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+// RevokeSessionsResponse reports how many sessions were signed out
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
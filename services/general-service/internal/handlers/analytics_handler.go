@@ -59,3 +59,25 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 
 	utils.RespondSuccess(c, data, "Dashboard analytics")
 }
+
+// GetScheduledJobs godoc
+// @Summary List scheduled job runs (admin only)
+// @Description Returns the latest run of every scheduled job executed by the sqs-worker: schedule, status, timings, last error and result summary.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 "Scheduled job runs"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 500 "Internal server error"
+// @Router /admin/scheduled-jobs [get]
+func (h *AnalyticsHandler) GetScheduledJobs(c *gin.Context) {
+	runs, err := h.services.ScheduledJob.GetRuns(c.Request.Context())
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to load scheduled jobs")
+		return
+	}
+
+	utils.RespondSuccess(c, &runs, "Scheduled jobs")
+}
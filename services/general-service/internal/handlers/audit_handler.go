@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"general-service/internal/common/utils"
+	"general-service/internal/repositories"
+	"general-service/internal/services"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	services *services.Services
+}
+
+func NewAuditHandler(services *services.Services) *AuditHandler {
+	return &AuditHandler{services: services}
+}
+
+// GetAuditLogs godoc
+// @Summary List the admin audit log (admin only)
+// @Description Paginated privileged actions by admins and staff, newest first: actor, action, target, before/after diff (PII redacted), status, IP and request ID, with the hash chain fields.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(20) minimum(1) maximum(100)
+// @Param actor_id query string false "Filter by actor user ID" format(uuid)
+// @Param action query string false "Filter by action, e.g. tickets.approve"
+// @Param entity_type query string false "Filter by entity type, e.g. tickets"
+// @Param entity_id query string false "Filter by entity ID"
+// @Param from query string false "From (inclusive), RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "To (exclusive), RFC 3339 or YYYY-MM-DD"
+// @Success 200 {array} responses.AuditLogResponse "Audit log entries"
+// @Failure 400 "Invalid filter"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+	page := 1
+	pageSize := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	entries, meta, err := h.services.Audit.GetLogs(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to retrieve audit log")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &entries, meta, "Successfully retrieved audit log")
+}
+
+// ExportAuditLogs godoc
+// @Summary Export the admin audit log as CSV (admin only)
+// @Description Streams the entries matching the filters as CSV, oldest first, including prev_hash and hash so the export can be checked against the chain.
+// @Tags admin
+// @Produce text/csv
+// @Security BearerAuth
+// @Param actor_id query string false "Filter by actor user ID" format(uuid)
+// @Param action query string false "Filter by action, e.g. tickets.approve"
+// @Param entity_type query string false "Filter by entity type, e.g. tickets"
+// @Param entity_id query string false "Filter by entity ID"
+// @Param from query string false "From (inclusive), RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "To (exclusive), RFC 3339 or YYYY-MM-DD"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 "Invalid filter"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Router /admin/audit-logs/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+	filename := "audit-log-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(200)
+	// Headers are sent with the first row, so a failure part-way can only cut the file short.
+	if err := h.services.Audit.ExportCSV(c.Request.Context(), filter, c.Writer); err != nil {
+		log.Printf("Audit log CSV export failed: %v", err)
+	}
+}
+
+// VerifyAuditLogChain godoc
+// @Summary Verify the admin audit log hash chain (admin only)
+// @Description Recomputes the hash of every entry in order. Reports the first entry that was modified, removed or reordered, and the head of the chain (keep it elsewhere to detect truncation).
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.AuditChainVerificationResponse "Verification result"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/audit-logs/verify [get]
+func (h *AuditHandler) VerifyAuditLogChain(c *gin.Context) {
+	result, err := h.services.Audit.VerifyChain(c.Request.Context())
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to verify audit log")
+		return
+	}
+	utils.RespondSuccess(c, result, "Audit log verified")
+}
+
+// parseAuditLogFilter reads the audit log filters from the query, responding 400 when one is invalid.
+func parseAuditLogFilter(c *gin.Context) (repositories.AdminAuditLogFilter, bool) {
+	filter := repositories.AdminAuditLogFilter{
+		Action:     strings.TrimSpace(c.Query("action")),
+		EntityType: strings.TrimSpace(c.Query("entity_type")),
+		EntityID:   strings.TrimSpace(c.Query("entity_id")),
+	}
+	if actor := strings.TrimSpace(c.Query("actor_id")); actor != "" {
+		id, err := uuid.Parse(actor)
+		if err != nil {
+			utils.RespondBadRequest(c, "Invalid actor ID")
+			return filter, false
+		}
+		filter.ActorID = &id
+	}
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := strings.TrimSpace(c.Query(bound.param))
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t, err = time.Parse(utils.DateLayout, value)
+		}
+		if err != nil {
+			utils.RespondBadRequest(c, "Invalid "+bound.param+" (use RFC 3339 or YYYY-MM-DD)")
+			return filter, false
+		}
+		*bound.dest = &t
+	}
+	return filter, true
+}
@@ -6,6 +6,7 @@ import (
 	"general-service/internal/common/constants"
 	"general-service/internal/common/utils"
 	"general-service/internal/dto/auth/requests"
+	"general-service/internal/dto/auth/responses"
 	"general-service/internal/services"
 	"os"
 	"strings"
@@ -26,18 +27,7 @@ func getEnvOr(key, defaultValue string) string {
 	return defaultValue
 }
 
-func NewAuthHandler(services *services.Services) *AuthHandler {
-	// Load cookie configuration from environment
-	secure := getEnvOr("COOKIE_SECURE", "true") != "false" // Default to true
-	sameSite := getEnvOr("COOKIE_SAMESITE", "Strict")      // Default to Strict for security
-
-	cookieConfig := utils.CookieConfig{
-		Domain:   getEnvOr("COOKIE_DOMAIN", ""),
-		Secure:   secure,
-		SameSite: sameSite,
-		MaxAge:   int(utils.GetAccessTokenExpiry().Seconds()),
-	}
-
+func NewAuthHandler(services *services.Services, cookieConfig utils.CookieConfig) *AuthHandler {
 	return &AuthHandler{
 		services:     services,
 		cookieConfig: cookieConfig,
@@ -101,6 +91,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Description 1. Send POST request with email and password
 // @Description 2. Receive access_token and refresh_token
 // @Description 3. Use access_token in Authorization header: Bearer YOUR_ACCESS_TOKEN
+// @Description
+// @Description If the account has two-factor authentication (mandatory for some roles), no cookies are set;
+// @Description the response data holds mfa_token for POST /auth/mfa/verify, or for /auth/mfa/enroll when enrollment_required is true.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -128,7 +121,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Call service with context
-	response, err := h.services.Auth.Login(c.Request.Context(), &req)
+	response, err := h.services.Auth.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
 		// Check if it's a rate limit error using sentinel
 		if errors.Is(err, constants.ErrAccountLocked) {
@@ -159,8 +152,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Set JWT access token in cookie using helper
-	utils.SetAuthCookie(c, response.AccessToken, h.cookieConfig)
+	// Second factor needed: no cookies until POST /auth/mfa/verify (or enrolment) succeeds
+	if response.MFA != nil {
+		utils.RespondSuccess(c, response.MFA, "Two-factor authentication required")
+		return
+	}
+
+	// Set JWT access token and refresh token cookies
+	h.setSessionCookies(c, response)
 	utils.RespondSuccess[any](c, nil, "Login successful")
 }
 
@@ -199,7 +198,7 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Auth.ResetPassword(userID, &req); err != nil {
+	if err := h.services.Auth.ResetPassword(c.Request.Context(), userID, currentSessionID(c), &req); err != nil {
 		if errors.Is(err, constants.ErrUserNotFound) {
 			utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
 			return
@@ -257,7 +256,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Auth.ResetPassword(userID, &req); err != nil {
+	if err := h.services.Auth.ResetPassword(c.Request.Context(), userID, currentSessionID(c), &req); err != nil {
 		if errors.Is(err, constants.ErrUserNotFound) {
 			utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
 			return
@@ -283,7 +282,9 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 // GoogleLogin godoc
 // @Summary Login or register with Google
-// @Description Verify Google ID token (credential from Sign-In). If user exists (by Google ID or email), log in; otherwise create account and log in. Sets access token cookie.
+// @Description Verify Google ID token (credential from Sign-In). If a user is linked to the Google account, log in; otherwise create account and log in. Sets access token cookie.
+// @Description An existing account with the same email is not linked automatically (409); its owner links Google from account settings.
+// @Description fullName and nickname default to the Google profile name.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -292,7 +293,9 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 // @Failure 400 "Bad request - missing or invalid credential"
 // @Failure 401 "Invalid Google token"
 // @Failure 403 "Forbidden - account banned"
+// @Failure 409 "An account with this email exists - sign in and link Google instead"
 // @Failure 500 "Internal server error"
+// @Failure 503 "Google sign-in is not configured"
 // @Router /auth/google [post]
 func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 	var req requests.GoogleLoginRequest
@@ -300,51 +303,464 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
 		return
 	}
-	googleClientID := getEnvOr("GOOGLE_CLIENT_ID", "")
-	if googleClientID == "" {
-		utils.RespondErrorWithErrorMessage(c, 503, "SERVICE_UNAVAILABLE", "Google sign-in is not configured", "googleNotConfigured")
-		return
-	}
-	response, err := h.services.Auth.GoogleLoginOrRegister(c.Request.Context(), &req, googleClientID)
+	response, err := h.services.OAuth.LoginWithGoogleIDToken(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		if errors.Is(err, constants.ErrGoogleRegistrationDetailsRequired) {
-			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "googleRegistrationDetailsRequired")
+		if errors.Is(err, constants.ErrOAuthProviderNotConfigured) {
+			utils.RespondErrorWithErrorMessage(c, 503, "SERVICE_UNAVAILABLE", "Google sign-in is not configured", "googleNotConfigured")
 			return
 		}
-		if errors.Is(err, constants.ErrAgeRequirement) {
-			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validation.ageRequirement")
+		if errors.Is(err, constants.ErrGoogleRegistrationDetailsRequired) {
+			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "googleRegistrationDetailsRequired")
 			return
 		}
-		if errors.Is(err, constants.ErrInvalidDateOfBirth) {
-			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		if errors.Is(err, constants.ErrInvalidOAuthLogin) {
+			utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, "Invalid Google token", "invalidGoogleToken")
 			return
 		}
-		if errors.Is(err, constants.ErrPasswordMismatch) {
-			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, "Passwords do not match", "passwordsDoNotMatch")
+		respondOAuthError(c, err, "Google sign-in failed", "googleLoginFailed")
+		return
+	}
+	h.respondProviderLogin(c, response)
+}
+
+// GetOAuthProviders godoc
+// @Summary List external sign-in providers
+// @Description Providers that are configured: google, discord and facebook use /auth/oauth/{provider}/authorize; google also accepts Sign-In credentials at /auth/google; telegram uses /auth/telegram.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} responses.OAuthProvidersResponse
+// @Router /auth/oauth/providers [get]
+func (h *AuthHandler) GetOAuthProviders(c *gin.Context) {
+	utils.RespondSuccess(c, h.services.OAuth.Providers(), "Sign-in providers retrieved")
+}
+
+// BeginOAuthLogin godoc
+// @Summary Start a provider login
+// @Description Returns the provider's authorization URL (authorization code flow with PKCE). After the user approves, the provider redirects to the frontend callback with code and state; post them to /auth/oauth/{provider}/callback.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider" Enums(google, discord, facebook)
+// @Success 200 {object} responses.OAuthAuthorizationResponse
+// @Failure 503 "Provider not configured"
+// @Router /auth/oauth/{provider}/authorize [post]
+func (h *AuthHandler) BeginOAuthLogin(c *gin.Context) {
+	response, err := h.services.OAuth.BeginLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		respondOAuthError(c, err, "Failed to start sign-in", "oauthLoginFailed")
+		return
+	}
+	utils.RespondSuccess(c, response, "Authorization URL created")
+}
+
+// OAuthCallback godoc
+// @Summary Finish a provider login
+// @Description Exchange the code and state from the provider redirect. Logs in (sets cookies), or returns an MFA challenge, or, for a new account that needs more details, registration_required with a registration_token and the profile fields prefilled from the provider.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider" Enums(google, discord, facebook)
+// @Param request body requests.OAuthCallbackRequest true "Code, state and optional registration details"
+// @Success 200 "Logged in, MFA challenge, or registration details required"
+// @Failure 400 "Provider shared no verified email, or invalid registration details"
+// @Failure 401 "Invalid or expired state, or the provider rejected the code"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 409 "An account with this email exists - sign in and link the provider instead"
+// @Failure 503 "Provider not configured"
+// @Router /auth/oauth/{provider}/callback [post]
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	var req requests.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+	response, err := h.services.OAuth.CompleteLogin(c.Request.Context(), c.Param("provider"), &req, clientInfo(c))
+	if err != nil {
+		respondOAuthError(c, err, "Sign-in failed", "oauthLoginFailed")
+		return
+	}
+	h.respondProviderLogin(c, response)
+}
+
+// CompleteOAuthRegistration godoc
+// @Summary Complete sign-up after a provider login
+// @Description Create the account for a provider login that answered registration_required, then log in.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.OAuthRegisterRequest true "Registration token and details"
+// @Success 200 "Registered and logged in, or MFA challenge"
+// @Failure 400 "Missing or invalid registration details"
+// @Failure 401 "Registration token expired"
+// @Failure 409 "An account with this email exists - sign in and link the provider instead"
+// @Router /auth/oauth/register [post]
+func (h *AuthHandler) CompleteOAuthRegistration(c *gin.Context) {
+	var req requests.OAuthRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+	response, err := h.services.OAuth.CompleteRegistration(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondOAuthError(c, err, "Sign-up failed", "oauthLoginFailed")
+		return
+	}
+	h.respondProviderLogin(c, response)
+}
+
+// TelegramLogin godoc
+// @Summary Login with Telegram
+// @Description Verify the data signed by the Telegram Login Widget and log in the account linked to that Telegram user. Telegram shares no email address, so it cannot create accounts.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.TelegramLoginRequest true "Telegram Login Widget data"
+// @Success 200 "Logged in or MFA challenge"
+// @Failure 400 "No account is linked to this Telegram user"
+// @Failure 401 "Invalid or expired Telegram data"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 503 "Telegram sign-in is not configured"
+// @Router /auth/telegram [post]
+func (h *AuthHandler) TelegramLogin(c *gin.Context) {
+	var req requests.TelegramLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+	response, err := h.services.OAuth.LoginWithTelegram(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondOAuthError(c, err, "Telegram sign-in failed", "oauthLoginFailed")
+		return
+	}
+	h.respondProviderLogin(c, response)
+}
+
+// respondProviderLogin answers a provider login: registration details needed, MFA challenge, or
+// session cookies.
+func (h *AuthHandler) respondProviderLogin(c *gin.Context, response *responses.LoginResponse) {
+	if response.Registration != nil {
+		utils.RespondSuccess(c, response.Registration, "Registration details required")
+		return
+	}
+	if response.MFA != nil {
+		utils.RespondSuccess(c, response.MFA, "Two-factor authentication required")
+		return
+	}
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Login successful")
+}
+
+// respondOAuthError maps provider login errors, including registration validation errors
+func respondOAuthError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrOAuthProviderNotConfigured):
+		utils.RespondErrorWithErrorMessage(c, 503, "SERVICE_UNAVAILABLE", err.Error(), "oauthProviderNotConfigured")
+	case errors.Is(err, constants.ErrInvalidOAuthState):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "oauthStateInvalid")
+	case errors.Is(err, constants.ErrInvalidOAuthLogin):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, "Sign-in provider rejected the login", "oauthLoginInvalid")
+	case errors.Is(err, constants.ErrOAuthRegistrationExpired):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "oauthRegistrationExpired")
+	case errors.Is(err, constants.ErrOAuthVerifiedEmailRequired):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "oauthVerifiedEmailRequired")
+	case errors.Is(err, constants.ErrOAuthAccountExists):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "oauthAccountExists")
+	case errors.Is(err, constants.ErrIdentityAlreadyLinked):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "identityAlreadyLinked")
+	case errors.Is(err, constants.ErrProviderAlreadyLinked):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "providerAlreadyLinked")
+	case errors.Is(err, constants.ErrIdentityNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "identityNotFound")
+	case errors.Is(err, constants.ErrLastLoginMethod):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "lastLoginMethod")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	case errors.Is(err, constants.ErrOAuthRegistrationDetailsRequired):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "oauthRegistrationDetailsRequired")
+	case errors.Is(err, constants.ErrAgeRequirement):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validation.ageRequirement")
+	case errors.Is(err, constants.ErrInvalidDateOfBirth):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+	case errors.Is(err, constants.ErrPasswordMismatch):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, "Passwords do not match", "passwordsDoNotMatch")
+	case strings.Contains(err.Error(), "password must be at least 6"):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+	case errors.Is(err, constants.ErrAccountBanned):
+		utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, "Account is banned", "accountBanned")
+	default:
+		fmt.Printf("[ERROR] Provider sign-in failed: %v\n", err)
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
+
+// VerifyMFA godoc
+// @Summary Complete login with a two-factor code
+// @Description Exchange the mfa_token returned by login and a code from the authenticator app (or a recovery code) for the session cookies.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MFAVerifyRequest true "MFA token and code"
+// @Success 200 "Login successful"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Invalid or expired MFA token, or wrong code"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 429 "Too many wrong codes - temporarily locked"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req requests.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, "Provide either code or recovery_code", "validationFailed")
+		return
+	}
+
+	response, err := h.services.MFA.CompleteLogin(c.Request.Context(), req.MFAToken, req.Code, req.RecoveryCode, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err, "Failed to verify two-factor code", "mfaVerifyFailed")
+		return
+	}
+
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Login successful")
+}
+
+// EnrollMFA godoc
+// @Summary Set up an authenticator during login
+// @Description For accounts whose role requires two-factor authentication but that have no authenticator yet.
+// @Description Takes the mfa_token from login (enrollment_required=true) and returns the TOTP secret, otpauth URI and QR code.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MFAEnrollRequest true "MFA enrolment token"
+// @Success 200 {object} responses.TOTPSetupResponse "Authenticator secret"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Invalid or expired MFA token"
+// @Failure 409 "Two-factor authentication already enabled"
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req requests.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	setup, err := h.services.MFA.BeginEnrollmentForLogin(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err, "Failed to start two-factor setup", "mfaSetupFailed")
+		return
+	}
+	utils.RespondSuccess(c, setup, "Scan the QR code with your authenticator app")
+}
+
+// ConfirmMFAEnrollment godoc
+// @Summary Confirm the authenticator and finish login
+// @Description Checks a code from the authenticator set up with /auth/mfa/enroll, enables two-factor authentication,
+// @Description sets the session cookies and returns the recovery codes (shown only once).
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MFAEnrollConfirmRequest true "MFA enrolment token and code"
+// @Success 200 {object} responses.RecoveryCodesResponse "Login successful; recovery codes"
+// @Failure 400 "Bad request - validation error or setup not started"
+// @Failure 401 "Invalid or expired MFA token, or wrong code"
+// @Failure 429 "Too many wrong codes - temporarily locked"
+// @Router /auth/mfa/enroll/confirm [post]
+func (h *AuthHandler) ConfirmMFAEnrollment(c *gin.Context) {
+	var req requests.MFAEnrollConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	response, codes, err := h.services.MFA.ConfirmEnrollmentForLogin(c.Request.Context(), req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err, "Failed to confirm two-factor setup", "mfaSetupFailed")
+		return
+	}
+
+	h.setSessionCookies(c, response)
+	data := responses.RecoveryCodesResponse{RecoveryCodes: codes}
+	utils.RespondSuccess(c, &data, "Two-factor authentication enabled")
+}
+
+// BeginPasskeyLogin godoc
+// @Summary Start a passkey login
+// @Description Returns PublicKeyCredentialRequestOptions for navigator.credentials.get(). Valid for 5 minutes and one attempt.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} responses.PasskeyRequestOptions "WebAuthn request options"
+// @Failure 500 "Internal server error"
+// @Router /auth/passkey/login/begin [post]
+func (h *AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	options, err := h.services.Auth.BeginPasskeyLogin(c.Request.Context())
+	if err != nil {
+		respondMFAError(c, err, "Failed to start passkey login", "passkeyLoginFailed")
+		return
+	}
+	utils.RespondSuccess(c, options, "Passkey login started")
+}
+
+// FinishPasskeyLogin godoc
+// @Summary Log in with a passkey
+// @Description Verifies the credential returned by navigator.credentials.get() and sets the session cookies.
+// @Description Passkeys require user verification, so no two-factor challenge follows.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.PasskeyLoginRequest true "WebAuthn assertion"
+// @Success 200 "Login successful"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Passkey verification failed or challenge expired"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 404 "Unknown passkey"
+// @Router /auth/passkey/login/finish [post]
+func (h *AuthHandler) FinishPasskeyLogin(c *gin.Context) {
+	var req requests.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	response, err := h.services.Auth.FinishPasskeyLogin(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err, "Passkey login failed", "passkeyLoginFailed")
+		return
+	}
+
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Login successful")
+}
+
+// BeginPasskeyMFA godoc
+// @Summary Answer a two-factor challenge with a passkey
+// @Description Takes the mfa_token from login (when methods contains "passkey") and returns request options limited to the user's passkeys.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MFAEnrollRequest true "MFA token"
+// @Success 200 {object} responses.PasskeyRequestOptions "WebAuthn request options"
+// @Failure 401 "Invalid or expired MFA token"
+// @Failure 404 "User has no passkeys"
+// @Router /auth/mfa/passkey/begin [post]
+func (h *AuthHandler) BeginPasskeyMFA(c *gin.Context) {
+	var req requests.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	options, err := h.services.MFA.BeginPasskeyChallenge(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err, "Failed to start passkey verification", "mfaVerifyFailed")
+		return
+	}
+	utils.RespondSuccess(c, options, "Passkey verification started")
+}
+
+// FinishPasskeyMFA godoc
+// @Summary Complete login with a passkey as second factor
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.PasskeyMFARequest true "MFA token and WebAuthn assertion"
+// @Success 200 "Login successful"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Invalid MFA token, passkey verification failed or challenge expired"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 404 "Unknown passkey"
+// @Router /auth/mfa/passkey/finish [post]
+func (h *AuthHandler) FinishPasskeyMFA(c *gin.Context) {
+	var req requests.PasskeyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	response, err := h.services.MFA.CompletePasskeyChallenge(c.Request.Context(), req.MFAToken, &req.Credential, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err, "Failed to verify passkey", "mfaVerifyFailed")
+		return
+	}
+
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Login successful")
+}
+
+// respondMFAError maps two-factor and passkey errors to responses; anything else is a 500 with fallback
+func respondMFAError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrInvalidPasskey):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, constants.ErrInvalidPasskey.Error(), "passkeyInvalid")
+	case errors.Is(err, constants.ErrPasskeyChallengeExpired):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "passkeyChallengeExpired")
+	case errors.Is(err, constants.ErrPasskeyNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "passkeyNotFound")
+	case errors.Is(err, constants.ErrPasskeyAlreadyRegistered):
+		utils.RespondErrorWithErrorMessage(c, 409, "PASSKEY_ALREADY_REGISTERED", err.Error(), "passkeyAlreadyRegistered")
+	case errors.Is(err, constants.ErrInvalidMFAToken):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "mfaTokenInvalid")
+	case errors.Is(err, constants.ErrInvalidMFACode):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "mfaCodeInvalid")
+	case errors.Is(err, constants.ErrAccountLocked):
+		utils.RespondErrorWithErrorMessage(c, 429, constants.ErrCodeTooManyRequests, "Too many failed attempts", "accountLocked")
+	case errors.Is(err, constants.ErrAccountBanned):
+		utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, "Account is banned", "accountBanned")
+	case errors.Is(err, constants.ErrMFAAlreadyEnabled):
+		utils.RespondErrorWithErrorMessage(c, 409, "MFA_ALREADY_ENABLED", err.Error(), "mfaAlreadyEnabled")
+	case errors.Is(err, constants.ErrMFANotEnabled):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "mfaNotEnabled")
+	case errors.Is(err, constants.ErrMFASetupNotStarted):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "mfaSetupNotStarted")
+	case errors.Is(err, constants.ErrMFARequiredForRole):
+		utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, err.Error(), "mfaRequiredForRole")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	default:
+		fmt.Printf("[ERROR] %s: %v\n", fallbackMsg, err)
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
+
+// Refresh godoc
+// @Summary Refresh the access token
+// @Description Exchange the refresh_token cookie for a new access token and a new refresh token (rotation).
+// @Description Each refresh token can be used once; presenting a used token revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 "Tokens refreshed"
+// @Failure 401 "Missing, invalid, expired or reused refresh token"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 500 "Internal server error"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	refreshToken, _ := c.Cookie(utils.RefreshCookieName)
+
+	response, err := h.services.Auth.RefreshTokens(c.Request.Context(), refreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidRefreshToken) || errors.Is(err, constants.ErrRefreshTokenReused) {
+			h.clearSessionCookies(c)
+			utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "sessionExpired")
 			return
 		}
 		if errors.Is(err, constants.ErrAccountBanned) {
+			h.clearSessionCookies(c)
 			utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, "Account is banned", "accountBanned")
 			return
 		}
-		if strings.Contains(err.Error(), "invalid google token") {
-			utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, "Invalid Google token", "invalidGoogleToken")
-			return
-		}
-		if strings.Contains(err.Error(), "password must be at least 6") {
-			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
-			return
-		}
-		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "Google sign-in failed", "googleLoginFailed")
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "Failed to refresh session", "refreshFailed")
 		return
 	}
-	utils.SetAuthCookie(c, response.AccessToken, h.cookieConfig)
-	utils.RespondSuccess[any](c, nil, "Login successful")
+
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Session refreshed")
 }
 
 // Logout godoc
 // @Summary Logout from the system
-// @Description Remove access token cookie to logout user
+// @Description End the current session (its access and refresh tokens stop working) and remove the auth cookies. With an impersonation token, end the impersonation
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -352,11 +768,64 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 // @Success 200 "Successfully logged out"
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// Clear the access token cookie using helper
-	utils.ClearAuthCookie(c, h.cookieConfig)
+	refreshToken, _ := c.Cookie(utils.RefreshCookieName)
+	if err := h.services.Auth.Logout(c.Request.Context(), currentClaims(c), refreshToken); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke session on logout: %v\n", err)
+	}
+	h.clearSessionCookies(c)
 	utils.RespondSuccess[any](c, nil, "Logout successful")
 }
 
+// GetJWKS godoc
+// @Summary Get token verification keys
+// @Description Public keys (JWKS, RFC 7517) other services use to verify access tokens; tokens name their key in the kid header
+// @Tags auth
+// @Produce json
+// @Success 200 "JWKS document"
+// @Failure 500 "Signing keys misconfigured"
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	jwks, err := utils.GetJWKS()
+	if err != nil {
+		fmt.Printf("[ERROR] Failed to load JWT signing keys: %v\n", err)
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "Signing keys unavailable", "jwksUnavailable")
+		return
+	}
+	// Standard JWKS document rather than the usual response envelope, so JWT libraries can fetch it
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, jwks)
+}
+
+func (h *AuthHandler) setSessionCookies(c *gin.Context, response *responses.LoginResponse) {
+	utils.SetAuthCookie(c, response.AccessToken, h.cookieConfig)
+	utils.SetRefreshCookie(c, response.RefreshToken, h.cookieConfig)
+}
+
+func (h *AuthHandler) clearSessionCookies(c *gin.Context) {
+	utils.ClearAuthCookie(c, h.cookieConfig)
+	utils.ClearRefreshCookie(c, h.cookieConfig)
+}
+
+// currentClaims returns the access token claims set by JWTAuthMiddleware, or nil
+func currentClaims(c *gin.Context) *utils.JWTClaims {
+	claims, _ := c.Get("claims")
+	jwtClaims, _ := claims.(*utils.JWTClaims)
+	return jwtClaims
+}
+
+// currentSessionID returns the session of the request's access token ("" for older tokens)
+func currentSessionID(c *gin.Context) string {
+	if claims := currentClaims(c); claims != nil {
+		return claims.SessionID
+	}
+	return ""
+}
+
+// clientInfo describes the requesting device for the session record
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+}
+
 // VerifyOtp godoc
 // @Summary Verify OTP code
 // @Description Verify the OTP code sent to user's email and mark account as verified
@@ -470,6 +939,83 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	utils.RespondSuccess[any](c, nil, "If an account with that email exists, a password reset token has been sent")
 }
 
+// RequestMagicLink godoc
+// @Summary Request a sign-in link by email
+// @Description Emails a single-use link to sign in without a password. The response is the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MagicLinkRequest true "Account email"
+// @Success 200 "Sign-in link sent if the account exists"
+// @Failure 400 "Bad request - validation error"
+// @Failure 429 "Too many requests"
+// @Failure 500 "Internal server error"
+// @Router /auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req requests.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	fromEmail := getEnvOr("SES_EMAIL_IDENTITY", "")
+	frontendURL := getEnvOr("FRONTEND_URL", "")
+
+	if err := h.services.Auth.RequestMagicLink(c.Request.Context(), req.Email, h.services.Mail, frontendURL, fromEmail); err != nil {
+		fmt.Printf("[ERROR] Magic link request failed: %v\n", err)
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "Failed to send sign-in link", "magicLinkFailed")
+		return
+	}
+
+	utils.RespondSuccess[any](c, nil, "If an account with that email exists, a sign-in link has been sent")
+}
+
+// MagicLinkLogin godoc
+// @Summary Sign in with a magic link
+// @Description Exchange the token from the emailed link for the session cookies. The link works once; an unverified account is verified by it.
+// @Description If the account has two-factor authentication, no cookies are set and the response data holds mfa_token as for /auth/login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.MagicLinkLoginRequest true "Token from the link"
+// @Success 200 "Logged in or MFA challenge"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Invalid, expired or already used link"
+// @Failure 403 "Forbidden - account banned"
+// @Failure 500 "Internal server error"
+// @Router /auth/magic-link/verify [post]
+func (h *AuthHandler) MagicLinkLogin(c *gin.Context) {
+	var req requests.MagicLinkLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	response, err := h.services.Auth.LoginWithMagicLink(c.Request.Context(), req.Token, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidMagicLink) {
+			utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "magicLinkInvalid")
+			return
+		}
+		if errors.Is(err, constants.ErrAccountBanned) {
+			utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, "Account is banned", "accountBanned")
+			return
+		}
+		fmt.Printf("[ERROR] Magic link login failed: %v\n", err)
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "An error occurred during login", "loginFailed")
+		return
+	}
+
+	// Second factor needed: no cookies until POST /auth/mfa/verify (or enrolment) succeeds
+	if response.MFA != nil {
+		utils.RespondSuccess(c, response.MFA, "Two-factor authentication required")
+		return
+	}
+
+	h.setSessionCookies(c, response)
+	utils.RespondSuccess[any](c, nil, "Login successful")
+}
+
 // ResetPasswordConfirm godoc
 // @Tags auth
 // @Accept json
@@ -484,7 +1030,7 @@ func (h *AuthHandler) ResetPasswordConfirm(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Auth.ResetPasswordWithToken(req.Token, &req); err != nil {
+	if err := h.services.Auth.ResetPasswordWithToken(c.Request.Context(), req.Token, &req); err != nil {
 		if errors.Is(err, constants.ErrPasswordMismatch) {
 			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "passwordsDoNotMatch")
 			return
@@ -499,3 +1045,38 @@ func (h *AuthHandler) ResetPasswordConfirm(c *gin.Context) {
 
 	utils.RespondSuccess[any](c, nil, "Password has been reset successfully")
 }
+
+// RevertEmailChange godoc
+// @Summary Undo an email change
+// @Description Restores the previous email with the token from the notice sent to it when the email was changed. All sessions are signed out; reset the password if the change was not yours.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.RevertEmailChangeRequest true "Revert token"
+// @Success 200 "Previous email restored"
+// @Failure 400 "Invalid or expired revert link"
+// @Failure 409 "Previous email is now used by another account"
+// @Failure 500 "Internal server error"
+// @Router /auth/email/revert [post]
+func (h *AuthHandler) RevertEmailChange(c *gin.Context) {
+	var req requests.RevertEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeValidationFailed, err.Error(), "validationFailed")
+		return
+	}
+
+	if err := h.services.Auth.RevertEmailChange(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, constants.ErrInvalidEmailRevertToken) {
+			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "emailRevertInvalid")
+			return
+		}
+		if errors.Is(err, constants.ErrEmailAlreadyInUse) {
+			utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "emailAlreadyInUse")
+			return
+		}
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, "Failed to restore email", "emailRevertFailed")
+		return
+	}
+
+	utils.RespondSuccess[any](c, nil, "Previous email has been restored")
+}
@@ -164,6 +164,7 @@ func (h *DealerHandler) EditDealer(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20) maximum(100)
 // @Param is_verified query bool false "Filter by verification status"
@@ -214,6 +215,7 @@ func (h *DealerHandler) GetDealersForAdmin(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param id path string true "Dealer Booth ID" format(uuid)
 // @Success 200 "Successfully retrieved dealer booth"
 // @Failure 400 "Invalid dealer booth ID"
@@ -317,7 +319,7 @@ func (h *DealerHandler) DenyDealer(c *gin.Context) {
 		return
 	}
 
-	booth, err := h.services.Dealer.DenyDealerBooth(boothID)
+	booth, err := h.services.Dealer.DenyDealerBooth(c.Request.Context(), boothID)
 	if err != nil {
 		errMsg := err.Error()
 		switch errMsg {
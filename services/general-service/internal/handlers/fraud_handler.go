@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/user/requests"
+	"general-service/internal/models"
+	"general-service/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FraudHandler struct {
+	services *services.Services
+}
+
+func NewFraudHandler(services *services.Services) *FraudHandler {
+	return &FraudHandler{services: services}
+}
+
+// GetFraudAssessments godoc
+// @Summary List the fraud review queue (admin only)
+// @Description Ticket purchases flagged as likely duplicate accounts (risk score at least FRAUD_REVIEW_SCORE), highest score first, with the signals shared with other accounts.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param status query string false "Review status" Enums(open, cleared, confirmed)
+// @Param min_score query int false "Only assessments scoring at least this" minimum(0) maximum(100)
+// @Success 200 {array} responses.FraudAssessmentResponse "Fraud assessments"
+// @Failure 400 "Invalid status"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/fraud/assessments [get]
+func (h *FraudHandler) GetFraudAssessments(c *gin.Context) {
+	page := 1
+	pageSize := 10
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+	minScore := 0
+	if minScoreStr := c.Query("min_score"); minScoreStr != "" {
+		if parsed, err := strconv.Atoi(minScoreStr); err == nil && parsed > 0 {
+			minScore = parsed
+		}
+	}
+	status := strings.TrimSpace(c.Query("status"))
+	switch models.FraudReviewStatus(status) {
+	case "", models.FraudReviewOpen, models.FraudReviewCleared, models.FraudReviewConfirmed:
+	default:
+		utils.RespondBadRequest(c, "Invalid status. Expected open, cleared or confirmed")
+		return
+	}
+
+	assessments, meta, err := h.services.Fraud.GetAssessments(c.Request.Context(), page, pageSize, status, minScore)
+	if err != nil {
+		respondFraudError(c, err, "Failed to retrieve fraud assessments", "fraudAssessmentListFailed")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &assessments, meta, "Successfully retrieved fraud assessments")
+}
+
+// GetFraudAssessment godoc
+// @Summary Get a fraud assessment (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Fraud assessment ID" format(uuid)
+// @Success 200 {object} responses.FraudAssessmentResponse "Fraud assessment"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Fraud assessment not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/fraud/assessments/{id} [get]
+func (h *FraudHandler) GetFraudAssessment(c *gin.Context) {
+	assessment, err := h.services.Fraud.GetAssessment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondFraudError(c, err, "Failed to retrieve fraud assessment", "fraudAssessmentGetFailed")
+		return
+	}
+	utils.RespondSuccess(c, assessment, "Successfully retrieved fraud assessment")
+}
+
+// ClearFraudAssessment godoc
+// @Summary Clear a flagged purchase (admin only)
+// @Description Closes the assessment as not a duplicate account; a held ticket can be approved again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Fraud assessment ID" format(uuid)
+// @Param request body requests.ClearFraudAssessmentRequest false "Review note"
+// @Success 200 {object} responses.FraudAssessmentResponse "Fraud assessment cleared"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Fraud assessment not found"
+// @Failure 409 "Fraud assessment already reviewed"
+// @Failure 500 "Internal server error"
+// @Router /admin/fraud/assessments/{id}/clear [patch]
+func (h *FraudHandler) ClearFraudAssessment(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.ClearFraudAssessmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondValidationError(c, err.Error())
+			return
+		}
+	}
+	assessment, err := h.services.Fraud.ClearAssessment(c.Request.Context(), adminID.String(), c.Param("id"), &req)
+	if err != nil {
+		respondFraudError(c, err, "Failed to clear fraud assessment", "fraudAssessmentClearFailed")
+		return
+	}
+	utils.RespondSuccess(c, assessment, "Fraud assessment cleared")
+}
+
+// ConfirmFraudAssessment godoc
+// @Summary Confirm a duplicate account (admin only)
+// @Description Closes the assessment as a duplicate account. The ticket stays held so it can be denied; with blacklist the account is banned from buying tickets too.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Fraud assessment ID" format(uuid)
+// @Param request body requests.ConfirmFraudAssessmentRequest false "Review note and whether to blacklist"
+// @Success 200 {object} responses.FraudAssessmentResponse "Fraud assessment confirmed"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Fraud assessment not found"
+// @Failure 409 "Fraud assessment already reviewed"
+// @Failure 500 "Internal server error"
+// @Router /admin/fraud/assessments/{id}/confirm [patch]
+func (h *FraudHandler) ConfirmFraudAssessment(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.ConfirmFraudAssessmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondValidationError(c, err.Error())
+			return
+		}
+	}
+	assessment, err := h.services.Fraud.ConfirmAssessment(c.Request.Context(), adminID.String(), c.Param("id"), &req)
+	if err != nil {
+		respondFraudError(c, err, "Failed to confirm fraud assessment", "fraudAssessmentConfirmFailed")
+		return
+	}
+	utils.RespondSuccess(c, assessment, "Fraud assessment confirmed")
+}
+
+// respondFraudError maps fraud review errors
+func respondFraudError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrFraudAssessmentNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "fraudAssessmentNotFound")
+	case errors.Is(err, constants.ErrFraudAssessmentReviewed):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "fraudAssessmentReviewed")
+	case errors.Is(err, constants.ErrInvalidUserID):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidUserId")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
@@ -1,32 +1,43 @@
 package handlers
 
 import (
+	"general-service/internal/common/utils"
 	"general-service/internal/queue"
 	"general-service/internal/services"
 )
 
 type Handlers struct {
-	Auth      *AuthHandler
-	User      *UserHandler
-	Ticket    *TicketHandler
-	Dealer    *DealerHandler
-	Conbook   *ConbookHandler
-	Panel     *PanelHandler
-	Talent    *TalentHandler
-	Analytics *AnalyticsHandler
-	DevMail   *DevMailHandler
+	Auth           *AuthHandler
+	User           *UserHandler
+	Ticket         *TicketHandler
+	Dealer         *DealerHandler
+	Conbook        *ConbookHandler
+	Panel          *PanelHandler
+	Talent         *TalentHandler
+	Analytics      *AnalyticsHandler
+	Retention      *RetentionHandler
+	Audit          *AuditHandler
+	Impersonation  *ImpersonationHandler
+	ServiceAccount *ServiceAccountHandler
+	Fraud          *FraudHandler
+	DevMail        *DevMailHandler
 }
 
-func NewHandlers(services *services.Services, queuePublisher queue.Publisher) *Handlers {
+func NewHandlers(services *services.Services, queuePublisher queue.Publisher, cookieConfig utils.CookieConfig) *Handlers {
 	return &Handlers{
-		Auth:      NewAuthHandler(services),
-		User:      NewUserHandler(services),
-		Ticket:    NewTicketHandler(services, queuePublisher),
-		Dealer:    NewDealerHandler(services),
-		Conbook:   NewConbookHandler(services),
-		Panel:     NewPanelHandler(services),
-		Talent:    NewTalentHandler(services),
-		Analytics: NewAnalyticsHandler(services),
-		DevMail:   NewDevMailHandler(services),
+		Auth:           NewAuthHandler(services, cookieConfig),
+		User:           NewUserHandler(services, queuePublisher),
+		Ticket:         NewTicketHandler(services, queuePublisher),
+		Dealer:         NewDealerHandler(services),
+		Conbook:        NewConbookHandler(services),
+		Panel:          NewPanelHandler(services),
+		Talent:         NewTalentHandler(services),
+		Analytics:      NewAnalyticsHandler(services),
+		Retention:      NewRetentionHandler(services),
+		Audit:          NewAuditHandler(services),
+		Impersonation:  NewImpersonationHandler(services),
+		ServiceAccount: NewServiceAccountHandler(services),
+		Fraud:          NewFraudHandler(services),
+		DevMail:        NewDevMailHandler(services),
 	}
 }
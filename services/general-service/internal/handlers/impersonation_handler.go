@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/user/requests"
+	"general-service/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	services *services.Services
+}
+
+func NewImpersonationHandler(services *services.Services) *ImpersonationHandler {
+	return &ImpersonationHandler{services: services}
+}
+
+// StartImpersonation godoc
+// @Summary Impersonate an attendee for support (admin only)
+// @Description Issues a short-lived access token (IMPERSONATION_TOKEN_EXPIRY_MINUTES, at most the access token expiry) for an attendee account, with an act claim naming the admin.
+// @Description Every request made with it is written to the admin audit log; password, login method, payment, deletion and data export actions are refused. The user is emailed once it is over.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.StartImpersonationRequest true "User to impersonate and the support reason"
+// @Success 200 {object} responses.ImpersonationTokenResponse "Impersonation token"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions, or the account cannot be impersonated"
+// @Failure 404 "User not found"
+// @Failure 409 "The admin already has an active impersonation"
+// @Failure 500 "Internal server error"
+// @Router /admin/impersonations [post]
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	token, err := h.services.Impersonation.Start(c.Request.Context(), adminID.String(), &req)
+	if err != nil {
+		respondImpersonationError(c, err, "Failed to start impersonation", "impersonationStartFailed")
+		return
+	}
+	utils.RespondSuccess(c, token, "Impersonation started")
+}
+
+// EndImpersonation godoc
+// @Summary End an impersonation (admin only)
+// @Description Revokes the impersonation token before it expires and emails the user.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Impersonation ID" format(uuid)
+// @Success 200 {object} responses.AdminImpersonationResponse "Impersonation ended"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Impersonation not found"
+// @Failure 409 "Impersonation already ended or expired"
+// @Failure 500 "Internal server error"
+// @Router /admin/impersonations/{id}/end [post]
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	impersonation, err := h.services.Impersonation.End(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondImpersonationError(c, err, "Failed to end impersonation", "impersonationEndFailed")
+		return
+	}
+	utils.RespondSuccess(c, impersonation, "Impersonation ended")
+}
+
+// GetImpersonations godoc
+// @Summary List impersonations (admin only)
+// @Description Paginated impersonations, newest first. The requests made during one are in the audit log (action impersonation.*, entity the user).
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param user_id query string false "Only impersonations of this user" format(uuid)
+// @Success 200 {array} responses.AdminImpersonationResponse "Impersonations"
+// @Failure 400 "Invalid user ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/impersonations [get]
+func (h *ImpersonationHandler) GetImpersonations(c *gin.Context) {
+	page := 1
+	pageSize := 10
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	impersonations, meta, err := h.services.Impersonation.GetImpersonations(c.Request.Context(), page, pageSize, strings.TrimSpace(c.Query("user_id")))
+	if err != nil {
+		respondImpersonationError(c, err, "Failed to retrieve impersonations", "impersonationListFailed")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &impersonations, meta, "Successfully retrieved impersonations")
+}
+
+// respondImpersonationError maps impersonation errors
+func respondImpersonationError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrImpersonationNotAllowed):
+		utils.RespondErrorWithErrorMessage(c, 403, constants.ErrCodeForbidden, err.Error(), "impersonationNotAllowed")
+	case errors.Is(err, constants.ErrImpersonationActive):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "impersonationActive")
+	case errors.Is(err, constants.ErrImpersonationEnded):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "impersonationEnded")
+	case errors.Is(err, constants.ErrImpersonationNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "impersonationNotFound")
+	case errors.Is(err, constants.ErrInvalidUserID):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidUserId")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
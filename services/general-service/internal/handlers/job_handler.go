@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"general-service/internal/audit"
+	"general-service/internal/common/constants"
 	"general-service/internal/common/utils"
 	"general-service/internal/dto/ticket/requests"
 	"general-service/internal/queue"
@@ -9,12 +12,14 @@ import (
 	"general-service/internal/services"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // ProcessTicketJob handles internal ticket job requests from the SQS worker.
-// Expects X-Internal-Api-Key header and JSON body matching queue.TicketJobMessage.
+// Expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching queue.TicketJobMessage.
 func (h *TicketHandler) ProcessTicketJob(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -24,6 +29,13 @@ func (h *TicketHandler) ProcessTicketJob(c *gin.Context) {
 		return
 	}
 
+	// Staff decisions queued by the admin API are logged with their changes against the staff member.
+	if msg.StaffID != "" {
+		recorder := &audit.Recorder{}
+		ctx = audit.NewContext(ctx, recorder)
+		defer h.recordStaffTicketJob(c, &msg, recorder)
+	}
+
 	switch msg.Action {
 	case queue.ActionPurchaseTicket:
 		_, err := h.services.Ticket.PurchaseTicket(ctx, msg.UserID, &requests.PurchaseTicketRequest{TierID: msg.TierID}, msg.AdminBypass)
@@ -101,6 +113,192 @@ func (h *TicketHandler) ProcessTicketJob(c *gin.Context) {
 	}
 }
 
+// ProcessBulkApproveJob approves a chunk of a queued bulk approval through the same path as the
+// synchronous one, so approval emails are sent. Tickets already approved by an earlier attempt are
+// reported as failed (wrong status) rather than approved and emailed again.
+// Called by the sqs-worker; expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching queue.BulkApproveJobMessage.
+func (h *TicketHandler) ProcessBulkApproveJob(c *gin.Context) {
+	var msg queue.BulkApproveJobMessage
+	if err := c.ShouldBindJSON(&msg); err != nil {
+		utils.RespondBadRequest(c, "Invalid job payload: "+err.Error())
+		return
+	}
+	if _, err := uuid.Parse(msg.StaffID); err != nil || len(msg.TicketIDs) == 0 {
+		utils.RespondBadRequest(c, "Invalid job payload: staff_id and ticket_ids are required")
+		return
+	}
+
+	recorder := &audit.Recorder{}
+	ctx := audit.NewContext(c.Request.Context(), recorder)
+	result := h.services.Ticket.BulkApproveTickets(ctx, msg.TicketIDs, msg.StaffID)
+	if len(result.Approved) > 0 {
+		action := &audit.Action{
+			ActorID:   msg.StaffID,
+			Name:      "tickets.bulk_approve",
+			RequestID: c.GetString("request_id"),
+			Status:    http.StatusOK,
+			Changes:   recorder.Changes(),
+		}
+		if err := h.services.Audit.RecordAdminAction(context.WithoutCancel(ctx), action); err != nil {
+			log.Printf("Failed to write admin audit log for bulk approve job: %v", err)
+		}
+	}
+	utils.RespondSuccess(c, result, "Bulk approve processed")
+}
+
+// recordStaffTicketJob writes the admin audit entry of a queued staff decision once it has run.
+func (h *TicketHandler) recordStaffTicketJob(c *gin.Context, msg *queue.TicketJobMessage, recorder *audit.Recorder) {
+	action := &audit.Action{
+		ActorID:   msg.StaffID,
+		Name:      "tickets." + string(msg.Action),
+		RequestID: c.GetString("request_id"),
+		Status:    c.Writer.Status(),
+		Changes:   recorder.Changes(),
+	}
+	if msg.TicketID != "" {
+		action.EntityType, action.EntityID = "tickets", msg.TicketID
+	} else if msg.TargetUserID != "" {
+		action.Name = "users." + strings.TrimSuffix(string(msg.Action), "_user")
+		action.EntityType, action.EntityID = "users", msg.TargetUserID
+	}
+	if err := h.services.Audit.RecordAdminAction(context.WithoutCancel(c.Request.Context()), action); err != nil {
+		log.Printf("Failed to write admin audit log for ticket job %s: %v", msg.Action, err)
+	}
+}
+
+// ProcessBlacklistSignOutJob signs out a user the sqs-worker blacklisted automatically (third denied
+// ticket) while processing a queued denial.
+// Called by the sqs-worker; expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching requests.BlacklistSignOutJobRequest.
+func (h *TicketHandler) ProcessBlacklistSignOutJob(c *gin.Context) {
+	var req requests.BlacklistSignOutJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBadRequest(c, "Invalid job payload: "+err.Error())
+		return
+	}
+	if err := h.services.Ticket.SignOutBlacklistedUser(c.Request.Context(), req.UserID); err != nil {
+		respondTicketJobError(c, err)
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Blacklisted user signed out")
+}
+
+// ProcessPaymentRemindersJob emails users whose ticket is still awaiting payment.
+// Called by the sqs-worker scheduler; expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching requests.PaymentReminderJobRequest.
+func (h *TicketHandler) ProcessPaymentRemindersJob(c *gin.Context) {
+	var req requests.PaymentReminderJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBadRequest(c, "Invalid job payload: "+err.Error())
+		return
+	}
+	result, err := h.services.Ticket.SendPaymentReminders(c.Request.Context(), &req)
+	if err != nil {
+		log.Printf("Payment reminders job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Payment reminders processed")
+}
+
+// ProcessWeeklyStatsJob emails the weekly statistics summary to all admins.
+// Called by the sqs-worker scheduler; expects X-Internal-Api-Key and X-Job-Signature headers.
+func (h *AnalyticsHandler) ProcessWeeklyStatsJob(c *gin.Context) {
+	result, err := h.services.Analytics.SendWeeklyStatsEmail(c.Request.Context())
+	if err != nil {
+		log.Printf("Weekly stats job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Weekly stats processed")
+}
+
+// ProcessDataExportJob builds a personal data export requested by a user and emails its link.
+// Expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching queue.DataExportJobMessage.
+func (h *UserHandler) ProcessDataExportJob(c *gin.Context) {
+	var msg queue.DataExportJobMessage
+	if err := c.ShouldBindJSON(&msg); err != nil {
+		utils.RespondBadRequest(c, "Invalid job payload: "+err.Error())
+		return
+	}
+	result, err := h.services.DataExport.BuildExport(c.Request.Context(), msg.ExportID, msg.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrDataExportNotFound):
+			utils.RespondNotFound(c, err.Error())
+		case errors.Is(err, constants.ErrInvalidUserID):
+			utils.RespondBadRequest(c, err.Error())
+		default:
+			log.Printf("Data export job failed: %v", err)
+			utils.RespondInternalServerError(c, "Job processing failed")
+		}
+		return
+	}
+	utils.RespondSuccess(c, result, "Data export processed")
+}
+
+// ProcessAccountDeletionsJob anonymises users whose account deletion grace period has ended.
+// Called by the sqs-worker scheduler; expects X-Internal-Api-Key and X-Job-Signature headers.
+func (h *UserHandler) ProcessAccountDeletionsJob(c *gin.Context) {
+	result, err := h.services.Deletion.ProcessDueDeletions(c.Request.Context())
+	if err != nil {
+		log.Printf("Account deletions job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Account deletions processed")
+}
+
+// ProcessRetentionPurgeJob applies the data retention policy (a dry run until RETENTION_PURGE_ENABLED is set).
+// Called by the sqs-worker scheduler; expects X-Internal-Api-Key and X-Job-Signature headers.
+func (h *RetentionHandler) ProcessRetentionPurgeJob(c *gin.Context) {
+	result, err := h.services.Retention.RunScheduledPurge(c.Request.Context())
+	if err != nil {
+		log.Printf("Retention purge job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Retention purge processed")
+}
+
+// ProcessImpersonationNoticesJob emails users whose impersonation expired without being ended.
+// Called by the sqs-worker scheduler; expects X-Internal-Api-Key and X-Job-Signature headers.
+func (h *ImpersonationHandler) ProcessImpersonationNoticesJob(c *gin.Context) {
+	result, err := h.services.Impersonation.ProcessNotices(c.Request.Context())
+	if err != nil {
+		log.Printf("Impersonation notices job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Impersonation notices processed")
+}
+
+// ProcessFraudScreeningJob scores ticket purchases not screened when they were made (queued
+// purchases): the one in ticket_id, or a batch of the oldest.
+// Called by the sqs-worker; expects X-Internal-Api-Key and X-Job-Signature headers and JSON body matching requests.FraudScreeningJobRequest.
+func (h *FraudHandler) ProcessFraudScreeningJob(c *gin.Context) {
+	var req requests.FraudScreeningJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBadRequest(c, "Invalid job payload: "+err.Error())
+		return
+	}
+	if req.TicketID != "" {
+		result, err := h.services.Fraud.ScreenPurchase(c.Request.Context(), uuid.MustParse(req.TicketID))
+		if err != nil {
+			respondTicketJobError(c, err)
+			return
+		}
+		utils.RespondSuccess(c, result, "Fraud screening processed")
+		return
+	}
+
+	result, err := h.services.Fraud.ScreenPurchases(c.Request.Context())
+	if err != nil {
+		log.Printf("Fraud screening job failed: %v", err)
+		utils.RespondInternalServerError(c, "Job processing failed")
+		return
+	}
+	utils.RespondSuccess(c, result, "Fraud screening processed")
+}
+
 func respondTicketJobError(c *gin.Context, err error) {
 	switch {
 	case err == nil:
@@ -121,6 +319,10 @@ func respondTicketJobError(c *gin.Context, err error) {
 		utils.RespondError(c, http.StatusConflict, "CANNOT_DOWNGRADE", err.Error())
 	case errors.Is(err, repositories.ErrTicketNotApproved):
 		utils.RespondError(c, http.StatusConflict, "TICKET_NOT_APPROVED", err.Error())
+	case errors.Is(err, repositories.ErrTicketOnHold):
+		utils.RespondError(c, http.StatusConflict, "TICKET_ON_HOLD", err.Error())
+	case errors.Is(err, repositories.ErrTicketNotScreened):
+		utils.RespondError(c, http.StatusConflict, "TICKET_NOT_SCREENED", err.Error())
 	default:
 		log.Printf("Job processing failed (unhandled error): %v", err)
 		utils.RespondInternalServerError(c, "Job processing failed")
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"general-service/internal/common/utils"
+	"general-service/internal/services"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RetentionHandler struct {
+	services *services.Services
+}
+
+func NewRetentionHandler(services *services.Services) *RetentionHandler {
+	return &RetentionHandler{services: services}
+}
+
+// GetRetentionRules godoc
+// @Summary List data retention rules (admin only)
+// @Description Returns every data retention rule with its effective period (RETENTION_<RULE>), anchor and the cutoff it would purge up to now, or why it does not run.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} responses.RetentionRuleResponse "Retention rules"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Router /admin/retention/rules [get]
+func (h *RetentionHandler) GetRetentionRules(c *gin.Context) {
+	rules := h.services.Retention.GetRules()
+	utils.RespondSuccess(c, &rules, "Retention rules")
+}
+
+// RunRetentionDryRun godoc
+// @Summary Report what the retention purge would remove (admin only)
+// @Description Counts, per rule, the records the retention purge would remove now without changing anything. The report is recorded in the purge log as a dry run.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.RetentionRunResponse "Dry-run report"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/retention/dry-run [post]
+func (h *RetentionHandler) RunRetentionDryRun(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	report, err := h.services.Retention.RunPurge(c.Request.Context(), true, adminID.String())
+	if err != nil {
+		log.Printf("Retention dry run failed: %v", err)
+		utils.RespondInternalServerError(c, "Failed to run retention dry run")
+		return
+	}
+	utils.RespondSuccess(c, report, "Retention dry run")
+}
+
+// GetRetentionLogs godoc
+// @Summary List retention purge log (admin only)
+// @Description Paginated audit of the retention purge, newest first: per run and rule, the cutoff, how many records were (or in a dry run would be) purged and their IDs.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(20) minimum(1) maximum(100)
+// @Param rule query string false "Filter by rule name"
+// @Param run_id query string false "Filter by run ID" format(uuid)
+// @Success 200 {array} models.RetentionPurgeLog "Purge log entries"
+// @Failure 400 "Invalid rule or run ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/retention/logs [get]
+func (h *RetentionHandler) GetRetentionLogs(c *gin.Context) {
+	page := 1
+	pageSize := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+	rule := strings.TrimSpace(c.Query("rule"))
+	if rule != "" && !services.IsRetentionRule(rule) {
+		utils.RespondBadRequest(c, "Invalid rule")
+		return
+	}
+	var runID *uuid.UUID
+	if runIDStr := strings.TrimSpace(c.Query("run_id")); runIDStr != "" {
+		parsed, err := uuid.Parse(runIDStr)
+		if err != nil {
+			utils.RespondBadRequest(c, "Invalid run ID")
+			return
+		}
+		runID = &parsed
+	}
+
+	logs, meta, err := h.services.Retention.GetLogs(c.Request.Context(), page, pageSize, rule, runID)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to retrieve retention purge log")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &logs, meta, "Successfully retrieved retention purge log")
+}
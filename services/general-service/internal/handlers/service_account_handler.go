@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/auth/requests"
+	"general-service/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceAccountHandler struct {
+	services *services.Services
+}
+
+func NewServiceAccountHandler(services *services.Services) *ServiceAccountHandler {
+	return &ServiceAccountHandler{services: services}
+}
+
+// GetServiceAccounts godoc
+// @Summary List service accounts (admin only)
+// @Description Paginated service accounts of partner integrations, by name, with their API keys (never the secrets).
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
+// @Success 200 {array} responses.ServiceAccountResponse "Service accounts"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts [get]
+func (h *ServiceAccountHandler) GetServiceAccounts(c *gin.Context) {
+	page := 1
+	pageSize := 10
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	accounts, meta, err := h.services.ServiceAccount.GetServiceAccounts(c.Request.Context(), page, pageSize)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to retrieve service accounts", "serviceAccountListFailed")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &accounts, meta, "Successfully retrieved service accounts")
+}
+
+// GetServiceAccount godoc
+// @Summary Get a service account (admin only)
+// @Description A service account with its API keys, including when and from where each was last used.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID" format(uuid)
+// @Success 200 {object} responses.ServiceAccountResponse "Service account"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Service account not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts/{id} [get]
+func (h *ServiceAccountHandler) GetServiceAccount(c *gin.Context) {
+	account, err := h.services.ServiceAccount.GetServiceAccount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to retrieve service account", "serviceAccountGetFailed")
+		return
+	}
+	utils.RespondSuccess(c, account, "Successfully retrieved service account")
+}
+
+// CreateServiceAccount godoc
+// @Summary Create a service account (admin only)
+// @Description Registers a partner integration (badge printer station, Discord bot, hotel partner). Issue it API keys to call the admin API.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateServiceAccountRequest true "Service account"
+// @Success 200 {object} responses.ServiceAccountResponse "Service account created"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 409 "A service account with this name already exists"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts [post]
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	account, err := h.services.ServiceAccount.CreateServiceAccount(c.Request.Context(), adminID.String(), &req)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to create service account", "serviceAccountCreateFailed")
+		return
+	}
+	utils.RespondSuccess(c, account, "Service account created")
+}
+
+// DisableServiceAccount godoc
+// @Summary Disable a service account (admin only)
+// @Description Disables the service account and revokes all its API keys at once.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID" format(uuid)
+// @Success 200 {object} responses.ServiceAccountResponse "Service account disabled"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Service account not found"
+// @Failure 409 "Service account already disabled"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts/{id}/disable [post]
+func (h *ServiceAccountHandler) DisableServiceAccount(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	account, err := h.services.ServiceAccount.DisableServiceAccount(c.Request.Context(), adminID.String(), c.Param("id"))
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to disable service account", "serviceAccountDisableFailed")
+		return
+	}
+	utils.RespondSuccess(c, account, "Service account disabled")
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key (admin only)
+// @Description Issues an API key to the service account with the given scopes (tickets:read, checkin:write, dealers:read, users:read) and optional expiry.
+// @Description The key is sent as X-Api-Key (or Authorization: Bearer) and is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID" format(uuid)
+// @Param request body requests.CreateAPIKeyRequest true "Key scopes and expiry"
+// @Success 200 {object} responses.IssuedAPIKeyResponse "API key created"
+// @Failure 400 "Bad request - validation error or unknown scope"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Service account not found"
+// @Failure 409 "Service account is disabled"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts/{id}/keys [post]
+func (h *ServiceAccountHandler) CreateAPIKey(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	key, err := h.services.ServiceAccount.CreateAPIKey(c.Request.Context(), adminID.String(), c.Param("id"), &req)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to create API key", "apiKeyCreateFailed")
+		return
+	}
+	utils.RespondSuccess(c, key, "API key created")
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key (admin only)
+// @Description Issues a new key with the same name, scopes and lifetime. The old key is revoked at once, or keeps working for grace_period_minutes so the integration can switch over.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID" format(uuid)
+// @Param keyId path string true "API key ID" format(uuid)
+// @Param request body requests.RotateAPIKeyRequest false "Grace period of the old key"
+// @Success 200 {object} responses.IssuedAPIKeyResponse "API key rotated"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Service account or API key not found"
+// @Failure 409 "API key already revoked or expired, or service account disabled"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts/{id}/keys/{keyId}/rotate [post]
+func (h *ServiceAccountHandler) RotateAPIKey(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondValidationError(c, err.Error())
+			return
+		}
+	}
+	key, err := h.services.ServiceAccount.RotateAPIKey(c.Request.Context(), adminID.String(), c.Param("id"), c.Param("keyId"), &req)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to rotate API key", "apiKeyRotateFailed")
+		return
+	}
+	utils.RespondSuccess(c, key, "API key rotated")
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key (admin only)
+// @Description The key stops working at once.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID" format(uuid)
+// @Param keyId path string true "API key ID" format(uuid)
+// @Success 200 {object} responses.APIKeyResponse "API key revoked"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Service account or API key not found"
+// @Failure 409 "API key already revoked"
+// @Failure 500 "Internal server error"
+// @Router /admin/service-accounts/{id}/keys/{keyId} [delete]
+func (h *ServiceAccountHandler) RevokeAPIKey(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	key, err := h.services.ServiceAccount.RevokeAPIKey(c.Request.Context(), adminID.String(), c.Param("id"), c.Param("keyId"))
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to revoke API key", "apiKeyRevokeFailed")
+		return
+	}
+	utils.RespondSuccess(c, key, "API key revoked")
+}
+
+// respondServiceAccountError maps service account and API key errors
+func respondServiceAccountError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrServiceAccountNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "serviceAccountNotFound")
+	case errors.Is(err, constants.ErrAPIKeyNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "apiKeyNotFound")
+	case errors.Is(err, constants.ErrServiceAccountExists):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "serviceAccountExists")
+	case errors.Is(err, constants.ErrServiceAccountDisabled):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "serviceAccountDisabled")
+	case errors.Is(err, constants.ErrAPIKeyRevoked):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "apiKeyRevoked")
+	case errors.Is(err, constants.ErrInvalidAPIKeyScope):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidApiKeyScope")
+	case errors.Is(err, constants.ErrInvalidUserID):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidUserId")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
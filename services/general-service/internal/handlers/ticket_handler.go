@@ -12,6 +12,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type TicketHandler struct {
@@ -434,9 +435,10 @@ func (h *TicketHandler) UpgradeTicket(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param status query string false "Filter by status (pending, self_confirmed, approved, denied)"
 // @Param tier_id query string false "Filter by tier ID"
-// @Param search query string false "Search by reference code, user name, or email"
+// @Param search query string false "Search by reference code, email, fursona name, user name (word prefixes) or exact ID card number"
 // @Param pending_over_24 query bool false "Only show tickets pending > 24 hours"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
@@ -496,6 +498,7 @@ func (h *TicketHandler) GetTicketsForAdmin(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param id path string true "Ticket ID" format(uuid)
 // @Success 200 "Successfully retrieved ticket"
 // @Failure 400 "Invalid ticket ID"
@@ -541,7 +544,7 @@ func (h *TicketHandler) GetTicketByID(c *gin.Context) {
 // @Failure 401 "Unauthorized"
 // @Failure 403 "Forbidden - admin only"
 // @Failure 404 "Ticket not found"
-// @Failure 409 "Ticket cannot be approved (wrong status)"
+// @Failure 409 "Ticket cannot be approved (wrong status, held for fraud review or not screened yet)"
 // @Failure 500 "Internal server error"
 // @Router /admin/tickets/{id}/approve [patch]
 func (h *TicketHandler) ApproveTicket(c *gin.Context) {
@@ -567,6 +570,10 @@ func (h *TicketHandler) ApproveTicket(c *gin.Context) {
 			utils.RespondNotFound(c, "Ticket not found")
 		case errors.Is(err, repositories.ErrInvalidTicketStatus):
 			utils.RespondError(c, 409, "INVALID_STATUS", "Ticket cannot be approved (wrong status)")
+		case errors.Is(err, repositories.ErrTicketOnHold):
+			utils.RespondError(c, 409, "TICKET_ON_HOLD", "Ticket is held for fraud review")
+		case errors.Is(err, repositories.ErrTicketNotScreened):
+			utils.RespondError(c, 409, "TICKET_NOT_SCREENED", "Ticket has not been screened for fraud yet, try again shortly")
 		default:
 			utils.RespondInternalServerError(c, "Failed to approve ticket")
 		}
@@ -576,12 +583,60 @@ func (h *TicketHandler) ApproveTicket(c *gin.Context) {
 	utils.RespondSuccess(c, ticket, "Ticket approved successfully")
 }
 
+// BulkApproveTickets godoc
+// @Summary Approve many tickets at once (admin)
+// @Description Approve up to 200 pending or self-confirmed tickets. When queue is enabled, the batch is queued as a bulk_approve job (202); otherwise tickets are approved synchronously and per-ticket failures are returned. Approval emails are sent either way.
+// @Tags admin-tickets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.BulkApproveTicketsRequest true "Ticket IDs to approve"
+// @Success 200 {object} responses.BulkApproveResponse "Bulk approval processed"
+// @Success 202 "Request queued for processing"
+// @Failure 400 "Invalid request body"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden - admin only"
+// @Failure 500 "Internal server error"
+// @Router /admin/tickets/bulk-approve [post]
+func (h *TicketHandler) BulkApproveTickets(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	staffID, exists := c.Get("user_id")
+	if !exists {
+		utils.RespondUnauthorized(c, "Staff ID not found in token")
+		return
+	}
+
+	var req requests.BulkApproveTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+
+	if h.queue != nil {
+		if err := h.queue.PublishJob(ctx, queue.JobTypeBulkApprove, &queue.BulkApproveJobMessage{
+			TicketIDs: req.TicketIDs,
+			StaffID:   staffID.(string),
+		}); err != nil {
+			log.Printf("SQS PublishJob (bulk_approve) failed: %v", err)
+			utils.RespondInternalServerError(c, "Failed to queue bulk approval")
+			return
+		}
+		utils.RespondAccepted(c, "Bulk approval queued for processing.")
+		return
+	}
+
+	result := h.services.Ticket.BulkApproveTickets(ctx, req.TicketIDs, staffID.(string))
+	utils.RespondSuccess(c, result, "Bulk approval processed")
+}
+
 // ConfirmCheckIn godoc
 // @Summary Confirm ticket check-in (admin/staff)
 // @Description Set is_checked_in = true for a ticket. Accepts ticket ID (UUID) or reference code in path.
 // @Tags admin-tickets
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param id path string true "Ticket ID or reference code"
 // @Success 200 "Check-in confirmed"
 // @Failure 400 "Invalid ticket ID or reference"
@@ -597,12 +652,16 @@ func (h *TicketHandler) ConfirmCheckIn(c *gin.Context) {
 		utils.RespondBadRequest(c, "Ticket ID or reference code is required")
 		return
 	}
-	staffID, exists := c.Get("user_id")
-	if !exists {
+	staffID := c.GetString("user_id")
+	if staffID == "" {
+		// Check-in stations call this with a service account API key
+		staffID = c.GetString("service_account_id")
+	}
+	if staffID == "" {
 		utils.RespondUnauthorized(c, "Staff ID not found in token")
 		return
 	}
-	ticket, err := h.services.Ticket.ConfirmCheckIn(ctx, ticketIDOrRef, staffID.(string))
+	ticket, err := h.services.Ticket.ConfirmCheckIn(ctx, ticketIDOrRef, staffID)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidTicketID), errors.Is(err, services.ErrInvalidUserID):
@@ -1099,6 +1158,12 @@ func (h *TicketHandler) BlacklistUser(c *gin.Context) {
 	staffID, _ := c.Get("user_id")
 
 	if h.queue != nil {
+		// Sign the user out now rather than when the queued blacklist is applied
+		if targetID, err := uuid.Parse(userID); err == nil {
+			if _, err := h.services.Session.RevokeAllForUser(ctx, targetID, uuid.Nil, services.SessionRevokedBlacklisted); err != nil {
+				log.Printf("[ERROR] Failed to revoke sessions of blacklisted user %s: %v", userID, err)
+			}
+		}
 		if err := h.queue.PublishTicketJob(ctx, &queue.TicketJobMessage{
 			Action:       queue.ActionBlacklistUser,
 			StaffID:      staffID.(string),
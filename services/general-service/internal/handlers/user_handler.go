@@ -4,21 +4,30 @@ import (
 	"errors"
 	"general-service/internal/common/constants"
 	"general-service/internal/common/utils"
+	authrequests "general-service/internal/dto/auth/requests"
+	authresponses "general-service/internal/dto/auth/responses"
 	"general-service/internal/dto/user/requests"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/queue"
 	"general-service/internal/services"
+	"log"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserHandler struct {
 	services *services.Services
+	queue    queue.Publisher
 }
 
-func NewUserHandler(services *services.Services) *UserHandler {
-	return &UserHandler{services: services}
+func NewUserHandler(services *services.Services, queuePublisher queue.Publisher) *UserHandler {
+	return &UserHandler{services: services, queue: queuePublisher}
 }
 
 // GetMe godoc
@@ -235,10 +244,11 @@ func (h *UserHandler) GetUserCountByAgeRange(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
 // @Param pageSize query int false "Page size (alias)" default(10) minimum(1) maximum(100)
-// @Param search query string false "Search by email, name, or fursona name"
+// @Param search query string false "Search by email or fursona name (substring), name (word prefixes) or exact ID card number"
 // @Success 200 "Successfully retrieved users list"
 // @Failure 401 "Unauthorized - missing or invalid token"
 // @Failure 403 "Forbidden - insufficient permissions"
@@ -287,6 +297,7 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ServiceAPIKey
 // @Param id path string true "User ID" format(uuid)
 // @Success 200 "Successfully retrieved user information"
 // @Failure 401 "Unauthorized - missing or invalid token"
@@ -348,7 +359,7 @@ func (h *UserHandler) UpdateUserByAdmin(c *gin.Context) {
 	}
 
 	// Call service
-	user, err := h.services.User.UpdateUserByAdmin(userID, &req)
+	user, err := h.services.User.UpdateUserByAdmin(c.Request.Context(), userID, &req)
 	if err != nil {
 		errMsg := err.Error()
 		switch errMsg {
@@ -390,7 +401,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	// Call service
-	err := h.services.User.DeleteUser(userID)
+	err := h.services.User.DeleteUser(c.Request.Context(), userID)
 	if err != nil {
 		errMsg := err.Error()
 		switch errMsg {
@@ -431,7 +442,7 @@ func (h *UserHandler) VerifyUser(c *gin.Context) {
 	}
 
 	// Call service
-	user, err := h.services.User.VerifyUser(userID)
+	user, err := h.services.User.VerifyUser(c.Request.Context(), userID)
 	if err != nil {
 		errMsg := err.Error()
 		switch errMsg {
@@ -447,3 +458,1018 @@ func (h *UserHandler) VerifyUser(c *gin.Context) {
 
 	utils.RespondSuccess(c, user, "User verified successfully")
 }
+
+// GetMySessions godoc
+// @Summary List my active sessions
+// @Description List the devices currently signed in to the account, with IP, user agent and last activity (updated on every token refresh).
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} []responses.SessionResponse "Active sessions"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/sessions [get]
+func (h *UserHandler) GetMySessions(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	sessions, err := h.services.Session.ListActive(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to get sessions")
+		return
+	}
+	data := mappers.MapSessionsToResponse(sessions, currentSessionID(c))
+	utils.RespondSuccess(c, &data, "Sessions retrieved successfully")
+}
+
+// RevokeMySession godoc
+// @Summary Sign out one of my sessions
+// @Description Revoke a session by ID; its access and refresh tokens stop working immediately.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID" format(uuid)
+// @Success 200 "Session revoked"
+// @Failure 400 "Invalid session ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "Session not found"
+// @Failure 500 "Internal server error"
+// @Router /users/me/sessions/{id} [delete]
+func (h *UserHandler) RevokeMySession(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondBadRequest(c, "Invalid session ID format")
+		return
+	}
+	if err := h.services.Session.RevokeForUser(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			utils.RespondNotFound(c, "Session not found")
+			return
+		}
+		utils.RespondInternalServerError(c, "Failed to revoke session")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Session revoked successfully")
+}
+
+// RevokeMyOtherSessions godoc
+// @Summary Sign out all my other sessions
+// @Description Revoke every session of the current user except the one making the request.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.RevokeSessionsResponse "Sessions revoked"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/sessions [delete]
+func (h *UserHandler) RevokeMyOtherSessions(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	keep, _ := uuid.Parse(currentSessionID(c))
+	revoked, err := h.services.Session.RevokeAllForUser(c.Request.Context(), userID, keep, services.SessionRevokedByUser)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	data := responses.RevokeSessionsResponse{Revoked: revoked}
+	utils.RespondSuccess(c, &data, "Other sessions revoked successfully")
+}
+
+// RevokeUserSessions godoc
+// @Summary Sign a user out everywhere (admin only)
+// @Description Revoke every session of the user; their access and refresh tokens stop working immediately.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} responses.RevokeSessionsResponse "Sessions revoked"
+// @Failure 400 "Invalid user ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondBadRequest(c, "Invalid user ID format")
+		return
+	}
+	revoked, err := h.services.Session.RevokeAllForUser(c.Request.Context(), userID, uuid.Nil, services.SessionRevokedByAdmin)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	data := responses.RevokeSessionsResponse{Revoked: revoked}
+	utils.RespondSuccess(c, &data, "User signed out of all sessions")
+}
+
+// GetMyMFA godoc
+// @Summary Get my two-factor authentication status
+// @Description Whether an authenticator is set up, whether 2FA is mandatory for the user's role, and how many recovery codes are left.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} authresponses.MFAStatusResponse "Two-factor status"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/mfa [get]
+func (h *UserHandler) GetMyMFA(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	status, err := h.services.MFA.Status(c.Request.Context(), userID, utils.GetRoleFromContext(c))
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to get two-factor status")
+		return
+	}
+	utils.RespondSuccess(c, status, "Two-factor status retrieved successfully")
+}
+
+// SetupMyTOTP godoc
+// @Summary Start setting up an authenticator app
+// @Description Generates a TOTP secret and returns it with its otpauth URI and QR code. It is not required at login until confirmed
+// @Description with POST /users/me/mfa/totp/confirm. Calling this again replaces an unconfirmed secret.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} authresponses.TOTPSetupResponse "Authenticator secret"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 409 "Two-factor authentication already enabled"
+// @Failure 500 "Internal server error"
+// @Router /users/me/mfa/totp [post]
+func (h *UserHandler) SetupMyTOTP(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	setup, err := h.services.MFA.BeginEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to start two-factor setup", "mfaSetupFailed")
+		return
+	}
+	utils.RespondSuccess(c, setup, "Scan the QR code with your authenticator app")
+}
+
+// ConfirmMyTOTP godoc
+// @Summary Confirm my authenticator app
+// @Description Enables two-factor authentication if the code matches the secret from POST /users/me/mfa/totp. Returns the recovery codes (shown only once).
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} authresponses.RecoveryCodesResponse "Recovery codes"
+// @Failure 400 "Bad request - validation error or setup not started"
+// @Failure 401 "Unauthorized or wrong code"
+// @Failure 409 "Two-factor authentication already enabled"
+// @Failure 500 "Internal server error"
+// @Router /users/me/mfa/totp/confirm [post]
+func (h *UserHandler) ConfirmMyTOTP(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	codes, err := h.services.MFA.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to confirm two-factor setup", "mfaSetupFailed")
+		return
+	}
+	data := authresponses.RecoveryCodesResponse{RecoveryCodes: codes}
+	utils.RespondSuccess(c, &data, "Two-factor authentication enabled")
+}
+
+// RegenerateMyRecoveryCodes godoc
+// @Summary Replace my recovery codes
+// @Description Invalidates the current recovery codes and returns new ones (shown only once). Requires a code from the authenticator app.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} authresponses.RecoveryCodesResponse "Recovery codes"
+// @Failure 400 "Bad request - validation error or 2FA not enabled"
+// @Failure 401 "Unauthorized or wrong code"
+// @Failure 500 "Internal server error"
+// @Router /users/me/mfa/recovery-codes [post]
+func (h *UserHandler) RegenerateMyRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	codes, err := h.services.MFA.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to regenerate recovery codes", "mfaRecoveryCodesFailed")
+		return
+	}
+	data := authresponses.RecoveryCodesResponse{RecoveryCodes: codes}
+	utils.RespondSuccess(c, &data, "Recovery codes regenerated")
+}
+
+// DisableMyMFA godoc
+// @Summary Turn off two-factor authentication
+// @Description Removes the authenticator and recovery codes. Not allowed for roles where 2FA is mandatory. Requires a code from the authenticator app.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 "Two-factor authentication disabled"
+// @Failure 400 "Bad request - validation error or 2FA not enabled"
+// @Failure 401 "Unauthorized or wrong code"
+// @Failure 403 "Two-factor authentication is mandatory for this role"
+// @Failure 500 "Internal server error"
+// @Router /users/me/mfa [delete]
+func (h *UserHandler) DisableMyMFA(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	if err := h.services.MFA.Disable(c.Request.Context(), userID, utils.GetRoleFromContext(c), req.Code); err != nil {
+		respondMFAError(c, err, "Failed to disable two-factor authentication", "mfaDisableFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Two-factor authentication disabled")
+}
+
+// GetMyPasskeys godoc
+// @Summary List my passkeys
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} []authresponses.PasskeyResponse "Passkeys"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/passkeys [get]
+func (h *UserHandler) GetMyPasskeys(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	passkeys, err := h.services.Passkey.List(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to get passkeys")
+		return
+	}
+	utils.RespondSuccess(c, &passkeys, "Passkeys retrieved successfully")
+}
+
+// BeginMyPasskeyRegistration godoc
+// @Summary Start adding a passkey
+// @Description Returns PublicKeyCredentialCreationOptions for navigator.credentials.create(). Valid for 5 minutes and one attempt.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} authresponses.PasskeyCreationOptions "WebAuthn creation options"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/passkeys/register/begin [post]
+func (h *UserHandler) BeginMyPasskeyRegistration(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	options, err := h.services.Passkey.BeginRegistration(c.Request.Context(), userID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to start passkey registration", "passkeyRegisterFailed")
+		return
+	}
+	utils.RespondSuccess(c, options, "Passkey registration started")
+}
+
+// FinishMyPasskeyRegistration godoc
+// @Summary Add a passkey
+// @Description Verifies the credential returned by navigator.credentials.create() and saves it.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.PasskeyRegisterRequest true "Passkey name and WebAuthn attestation"
+// @Success 200 {object} authresponses.PasskeyResponse "Passkey added"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized, passkey verification failed or challenge expired"
+// @Failure 409 "Passkey already registered"
+// @Failure 500 "Internal server error"
+// @Router /users/me/passkeys/register/finish [post]
+func (h *UserHandler) FinishMyPasskeyRegistration(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.PasskeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	passkey, err := h.services.Passkey.FinishRegistration(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondMFAError(c, err, "Failed to register passkey", "passkeyRegisterFailed")
+		return
+	}
+	utils.RespondSuccess(c, passkey, "Passkey added successfully")
+}
+
+// DeleteMyPasskey godoc
+// @Summary Remove a passkey
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Passkey ID" format(uuid)
+// @Success 200 "Passkey removed"
+// @Failure 400 "Invalid passkey ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "Passkey not found"
+// @Failure 500 "Internal server error"
+// @Router /users/me/passkeys/{id} [delete]
+func (h *UserHandler) DeleteMyPasskey(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondBadRequest(c, "Invalid passkey ID format")
+		return
+	}
+	if err := h.services.Passkey.Delete(c.Request.Context(), userID, id); err != nil {
+		respondMFAError(c, err, "Failed to remove passkey", "passkeyDeleteFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Passkey removed successfully")
+}
+
+// GetMyLoginMethods godoc
+// @Summary List my login methods
+// @Description Whether the account has a password, how many passkeys it has, and its linked sign-in provider accounts.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} authresponses.LoginMethodsResponse "Login methods"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 500 "Internal server error"
+// @Router /users/me/login-methods [get]
+func (h *UserHandler) GetMyLoginMethods(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	methods, err := h.services.OAuth.LoginMethods(c.Request.Context(), userID)
+	if err != nil {
+		respondOAuthError(c, err, "Failed to get login methods", "loginMethodsFailed")
+		return
+	}
+	utils.RespondSuccess(c, methods, "Login methods retrieved successfully")
+}
+
+// BeginMyIdentityLink godoc
+// @Summary Start linking a sign-in provider
+// @Description Returns the provider's authorization URL. After the user approves, post the code and state from the redirect to /users/me/identities/{provider}/callback.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider" Enums(google, discord, facebook)
+// @Success 200 {object} authresponses.OAuthAuthorizationResponse
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 503 "Provider not configured"
+// @Router /users/me/identities/{provider}/authorize [post]
+func (h *UserHandler) BeginMyIdentityLink(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	response, err := h.services.OAuth.BeginLink(c.Request.Context(), userID, c.Param("provider"))
+	if err != nil {
+		respondOAuthError(c, err, "Failed to start linking", "identityLinkFailed")
+		return
+	}
+	utils.RespondSuccess(c, response, "Authorization URL created")
+}
+
+// FinishMyIdentityLink godoc
+// @Summary Link a sign-in provider
+// @Description Exchange the code and state from the provider redirect and link the provider account. The user is notified by email.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider" Enums(google, discord, facebook)
+// @Param request body authrequests.OAuthLinkRequest true "Code and state"
+// @Success 200 {object} authresponses.LinkedIdentityResponse "Provider account linked"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized, invalid or expired state, or the provider rejected the code"
+// @Failure 409 "Provider account linked to another user, or a login from this provider is already linked"
+// @Failure 503 "Provider not configured"
+// @Router /users/me/identities/{provider}/callback [post]
+func (h *UserHandler) FinishMyIdentityLink(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.OAuthLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	identity, err := h.services.OAuth.CompleteLink(c.Request.Context(), userID, c.Param("provider"), &req)
+	if err != nil {
+		respondOAuthError(c, err, "Failed to link sign-in provider", "identityLinkFailed")
+		return
+	}
+	utils.RespondSuccess(c, identity, "Sign-in provider linked successfully")
+}
+
+// LinkMyGoogle godoc
+// @Summary Link Google with a Sign-In credential
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.GoogleLinkRequest true "Google ID token (credential)"
+// @Success 200 {object} authresponses.LinkedIdentityResponse "Google account linked"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized or invalid Google token"
+// @Failure 409 "Google account linked to another user, or a Google login is already linked"
+// @Failure 503 "Google sign-in is not configured"
+// @Router /users/me/identities/google [post]
+func (h *UserHandler) LinkMyGoogle(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.GoogleLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	identity, err := h.services.OAuth.LinkGoogleIDToken(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondOAuthError(c, err, "Failed to link Google account", "identityLinkFailed")
+		return
+	}
+	utils.RespondSuccess(c, identity, "Google account linked successfully")
+}
+
+// LinkMyTelegram godoc
+// @Summary Link Telegram with Login Widget data
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.TelegramLoginRequest true "Telegram Login Widget data"
+// @Success 200 {object} authresponses.LinkedIdentityResponse "Telegram account linked"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized or invalid/expired Telegram data"
+// @Failure 409 "Telegram account linked to another user, or a Telegram login is already linked"
+// @Failure 503 "Telegram sign-in is not configured"
+// @Router /users/me/identities/telegram [post]
+func (h *UserHandler) LinkMyTelegram(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.TelegramLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	identity, err := h.services.OAuth.LinkTelegram(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondOAuthError(c, err, "Failed to link Telegram account", "identityLinkFailed")
+		return
+	}
+	utils.RespondSuccess(c, identity, "Telegram account linked successfully")
+}
+
+// UnlinkMyIdentity godoc
+// @Summary Unlink a sign-in provider
+// @Description Removes a linked provider account. At least one way to sign in (password, passkey or another provider) must remain. The user is notified by email.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Linked identity ID" format(uuid)
+// @Success 200 "Sign-in provider unlinked"
+// @Failure 400 "Invalid identity ID"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "Linked identity not found"
+// @Failure 409 "It is the only remaining login method"
+// @Failure 500 "Internal server error"
+// @Router /users/me/identities/{id} [delete]
+func (h *UserHandler) UnlinkMyIdentity(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondBadRequest(c, "Invalid identity ID format")
+		return
+	}
+	if err := h.services.OAuth.Unlink(c.Request.Context(), userID, id); err != nil {
+		respondOAuthError(c, err, "Failed to unlink sign-in provider", "identityUnlinkFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Sign-in provider unlinked successfully")
+}
+
+// SetMyPassword godoc
+// @Summary Set a password
+// @Description For accounts created through a sign-in provider without a password. Accounts that already have one use /auth/change-password.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.SetPasswordRequest true "New password"
+// @Success 200 "Password set"
+// @Failure 400 "Bad request - validation error or passwords do not match"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 409 "Account already has a password"
+// @Failure 500 "Internal server error"
+// @Router /users/me/password [post]
+func (h *UserHandler) SetMyPassword(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	if err := h.services.Auth.SetInitialPassword(c.Request.Context(), userID.String(), &req); err != nil {
+		switch {
+		case errors.Is(err, constants.ErrPasswordAlreadySet):
+			utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "passwordAlreadySet")
+		case errors.Is(err, constants.ErrPasswordMismatch):
+			utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "passwordsDoNotMatch")
+		case errors.Is(err, constants.ErrUserNotFound):
+			utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+		default:
+			utils.RespondInternalServerError(c, "Failed to set password")
+		}
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Password set successfully")
+}
+
+// RequestMyEmailChange godoc
+// @Summary Start changing my email
+// @Description Checks the current password and sends a verification code to the new address. The email changes once the code is confirmed.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.ChangeEmailRequest true "New email and current password"
+// @Success 200 "Verification code sent to the new email"
+// @Failure 400 "Bad request - validation error or same email"
+// @Failure 401 "Unauthorized - missing/invalid token or wrong current password"
+// @Failure 409 "Email already in use"
+// @Failure 500 "Internal server error"
+// @Router /users/me/email [post]
+func (h *UserHandler) RequestMyEmailChange(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	fromEmail := getEnvOr("SES_EMAIL_IDENTITY", "")
+	if err := h.services.Auth.RequestEmailChange(c.Request.Context(), userID.String(), &req, h.services.Mail, fromEmail); err != nil {
+		respondEmailChangeError(c, err, "Failed to start email change", "emailChangeFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Verification code sent to the new email")
+}
+
+// ConfirmMyEmailChange godoc
+// @Summary Confirm my new email
+// @Description Changes the email with the code sent to the new address. The previous address is notified with a link to undo the change, and other sessions are signed out.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body authrequests.ConfirmEmailChangeRequest true "New email and verification code"
+// @Success 200 "Email changed"
+// @Failure 400 "Bad request - validation error, invalid or expired code"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 409 "Email already in use"
+// @Failure 500 "Internal server error"
+// @Router /users/me/email/confirm [post]
+func (h *UserHandler) ConfirmMyEmailChange(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req authrequests.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	fromEmail := getEnvOr("SES_EMAIL_IDENTITY", "")
+	frontendURL := getEnvOr("FRONTEND_URL", "")
+	if err := h.services.Auth.ConfirmEmailChange(c.Request.Context(), userID.String(), currentSessionID(c), &req, h.services.Mail, frontendURL, fromEmail); err != nil {
+		respondEmailChangeError(c, err, "Failed to change email", "emailChangeFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Email changed successfully")
+}
+
+// respondEmailChangeError maps email change errors
+func respondEmailChangeError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrCurrentPasswordIncorrect):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "currentPasswordIncorrect")
+	case errors.Is(err, constants.ErrSameEmail):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "sameEmail")
+	case errors.Is(err, constants.ErrInvalidEmailChangeCode):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidOrExpiredOtp")
+	case errors.Is(err, constants.ErrEmailAlreadyInUse):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "emailAlreadyInUse")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
+
+// RequestMyDataExport godoc
+// @Summary Request an export of my personal data
+// @Description Starts building an archive (data.json plus a readable index.html) of everything stored about the current user: profile, linked logins, tickets and their status history, dealer booths, conbook/panel/talent submissions, ban and emails sent.
+// @Description When queue is enabled the export is built by the worker (202); otherwise it is built synchronously (200). Either way the download link is emailed to the user.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.DataExportResponse "Export ready, download link emailed"
+// @Success 202 "Export queued"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 409 "An export is already being prepared"
+// @Failure 429 "Too many export requests"
+// @Failure 500 "Internal server error"
+// @Router /users/me/export [post]
+func (h *UserHandler) RequestMyDataExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	export, err := h.services.DataExport.RequestExport(ctx, userID.String())
+	if err != nil {
+		respondDataExportError(c, err, "Failed to request data export", "dataExportFailed")
+		return
+	}
+
+	if h.queue != nil {
+		if err := h.queue.PublishJob(ctx, queue.JobTypeDataExport, &queue.DataExportJobMessage{
+			ExportID: export.Id.String(),
+			UserID:   userID.String(),
+		}); err != nil {
+			log.Printf("SQS PublishJob (data_export) failed: %v", err)
+			if failErr := h.services.DataExport.FailExport(ctx, export.Id, "failed to queue export"); failErr != nil {
+				log.Printf("[WARN] Failed to mark data export %s as failed: %v", export.Id, failErr)
+			}
+			utils.RespondInternalServerError(c, "Failed to queue data export")
+			return
+		}
+		utils.RespondAccepted(c, "Data export queued. The download link will be emailed to you.")
+		return
+	}
+
+	export, err = h.services.DataExport.BuildExport(ctx, export.Id.String(), userID.String())
+	if err != nil {
+		log.Printf("Data export failed: %v", err)
+		respondDataExportError(c, err, "Failed to build data export", "dataExportFailed")
+		return
+	}
+	utils.RespondSuccess(c, export, "Data export ready. The download link has been emailed to you.")
+}
+
+// GetMyDataExport godoc
+// @Summary Get my latest data export
+// @Description Returns the status of the current user's most recent personal data export request.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.DataExportResponse "Latest export"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "No export requested"
+// @Failure 500 "Internal server error"
+// @Router /users/me/export [get]
+func (h *UserHandler) GetMyDataExport(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	export, err := h.services.DataExport.GetLatestExport(c.Request.Context(), userID.String())
+	if err != nil {
+		respondDataExportError(c, err, "Failed to get data export", "dataExportFailed")
+		return
+	}
+	utils.RespondSuccess(c, export, "Data export retrieved successfully")
+}
+
+// DownloadDataExport godoc
+// @Summary Download a personal data export
+// @Description Downloads the zip archive of a ready export with the token from the emailed link. No login is needed; the link expires after DATA_EXPORT_LINK_EXPIRY_HOURS.
+// @Tags user
+// @Produce application/zip
+// @Param token query string true "Download token from the email"
+// @Success 200 {file} file "Export archive"
+// @Failure 404 "Link invalid or expired"
+// @Failure 500 "Internal server error"
+// @Router /users/export/download [get]
+func (h *UserHandler) DownloadDataExport(c *gin.Context) {
+	filename, archive, err := h.services.DataExport.DownloadExport(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondDataExportError(c, err, "Failed to download data export", "dataExportDownloadFailed")
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(200, "application/zip", archive)
+}
+
+// respondDataExportError maps personal data export errors
+func respondDataExportError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrDataExportInProgress):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "dataExportInProgress")
+	case errors.Is(err, constants.ErrDataExportNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "dataExportNotFound")
+	case errors.Is(err, constants.ErrInvalidDataExportLink):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "dataExportLinkInvalid")
+	case errors.Is(err, constants.ErrInvalidUserID):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidUserId")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
+
+// RequestMyAccountDeletion godoc
+// @Summary Delete my account
+// @Description Schedules the deletion of the current user's account after a grace period (ACCOUNT_DELETION_GRACE_DAYS), during which it can be cancelled.
+// @Description Then personal details are erased for good; tickets and payments are kept anonymised for accounting, owned dealer booths pass to another staff member (or are flagged for admins) and conbook/panel/talent submissions are kept anonymised or deleted per the configured policy.
+// @Description The password is required when the account has one.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.RequestAccountDeletionRequest true "Password and optional reason"
+// @Success 200 {object} responses.AccountDeletionResponse "Deletion scheduled"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing/invalid token or wrong password"
+// @Failure 409 "Deletion already scheduled"
+// @Failure 500 "Internal server error"
+// @Router /users/me/deletion [post]
+func (h *UserHandler) RequestMyAccountDeletion(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.RequestAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	deletion, err := h.services.Deletion.RequestDeletion(c.Request.Context(), userID.String(), &req)
+	if err != nil {
+		respondAccountDeletionError(c, err, "Failed to schedule account deletion", "accountDeletionFailed")
+		return
+	}
+	utils.RespondSuccess(c, deletion, "Account deletion scheduled")
+}
+
+// GetMyAccountDeletion godoc
+// @Summary Get my account deletion request
+// @Description Returns the current user's most recent account deletion request and when it runs.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.AccountDeletionResponse "Latest deletion request"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "No deletion requested"
+// @Failure 500 "Internal server error"
+// @Router /users/me/deletion [get]
+func (h *UserHandler) GetMyAccountDeletion(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	deletion, err := h.services.Deletion.GetMyDeletion(c.Request.Context(), userID.String())
+	if err != nil {
+		respondAccountDeletionError(c, err, "Failed to get account deletion", "accountDeletionFailed")
+		return
+	}
+	utils.RespondSuccess(c, deletion, "Account deletion retrieved successfully")
+}
+
+// CancelMyAccountDeletion godoc
+// @Summary Cancel my account deletion
+// @Description Cancels the current user's scheduled account deletion.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.AccountDeletionResponse "Deletion cancelled"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 404 "No scheduled deletion"
+// @Failure 500 "Internal server error"
+// @Router /users/me/deletion [delete]
+func (h *UserHandler) CancelMyAccountDeletion(c *gin.Context) {
+	userID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	deletion, err := h.services.Deletion.CancelDeletion(c.Request.Context(), userID.String())
+	if err != nil {
+		respondAccountDeletionError(c, err, "Failed to cancel account deletion", "accountDeletionCancelFailed")
+		return
+	}
+	utils.RespondSuccess(c, deletion, "Account deletion cancelled")
+}
+
+// GetAccountDeletions godoc
+// @Summary List account deletion requests (admin only)
+// @Description Paginated self-service account deletions, soonest first. Filter by status to see pending or held ones.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param status query string false "Filter by status" Enums(pending, held, cancelled, completed)
+// @Success 200 {array} responses.AdminAccountDeletionResponse "Deletion requests"
+// @Failure 400 "Invalid status"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/users/deletions [get]
+func (h *UserHandler) GetAccountDeletions(c *gin.Context) {
+	page := 1
+	pageSize := 10
+	if pageStr := c.Query("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if parsed, err := strconv.Atoi(pageSizeStr); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+	status := strings.TrimSpace(c.Query("status"))
+	switch models.AccountDeletionStatus(status) {
+	case "", models.AccountDeletionStatusPending, models.AccountDeletionStatusHeld,
+		models.AccountDeletionStatusCancelled, models.AccountDeletionStatusCompleted:
+	default:
+		utils.RespondBadRequest(c, "Invalid status")
+		return
+	}
+
+	deletions, meta, err := h.services.Deletion.GetDeletionsForAdmin(c.Request.Context(), page, pageSize, status)
+	if err != nil {
+		utils.RespondInternalServerError(c, "Failed to retrieve account deletions")
+		return
+	}
+	utils.RespondSuccessWithMeta(c, &deletions, meta, "Successfully retrieved account deletions")
+}
+
+// HoldAccountDeletion godoc
+// @Summary Hold an account deletion (admin only)
+// @Description Stops a pending account deletion from running, e.g. while a payment is disputed, until it is released.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Deletion request ID" format(uuid)
+// @Param request body requests.HoldAccountDeletionRequest true "Reason for the hold"
+// @Success 200 {object} responses.AdminAccountDeletionResponse "Deletion held"
+// @Failure 400 "Bad request - validation error"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Deletion request not found"
+// @Failure 409 "Deletion is not pending"
+// @Failure 500 "Internal server error"
+// @Router /admin/users/deletions/{id}/hold [patch]
+func (h *UserHandler) HoldAccountDeletion(c *gin.Context) {
+	adminID, ok := currentUserUUID(c)
+	if !ok {
+		return
+	}
+	var req requests.HoldAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err.Error())
+		return
+	}
+	deletion, err := h.services.Deletion.HoldDeletion(c.Request.Context(), c.Param("id"), adminID.String(), &req)
+	if err != nil {
+		respondAccountDeletionError(c, err, "Failed to hold account deletion", "accountDeletionHoldFailed")
+		return
+	}
+	utils.RespondSuccess(c, deletion, "Account deletion held")
+}
+
+// ReleaseAccountDeletion godoc
+// @Summary Release a held account deletion (admin only)
+// @Description Puts a held account deletion back on schedule. If its grace period has ended, the user is anonymised by the next purge run.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Deletion request ID" format(uuid)
+// @Success 200 {object} responses.AdminAccountDeletionResponse "Deletion released"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 404 "Deletion request not found"
+// @Failure 409 "Deletion is not held"
+// @Failure 500 "Internal server error"
+// @Router /admin/users/deletions/{id}/release [patch]
+func (h *UserHandler) ReleaseAccountDeletion(c *gin.Context) {
+	deletion, err := h.services.Deletion.ReleaseDeletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAccountDeletionError(c, err, "Failed to release account deletion", "accountDeletionReleaseFailed")
+		return
+	}
+	utils.RespondSuccess(c, deletion, "Account deletion released")
+}
+
+// respondAccountDeletionError maps account deletion errors
+func respondAccountDeletionError(c *gin.Context, err error, fallbackMsg, fallbackKey string) {
+	switch {
+	case errors.Is(err, constants.ErrCurrentPasswordIncorrect):
+		utils.RespondErrorWithErrorMessage(c, 401, constants.ErrCodeUnauthorized, err.Error(), "currentPasswordIncorrect")
+	case errors.Is(err, constants.ErrAccountDeletionScheduled):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "accountDeletionScheduled")
+	case errors.Is(err, constants.ErrAccountDeletionInvalidStatus):
+		utils.RespondErrorWithErrorMessage(c, 409, "CONFLICT", err.Error(), "accountDeletionInvalidStatus")
+	case errors.Is(err, constants.ErrAccountDeletionNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "accountDeletionNotFound")
+	case errors.Is(err, constants.ErrInvalidUserID):
+		utils.RespondErrorWithErrorMessage(c, 400, constants.ErrCodeBadRequest, err.Error(), "invalidUserId")
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.RespondErrorWithErrorMessage(c, 404, constants.ErrCodeNotFound, err.Error(), "userNotFound")
+	default:
+		utils.RespondErrorWithErrorMessage(c, 500, constants.ErrCodeInternalServerError, fallbackMsg, fallbackKey)
+	}
+}
+
+// ResetUserMFA godoc
+// @Summary Reset a user's two-factor authentication (admin only)
+// @Description Removes the user's authenticator and recovery codes (e.g. lost phone) and signs them out everywhere.
+// @Description If 2FA is mandatory for their role, they set up a new authenticator at their next login.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 "Two-factor authentication reset"
+// @Failure 400 "Invalid user ID or 2FA not set up"
+// @Failure 401 "Unauthorized - missing or invalid token"
+// @Failure 403 "Forbidden - insufficient permissions"
+// @Failure 500 "Internal server error"
+// @Router /admin/users/{id}/mfa [delete]
+func (h *UserHandler) ResetUserMFA(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondBadRequest(c, "Invalid user ID format")
+		return
+	}
+	if err := h.services.MFA.Reset(c.Request.Context(), userID); err != nil {
+		respondMFAError(c, err, "Failed to reset two-factor authentication", "mfaResetFailed")
+		return
+	}
+	utils.RespondSuccess[any](c, nil, "Two-factor authentication reset")
+}
+
+// currentUserUUID returns the authenticated user's ID, responding 401 if it is missing
+func currentUserUUID(c *gin.Context) (uuid.UUID, bool) {
+	userIDRaw, _ := c.Get("user_id")
+	userIDStr, _ := userIDRaw.(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		utils.RespondUnauthorized(c, "Invalid user ID in token")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,35 @@
+package mappers
+
+import (
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/models"
+)
+
+// MapAccountDeletionToResponse maps an AccountDeletion to an AccountDeletionResponse
+func MapAccountDeletionToResponse(deletion *models.AccountDeletion) *responses.AccountDeletionResponse {
+	return &responses.AccountDeletionResponse{
+		Id:           deletion.Id,
+		Status:       string(deletion.Status),
+		Reason:       deletion.Reason,
+		RequestedAt:  deletion.CreatedAt,
+		ScheduledFor: deletion.ScheduledFor,
+		CancelledAt:  deletion.CancelledAt,
+		CompletedAt:  deletion.CompletedAt,
+		IsHeld:       deletion.Status == models.AccountDeletionStatusHeld,
+	}
+}
+
+// MapAccountDeletionToAdminResponse maps an AccountDeletion (with User preloaded) to an
+// AdminAccountDeletionResponse
+func MapAccountDeletionToAdminResponse(deletion *models.AccountDeletion) *responses.AdminAccountDeletionResponse {
+	return &responses.AdminAccountDeletionResponse{
+		AccountDeletionResponse: *MapAccountDeletionToResponse(deletion),
+		UserId:                  deletion.UserId,
+		UserEmail:               deletion.User.Email,
+		FursonaName:             deletion.User.FursonaName,
+		HeldBy:                  deletion.HeldBy,
+		HeldAt:                  deletion.HeldAt,
+		HoldReason:              deletion.HoldReason,
+		Summary:                 deletion.Summary,
+	}
+}
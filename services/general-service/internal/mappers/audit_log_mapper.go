@@ -0,0 +1,30 @@
+package mappers
+
+import (
+	"encoding/json"
+	"general-service/internal/dto/audit/responses"
+	"general-service/internal/models"
+)
+
+// MapAuditLogToResponse maps an admin audit log entry to its response
+func MapAuditLogToResponse(entry *models.AdminAuditLog) *responses.AuditLogResponse {
+	resp := &responses.AuditLogResponse{
+		Seq:        entry.Seq,
+		ActorId:    entry.ActorId,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		Status:     entry.Status,
+		IpAddress:  entry.IpAddress,
+		UserAgent:  entry.UserAgent,
+		RequestId:  entry.RequestId,
+		CreatedAt:  entry.CreatedAt,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+	if entry.Changes != "" {
+		resp.Changes = json.RawMessage(entry.Changes)
+	}
+	return resp
+}
@@ -0,0 +1,18 @@
+package mappers
+
+import (
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/models"
+)
+
+// MapDataExportToResponse maps a DataExport to a DataExportResponse
+func MapDataExportToResponse(export *models.DataExport) *responses.DataExportResponse {
+	return &responses.DataExportResponse{
+		Id:          export.Id,
+		Status:      string(export.Status),
+		ArchiveSize: export.ArchiveSize,
+		RequestedAt: export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
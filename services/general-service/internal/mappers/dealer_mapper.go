@@ -45,6 +45,8 @@ func MapDealerBoothToDetailResponse(booth *models.DealerBooth) *responses.Dealer
 		BoothNumber: booth.BoothNumber,
 		PriceSheets: booth.PriceSheets,
 		IsVerified:  booth.IsVerified,
+		FlaggedAt:   booth.FlaggedAt,
+		FlagReason:  booth.FlagReason,
 		CreatedAt:   booth.CreatedAt,
 		ModifiedAt:  booth.ModifiedAt,
 		Staffs:      staffs,
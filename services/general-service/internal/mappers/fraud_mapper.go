@@ -0,0 +1,43 @@
+package mappers
+
+import (
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/models"
+)
+
+// MapFraudAssessmentToResponse maps a FraudAssessment (with User and UserTicket preloaded) to a
+// FraudAssessmentResponse
+func MapFraudAssessmentToResponse(assessment *models.FraudAssessment) *responses.FraudAssessmentResponse {
+	signals := make([]*responses.FraudSignalResponse, len(assessment.Signals))
+	for i, signal := range assessment.Signals {
+		signals[i] = &responses.FraudSignalResponse{
+			Kind:               signal.Kind,
+			MatchedUserId:      signal.MatchedUserId,
+			MatchedBlacklisted: signal.MatchedBlacklisted,
+			Weight:             signal.Weight,
+			Detail:             signal.Detail,
+		}
+	}
+	response := &responses.FraudAssessmentResponse{
+		Id:            assessment.Id,
+		UserId:        assessment.UserId,
+		UserEmail:     assessment.User.Email,
+		FursonaName:   assessment.User.FursonaName,
+		IsBlacklisted: assessment.User.IsBlacklisted,
+		UserTicketId:  assessment.UserTicketId,
+		Score:         assessment.Score,
+		Signals:       signals,
+		Status:        string(assessment.Status),
+		TicketHeld:    assessment.TicketHeld,
+		ReviewedBy:    assessment.ReviewedBy,
+		ReviewedAt:    assessment.ReviewedAt,
+		ReviewNote:    assessment.ReviewNote,
+		CreatedAt:     assessment.CreatedAt,
+	}
+	if assessment.UserTicket != nil {
+		response.ReferenceCode = assessment.UserTicket.ReferenceCode
+		response.TicketStatus = string(assessment.UserTicket.Status)
+		response.FraudHold = assessment.UserTicket.FraudHold
+	}
+	return response
+}
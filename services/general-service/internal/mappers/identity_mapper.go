@@ -0,0 +1,26 @@
+package mappers
+
+import (
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/models"
+)
+
+// MapIdentityToResponse maps a UserIdentity to a LinkedIdentityResponse
+func MapIdentityToResponse(identity *models.UserIdentity) *responses.LinkedIdentityResponse {
+	return &responses.LinkedIdentityResponse{
+		Id:          identity.Id,
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		LinkedAt:    identity.LinkedAt,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
+
+// MapIdentitiesToResponse maps the user's linked identities to responses
+func MapIdentitiesToResponse(identities []models.UserIdentity) []responses.LinkedIdentityResponse {
+	result := make([]responses.LinkedIdentityResponse, len(identities))
+	for i := range identities {
+		result[i] = *MapIdentityToResponse(&identities[i])
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package mappers
+
+import (
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/models"
+	"time"
+)
+
+// MapImpersonationToAdminResponse maps an Impersonation (with Admin and TargetUser preloaded) to
+// an AdminImpersonationResponse
+func MapImpersonationToAdminResponse(impersonation *models.Impersonation) *responses.AdminImpersonationResponse {
+	return &responses.AdminImpersonationResponse{
+		Id:           impersonation.Id,
+		AdminId:      impersonation.AdminId,
+		AdminEmail:   impersonation.Admin.Email,
+		TargetUserId: impersonation.TargetUserId,
+		UserEmail:    impersonation.TargetUser.Email,
+		FursonaName:  impersonation.TargetUser.FursonaName,
+		Reason:       impersonation.Reason,
+		StartedAt:    impersonation.CreatedAt,
+		ExpiresAt:    impersonation.ExpiresAt,
+		EndedAt:      impersonation.EndedAt,
+		NotifiedAt:   impersonation.NotifiedAt,
+		IsActive:     impersonation.IsActive(time.Now()),
+	}
+}
@@ -0,0 +1,27 @@
+package mappers
+
+import (
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/models"
+)
+
+// MapPasskeyToResponse maps a WebAuthnCredential to a PasskeyResponse
+func MapPasskeyToResponse(credential *models.WebAuthnCredential) *responses.PasskeyResponse {
+	return &responses.PasskeyResponse{
+		Id:         credential.Id,
+		Name:       credential.Name,
+		Transports: credential.TransportList(),
+		BackedUp:   credential.BackedUp,
+		CreatedAt:  credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
+
+// MapPasskeysToResponse maps the user's passkeys to responses
+func MapPasskeysToResponse(credentials []models.WebAuthnCredential) []responses.PasskeyResponse {
+	result := make([]responses.PasskeyResponse, len(credentials))
+	for i := range credentials {
+		result[i] = *MapPasskeyToResponse(&credentials[i])
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package mappers
+
+import (
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/models"
+	"time"
+)
+
+// MapServiceAccountToResponse maps a ServiceAccount (with Keys preloaded) to a
+// ServiceAccountResponse
+func MapServiceAccountToResponse(account *models.ServiceAccount) *responses.ServiceAccountResponse {
+	keys := make([]*responses.APIKeyResponse, len(account.Keys))
+	for i := range account.Keys {
+		keys[i] = MapAPIKeyToResponse(&account.Keys[i])
+	}
+	return &responses.ServiceAccountResponse{
+		Id:          account.Id,
+		Name:        account.Name,
+		Description: account.Description,
+		CreatedBy:   account.CreatedBy,
+		CreatedAt:   account.CreatedAt,
+		DisabledAt:  account.DisabledAt,
+		Keys:        keys,
+	}
+}
+
+// MapAPIKeyToResponse maps an APIKey to an APIKeyResponse
+func MapAPIKeyToResponse(key *models.APIKey) *responses.APIKeyResponse {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return &responses.APIKeyResponse{
+		Id:            key.Id,
+		Name:          key.Name,
+		Prefix:        key.Prefix,
+		Scopes:        scopes,
+		ExpiresAt:     key.ExpiresAt,
+		LastUsedAt:    key.LastUsedAt,
+		LastUsedIp:    key.LastUsedIp,
+		RotatedFromId: key.RotatedFromId,
+		RevokedAt:     key.RevokedAt,
+		CreatedAt:     key.CreatedAt,
+		IsActive:      key.IsActive(time.Now()),
+	}
+}
@@ -66,6 +66,10 @@ func MapUserTicketToResponse(ticket *models.UserTicket, includeUser bool) *respo
 	}
 
 	// Include user info if requested and available (for admin view)
+	if includeUser {
+		response.RiskScore = ticket.RiskScore
+		response.FraudHold = ticket.FraudHold
+	}
 	if includeUser && ticket.User.Id != [16]byte{} {
 		response.User = &responses.TicketUserResponse{
 			ID:          ticket.User.Id,
@@ -61,3 +61,20 @@ func MapUserToDetailedResponseWithDealer(user *models.User, isDealer bool, isHas
 		ModifiedAt:      user.ModifiedAt,
 	}
 }
+
+// MapSessionsToResponse maps sessions to DTOs, flagging the one the request was made with
+func MapSessionsToResponse(sessions []models.UserSession, currentSessionID string) []responses.SessionResponse {
+	out := make([]responses.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		out[i] = responses.SessionResponse{
+			Id:         s.Id,
+			UserAgent:  s.UserAgent,
+			IpAddress:  s.IpAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			IsCurrent:  s.Id.String() == currentSessionID,
+		}
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeaderName = "X-Api-Key"
+
+// APIKeyAuthenticator resolves a service account API key. Implemented by
+// services.ServiceAccountService.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key, ip string) (*utils.APIKeyPrincipal, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator installs the authenticator used by JWTOrAPIKeyAuthMiddleware. Call it
+// once at startup, before serving requests; without it API keys are rejected.
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// JWTOrAPIKeyAuthMiddleware authenticates admin routes with either a user's JWT (as
+// JWTAuthMiddleware) or a service account API key, sent as X-Api-Key or as an
+// "Authorization: Bearer fvk_..." header. A key may only call the routes its scopes grant:
+// scopeRoutes maps "METHOD /path" (path after /admin, as registered) to the scope it needs, and
+// every other route is refused. Role checks further down let keys through.
+func JWTOrAPIKeyAuthMiddleware(scopeRoutes map[string]constants.APIKeyScope) gin.HandlerFunc {
+	jwtAuth := JWTAuthMiddleware()
+	return func(c *gin.Context) {
+		key, found := extractAPIKey(c)
+		if !found {
+			jwtAuth(c)
+			return
+		}
+		if apiKeyAuthenticator == nil {
+			utils.RespondUnauthorized(c, "API keys are not accepted")
+			c.Abort()
+			return
+		}
+
+		principal, err := apiKeyAuthenticator.AuthenticateAPIKey(c.Request.Context(), key, c.ClientIP())
+		if err != nil {
+			if !errors.Is(err, constants.ErrInvalidAPIKey) {
+				log.Printf("[ERROR] API key authentication failed: %v", err)
+				utils.RespondInternalServerError(c, "Failed to authenticate API key")
+			} else {
+				utils.RespondUnauthorized(c, "Invalid or expired API key")
+			}
+			c.Abort()
+			return
+		}
+
+		scope, ok := scopeRoutes[apiKeyRoute(c.Request.Method, c.FullPath())]
+		if !ok {
+			utils.RespondForbidden(c, "This route cannot be called with an API key")
+			c.Abort()
+			return
+		}
+		if !slices.Contains(principal.Scopes, scope) {
+			utils.RespondForbidden(c, "API key is missing the "+string(scope)+" scope")
+			c.Abort()
+			return
+		}
+
+		c.Set("api_key_principal", principal)
+		c.Set("service_account_id", principal.ServiceAccountID)
+		c.Next()
+	}
+}
+
+// extractAPIKey returns the API key of the request, if it was made with one.
+func extractAPIKey(c *gin.Context) (string, bool) {
+	if key := c.GetHeader(apiKeyHeaderName); key != "" {
+		return key, true
+	}
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && parts[0] == "Bearer" && strings.HasPrefix(parts[1], utils.APIKeyTag) {
+		return parts[1], true
+	}
+	return "", false
+}
+
+// apiKeyRoute is the scope table key of a route: "GET /tickets/:id" for GET .../admin/tickets/:id.
+func apiKeyRoute(method, route string) string {
+	if i := strings.Index(route, "/admin/"); i >= 0 {
+		route = route[i+len("/admin"):]
+	}
+	return method + " " + route
+}
+
+// isAPIKeyRequest reports whether the request was authenticated with a service account API key
+// (and so already authorized by scope).
+func isAPIKeyRequest(c *gin.Context) bool {
+	return utils.GetAPIKeyPrincipalFromContext(c) != nil
+}
@@ -25,9 +25,9 @@ func handlePreflightRequest(c *gin.Context, origin string, trimmedOrigins []stri
 func setCorsHeaders(c *gin.Context, origin string) {
 	c.Header("Access-Control-Allow-Origin", origin)
 	c.Header("Access-Control-Allow-Credentials", "true")
-	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, Origin, X-Requested-With")
+	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, Origin, X-Requested-With, X-Request-Id")
 	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-	c.Header("Access-Control-Expose-Headers", "Set-Cookie")
+	c.Header("Access-Control-Expose-Headers", "Set-Cookie, X-Request-Id")
 }
 
 func handleActualRequest(c *gin.Context, origin string, trimmedOrigins []string) {
@@ -1,26 +1,45 @@
 package middlewares
 
 import (
+	"bytes"
+	"io"
+	"log"
 	"net/http"
 	"os"
+	"time"
+
+	"general-service/internal/security"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-const InternalAPIKeyHeader = "X-Internal-Api-Key"
+const (
+	InternalAPIKeyHeader    = "X-Internal-Api-Key"
+	InternalSignatureHeader = "X-Job-Signature"
+
+	// internalSignatureMaxAge bounds clock skew and how long a captured request can be replayed.
+	internalSignatureMaxAge = 5 * time.Minute
+	// maxInternalJobBody caps the body read for signature verification.
+	maxInternalJobBody = 1 << 20
+	// internalSignatureKeyPrefix namespaces the accepted job signatures remembered in Redis.
+	internalSignatureKeyPrefix = "jobsig:"
+)
+
+// jobSignatureStore remembers accepted job signatures so each can be used only once.
+var jobSignatureStore *redis.Client
+
+// SetupInternalJobReplayGuard sets the Redis client used to reject replayed internal job
+// requests. Without Redis (local development) signatures are only checked for age.
+func SetupInternalJobReplayGuard(redisClient *redis.Client) {
+	jobSignatureStore = redisClient
+}
 
 // InternalAPIKeyMiddleware requires X-Internal-Api-Key header to match INTERNAL_API_KEY env.
 // If INTERNAL_API_KEY is not set, all requests are rejected (internal jobs disabled).
-// In development (ENV=development), the check is skipped.
 func InternalAPIKeyMiddleware() gin.HandlerFunc {
 	expectedKey := os.Getenv("INTERNAL_API_KEY")
-	env := os.Getenv("USE_LOCALSTACK")
-	isDev := env == "true"
 	return func(c *gin.Context) {
-		if isDev {
-			c.Next()
-			return
-		}
 		if expectedKey == "" {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"isSuccess":  false,
@@ -43,3 +62,77 @@ func InternalAPIKeyMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// InternalJobSignatureMiddleware requires internal job requests to carry an X-Job-Signature HMAC
+// over the method, path and body (see security.JobSigner), signed within the last few minutes
+// and not seen before. If JOB_SIGNING_KEYS is not set or invalid, all requests are rejected.
+func InternalJobSignatureMiddleware() gin.HandlerFunc {
+	signer, err := security.NewJobSignerFromEnv()
+	if err != nil {
+		log.Printf("WARNING: internal job endpoints disabled: %v", err)
+	}
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"isSuccess":  false,
+				"errorCode":  "FORBIDDEN",
+				"message":    "Job signing keys not configured",
+				"statusCode": http.StatusForbidden,
+			})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxInternalJobBody))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"isSuccess":  false,
+				"errorCode":  "BAD_REQUEST",
+				"message":    "Failed to read request body",
+				"statusCode": http.StatusBadRequest,
+			})
+			return
+		}
+		signature := c.GetHeader(InternalSignatureHeader)
+		scope := security.JobHTTPScope(c.Request.Method, c.Request.URL.Path)
+		if err := signer.Verify(signature, scope, body, time.Now(), internalSignatureMaxAge); err != nil {
+			log.Printf("Rejected internal job request %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"isSuccess":  false,
+				"errorCode":  "UNAUTHORIZED",
+				"message":    "Invalid or missing job signature",
+				"statusCode": http.StatusUnauthorized,
+			})
+			return
+		}
+		if jobSignatureStore != nil {
+			// A signature stays valid for maxAge either side of its timestamp, so remember it
+			// for the whole window.
+			key := internalSignatureKeyPrefix + security.JobSignatureMAC(signature)
+			fresh, err := jobSignatureStore.SetNX(c.Request.Context(), key, 1, 2*internalSignatureMaxAge).Result()
+			if err != nil {
+				log.Printf("Failed to check internal job signature replay for %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"isSuccess":  false,
+					"errorCode":  "SERVICE_UNAVAILABLE",
+					"message":    "Unable to verify job signature",
+					"statusCode": http.StatusServiceUnavailable,
+				})
+				return
+			}
+			if !fresh {
+				// 409 so the worker retries with a fresh signature: its own retries within the
+				// same second sign identically.
+				log.Printf("Rejected replayed internal job request %s %s", c.Request.Method, c.Request.URL.Path)
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"isSuccess":  false,
+					"errorCode":  "JOB_SIGNATURE_REPLAYED",
+					"message":    "Job signature already used",
+					"statusCode": http.StatusConflict,
+				})
+				return
+			}
+		}
+		// Restore the body for the handler's binding.
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
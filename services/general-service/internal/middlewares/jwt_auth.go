@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"context"
 	role "general-service/internal/common/constants"
 	"general-service/internal/common/utils"
+	"log"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +14,34 @@ const (
 	accessTokenCookieName = "access_token"
 )
 
+// TokenRevocationChecker reports whether a validated access token was revoked (signed-out session,
+// banned user, ...). Implemented by services.SessionService.
+type TokenRevocationChecker interface {
+	IsAccessTokenRevoked(ctx context.Context, claims *utils.JWTClaims) (bool, error)
+}
+
+var revocationChecker TokenRevocationChecker
+
+// SetTokenRevocationChecker installs the denylist consulted by the JWT middlewares. Call it once at
+// startup, before serving requests; without it revoked tokens stay valid until they expire.
+func SetTokenRevocationChecker(checker TokenRevocationChecker) {
+	revocationChecker = checker
+}
+
+// isRevoked fails open when the denylist is unavailable (like the login rate limiter), so a
+// Redis outage does not sign everyone out.
+func isRevoked(c *gin.Context, claims *utils.JWTClaims) bool {
+	if revocationChecker == nil {
+		return false
+	}
+	revoked, err := revocationChecker.IsAccessTokenRevoked(c.Request.Context(), claims)
+	if err != nil {
+		log.Printf("[WARN] Token revocation check failed: %v", err)
+		return false
+	}
+	return revoked
+}
+
 // extractToken extracts the JWT token from either httponly cookie or Authorization header
 // Returns the token string and a boolean indicating if token was found
 func extractToken(c *gin.Context) (string, bool) {
@@ -50,6 +80,9 @@ func setUserContext(c *gin.Context, claims *utils.JWTClaims) error {
 	c.Set("fursona_name", claims.FursonaName)
 	c.Set("role", userRole) // Store as UserRole int
 	c.Set("claims", claims)
+	if claims.Actor != nil {
+		c.Set("impersonator_id", claims.Actor.Subject)
+	}
 
 	return nil
 }
@@ -81,6 +114,13 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens of revoked sessions
+		if isRevoked(c, claims) {
+			utils.RespondUnauthorized(c, "Session has been revoked")
+			c.Abort()
+			return
+		}
+
 		// Set user context
 		if err := setUserContext(c, claims); err != nil {
 			utils.RespondUnauthorized(c, "Invalid role in token")
@@ -88,6 +128,10 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.Actor != nil {
+			auditImpersonatedRequest(c, claims)
+			return
+		}
 		c.Next()
 	}
 }
@@ -113,14 +157,18 @@ func OptionalJWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Check if it's an access token and set user context
-		if claims.TokenType == "access" {
+		// Check if it's a live access token and set user context
+		if claims.TokenType == "access" && !isRevoked(c, claims) {
 			// Try to set user context, but don't abort on error since it's optional
 			if err := setUserContext(c, claims); err != nil {
 				// Invalid role but optional, so continue
 				c.Next()
 				return
 			}
+			if claims.Actor != nil {
+				auditImpersonatedRequest(c, claims)
+				return
+			}
 		}
 
 		c.Next()
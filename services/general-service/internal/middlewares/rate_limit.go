@@ -0,0 +1,213 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitKey selects who a rate limit policy counts requests for.
+type RateLimitKey int
+
+const (
+	// RateLimitByIP counts requests per client IP.
+	RateLimitByIP RateLimitKey = iota
+	// RateLimitByUser counts requests per signed-in user, or per client IP for anonymous requests.
+	// Use it after JWTAuthMiddleware or OptionalJWTAuthMiddleware.
+	RateLimitByUser
+)
+
+// RateLimitPolicy allows Limit requests per Window for each key. Routes that share a policy name
+// share its counters. A policy with Limit <= 0 is disabled.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    RateLimitKey
+}
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// rateLimitResult is the state of one key after counting a request.
+type rateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	Reset     time.Duration // until the oldest counted request leaves the window
+}
+
+// slidingWindowScript counts a request in a sorted set of request timestamps (Redis server time,
+// so every instance agrees). Requests older than the window are dropped first; a request over the
+// limit is rejected without being counted. Returns {allowed, count, reset ms}.
+var slidingWindowScript = redis.NewScript(`
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < tonumber(ARGV[1]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// rateLimiter counts requests in Redis, and in process memory while Redis is not configured or
+// failing, so an outage weakens the limits (per instance) instead of disabling them.
+type rateLimiter struct {
+	redisClient *redis.Client
+	allowlist   []*net.IPNet
+	memory      *memoryRateLimiter
+}
+
+var limiter = &rateLimiter{memory: newMemoryRateLimiter()}
+
+// SetupRateLimiting installs the Redis client used by RateLimit and the networks that are never
+// limited (e.g. staff check-in devices at the venue). Call it once at startup, before serving
+// requests; without it limits are counted in process memory.
+func SetupRateLimiting(redisClient *redis.Client, allowlist []*net.IPNet) {
+	limiter = &rateLimiter{redisClient: redisClient, allowlist: allowlist, memory: newMemoryRateLimiter()}
+}
+
+func (l *rateLimiter) allowlisted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range l.allowlist {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *rateLimiter) allow(ctx context.Context, key string, policy RateLimitPolicy) rateLimitResult {
+	if l.redisClient != nil {
+		res, err := slidingWindowScript.Run(ctx, l.redisClient, []string{key},
+			policy.Limit, policy.Window.Milliseconds(), uuid.NewString()).Int64Slice()
+		if err == nil && len(res) == 3 {
+			return rateLimitResult{
+				Allowed:   res[0] == 1,
+				Remaining: policy.Limit - int(res[1]),
+				Reset:     time.Duration(res[2]) * time.Millisecond,
+			}
+		}
+		log.Printf("[WARN] Rate limit check failed, counting in memory: %v", err)
+	}
+	return l.memory.allow(key, policy, time.Now())
+}
+
+// RateLimit rejects requests over the policy with 429 Too Many Requests. Every response carries
+// the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers;
+// rejected ones also carry Retry-After. Allowlisted client IPs are not limited.
+func RateLimit(policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.Limit <= 0 || policy.Window <= 0 {
+			c.Next()
+			return
+		}
+		ip := c.ClientIP()
+		if limiter.allowlisted(ip) {
+			c.Next()
+			return
+		}
+
+		subject := "ip:" + ip
+		if policy.Key == RateLimitByUser {
+			if userID, err := utils.GetUserIDFromContext(c); err == nil && userID != uuid.Nil {
+				subject = "user:" + userID.String()
+			}
+		}
+		res := limiter.allow(c.Request.Context(), rateLimitKeyPrefix+policy.Name+":"+subject, policy)
+
+		resetSeconds := int(math.Ceil(res.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(max(res.Remaining, 0)))
+		c.Header("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(resetSeconds, 1)))
+			utils.RespondErrorWithErrorMessage(c, 429, constants.ErrCodeTooManyRequests, "Too many requests, please try again later", "tooManyRequests")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ========== In-process fallback ==========
+
+// memoryRateLimiter is the same sliding window over request times kept in process memory.
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	requests  map[string][]time.Time
+	maxWindow time.Duration
+	calls     int
+}
+
+// memorySweepInterval is how many checks pass between sweeps of idle keys.
+const memorySweepInterval = 1000
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{requests: make(map[string][]time.Time)}
+}
+
+func (m *memoryRateLimiter) allow(key string, policy RateLimitPolicy, now time.Time) rateLimitResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.maxWindow = max(m.maxWindow, policy.Window)
+	m.calls++
+	if m.calls%memorySweepInterval == 0 {
+		m.sweep(now)
+	}
+
+	times := dropBefore(m.requests[key], now.Add(-policy.Window))
+	allowed := len(times) < policy.Limit
+	if allowed {
+		times = append(times, now)
+	}
+	m.requests[key] = times
+	return rateLimitResult{
+		Allowed:   allowed,
+		Remaining: policy.Limit - len(times),
+		Reset:     times[0].Add(policy.Window).Sub(now),
+	}
+}
+
+// sweep drops keys without requests within the longest window in use.
+func (m *memoryRateLimiter) sweep(now time.Time) {
+	for key, times := range m.requests {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) > m.maxWindow {
+			delete(m.requests, key)
+		}
+	}
+}
+
+// dropBefore removes the leading times that are not after cutoff (times are ascending).
+func dropBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,106 @@
+package middlewares_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"general-service/internal/config"
+	"general-service/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitedRouter serves GET /limited under policy with the trusted proxies main.go
+// configures.
+func newRateLimitedRouter(t *testing.T, policy middlewares.RateLimitPolicy) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		t.Fatal(err)
+	}
+	router.GET("/limited", middlewares.RateLimit(policy), func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	return router
+}
+
+func TestRateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	_, allowlisted, err := net.ParseCIDR("203.0.113.7/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	middlewares.SetupRateLimiting(nil, []*net.IPNet{allowlisted})
+	t.Cleanup(func() { middlewares.SetupRateLimiting(nil, nil) })
+
+	router := newRateLimitedRouter(t, middlewares.RateLimitPolicy{
+		Name:   "test-forged-xff",
+		Limit:  2,
+		Window: time.Minute,
+		Key:    middlewares.RateLimitByIP,
+	})
+
+	tests := []struct {
+		name          string
+		forwardedFor  string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{"first request", "198.51.100.1", http.StatusOK, "1"},
+		{"new forwarded IP shares the remote address key", "198.51.100.2", http.StatusOK, "0"},
+		{"another forwarded IP is still limited", "198.51.100.3", http.StatusTooManyRequests, "0"},
+		{"allowlisted forwarded IP is still limited", "203.0.113.7", http.StatusTooManyRequests, "0"},
+		{"forwarded chain ending in an allowlisted IP is still limited", "203.0.113.7, 203.0.113.7", http.StatusTooManyRequests, "0"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = "192.0.2.10:41234"
+		req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		req.Header.Set("X-Real-IP", tt.forwardedFor)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.wantStatus)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("%s: RateLimit-Remaining %q, want %q", tt.name, got, tt.wantRemaining)
+		}
+		if rec.Code == http.StatusOK && rec.Body.String() != "192.0.2.10" {
+			t.Errorf("%s: client IP %q, want the remote address", tt.name, rec.Body.String())
+		}
+	}
+}
+
+func TestRateLimitAllowlistsRemoteAddress(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	_, allowlisted, err := net.ParseCIDR("203.0.113.7/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	middlewares.SetupRateLimiting(nil, []*net.IPNet{allowlisted})
+	t.Cleanup(func() { middlewares.SetupRateLimiting(nil, nil) })
+
+	router := newRateLimitedRouter(t, middlewares.RateLimitPolicy{
+		Name:   "test-allowlist",
+		Limit:  1,
+		Window: time.Minute,
+		Key:    middlewares.RateLimitByIP,
+	})
+	for i := range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = "203.0.113.7:41234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want %d", i+1, rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "" {
+			t.Fatalf("request %d: allowlisted request was counted (RateLimit-Limit %q)", i+1, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID that ties a request to its logs and audit entries.
+const RequestIDHeader = "X-Request-Id"
+
+// requestIDPattern limits client-supplied request IDs to something safe to log and store.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID keeps the caller's X-Request-Id when it is well-formed, otherwise assigns a new one.
+// The ID is stored in the gin context ("request_id") and echoed in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -11,6 +11,12 @@ import (
 // RequireRole creates a middleware that checks if the user has the required role
 func RequireRole(allowedRoles ...role.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Service account API keys are authorized by scope when they are authenticated
+		if isAPIKeyRequest(c) {
+			c.Next()
+			return
+		}
+
 		// Check if user is authenticated
 		if !utils.IsAuthenticated(c) {
 			utils.RespondUnauthorized(c, "Authentication required")
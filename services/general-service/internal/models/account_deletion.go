@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionStatusPending   AccountDeletionStatus = "pending"   // waiting for the grace period to end
+	AccountDeletionStatusHeld      AccountDeletionStatus = "held"      // put on hold by an admin (e.g. payment dispute)
+	AccountDeletionStatusCancelled AccountDeletionStatus = "cancelled" // cancelled by the user
+	AccountDeletionStatusCompleted AccountDeletionStatus = "completed" // user anonymised
+)
+
+// SubmissionPolicy sets what happens to the conbook, panel and talent submissions of a deleted user.
+type SubmissionPolicy string
+
+const (
+	SubmissionPolicyKeepApproved SubmissionPolicy = "keep_approved" // approved submissions are kept without personal details, the rest deleted
+	SubmissionPolicyAnonymise    SubmissionPolicy = "anonymise"     // every submission is kept without personal details
+	SubmissionPolicyDelete       SubmissionPolicy = "delete"        // every submission is deleted
+)
+
+// AccountDeletion is a user's request to delete their own account. Once ScheduledFor has passed
+// (and it is not held) the purge job anonymises the user; the request row is kept as the record
+// of what was done.
+type AccountDeletion struct {
+	Id           uuid.UUID               `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId       uuid.UUID               `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status       AccountDeletionStatus   `gorm:"type:varchar(20);not null;index" json:"status"`
+	Reason       string                  `gorm:"type:varchar(500)" json:"reason,omitempty"` // optional, from the user
+	ScheduledFor time.Time               `gorm:"not null;index" json:"scheduled_for"`
+	HeldBy       *uuid.UUID              `gorm:"type:uuid" json:"held_by,omitempty"`
+	HeldAt       *time.Time              `json:"held_at,omitempty"`
+	HoldReason   string                  `gorm:"type:varchar(500)" json:"hold_reason,omitempty"`
+	CancelledAt  *time.Time              `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time              `json:"completed_at,omitempty"`
+	Summary      *AccountDeletionSummary `gorm:"serializer:json;type:text" json:"summary,omitempty"`
+	CreatedAt    time.Time               `gorm:"autoCreateTime" json:"created_at"`
+	ModifiedAt   time.Time               `gorm:"autoUpdateTime" json:"modified_at"`
+	User         User                    `gorm:"foreignKey:UserId" json:"-"`
+}
+
+// AccountDeletionSummary records what anonymising a user did to their other records.
+type AccountDeletionSummary struct {
+	TicketsKept            int `json:"tickets_kept"`
+	BoothsTransferred      int `json:"booths_transferred"`
+	BoothsFlagged          int `json:"booths_flagged"`
+	SubmissionsKept        int `json:"submissions_kept"`
+	SubmissionsDeleted     int `json:"submissions_deleted"`
+	LoginMethodsRemoved    int `json:"login_methods_removed"`
+	SessionsRemoved        int `json:"sessions_removed"`
+	DataExportsRemoved     int `json:"data_exports_removed"`
+	EmailLogEntriesRemoved int `json:"email_log_entries_removed"`
+}
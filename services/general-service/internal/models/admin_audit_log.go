@@ -0,0 +1,56 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminAuditLog is one privileged action by an admin or staff member: who did it, from where,
+// what it targeted and, per changed entity, the before/after diff with PII redacted. The table is
+// append-only (a trigger rejects updates and deletes) and hash chained: each row's Hash covers its
+// content and the previous row's Hash, so editing, removing or reordering rows breaks the chain.
+type AdminAuditLog struct {
+	Seq        int64      `gorm:"primaryKey;autoIncrement:false" json:"seq"` // position in the chain, gapless from 1
+	ActorId    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	ActorRole  int        `gorm:"type:integer" json:"actor_role"`
+	Action     string     `gorm:"type:varchar(100);not null;index" json:"action"` // e.g. tickets.approve
+	EntityType string     `gorm:"type:varchar(50);index:idx_admin_audit_logs_entity" json:"entity_type"`
+	EntityId   string     `gorm:"type:varchar(100);index:idx_admin_audit_logs_entity" json:"entity_id"`
+	Changes    string     `gorm:"type:text" json:"-"`         // JSON: field => {before, after}; hashed as stored
+	Status     int        `gorm:"type:integer" json:"status"` // HTTP status of the request
+	IpAddress  string     `gorm:"type:varchar(64)" json:"ip_address"`
+	UserAgent  string     `gorm:"type:varchar(500)" json:"user_agent"`
+	RequestId  string     `gorm:"type:varchar(64);index" json:"request_id"`
+	CreatedAt  time.Time  `gorm:"not null;index" json:"created_at"`
+	PrevHash   string     `gorm:"type:varchar(64)" json:"prev_hash"`
+	Hash       string     `gorm:"type:varchar(64);not null" json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 (hex) over PrevHash and the row content. CreatedAt is hashed in
+// UTC with microsecond precision, as Postgres stores it.
+func (l *AdminAuditLog) ComputeHash() string {
+	actorID := ""
+	if l.ActorId != nil {
+		actorID = l.ActorId.String()
+	}
+	content, _ := json.Marshal([]interface{}{
+		l.Seq,
+		actorID,
+		l.ActorRole,
+		l.Action,
+		l.EntityType,
+		l.EntityId,
+		l.Changes,
+		l.Status,
+		l.IpAddress,
+		l.UserAgent,
+		l.RequestId,
+		l.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(append([]byte(l.PrevHash+"\n"), content...))
+	return hex.EncodeToString(sum[:])
+}
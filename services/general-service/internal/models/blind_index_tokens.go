@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// BlindIndexTokens is a Postgres text[] of blind index digests (hex strings, so they never need
+// quoting in the array literal). Search it with the @> (contains) operator.
+type BlindIndexTokens []string
+
+// Value implements driver.Valuer. An empty set is stored as '{}' rather than NULL, so NULL can
+// mean "not indexed yet".
+func (t BlindIndexTokens) Value() (driver.Value, error) {
+	return "{" + strings.Join(t, ",") + "}", nil
+}
+
+// Scan implements sql.Scanner for the text form of the array.
+func (t *BlindIndexTokens) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into BlindIndexTokens", value)
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if s == "" {
+		*t = BlindIndexTokens{}
+		return nil
+	}
+	*t = strings.Split(s, ",")
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusReady      DataExportStatus = "ready"
+	DataExportStatusFailed     DataExportStatus = "failed"
+)
+
+// DataExport is a user's request for a copy of their personal data. The worker builds the archive
+// (a zip of data.json and index.html), stored base64-encoded and encrypted with the user PII keys,
+// and emails a download link. Only the SHA-256 of the link token is stored.
+type DataExport struct {
+	Id                uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId            uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status            DataExportStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Archive           string           `gorm:"type:text" json:"-"`
+	ArchiveSize       int64            `gorm:"type:bigint;default:0" json:"archive_size"` // zip size in bytes
+	DownloadTokenHash string           `gorm:"type:varchar(64);index" json:"-"`
+	ExpiresAt         *time.Time       `gorm:"index" json:"expires_at,omitempty"` // download link expiry
+	Error             string           `gorm:"type:varchar(500)" json:"error,omitempty"`
+	CompletedAt       *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt         time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	ModifiedAt        time.Time        `gorm:"autoUpdateTime" json:"modified_at"`
+}
@@ -13,6 +13,8 @@ type DealerBooth struct {
 	BoothNumber string            `gorm:"type:varchar(100)"`
 	PriceSheets []string          `gorm:"serializer:json;type:text"` // image urls
 	IsVerified  bool              `gorm:"default:false"`
+	FlaggedAt   *time.Time        `gorm:"index"` // needs admin attention, e.g. its owner deleted their account
+	FlagReason  string            `gorm:"type:varchar(255)"`
 	CreatedAt   time.Time         `gorm:"autoCreateTime"`
 	ModifiedAt  time.Time         `gorm:"autoUpdateTime"`
 	DeletedAt   *time.Time        `gorm:"index"`
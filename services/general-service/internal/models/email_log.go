@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailLog records an email sent by the mail service (recipient and subject, not the body), so
+// users can be told which emails they were sent.
+type EmailLog struct {
+	Id        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Recipient string    `gorm:"type:varchar(255);not null;index" json:"recipient"` // lower-cased
+	Subject   string    `gorm:"type:varchar(500)" json:"subject"`
+	Provider  string    `gorm:"type:varchar(20)" json:"provider"` // ses, sendgrid
+	SentAt    time.Time `gorm:"not null;index" json:"sent_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FraudReviewStatus is where a fraud assessment is in the admin review queue
+type FraudReviewStatus string
+
+const (
+	FraudReviewOpen      FraudReviewStatus = "open"
+	FraudReviewCleared   FraudReviewStatus = "cleared"   // not a duplicate; any hold is released
+	FraudReviewConfirmed FraudReviewStatus = "confirmed" // a duplicate; the ticket stays held for denial
+)
+
+// Kinds of fraud signals
+const (
+	FraudSignalIDCard      = "id_card"      // same ID card number
+	FraudSignalBirthName   = "dob_name"     // same date of birth and a similar name
+	FraudSignalIdentity    = "identity"     // same Google (or other provider) account
+	FraudSignalDevice      = "device"       // signed in from the same device
+	FraudSignalIP          = "ip"           // signed in from the same IP address
+	FraudSignalPaymentMemo = "payment_memo" // overlapping bank transfer memo
+)
+
+// FraudSignal is one reason an account looks like a duplicate of another.
+type FraudSignal struct {
+	Kind               string    `json:"kind"`
+	MatchedUserId      uuid.UUID `json:"matched_user_id"`
+	MatchedBlacklisted bool      `json:"matched_blacklisted"`
+	Weight             int       `json:"weight"`
+	Detail             string    `json:"detail"`
+}
+
+// FraudAssessment flags a ticket purchase whose account looks like a duplicate of other accounts
+// (typically a blacklisted user registering again). Score is 0-100; assessments are created for
+// purchases scoring at least FRAUD_REVIEW_SCORE and wait in the review queue, and the ticket is
+// held (cannot be approved) when the score reaches FRAUD_HOLD_SCORE.
+type FraudAssessment struct {
+	Id           uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId       uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	UserTicketId *uuid.UUID        `gorm:"type:uuid;index" json:"user_ticket_id,omitempty"`
+	Score        int               `gorm:"type:int;not null;index" json:"score"`
+	Signals      []FraudSignal     `gorm:"serializer:json;type:text" json:"signals"`
+	Status       FraudReviewStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	TicketHeld   bool              `gorm:"not null;default:false" json:"ticket_held"` // the ticket was held automatically
+	ReviewedBy   *uuid.UUID        `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time        `json:"reviewed_at,omitempty"`
+	ReviewNote   string            `gorm:"type:varchar(500)" json:"review_note,omitempty"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	ModifiedAt   time.Time         `gorm:"autoUpdateTime" json:"modified_at"`
+	User         User              `gorm:"foreignKey:UserId" json:"-"`
+	UserTicket   *UserTicket       `gorm:"foreignKey:UserTicketId" json:"-"`
+}
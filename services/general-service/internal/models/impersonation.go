@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is an admin signed in as an attendee for support, through a short-lived access
+// token whose act claim names the admin and whose sid is this ID. It is active until ExpiresAt or
+// until ended; afterwards the user is emailed that it happened (NotifiedAt).
+type Impersonation struct {
+	Id           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	AdminId      uuid.UUID  `gorm:"type:uuid;not null;index" json:"admin_id"`
+	TargetUserId uuid.UUID  `gorm:"type:uuid;not null;index" json:"target_user_id"`
+	Reason       string     `gorm:"type:varchar(500);not null" json:"reason"` // support case, shown to admins only
+	TokenId      string     `gorm:"type:varchar(64)" json:"-"`                // jti of the issued token
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"` // set when an admin ended it before it expired
+	NotifiedAt   *time.Time `gorm:"index" json:"notified_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	Admin        User       `gorm:"foreignKey:AdminId" json:"-"`
+	TargetUser   User       `gorm:"foreignKey:TargetUserId" json:"-"`
+}
+
+// IsActive reports whether the impersonation token is still usable at now.
+func (i *Impersonation) IsActive(now time.Time) bool {
+	return i.EndedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -15,5 +18,18 @@ type Payment struct {
 	GatewayTransactionId string          `gorm:"type:varchar(255)"`
 	Provider             string          `gorm:"type:varchar(255)"`
 	RawResponse          string          `gorm:"type:varchar(1000)"`
+	Memo                 string          `gorm:"type:varchar(255)"` // transfer note reported by the bank or gateway
 	UserTicket           *UserTicket     `gorm:"foreignKey:UserTicketId"`
 }
+
+// PaymentMemoKeySQL is the memo compared between payments: upper case, letters and digits only, so
+// the same transfer note typed differently still matches. payments is indexed on it (see
+// database.AutoMigrate); PaymentMemoKey must compute the same value.
+const PaymentMemoKeySQL = `regexp_replace(upper(memo), '[^A-Z0-9]', '', 'g')`
+
+var paymentMemoNoise = regexp.MustCompile(`[^A-Z0-9]`)
+
+// PaymentMemoKey returns memo as PaymentMemoKeySQL computes it.
+func PaymentMemoKey(memo string) string {
+	return paymentMemoNoise.ReplaceAllString(strings.ToUpper(memo), "")
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is one issued refresh token. Every token minted by rotating the same login shares
+// its FamilyId, which is the UserSession ID; only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	Id        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyId  uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when rotated; presenting it again means it was stolen
+	RevokedAt *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
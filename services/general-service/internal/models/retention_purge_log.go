@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RetentionAction string
+
+const (
+	RetentionActionClear  RetentionAction = "clear"  // personal fields are blanked, the row is kept
+	RetentionActionDelete RetentionAction = "delete" // the row (and, for users, what hangs off it) is deleted
+)
+
+// RetentionPurgeLog records what one data retention rule purged in one run of the retention
+// purge job, or in a dry run what it would have purged. Every run writes one row per rule, so
+// the table is the audit trail of personal data removed under the retention policy.
+type RetentionPurgeLog struct {
+	Id          uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	RunId       uuid.UUID       `gorm:"type:uuid;not null;index" json:"run_id"`
+	Rule        string          `gorm:"type:varchar(50);not null;index" json:"rule"`
+	Action      RetentionAction `gorm:"type:varchar(20)" json:"action"`
+	Cutoff      time.Time       `json:"cutoff"` // rows anchored at or before this time were due
+	DryRun      bool            `gorm:"default:false;index" json:"dry_run"`
+	Affected    int64           `gorm:"type:bigint;default:0" json:"affected"`
+	RecordIds   []uuid.UUID     `gorm:"type:text;serializer:json" json:"record_ids,omitempty"` // purged rows; empty for dry runs
+	Error       string          `gorm:"type:text" json:"error,omitempty"`
+	TriggeredBy string          `gorm:"type:varchar(100)" json:"triggered_by"` // "schedule" or the admin's user ID
+	CreatedAt   time.Time       `gorm:"autoCreateTime;index" json:"created_at"`
+}
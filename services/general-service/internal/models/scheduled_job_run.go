@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type ScheduledJobStatus string
+
+const (
+	ScheduledJobStatusRunning   ScheduledJobStatus = "running"
+	ScheduledJobStatusSucceeded ScheduledJobStatus = "succeeded"
+	ScheduledJobStatusFailed    ScheduledJobStatus = "failed"
+)
+
+// ScheduledJobRun holds the latest run of each scheduled job executed by the sqs-worker.
+// One row per job name; the worker upserts it at the start and end of every run. The manual
+// "pii-reencryption" run (cmd/migrate -reencrypt-pii) reports its progress here too.
+type ScheduledJobRun struct {
+	Name           string             `gorm:"type:varchar(100);primaryKey" json:"name"`
+	Schedule       string             `gorm:"type:varchar(100)" json:"schedule"`
+	LastStatus     ScheduledJobStatus `gorm:"type:varchar(20)" json:"last_status"`
+	LastStartedAt  *time.Time         `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time         `json:"last_finished_at,omitempty"`
+	LastDurationMs int64              `gorm:"type:bigint;default:0" json:"last_duration_ms"`
+	LastError      string             `gorm:"type:text" json:"last_error,omitempty"`
+	LastResult     string             `gorm:"type:text" json:"last_result,omitempty"` // Job-specific JSON summary
+	LastSuccessAt  *time.Time         `json:"last_success_at,omitempty"`
+	ModifiedAt     time.Time          `gorm:"autoUpdateTime" json:"modified_at"`
+}
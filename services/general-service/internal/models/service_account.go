@@ -0,0 +1,54 @@
+package models
+
+import (
+	"general-service/internal/common/constants"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceAccount is a partner integration (badge printer station, Discord bot, hotel partner)
+// that calls the admin API with its own API keys instead of a person's token. Disabling it
+// revokes all its keys.
+type ServiceAccount struct {
+	Id          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name        string     `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Description string     `gorm:"type:varchar(500)" json:"description"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ModifiedAt  time.Time  `gorm:"autoUpdateTime" json:"modified_at"`
+	Keys        []APIKey   `gorm:"foreignKey:ServiceAccountId" json:"-"`
+}
+
+// APIKey is a credential of a service account, presented as "<Prefix>_<secret>". Prefix is
+// public and identifies the key; only the SHA-256 of the whole key is stored. A key can call the
+// admin routes of its Scopes until it expires or is revoked.
+type APIKey struct {
+	Id               uuid.UUID               `gorm:"type:uuid;primaryKey" json:"id"`
+	ServiceAccountId uuid.UUID               `gorm:"type:uuid;not null;index" json:"service_account_id"`
+	Name             string                  `gorm:"type:varchar(100)" json:"name"`
+	Prefix           string                  `gorm:"type:varchar(32);not null;uniqueIndex" json:"prefix"`
+	SecretHash       string                  `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes           []constants.APIKeyScope `gorm:"serializer:json;type:text" json:"scopes"`
+	ExpiresAt        *time.Time              `json:"expires_at,omitempty"`
+	LastUsedAt       *time.Time              `json:"last_used_at,omitempty"`
+	LastUsedIp       string                  `gorm:"type:varchar(64)" json:"last_used_ip,omitempty"`
+	RotatedFromId    *uuid.UUID              `gorm:"type:uuid" json:"rotated_from_id,omitempty"` // key this one replaced
+	RevokedAt        *time.Time              `json:"revoked_at,omitempty"`
+	RevokedBy        *uuid.UUID              `gorm:"type:uuid" json:"revoked_by,omitempty"`
+	CreatedBy        uuid.UUID               `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt        time.Time               `gorm:"autoCreateTime" json:"created_at"`
+	ServiceAccount   ServiceAccount          `gorm:"foreignKey:ServiceAccountId" json:"-"`
+}
+
+// IsActive reports whether the key can be used at now.
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope constants.APIKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of sign-in fingerprints recorded for duplicate account detection
+const (
+	FingerprintKindIP       = "ip"
+	FingerprintKindDevice   = "device"   // user agent
+	FingerprintKindIdentity = "identity" // "<provider>:<subject>" of a linked login provider
+)
+
+// UserFingerprint is something a user signed in with: an IP address, a device or an identity
+// provider account. Only its blind index is stored, so accounts sharing one can be found without
+// keeping the values; rows outlive the sessions and identities they came from (until the
+// user_fingerprint retention rule), so a banned user coming back is still recognised.
+type UserFingerprint struct {
+	Id          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_fingerprints_user_value" json:"user_id"`
+	Kind        string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_user_fingerprints_user_value;index:idx_user_fingerprints_value" json:"kind"`
+	ValueIndex  string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_user_fingerprints_user_value;index:idx_user_fingerprints_value" json:"-"`
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null;index" json:"last_seen_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity providers a user can sign in with
+const (
+	IdentityProviderGoogle   = "google"
+	IdentityProviderDiscord  = "discord"
+	IdentityProviderFacebook = "facebook"
+	IdentityProviderTelegram = "telegram"
+)
+
+// UserIdentity links a user to an account at an external identity provider. Subject is the
+// provider's stable user ID; Email is what the provider last reported (empty if it shares none).
+type UserIdentity struct {
+	Id          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider    string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(255)" json:"email,omitempty"`
+	LinkedAt    time.Time  `gorm:"not null" json:"linked_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
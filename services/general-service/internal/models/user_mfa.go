@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA is a user's TOTP authenticator. Secret is encrypted at rest with the user PII key ring (see
+// database.RegisterUserPIIEncryption). Until ConfirmedAt is set the enrolment is pending and the
+// authenticator is not required at login.
+type UserMFA struct {
+	UserId       uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Secret       string     `gorm:"type:text;not null" json:"-"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"` // TOTP time step of the last accepted code
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ModifiedAt   time.Time  `gorm:"autoUpdateTime" json:"modified_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a single-use code for signing in without the authenticator. Only the SHA-256
+// hash of the code is stored.
+type MFARecoveryCode struct {
+	Id        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
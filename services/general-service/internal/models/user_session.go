@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession is one signed-in device. It is created at login, carried in the access token as
+// the "sid" claim and shared by every refresh token rotated from that login.
+type UserSession struct {
+	Id            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CurrentJti    string     `gorm:"type:varchar(64);index" json:"-"` // jti of the latest access token
+	UserAgent     string     `gorm:"type:varchar(500)" json:"user_agent"`
+	IpAddress     string     `gorm:"type:varchar(64)" json:"ip_address"`
+	LastSeenAt    time.Time  `json:"last_seen_at"` // Updated on login and every token refresh
+	ExpiresAt     time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt     *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	RevokedReason string     `gorm:"type:varchar(50)" json:"revoked_reason,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -38,6 +38,8 @@ type UserTicket struct {
 	UpgradedFromTierID    *uuid.UUID   `gorm:"type:uuid" json:"upgraded_from_tier_id,omitempty"`          // Tier ID before upgrade (nil for fresh purchases)
 	PreviousReferenceCode string       `gorm:"type:varchar(50)" json:"previous_reference_code,omitempty"` // Reference code before upgrade
 	UpgradeDenialReason   string       `gorm:"type:varchar(500)" json:"upgrade_denial_reason,omitempty"`  // Reason for upgrade denial (set when upgrade is rolled back)
+	RiskScore             *int         `gorm:"type:int" json:"risk_score,omitempty"`                      // Duplicate-account risk 0-100, set once the purchase is screened
+	FraudHold             bool         `gorm:"default:false" json:"fraud_hold"`                           // Approval blocked until the fraud review is cleared
 	CreatedAt             time.Time    `gorm:"autoCreateTime" json:"created_at"`
 	ModifiedAt            time.Time    `gorm:"autoUpdateTime" json:"modified_at"`
 	DeletedAt             *time.Time   `gorm:"index" json:"deleted_at,omitempty"`
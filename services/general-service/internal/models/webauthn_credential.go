@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential is a passkey registered by a user. PublicKey is the COSE_Key from the
+// attestation; SignCount is the authenticator's counter from the last assertion (0 for synced
+// passkeys, which do not count).
+type WebAuthnCredential struct {
+	Id             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CredentialId   string     `gorm:"type:varchar(1400);not null;uniqueIndex" json:"credential_id"` // base64url
+	PublicKey      []byte     `gorm:"type:bytea;not null" json:"-"`
+	Algorithm      int        `gorm:"not null" json:"algorithm"` // COSE algorithm
+	SignCount      int64      `gorm:"not null;default:0" json:"sign_count"`
+	Aaguid         string     `gorm:"type:varchar(36)" json:"aaguid,omitempty"`
+	Transports     string     `gorm:"type:varchar(100)" json:"transports,omitempty"` // comma-separated
+	Name           string     `gorm:"type:varchar(100)" json:"name"`
+	BackupEligible bool       `gorm:"not null;default:false" json:"backup_eligible"`
+	BackedUp       bool       `gorm:"not null;default:false" json:"backed_up"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TransportList returns Transports as a slice (empty, not nil, when unknown).
+func (c WebAuthnCredential) TransportList() []string {
+	if c.Transports == "" {
+		return []string{}
+	}
+	return strings.Split(c.Transports, ",")
+}
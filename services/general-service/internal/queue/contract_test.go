@@ -0,0 +1,116 @@
+package queue_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"general-service/internal/queue"
+)
+
+// The job message contract fixtures in services/contracts/jobs must match what general-service
+// publishes today; `go test ./internal/queue -run TestJobContract -update` rewrites them.
+// sqs-worker's jobmsg contract test replays the same fixtures through the worker's decoder, so
+// together they round-trip every job and action.
+var update = flag.Bool("update", false, "rewrite the job contract fixtures instead of verifying them")
+
+const contractDir = "../../../contracts/jobs"
+
+// Fixed values so fixtures are stable between runs.
+const (
+	sampleUserID   = "11111111-1111-4111-8111-111111111111"
+	sampleStaffID  = "22222222-2222-4222-8222-222222222222"
+	sampleTicketID = "33333333-3333-4333-8333-333333333333"
+	sampleTierID   = "44444444-4444-4444-8444-444444444444"
+	sampleTraceID  = "55555555-5555-4555-8555-555555555555"
+	sampleExportID = "77777777-7777-4777-8777-777777777777"
+)
+
+var sampleEnqueuedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ticketSamples has one fully populated message per action.
+var ticketSamples = map[queue.TicketJobAction]queue.TicketJobMessage{
+	queue.ActionPurchaseTicket:  {Action: queue.ActionPurchaseTicket, UserID: sampleUserID, TierID: sampleTierID, AdminBypass: true},
+	queue.ActionConfirmPayment:  {Action: queue.ActionConfirmPayment, UserID: sampleUserID},
+	queue.ActionCancelTicket:    {Action: queue.ActionCancelTicket, UserID: sampleUserID},
+	queue.ActionUpdateBadge:     {Action: queue.ActionUpdateBadge, UserID: sampleUserID, ConBadgeName: "Badge", BadgeImage: "https://example.com/badge.png", NamecardUrl: "https://example.com/card.png", IsFursuiter: true, IsFursuitStaff: true},
+	queue.ActionApproveTicket:   {Action: queue.ActionApproveTicket, TicketID: sampleTicketID, StaffID: sampleStaffID},
+	queue.ActionDenyTicket:      {Action: queue.ActionDenyTicket, TicketID: sampleTicketID, StaffID: sampleStaffID, Reason: "Payment not received"},
+	queue.ActionUpgradeTicket:   {Action: queue.ActionUpgradeTicket, UserID: sampleUserID, TierID: sampleTierID, AdminBypass: true},
+	queue.ActionBlacklistUser:   {Action: queue.ActionBlacklistUser, TargetUserID: sampleUserID, StaffID: sampleStaffID, Reason: "Chargeback"},
+	queue.ActionUnblacklistUser: {Action: queue.ActionUnblacklistUser, TargetUserID: sampleUserID, StaffID: sampleStaffID},
+}
+
+type contractCase struct {
+	name    string
+	jobType queue.JobType
+	payload any
+}
+
+// contractCases lists one fixture per ticket action and job type.
+func contractCases(t *testing.T) []contractCase {
+	t.Helper()
+	var cases []contractCase
+	for _, action := range queue.TicketJobActions {
+		msg, ok := ticketSamples[action]
+		if !ok {
+			t.Fatalf("no contract sample for ticket action %q", action)
+		}
+		cases = append(cases, contractCase{fmt.Sprintf("ticket_%s", action), queue.JobTypeTicket, msg})
+	}
+	return append(cases,
+		contractCase{"bulk_approve", queue.JobTypeBulkApprove, queue.BulkApproveJobMessage{
+			TicketIDs: []string{sampleTicketID},
+			StaffID:   sampleStaffID,
+		}},
+		contractCase{"data_export", queue.JobTypeDataExport, queue.DataExportJobMessage{
+			ExportID: sampleExportID,
+			UserID:   sampleUserID,
+		}},
+	)
+}
+
+func TestJobContract(t *testing.T) {
+	for _, tc := range contractCases(t) {
+		name := fmt.Sprintf("v%d_%s.json", queue.JobSchemaVersion, tc.name)
+		t.Run(name, func(t *testing.T) {
+			want, err := envelopeFixture(tc.jobType, tc.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(contractDir, name)
+			if *update {
+				if err := os.WriteFile(path, want, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update and check sqs-worker still accepts it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("fixture out of date (run with -update and check sqs-worker still accepts it):\n  have %s\n  want %s", got, want)
+			}
+		})
+	}
+}
+
+func envelopeFixture(jobType queue.JobType, payload any) ([]byte, error) {
+	env, err := queue.NewJobEnvelope(jobType, payload)
+	if err != nil {
+		return nil, err
+	}
+	env.TraceID = sampleTraceID
+	env.EnqueuedAt = &sampleEnqueuedAt
+	b, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobSchemaVersion is the job message contract version stamped on every envelope (must match
+// sqs-worker jobmsg.CurrentVersion). The worker accepts this version and the one before it, and
+// sends newer versions to the DLQ, so bump it whenever a payload changes shape or a job type or
+// ticket action is added, and regenerate the fixtures with
+// `go test ./internal/queue -run TestJobContract -update`.
+const JobSchemaVersion = 3
+
+// JobType identifies which worker handler processes a job (must match sqs-worker jobmsg.JobType).
+type JobType string
+
+const (
+	JobTypeTicket      JobType = "ticket"
+	JobTypeBulkApprove JobType = "bulk_approve"
+	JobTypeDataExport  JobType = "data_export"
+)
+
+// JobEnvelope wraps every message sent to the worker queue.
+type JobEnvelope struct {
+	Type       JobType         `json:"type"`
+	Version    int             `json:"version"`
+	Payload    json.RawMessage `json:"payload"`
+	TraceID    string          `json:"trace_id,omitempty"`
+	EnqueuedAt *time.Time      `json:"enqueued_at,omitempty"`
+}
+
+// NewJobEnvelope wraps payload in a current-version envelope with a fresh trace ID.
+func NewJobEnvelope(jobType JobType, payload any) (*JobEnvelope, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &JobEnvelope{
+		Type:       jobType,
+		Version:    JobSchemaVersion,
+		Payload:    raw,
+		TraceID:    uuid.New().String(),
+		EnqueuedAt: &now,
+	}, nil
+}
+
+// BulkApproveJobMessage approves many tickets on behalf of one staff member.
+type BulkApproveJobMessage struct {
+	TicketIDs []string `json:"ticket_ids"`
+	StaffID   string   `json:"staff_id"`
+}
+
+// DataExportJobMessage builds the personal data export a user requested and emails its link.
+type DataExportJobMessage struct {
+	ExportID string `json:"export_id"`
+	UserID   string `json:"user_id"`
+}
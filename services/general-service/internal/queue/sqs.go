@@ -7,24 +7,33 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
+
+	"general-service/internal/security"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // Publisher sends messages to SQS.
 type Publisher interface {
 	PublishTicketJob(ctx context.Context, msg *TicketJobMessage) error
+	PublishJob(ctx context.Context, jobType JobType, payload any) error
 }
 
 // SQSClient wraps the AWS SQS client for publishing.
 type SQSClient struct {
 	client   *sqs.Client
 	queueURL string
+	signer   *security.JobSigner
 }
 
+// SignatureAttribute is the SQS message attribute carrying the job signature (must match sqs-worker).
+const SignatureAttribute = "JobSignature"
+
 // NewSQSClient creates an SQS client. If SQS_QUEUE_URL (or SQS_QUEUE) is empty, returns nil (queue disabled).
 func NewSQSClient(ctx context.Context) (*SQSClient, error) {
 	queueURL := os.Getenv("SQS_QUEUE_URL")
@@ -36,6 +45,12 @@ func NewSQSClient(ctx context.Context) (*SQSClient, error) {
 		return nil, nil
 	}
 
+	// Every message is signed; the worker rejects unsigned ones.
+	signer, err := security.NewJobSignerFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("job signing keys: %w", err)
+	}
+
 	region := os.Getenv("AWS_REGION")
 	if region == "" {
 		region = "ap-southeast-1"
@@ -45,7 +60,6 @@ func NewSQSClient(ctx context.Context) (*SQSClient, error) {
 	useLocalStack := os.Getenv("USE_LOCALSTACK") == "true" ||
 		strings.Contains(queueURL, "localhost") || strings.Contains(queueURL, "localstack")
 	var cfg aws.Config
-	var err error
 
 	if useLocalStack {
 		accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
@@ -80,6 +94,7 @@ func NewSQSClient(ctx context.Context) (*SQSClient, error) {
 	return &SQSClient{
 		client:   sqs.NewFromConfig(cfg),
 		queueURL: queueURL,
+		signer:   signer,
 	}, nil
 }
 
@@ -89,13 +104,32 @@ func (c *SQSClient) PublishTicketJob(ctx context.Context, msg *TicketJobMessage)
 		log.Printf("ERROR: PublishTicketJob called on nil SQSClient (action=%s) — this indicates a nil interface bug", msg.Action)
 		return fmt.Errorf("SQS client is nil; cannot publish job")
 	}
-	body, err := json.Marshal(msg)
+	return c.PublishJob(ctx, JobTypeTicket, msg)
+}
+
+// PublishJob wraps payload in a JobEnvelope of the given type, signs it and sends it to the queue.
+func (c *SQSClient) PublishJob(ctx context.Context, jobType JobType, payload any) error {
+	if c == nil {
+		log.Printf("ERROR: PublishJob called on nil SQSClient (type=%s) — this indicates a nil interface bug", jobType)
+		return fmt.Errorf("SQS client is nil; cannot publish job")
+	}
+	env, err := NewJobEnvelope(jobType, payload)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(env)
 	if err != nil {
 		return err
 	}
 	_, err = c.client.SendMessage(ctx, &sqs.SendMessageInput{
 		QueueUrl:    aws.String(c.queueURL),
 		MessageBody: aws.String(string(body)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			SignatureAttribute: {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(c.signer.Sign(security.JobScopeQueue, body, time.Now())),
+			},
+		},
 	})
 	return err
 }
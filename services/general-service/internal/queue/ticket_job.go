@@ -15,6 +15,19 @@ const (
 	ActionUnblacklistUser TicketJobAction = "unblacklist_user"
 )
 
+// TicketJobActions lists every action in the job contract (must match sqs-worker jobmsg.KnownActions).
+var TicketJobActions = []TicketJobAction{
+	ActionPurchaseTicket,
+	ActionConfirmPayment,
+	ActionCancelTicket,
+	ActionUpdateBadge,
+	ActionApproveTicket,
+	ActionDenyTicket,
+	ActionUpgradeTicket,
+	ActionBlacklistUser,
+	ActionUnblacklistUser,
+}
+
 // TicketJobMessage is the payload sent to SQS for ticket-related work.
 type TicketJobMessage struct {
 	Action       TicketJobAction `json:"action"`
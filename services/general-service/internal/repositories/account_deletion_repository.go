@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccountDeletionNotDue is returned by AnonymiseUser when the request was held or cancelled
+// after it was picked up.
+var ErrAccountDeletionNotDue = errors.New("account deletion is no longer pending")
+
+// deletedUserName replaces the fursona name of anonymised users, so staff lists stay readable.
+const deletedUserName = "Deleted user"
+
+type AccountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// AnonymiseOptions sets what AnonymiseUser does with the user's submissions.
+type AnonymiseOptions struct {
+	ConbookPolicy     models.SubmissionPolicy
+	PerformancePolicy models.SubmissionPolicy
+}
+
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *models.AccountDeletion) error {
+	return r.db.WithContext(ctx).Create(deletion).Error
+}
+
+func (r *AccountDeletionRepository) Save(ctx context.Context, deletion *models.AccountDeletion) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(deletion).Error
+}
+
+// FindByID returns a deletion request with its user (gorm.ErrRecordNotFound if none).
+func (r *AccountDeletionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	if err := r.db.WithContext(ctx).Preload("User").Where("id = ?", id).First(&deletion).Error; err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// FindActiveByUser returns the user's pending or held deletion request.
+func (r *AccountDeletionRepository) FindActiveByUser(ctx context.Context, userID uuid.UUID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status IN ?", userID,
+			[]models.AccountDeletionStatus{models.AccountDeletionStatusPending, models.AccountDeletionStatusHeld}).
+		Order("created_at DESC").
+		First(&deletion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// FindLatestByUser returns the user's most recent deletion request.
+func (r *AccountDeletionRepository) FindLatestByUser(ctx context.Context, userID uuid.UUID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&deletion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// FindAll returns deletion requests with their users, soonest first, optionally filtered by status.
+func (r *AccountDeletionRepository) FindAll(ctx context.Context, page, pageSize int, status string) ([]*models.AccountDeletion, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.AccountDeletion{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deletions []*models.AccountDeletion
+	err := query.Preload("User").
+		Order("scheduled_for ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deletions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deletions, total, nil
+}
+
+// FindDue returns up to limit pending requests whose grace period ended before now.
+func (r *AccountDeletionRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*models.AccountDeletion, error) {
+	var deletions []*models.AccountDeletion
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_for <= ?", models.AccountDeletionStatusPending, now).
+		Order("scheduled_for ASC").
+		Limit(limit).
+		Find(&deletions).Error
+	return deletions, err
+}
+
+// AnonymiseUser irreversibly removes the personal data of the deletion's user in one transaction
+// and marks the request completed. Ticket and payment rows are kept for accounting with the badge
+// details cleared; booths the user owned pass to their longest-serving staff member, or are
+// flagged for admins when there is none. Login methods, sessions, data exports and the email log
+// are deleted. It returns ErrAccountDeletionNotDue if the request is no longer pending.
+func (r *AccountDeletionRepository) AnonymiseUser(ctx context.Context, deletionID uuid.UUID, opts AnonymiseOptions) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", deletionID).First(&deletion).Error; err != nil {
+			return err
+		}
+		if deletion.Status != models.AccountDeletionStatusPending {
+			return ErrAccountDeletionNotDue
+		}
+		var user models.User
+		if err := tx.Where("id = ?", deletion.UserId).First(&user).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		summary := &models.AccountDeletionSummary{}
+		deletedAt := user.DeletedAt
+		if deletedAt == nil {
+			deletedAt = &now
+		}
+		// A map update skips the PII callbacks: the cleared values need neither encryption nor indexing.
+		err := tx.Model(&models.User{}).Where("id = ?", user.Id).Updates(map[string]interface{}{
+			"email":            "deleted-" + user.Id.String() + "@deleted.invalid",
+			"fursona_name":     deletedUserName,
+			"first_name":       "",
+			"last_name":        "",
+			"id_card":          "",
+			"id_card_index":    "",
+			"name_index":       models.BlindIndexTokens{},
+			"date_of_birth":    nil,
+			"avatar":           "",
+			"password":         "",
+			"password_unset":   true,
+			"is_verified":      false,
+			"blacklist_reason": "",
+			"is_deleted":       true,
+			"deleted_at":       deletedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.UserTicket{}).Where("user_id = ?", user.Id).Updates(map[string]interface{}{
+			"con_badge_name": "",
+			"badge_image":    "",
+			"namecard_url":   "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		summary.TicketsKept = int(result.RowsAffected)
+
+		if err := releaseDealerBooths(tx, user.Id, now, summary); err != nil {
+			return err
+		}
+
+		if err := applySubmissionPolicy(tx, &models.ConBookArt{}, user.Id, opts.ConbookPolicy,
+			"con_book_art_status", models.ConbookStatusApproved,
+			map[string]interface{}{"handle": ""}, summary); err != nil {
+			return err
+		}
+		performanceFields := map[string]interface{}{
+			"nickname":            "",
+			"materials_drive_url": "",
+			"members_info":        "[]",
+		}
+		panelFields := map[string]interface{}{"representative_facebook_url": ""}
+		talentFields := map[string]interface{}{"representative_url": ""}
+		for k, v := range performanceFields {
+			panelFields[k] = v
+			talentFields[k] = v
+		}
+		if err := applySubmissionPolicy(tx, &models.PerformancePanel{}, user.Id, opts.PerformancePolicy,
+			"panel_status", models.PanelStatusApproved, panelFields, summary); err != nil {
+			return err
+		}
+		if err := applySubmissionPolicy(tx, &models.PerformanceTalent{}, user.Id, opts.PerformancePolicy,
+			"talent_status", models.TalentStatusApproved, talentFields, summary); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.UserIdentity{}, &models.WebAuthnCredential{}} {
+			result := tx.Where("user_id = ?", user.Id).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			summary.LoginMethodsRemoved += int(result.RowsAffected)
+		}
+		for _, model := range []interface{}{&models.UserMFA{}, &models.MFARecoveryCode{}, &models.RefreshToken{}, &models.UserFingerprint{}} {
+			if err := tx.Where("user_id = ?", user.Id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		result = tx.Where("user_id = ?", user.Id).Delete(&models.UserSession{})
+		if result.Error != nil {
+			return result.Error
+		}
+		summary.SessionsRemoved = int(result.RowsAffected)
+		result = tx.Where("user_id = ?", user.Id).Delete(&models.DataExport{})
+		if result.Error != nil {
+			return result.Error
+		}
+		summary.DataExportsRemoved = int(result.RowsAffected)
+		result = tx.Where("recipient = ?", normalizeEmailLogRecipient(user.Email)).Delete(&models.EmailLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		summary.EmailLogEntriesRemoved = int(result.RowsAffected)
+
+		deletion.Status = models.AccountDeletionStatusCompleted
+		deletion.CompletedAt = &now
+		deletion.Summary = summary
+		deletion.User = user
+		return tx.Omit(clause.Associations).Save(&deletion).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// releaseDealerBooths ends the user's booth memberships. A booth the user owned passes to the
+// staff member who joined it first, or is flagged when no one else is left.
+func releaseDealerBooths(tx *gorm.DB, userID uuid.UUID, now time.Time, summary *models.AccountDeletionSummary) error {
+	var memberships []models.UserDealerStaff
+	if err := tx.Where("user_id = ? AND is_deleted = ?", userID, false).Find(&memberships).Error; err != nil {
+		return err
+	}
+	for _, membership := range memberships {
+		if !membership.IsOwner {
+			continue
+		}
+		var successor models.UserDealerStaff
+		err := tx.Where("booth_id = ? AND user_id <> ? AND is_deleted = ?", membership.BoothId, userID, false).
+			Order("created_at ASC").
+			First(&successor).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&successor).Update("is_owner", true).Error; err != nil {
+				return err
+			}
+			summary.BoothsTransferred++
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&models.DealerBooth{}).Where("id = ?", membership.BoothId).Updates(map[string]interface{}{
+				"flagged_at":  now,
+				"flag_reason": "Owner deleted their account and the booth has no other staff",
+			}).Error; err != nil {
+				return err
+			}
+			summary.BoothsFlagged++
+		default:
+			return err
+		}
+	}
+	return tx.Model(&models.UserDealerStaff{}).Where("user_id = ? AND is_deleted = ?", userID, false).
+		Updates(map[string]interface{}{"is_owner": false, "is_deleted": true, "deleted_at": now}).Error
+}
+
+// applySubmissionPolicy deletes the user's submissions of model that policy does not keep
+// (always including ones the user had already deleted) and clears anonymise on the rest.
+func applySubmissionPolicy(tx *gorm.DB, model interface{}, userID uuid.UUID, policy models.SubmissionPolicy, statusColumn string, approved interface{}, anonymise map[string]interface{}, summary *models.AccountDeletionSummary) error {
+	remove := tx.Where("user_id = ?", userID)
+	switch policy {
+	case models.SubmissionPolicyDelete:
+	case models.SubmissionPolicyAnonymise:
+		remove = remove.Where("is_deleted = ?", true)
+	default:
+		remove = remove.Where("(is_deleted = ? OR "+statusColumn+" <> ?)", true, approved)
+	}
+	result := remove.Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	summary.SubmissionsDeleted += int(result.RowsAffected)
+
+	result = tx.Model(model).Where("user_id = ?", userID).Updates(anonymise)
+	if result.Error != nil {
+		return result.Error
+	}
+	summary.SubmissionsKept += int(result.RowsAffected)
+	return nil
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// adminAuditLogLockKey is the Postgres advisory lock serialising appends to the audit chain.
+const adminAuditLogLockKey = 0x61756469 // "audi"
+
+// AdminAuditLogFilter narrows audit log queries; zero fields match everything.
+type AdminAuditLogFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AdminAuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminAuditLogRepository(db *gorm.DB) *AdminAuditLogRepository {
+	return &AdminAuditLogRepository{db: db}
+}
+
+// Append adds entries to the end of the chain: each gets the next Seq, the previous entry's hash
+// and its own hash. Appends are serialised with an advisory lock so the chain never forks.
+func (r *AdminAuditLogRepository) Append(ctx context.Context, entries []*models.AdminAuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", adminAuditLogLockKey).Error; err != nil {
+			return err
+		}
+		var last models.AdminAuditLog
+		err := tx.Order("seq DESC").Limit(1).Take(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		for _, entry := range entries {
+			entry.Seq = last.Seq + 1
+			entry.PrevHash = last.Hash
+			entry.CreatedAt = now
+			entry.Hash = entry.ComputeHash()
+			last = *entry
+		}
+		return tx.Create(&entries).Error
+	})
+}
+
+func (r *AdminAuditLogRepository) filtered(ctx context.Context, filter AdminAuditLogFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AdminAuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// FindAll returns matching entries, newest first.
+func (r *AdminAuditLogRepository) FindAll(ctx context.Context, filter AdminAuditLogFilter, page, pageSize int) ([]*models.AdminAuditLog, int64, error) {
+	query := r.filtered(ctx, filter)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []*models.AdminAuditLog
+	err := query.Order("seq DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// FindAfter returns up to limit matching entries with Seq greater than afterSeq, in chain order.
+// Used to walk the log in batches (CSV export, chain verification).
+func (r *AdminAuditLogRepository) FindAfter(ctx context.Context, filter AdminAuditLogFilter, afterSeq int64, limit int) ([]*models.AdminAuditLog, error) {
+	var entries []*models.AdminAuditLog
+	err := r.filtered(ctx, filter).
+		Where("seq > ?", afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
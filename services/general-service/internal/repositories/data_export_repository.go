@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// UserDataRecords is everything stored about one user, including soft-deleted rows (still held).
+type UserDataRecords struct {
+	User        models.User
+	Tickets     []models.UserTicket // with Ticket (tier) and Payment
+	DealerStaff []models.UserDealerStaff
+	Conbooks    []models.ConBookArt
+	Panels      []models.PerformancePanel
+	Talents     []models.PerformanceTalent
+	Identities  []models.UserIdentity
+	Emails      []models.EmailLog
+}
+
+func (r *DataExportRepository) Create(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+// Save writes every field of export (the archive is encrypted by the PII callbacks).
+func (r *DataExportRepository) Save(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+// FindByID returns an export without its archive (gorm.ErrRecordNotFound if none).
+func (r *DataExportRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.WithContext(ctx).Omit("archive").Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindLatestByUser returns the user's most recent export without its archive.
+func (r *DataExportRepository) FindLatestByUser(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).Omit("archive").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindInProgressByUser returns the user's pending or processing export created after since.
+func (r *DataExportRepository) FindInProgressByUser(ctx context.Context, userID uuid.UUID, since time.Time) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).Omit("archive").
+		Where("user_id = ? AND status IN ? AND created_at > ?", userID,
+			[]models.DataExportStatus{models.DataExportStatusPending, models.DataExportStatusProcessing}, since).
+		Order("created_at DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindReadyByTokenHash returns the ready, unexpired export with this download token hash,
+// including its (decrypted) archive.
+func (r *DataExportRepository) FindReadyByTokenHash(ctx context.Context, tokenHash string) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("download_token_hash = ? AND status = ? AND expires_at > ?", tokenHash, models.DataExportStatusReady, time.Now()).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// SetStatus moves an export to status, only from one of the from statuses. It reports whether
+// the export was in one of them.
+func (r *DataExportRepository) SetStatus(ctx context.Context, id uuid.UUID, status models.DataExportStatus, errMsg string, from ...models.DataExportStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.DataExport{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{"status": status, "error": errMsg})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CollectUserData loads every record of the user for an export. User PII is decrypted by the
+// query callbacks; emails are matched on the user's current address.
+func (r *DataExportRepository) CollectUserData(ctx context.Context, userID uuid.UUID) (*UserDataRecords, error) {
+	db := r.db.WithContext(ctx)
+	records := &UserDataRecords{}
+	if err := db.Where("id = ?", userID).First(&records.User).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Ticket").Preload("Payment").
+		Where("user_id = ?", userID).Order("created_at ASC").Find(&records.Tickets).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Booth").
+		Where("user_id = ?", userID).Order("created_at ASC").Find(&records.DealerStaff).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&records.Conbooks).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&records.Panels).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&records.Talents).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("linked_at ASC").Find(&records.Identities).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("recipient = ?", normalizeEmailLogRecipient(records.User.Email)).
+		Order("sent_at ASC").Find(&records.Emails).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailLogRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailLogRepository(db *gorm.DB) *EmailLogRepository {
+	return &EmailLogRepository{db: db}
+}
+
+func normalizeEmailLogRecipient(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Record logs one sent email.
+func (r *EmailLogRepository) Record(ctx context.Context, recipient, subject, provider string) error {
+	return r.db.WithContext(ctx).Create(&models.EmailLog{
+		Id:        uuid.New(),
+		Recipient: normalizeEmailLogRecipient(recipient),
+		Subject:   subject,
+		Provider:  provider,
+		SentAt:    time.Now(),
+	}).Error
+}
+
+// DeleteByRecipient removes the log of emails sent to an address.
+func (r *EmailLogRepository) DeleteByRecipient(ctx context.Context, recipient string) error {
+	return r.db.WithContext(ctx).Where("recipient = ?", normalizeEmailLogRecipient(recipient)).Delete(&models.EmailLog{}).Error
+}
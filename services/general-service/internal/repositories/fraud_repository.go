@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"general-service/internal/security"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FraudMatch is another account that shares something with the user being screened.
+type FraudMatch struct {
+	UserId        uuid.UUID
+	IsBlacklisted bool
+	Kind          string // fingerprint kind, for fingerprint matches
+	SharedBy      int    // accounts sharing the fingerprint, for fingerprint matches
+}
+
+type FraudRepository struct {
+	db       *gorm.DB
+	piiIndex *security.BlindIndex
+}
+
+func NewFraudRepository(db *gorm.DB, piiIndex *security.BlindIndex) *FraudRepository {
+	return &FraudRepository{db: db, piiIndex: piiIndex}
+}
+
+// RecordFingerprint records that the user signed in with value (of kind) at now. Only its blind
+// index is stored; empty values are ignored.
+func (r *FraudRepository) RecordFingerprint(ctx context.Context, userID uuid.UUID, kind, value string, now time.Time) error {
+	index := r.piiIndex.Fingerprint(kind, value)
+	if index == "" {
+		return nil
+	}
+	fingerprint := &models.UserFingerprint{
+		Id:          uuid.New(),
+		UserId:      userID,
+		Kind:        kind,
+		ValueIndex:  index,
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}, {Name: "value_index"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_seen_at": now}),
+	}).Create(fingerprint).Error
+}
+
+// FindFingerprintMatches returns the other (not deleted) accounts that share a fingerprint with
+// the user, one row per account and kind. Fingerprints shared by more than maxShared accounts of
+// their kind (a venue Wi-Fi, a common browser) are ignored.
+func (r *FraudRepository) FindFingerprintMatches(ctx context.Context, userID uuid.UUID, maxShared map[string]int) ([]FraudMatch, error) {
+	var matches []FraudMatch
+	for kind, limit := range maxShared {
+		var rows []FraudMatch
+		err := r.db.WithContext(ctx).Raw(`
+			SELECT o.user_id, u.is_blacklisted, o.kind, MAX(shared.accounts) AS shared_by
+			FROM user_fingerprints f
+			JOIN user_fingerprints o ON o.kind = f.kind AND o.value_index = f.value_index AND o.user_id <> f.user_id
+			JOIN users u ON u.id = o.user_id AND u.is_deleted = false
+			JOIN LATERAL (
+				SELECT COUNT(DISTINCT s.user_id) AS accounts FROM user_fingerprints s
+				WHERE s.kind = f.kind AND s.value_index = f.value_index
+			) shared ON true
+			WHERE f.user_id = ? AND f.kind = ? AND shared.accounts <= ?
+			GROUP BY o.user_id, u.is_blacklisted, o.kind`, userID, kind, limit).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, rows...)
+	}
+	return matches, nil
+}
+
+// FindIDCardMatches returns the other (not deleted) accounts with the same ID card number.
+func (r *FraudRepository) FindIDCardMatches(ctx context.Context, userID uuid.UUID, idCardIndex string) ([]FraudMatch, error) {
+	var matches []FraudMatch
+	if idCardIndex == "" {
+		return matches, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Select("id AS user_id, is_blacklisted").
+		Where("id_card_index = ? AND id <> ? AND is_deleted = ?", idCardIndex, userID, false).
+		Scan(&matches).Error
+	return matches, err
+}
+
+// FindBirthDateMatches returns up to limit other (not deleted) accounts born on the same day, with
+// their names decrypted for comparison.
+func (r *FraudRepository) FindBirthDateMatches(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("date_of_birth = ? AND id <> ? AND is_deleted = ?", dateOfBirth.Format("2006-01-02"), userID, false).
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// FindPaymentMemoMatches returns the other (not deleted) accounts whose payment has the same
+// transfer memo as one of the user's payments (ignoring case, spaces and punctuation), or whose
+// ticket reference code appears in the user's memo. Memos shorter than minLength are ignored.
+func (r *FraudRepository) FindPaymentMemoMatches(ctx context.Context, userID uuid.UUID, minLength int) ([]FraudMatch, error) {
+	var matches []FraudMatch
+	var memos []string
+	err := r.db.WithContext(ctx).Model(&models.Payment{}).
+		Joins("JOIN user_tickets t ON t.id = payments.user_ticket_id").
+		Where("t.user_id = ? AND payments.memo <> ''", userID).
+		Pluck("payments.memo", &memos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var keys, codes []string
+	for _, memo := range memos {
+		if key := models.PaymentMemoKey(memo); len(key) >= minLength {
+			keys = append(keys, key)
+		}
+		codes = append(codes, referenceCodesInMemo(memo)...)
+	}
+	if len(keys) == 0 && len(codes) == 0 {
+		return matches, nil
+	}
+	// An empty IN list renders as IN (NULL), which matches nothing.
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT t.user_id, u.is_blacklisted
+		FROM user_tickets t
+		JOIN users u ON u.id = t.user_id AND u.is_deleted = false
+		WHERE t.user_id <> ?
+			AND (t.id IN (SELECT p.user_ticket_id FROM payments p WHERE `+models.PaymentMemoKeySQL+` IN ?)
+				OR upper(t.reference_code) IN ?)`, userID, keys, codes).
+		Scan(&matches).Error
+	return matches, err
+}
+
+// memoReferenceCode matches hyphenated runs such as "T1-0042" (reference codes are
+// "<tier code>-<number>").
+var memoReferenceCode = regexp.MustCompile(`[A-Z0-9]+(?:-[A-Z0-9]+)+`)
+
+// referenceCodesInMemo returns the upper-cased reference codes memo could contain: every
+// hyphenated run and its suffixes from each hyphen, since the code may follow other text joined
+// with a hyphen ("PAY-T1-0042").
+func referenceCodesInMemo(memo string) []string {
+	var codes []string
+	for _, run := range memoReferenceCode.FindAllString(strings.ToUpper(memo), -1) {
+		for {
+			codes = append(codes, run)
+			_, rest, _ := strings.Cut(run, "-")
+			if !strings.Contains(rest, "-") {
+				break
+			}
+			run = rest
+		}
+	}
+	return codes
+}
+
+// FindUnscreenedTickets returns up to limit pending or self-confirmed tickets that have no risk
+// score yet (purchases made through the queue), oldest first, with their users.
+func (r *FraudRepository) FindUnscreenedTickets(ctx context.Context, limit int) ([]*models.UserTicket, error) {
+	var tickets []*models.UserTicket
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("risk_score IS NULL AND is_deleted = ? AND status IN ?", false,
+			[]models.TicketStatus{models.TicketStatusPending, models.TicketStatusSelfConfirmed}).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// FindTicketForScreening returns the ticket with its user.
+func (r *FraudRepository) FindTicketForScreening(ctx context.Context, ticketID uuid.UUID) (*models.UserTicket, error) {
+	var ticket models.UserTicket
+	if err := r.db.WithContext(ctx).Preload("User").
+		Where("id = ? AND is_deleted = ?", ticketID, false).
+		First(&ticket).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// SaveScreening stores the ticket's risk score and hold, and the assessment when the purchase
+// was flagged (nil otherwise), together.
+func (r *FraudRepository) SaveScreening(ctx context.Context, ticketID uuid.UUID, score int, hold bool, assessment *models.FraudAssessment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		values := map[string]interface{}{"risk_score": score}
+		if hold {
+			values["fraud_hold"] = true
+		}
+		if err := tx.Model(&models.UserTicket{}).Where("id = ?", ticketID).UpdateColumns(values).Error; err != nil {
+			return err
+		}
+		if assessment == nil {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(assessment).Error
+	})
+}
+
+// SaveReview stores a reviewed assessment, releasing its ticket's hold when release is set.
+func (r *FraudRepository) SaveReview(ctx context.Context, assessment *models.FraudAssessment, release bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(assessment).Error; err != nil {
+			return err
+		}
+		if !release || assessment.UserTicketId == nil {
+			return nil
+		}
+		return tx.Model(&models.UserTicket{}).Where("id = ?", *assessment.UserTicketId).UpdateColumn("fraud_hold", false).Error
+	})
+}
+
+// FindAssessmentByID returns an assessment with its user and ticket (gorm.ErrRecordNotFound if none).
+func (r *FraudRepository) FindAssessmentByID(ctx context.Context, id uuid.UUID) (*models.FraudAssessment, error) {
+	var assessment models.FraudAssessment
+	err := r.db.WithContext(ctx).Preload("User").Preload("UserTicket").Where("id = ?", id).First(&assessment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+// FindAssessments returns assessments with their users and tickets, highest score first, then
+// newest, optionally only those with status and at least minScore.
+func (r *FraudRepository) FindAssessments(ctx context.Context, page, pageSize int, status string, minScore int) ([]*models.FraudAssessment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.FraudAssessment{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if minScore > 0 {
+		query = query.Where("score >= ?", minScore)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var assessments []*models.FraudAssessment
+	err := query.Preload("User").Preload("UserTicket").
+		Order("score DESC, created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&assessments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return assessments, total, nil
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReferenceCodesInMemo(t *testing.T) {
+	tests := []struct {
+		memo string
+		want []string
+	}{
+		{"t1-0042", []string{"T1-0042"}},
+		{"Nguyen Van A chuyen tien T1-0042", []string{"T1-0042"}},
+		{"PAY-T1-0042 thanks", []string{"PAY-T1-0042", "T1-0042"}},
+		{"T1-0042, T2-0007", []string{"T1-0042", "T2-0007"}},
+		{"T10042", nil},
+	}
+	for _, tt := range tests {
+		if got := referenceCodesInMemo(tt.memo); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("referenceCodesInMemo(%q) = %q, want %q", tt.memo, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImpersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+func (r *ImpersonationRepository) Create(ctx context.Context, impersonation *models.Impersonation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(impersonation).Error
+}
+
+func (r *ImpersonationRepository) Save(ctx context.Context, impersonation *models.Impersonation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(impersonation).Error
+}
+
+// FindByID returns an impersonation with its admin and user (gorm.ErrRecordNotFound if none).
+func (r *ImpersonationRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+	var impersonation models.Impersonation
+	err := r.db.WithContext(ctx).Preload("Admin").Preload("TargetUser").Where("id = ?", id).First(&impersonation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation, nil
+}
+
+// FindActiveByAdmin returns the admin's impersonation that has neither ended nor expired at now.
+func (r *ImpersonationRepository) FindActiveByAdmin(ctx context.Context, adminID uuid.UUID, now time.Time) (*models.Impersonation, error) {
+	var impersonation models.Impersonation
+	err := r.db.WithContext(ctx).
+		Where("admin_id = ? AND ended_at IS NULL AND expires_at > ?", adminID, now).
+		Order("created_at DESC").
+		First(&impersonation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation, nil
+}
+
+// FindAll returns impersonations with their admins and users, newest first, optionally only
+// those of one user.
+func (r *ImpersonationRepository) FindAll(ctx context.Context, page, pageSize int, userID *uuid.UUID) ([]*models.Impersonation, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Impersonation{})
+	if userID != nil {
+		query = query.Where("target_user_id = ?", *userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var impersonations []*models.Impersonation
+	err := query.Preload("Admin").Preload("TargetUser").
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&impersonations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return impersonations, total, nil
+}
+
+// FindUnnotified returns up to limit impersonations that are over at now (ended or expired) and
+// whose user has not been told yet, with their users.
+func (r *ImpersonationRepository) FindUnnotified(ctx context.Context, now time.Time, limit int) ([]*models.Impersonation, error) {
+	var impersonations []*models.Impersonation
+	err := r.db.WithContext(ctx).Preload("TargetUser").
+		Where("notified_at IS NULL AND (ended_at IS NOT NULL OR expires_at <= ?)", now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&impersonations).Error
+	return impersonations, err
+}
+
+// MarkNotified sets NotifiedAt unless it is already set. It returns false when another run got
+// there first, so each user is emailed once.
+func (r *ImpersonationRepository) MarkNotified(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Impersonation{}).
+		Where("id = ? AND notified_at IS NULL", id).
+		UpdateColumn("notified_at", now)
+	return result.RowsAffected == 1, result.Error
+}
@@ -1,23 +1,57 @@
 package repositories
 
-import "gorm.io/gorm"
+import (
+	"general-service/internal/security"
+
+	"gorm.io/gorm"
+)
 
 type Repositories struct {
-	User    *UserRepository
-	Ticket  *TicketRepository
-	Dealer  *DealerRepository
-	Conbook *ConbookRepository
-	Panel   *PanelRepository
-	Talent  *TalentRepository
+	User           *UserRepository
+	Ticket         *TicketRepository
+	Dealer         *DealerRepository
+	Conbook        *ConbookRepository
+	Panel          *PanelRepository
+	Talent         *TalentRepository
+	ScheduledJob   *ScheduledJobRepository
+	RefreshToken   *RefreshTokenRepository
+	Session        *UserSessionRepository
+	MFA            *UserMFARepository
+	Passkey        *WebAuthnCredentialRepository
+	Identity       *UserIdentityRepository
+	DataExport     *DataExportRepository
+	EmailLog       *EmailLogRepository
+	Deletion       *AccountDeletionRepository
+	Retention      *RetentionRepository
+	AuditLog       *AdminAuditLogRepository
+	Impersonation  *ImpersonationRepository
+	ServiceAccount *ServiceAccountRepository
+	Fraud          *FraudRepository
 }
 
-func NewRepositories(db *gorm.DB) *Repositories {
+// NewRepositories creates the repositories. piiIndex is the blind index of the encrypted user PII
+// (see database.LoadUserPIIBlindIndex), used to search users by name and ID card.
+func NewRepositories(db *gorm.DB, piiIndex *security.BlindIndex) *Repositories {
 	return &Repositories{
-		User:    NewUserRepository(db),
-		Ticket:  NewTicketRepository(db),
-		Dealer:  NewDealerRepository(db),
-		Conbook: NewConbookRepository(db),
-		Panel:   NewPanelRepository(db),
-		Talent:  NewTalentRepository(db),
+		User:           NewUserRepository(db, piiIndex),
+		Ticket:         NewTicketRepository(db, piiIndex),
+		Dealer:         NewDealerRepository(db),
+		Conbook:        NewConbookRepository(db),
+		Panel:          NewPanelRepository(db),
+		Talent:         NewTalentRepository(db),
+		ScheduledJob:   NewScheduledJobRepository(db),
+		RefreshToken:   NewRefreshTokenRepository(db),
+		Session:        NewUserSessionRepository(db),
+		MFA:            NewUserMFARepository(db),
+		Passkey:        NewWebAuthnCredentialRepository(db),
+		Identity:       NewUserIdentityRepository(db),
+		DataExport:     NewDataExportRepository(db),
+		EmailLog:       NewEmailLogRepository(db),
+		Deletion:       NewAccountDeletionRepository(db),
+		Retention:      NewRetentionRepository(db),
+		AuditLog:       NewAdminAuditLogRepository(db),
+		Impersonation:  NewImpersonationRepository(db),
+		ServiceAccount: NewServiceAccountRepository(db),
+		Fraud:          NewFraudRepository(db, piiIndex),
 	}
 }
@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionTarget names a kind of personal data the retention purge can remove.
+type RetentionTarget string
+
+const (
+	RetentionTargetUserIdCard                RetentionTarget = "user_id_card"
+	RetentionTargetUserDateOfBirth           RetentionTarget = "user_date_of_birth"
+	RetentionTargetTicketDenialReason        RetentionTarget = "ticket_denial_reason"
+	RetentionTargetTicketUpgradeDenialReason RetentionTarget = "ticket_upgrade_denial_reason"
+	RetentionTargetUnverifiedUser            RetentionTarget = "unverified_user"
+	RetentionTargetSession                   RetentionTarget = "session"
+	RetentionTargetRefreshToken              RetentionTarget = "refresh_token"
+	RetentionTargetDataExportArchive         RetentionTarget = "data_export_archive"
+	RetentionTargetEmailLog                  RetentionTarget = "email_log"
+	RetentionTargetUserFingerprint           RetentionTarget = "user_fingerprint"
+)
+
+// retentionTarget says which rows of a target are due and how they are purged. due selects the
+// rows whose anchor time is at or before the cutoff that still hold data to purge; purge runs
+// inside the transaction that locked the selected rows.
+type retentionTarget struct {
+	model  interface{}
+	action models.RetentionAction
+	due    func(tx *gorm.DB, before time.Time) *gorm.DB
+	purge  func(tx *gorm.DB, ids []uuid.UUID) error
+}
+
+// clearColumns blanks columns without running the hooks or touching modified_at, so a purge does
+// not look like a change by the user (and does not move the anchor of later rules). Cleared values
+// need neither encryption nor indexing, so skipping the PII callbacks is fine.
+func clearColumns(model interface{}, values map[string]interface{}) func(tx *gorm.DB, ids []uuid.UUID) error {
+	return func(tx *gorm.DB, ids []uuid.UUID) error {
+		return tx.Model(model).Where("id IN ?", ids).UpdateColumns(values).Error
+	}
+}
+
+func deleteRows(model interface{}) func(tx *gorm.DB, ids []uuid.UUID) error {
+	return func(tx *gorm.DB, ids []uuid.UUID) error {
+		return tx.Where("id IN ?", ids).Delete(model).Error
+	}
+}
+
+var retentionTargets = map[RetentionTarget]retentionTarget{
+	// Users who registered for the event that ended at the cutoff.
+	RetentionTargetUserIdCard: {
+		model:  &models.User{},
+		action: models.RetentionActionClear,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("created_at <= ? AND id_card <> ''", before)
+		},
+		purge: clearColumns(&models.User{}, map[string]interface{}{"id_card": "", "id_card_index": ""}),
+	},
+	RetentionTargetUserDateOfBirth: {
+		model:  &models.User{},
+		action: models.RetentionActionClear,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("created_at <= ? AND date_of_birth IS NOT NULL", before)
+		},
+		purge: clearColumns(&models.User{}, map[string]interface{}{"date_of_birth": nil}),
+	},
+	RetentionTargetTicketDenialReason: {
+		model:  &models.UserTicket{},
+		action: models.RetentionActionClear,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("denied_at <= ? AND denial_reason <> ''", before)
+		},
+		purge: clearColumns(&models.UserTicket{}, map[string]interface{}{"denial_reason": ""}),
+	},
+	// Upgrade denials have no timestamp of their own; the rollback is the ticket's last change.
+	RetentionTargetTicketUpgradeDenialReason: {
+		model:  &models.UserTicket{},
+		action: models.RetentionActionClear,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("modified_at <= ? AND upgrade_denial_reason <> ''", before)
+		},
+		purge: clearColumns(&models.UserTicket{}, map[string]interface{}{"upgrade_denial_reason": ""}),
+	},
+	// Never-verified accounts that never bought a ticket, joined a booth or submitted anything.
+	RetentionTargetUnverifiedUser: {
+		model:  &models.User{},
+		action: models.RetentionActionDelete,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("users.created_at <= ? AND users.is_verified = ? AND users.is_deleted = ?", before, false, false).
+				Where("NOT EXISTS (SELECT 1 FROM user_tickets WHERE user_tickets.user_id = users.id)").
+				Where("NOT EXISTS (SELECT 1 FROM user_dealer_staffs WHERE user_dealer_staffs.user_id = users.id)").
+				Where("NOT EXISTS (SELECT 1 FROM con_book_arts WHERE con_book_arts.user_id = users.id)").
+				Where("NOT EXISTS (SELECT 1 FROM performance_panels WHERE performance_panels.user_id = users.id)").
+				Where("NOT EXISTS (SELECT 1 FROM performance_talents WHERE performance_talents.user_id = users.id)")
+		},
+		purge: deleteUnverifiedUsers,
+	},
+	RetentionTargetSession: {
+		model:  &models.UserSession{},
+		action: models.RetentionActionDelete,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("expires_at <= ?", before)
+		},
+		purge: deleteRows(&models.UserSession{}),
+	},
+	RetentionTargetRefreshToken: {
+		model:  &models.RefreshToken{},
+		action: models.RetentionActionDelete,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("expires_at <= ?", before)
+		},
+		purge: deleteRows(&models.RefreshToken{}),
+	},
+	// The request row stays so the user still sees the export in their history.
+	RetentionTargetDataExportArchive: {
+		model:  &models.DataExport{},
+		action: models.RetentionActionClear,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("expires_at <= ? AND (archive <> '' OR download_token_hash <> '')", before)
+		},
+		purge: clearColumns(&models.DataExport{}, map[string]interface{}{"archive": "", "download_token_hash": ""}),
+	},
+	RetentionTargetEmailLog: {
+		model:  &models.EmailLog{},
+		action: models.RetentionActionDelete,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("sent_at <= ?", before)
+		},
+		purge: deleteRows(&models.EmailLog{}),
+	},
+	RetentionTargetUserFingerprint: {
+		model:  &models.UserFingerprint{},
+		action: models.RetentionActionDelete,
+		due: func(tx *gorm.DB, before time.Time) *gorm.DB {
+			return tx.Where("last_seen_at <= ?", before)
+		},
+		purge: deleteRows(&models.UserFingerprint{}),
+	},
+}
+
+// deleteUnverifiedUsers deletes the users and their login methods, sessions and requests. The
+// due filter guarantees they have no tickets, booths or submissions.
+func deleteUnverifiedUsers(tx *gorm.DB, ids []uuid.UUID) error {
+	dependents := []interface{}{
+		&models.UserIdentity{},
+		&models.WebAuthnCredential{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
+		&models.RefreshToken{},
+		&models.UserSession{},
+		&models.UserFingerprint{},
+		&models.DataExport{},
+		&models.AccountDeletion{},
+	}
+	for _, model := range dependents {
+		if err := tx.Where("user_id IN ?", ids).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("id IN ?", ids).Delete(&models.User{}).Error
+}
+
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// RetentionTargetAction returns whether the target clears fields or deletes rows.
+func RetentionTargetAction(target RetentionTarget) models.RetentionAction {
+	return retentionTargets[target].action
+}
+
+func lookupRetentionTarget(target RetentionTarget) (retentionTarget, error) {
+	t, ok := retentionTargets[target]
+	if !ok {
+		return retentionTarget{}, fmt.Errorf("unknown retention target %q", target)
+	}
+	return t, nil
+}
+
+// CountDue returns how many rows of the target are due for purging (the dry-run report).
+func (r *RetentionRepository) CountDue(ctx context.Context, target RetentionTarget, before time.Time) (int64, error) {
+	t, err := lookupRetentionTarget(target)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = t.due(r.db.WithContext(ctx).Model(t.model), before).Count(&count).Error
+	return count, err
+}
+
+// PurgeBatch purges up to limit due rows of the target in one transaction and returns their IDs.
+// Fewer than limit IDs means nothing more was due.
+func (r *RetentionRepository) PurgeBatch(ctx context.Context, target RetentionTarget, before time.Time, limit int) ([]uuid.UUID, error) {
+	t, err := lookupRetentionTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := t.due(tx.Model(t.model), before).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return t.purge(tx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateLog records what one rule of a run purged.
+func (r *RetentionRepository) CreateLog(ctx context.Context, entry *models.RetentionPurgeLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// FindLogs returns purge log entries, newest first, optionally filtered by rule and run.
+func (r *RetentionRepository) FindLogs(ctx context.Context, page, pageSize int, rule string, runID *uuid.UUID) ([]*models.RetentionPurgeLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.RetentionPurgeLog{})
+	if rule != "" {
+		query = query.Where("rule = ?", rule)
+	}
+	if runID != nil {
+		query = query.Where("run_id = ?", *runID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []*models.RetentionPurgeLog
+	err := query.Order("created_at DESC, rule ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledJobRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledJobRepository(db *gorm.DB) *ScheduledJobRepository {
+	return &ScheduledJobRepository{db: db}
+}
+
+// FindAll returns the latest run of every scheduled job, ordered by name.
+func (r *ScheduledJobRepository) FindAll(ctx context.Context) ([]models.ScheduledJobRun, error) {
+	var runs []models.ScheduledJobRun
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) *ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+func (r *ServiceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(account).Error
+}
+
+func (r *ServiceAccountRepository) Save(ctx context.Context, account *models.ServiceAccount) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(account).Error
+}
+
+// ExistsByName reports whether a service account has the name (case-insensitive).
+func (r *ServiceAccountRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ServiceAccount{}).Where("LOWER(name) = LOWER(?)", name).Count(&count).Error
+	return count > 0, err
+}
+
+// FindByID returns a service account with its keys, newest first (gorm.ErrRecordNotFound if none).
+func (r *ServiceAccountRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	var account models.ServiceAccount
+	err := r.db.WithContext(ctx).
+		Preload("Keys", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		Where("id = ?", id).
+		First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindAll returns service accounts with their keys, by name.
+func (r *ServiceAccountRepository) FindAll(ctx context.Context, page, pageSize int) ([]*models.ServiceAccount, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.ServiceAccount{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var accounts []*models.ServiceAccount
+	err := r.db.WithContext(ctx).
+		Preload("Keys", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		Order("name ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return accounts, total, nil
+}
+
+// Disable marks the service account disabled and revokes its active keys.
+func (r *ServiceAccountRepository) Disable(ctx context.Context, account *models.ServiceAccount, now time.Time, disabledBy uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account.DisabledAt = &now
+		if err := tx.Omit(clause.Associations).Save(account).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", account.Id).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_by": disabledBy}).Error
+	})
+}
+
+func (r *ServiceAccountRepository) CreateKey(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(key).Error
+}
+
+func (r *ServiceAccountRepository) SaveKey(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(key).Error
+}
+
+// FindKey returns a key of the service account (gorm.ErrRecordNotFound if none).
+func (r *ServiceAccountRepository) FindKey(ctx context.Context, accountID, keyID uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Where("id = ? AND service_account_id = ?", keyID, accountID).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindKeyByPrefix returns the key with the prefix and its service account (gorm.ErrRecordNotFound
+// if none).
+func (r *ServiceAccountRepository) FindKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Preload("ServiceAccount").Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RotateKey creates next in place of old, which stays valid until oldExpiresAt (revoked now when
+// oldExpiresAt is not after now).
+func (r *ServiceAccountRepository) RotateKey(ctx context.Context, old, next *models.APIKey, oldExpiresAt time.Time, now time.Time, rotatedBy uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(next).Error; err != nil {
+			return err
+		}
+		if oldExpiresAt.After(now) {
+			if old.ExpiresAt == nil || oldExpiresAt.Before(*old.ExpiresAt) {
+				old.ExpiresAt = &oldExpiresAt
+			}
+		} else {
+			old.RevokedAt = &now
+			old.RevokedBy = &rotatedBy
+		}
+		return tx.Omit(clause.Associations).Save(old).Error
+	})
+}
+
+// TouchKey records that the key was used at now from ip. It writes at most once per interval per
+// key, so busy integrations do not update the row on every request.
+func (r *ServiceAccountRepository) TouchKey(ctx context.Context, id uuid.UUID, now time.Time, ip string, interval time.Duration) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
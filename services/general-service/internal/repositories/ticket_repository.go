@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"general-service/internal/models"
+	"general-service/internal/security"
 	"regexp"
 	"strconv"
 	"strings"
@@ -26,14 +27,17 @@ var (
 	ErrUserNotFound         = errors.New("user not found")
 	ErrCannotDowngrade      = errors.New("cannot downgrade: new tier price must be higher than current tier price")
 	ErrTicketNotApproved    = errors.New("only approved tickets can be upgraded")
+	ErrTicketOnHold         = errors.New("ticket is held for fraud review")
+	ErrTicketNotScreened    = errors.New("ticket has not been screened for fraud yet")
 )
 
 type TicketRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	piiIndex *security.BlindIndex
 }
 
-func NewTicketRepository(db *gorm.DB) *TicketRepository {
-	return &TicketRepository{db: db}
+func NewTicketRepository(db *gorm.DB, piiIndex *security.BlindIndex) *TicketRepository {
+	return &TicketRepository{db: db, piiIndex: piiIndex}
 }
 
 // ========== Ticket Tier Operations ==========
@@ -525,6 +529,12 @@ func (r *TicketRepository) ApproveTicket(ctx context.Context, ticketID, staffID
 		if ticket.Status != models.TicketStatusPending && ticket.Status != models.TicketStatusSelfConfirmed {
 			return ErrInvalidTicketStatus
 		}
+		if ticket.FraudHold {
+			return ErrTicketOnHold
+		}
+		if ticket.RiskScore == nil {
+			return ErrTicketNotScreened
+		}
 
 		// If this is an upgrade, free the old tier seat now that admin has confirmed.
 		// (UpgradeTicketTier reserved the new tier seat at request time but deferred
@@ -868,10 +878,8 @@ func (r *TicketRepository) GetTicketsForAdmin(ctx context.Context, filter AdminT
 	}
 
 	if filter.Search != "" {
-		searchPattern := "%" + filter.Search + "%"
 		query = query.Joins("LEFT JOIN users ON users.id = user_tickets.user_id AND users.is_deleted = false").
-			Where("reference_code ILIKE ? OR users.first_name ILIKE ? OR users.last_name ILIKE ? OR users.email ILIKE ? OR users.fursona_name ILIKE ?",
-				searchPattern, searchPattern, searchPattern, searchPattern, searchPattern)
+			Where("reference_code ILIKE ? OR ?", "%"+filter.Search+"%", userSearchCondition(r.piiIndex, "users", filter.Search))
 	}
 
 	if filter.PendingOver24 {
@@ -907,6 +915,23 @@ func (r *TicketRepository) GetTicketsForAdmin(ctx context.Context, filter AdminT
 	return tickets, total, nil
 }
 
+// GetPendingTicketsLastModifiedBetween returns pending (not self-confirmed) tickets whose last change
+// falls in [from, to), with user and tier preloaded. Used by the payment reminder job.
+func (r *TicketRepository) GetPendingTicketsLastModifiedBetween(ctx context.Context, from, to time.Time) ([]models.UserTicket, error) {
+	var tickets []models.UserTicket
+	err := r.db.WithContext(ctx).
+		Preload("Ticket").
+		Preload("User").
+		Where("is_deleted = ? AND status = ? AND modified_at >= ? AND modified_at < ?",
+			false, models.TicketStatusPending, from, to).
+		Order("modified_at ASC").
+		Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
 // GetTicketStatistics returns ticket statistics for admin dashboard
 type TicketStatistics struct {
 	TotalTickets       int64
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+// Create links an identity. It returns gorm.ErrDuplicatedKey if the provider account is already linked.
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	err := r.db.WithContext(ctx).Create(identity).Error
+	if isDuplicateKeyError(err) {
+		return gorm.ErrDuplicatedKey
+	}
+	return err
+}
+
+// CreateWithUser creates a user together with their first linked identity.
+func (r *UserIdentityRepository) CreateWithUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(identity).Error
+	})
+}
+
+// FindByProviderSubject returns the identity for a provider account (gorm.ErrRecordNotFound if none).
+func (r *UserIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// FindByUser returns the user's linked identities, oldest first.
+func (r *UserIdentityRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("linked_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+// RecordLogin stores the time of a sign-in and the email the provider reported with it.
+func (r *UserIdentityRepository) RecordLogin(ctx context.Context, id uuid.UUID, email string) error {
+	return r.db.WithContext(ctx).Model(&models.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": time.Now(), "email": email}).Error
+}
+
+// CountByUser returns how many identities are linked to the user.
+func (r *UserIdentityRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Delete unlinks one of the user's identities. It returns the removed identity, or nil if the
+// user has no identity with that ID.
+func (r *UserIdentityRepository) Delete(ctx context.Context, userID, id uuid.UUID) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	res := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&identity)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return nil, res.Error
+	}
+	return &identity, nil
+}
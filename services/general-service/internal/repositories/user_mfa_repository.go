@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserMFARepository struct {
+	db *gorm.DB
+}
+
+func NewUserMFARepository(db *gorm.DB) *UserMFARepository {
+	return &UserMFARepository{db: db}
+}
+
+// FindByUser returns the user's authenticator, pending or confirmed (gorm.ErrRecordNotFound if none).
+func (r *UserMFARepository) FindByUser(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SavePending stores a new, unconfirmed authenticator, replacing a previous pending one. A
+// confirmed authenticator is never overwritten; it returns false in that case.
+func (r *UserMFARepository) SavePending(ctx context.Context, mfa *models.UserMFA) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "modified_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.confirmed_at IS NULL"}}},
+	}).Create(mfa)
+	return res.RowsAffected == 1, res.Error
+}
+
+// UseStep records the TOTP step of an accepted code. It returns false if a code for this or a
+// later step was already accepted, which callers treat as a replay.
+func (r *UserMFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+// Confirm enables a pending authenticator and replaces the user's recovery codes.
+func (r *UserMFARepository) Confirm(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error) {
+	confirmed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.UserMFA{}).
+			Where("user_id = ? AND confirmed_at IS NULL AND last_used_step < ?", userID, step).
+			Updates(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+		if res.Error != nil || res.RowsAffected != 1 {
+			return res.Error
+		}
+		confirmed = true
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	return confirmed, err
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones.
+func (r *UserMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.MFARecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.MFARecoveryCode{Id: uuid.New(), UserId: userID, CodeHash: hash}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false if there is none with that hash.
+func (r *UserMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// CountUnusedRecoveryCodes returns how many recovery codes the user has left.
+func (r *UserMFARepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Delete removes the user's authenticator and recovery codes. It returns false if there was none.
+func (r *UserMFARepository) Delete(ctx context.Context, userID uuid.UUID) (bool, error) {
+	deleted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("user_id = ?", userID).Delete(&models.UserMFA{})
+		deleted = res.RowsAffected > 0
+		return res.Error
+	})
+	return deleted, err
+}
@@ -3,19 +3,24 @@ package repositories
 import (
 	"errors"
 	"fmt"
+	role "general-service/internal/common/constants"
 	"general-service/internal/models"
+	"general-service/internal/security"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	piiIndex *security.BlindIndex
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *gorm.DB, piiIndex *security.BlindIndex) *UserRepository {
+	return &UserRepository{db: db, piiIndex: piiIndex}
 }
 
 // Create creates a new user
@@ -33,19 +38,6 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// FindByGoogleId finds a user by Google OAuth subject ID
-func (r *UserRepository) FindByGoogleId(googleId string) (*models.User, error) {
-	if googleId == "" {
-		return nil, gorm.ErrRecordNotFound
-	}
-	var user models.User
-	err := r.db.Where("google_id = ? AND is_deleted = ?", googleId, false).First(&user).Error
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
-
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(id string) (*models.User, error) {
 	var user models.User
@@ -60,6 +52,17 @@ func (r *UserRepository) UpdateUserProfile(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateEmail changes the user's email. It returns gorm.ErrDuplicatedKey if another account
+// (including a deleted one) uses the address.
+func (r *UserRepository) UpdateEmail(userID uuid.UUID, email string) error {
+	err := r.db.Model(&models.User{}).Where("id = ? AND is_deleted = ?", userID, false).
+		Update("email", email).Error
+	if isDuplicateKeyError(err) {
+		return gorm.ErrDuplicatedKey
+	}
+	return err
+}
+
 // SetVerified sets the is_verified flag for a user by ID (used after OTP verification).
 func (r *UserRepository) SetVerified(userID string, verified bool) error {
 	return r.db.Model(&models.User{}).Where("id = ? AND is_deleted = ?", userID, false).
@@ -73,7 +76,7 @@ func (r *UserRepository) Count() (int64, error) {
 	return total, err
 }
 
-// FindAll finds all users with pagination and optional search (email, first_name, last_name, fursona_name)
+// FindAll finds all users with pagination and optional search (see userSearchCondition)
 func (r *UserRepository) FindAll(page, pageSize int, search string) ([]*models.User, int64, error) {
 	// Validate pagination parameters
 	if page < 1 {
@@ -86,11 +89,7 @@ func (r *UserRepository) FindAll(page, pageSize int, search string) ([]*models.U
 	// Use a fresh session for count so no Limit/Offset from other chains can affect it
 	countDB := r.db.Session(&gorm.Session{}).Model(&models.User{}).Where("is_deleted = ?", false)
 	if search != "" {
-		pattern := "%" + search + "%"
-		countDB = countDB.Where(
-			"email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR fursona_name ILIKE ?",
-			pattern, pattern, pattern, pattern,
-		)
+		countDB = countDB.Where(userSearchCondition(r.piiIndex, "users", search))
 	}
 
 	var total int64
@@ -102,11 +101,7 @@ func (r *UserRepository) FindAll(page, pageSize int, search string) ([]*models.U
 	var users []*models.User
 	query := r.db.Session(&gorm.Session{}).Where("is_deleted = ?", false)
 	if search != "" {
-		pattern := "%" + search + "%"
-		query = query.Where(
-			"email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR fursona_name ILIKE ?",
-			pattern, pattern, pattern, pattern,
-		)
+		query = query.Where(userSearchCondition(r.piiIndex, "users", search))
 	}
 	if err := query.Order("created_at DESC").
 		Offset(offset).
@@ -118,6 +113,24 @@ func (r *UserRepository) FindAll(page, pageSize int, search string) ([]*models.U
 	return users, total, nil
 }
 
+// userSearchCondition matches users of table whose email or fursona name contains search, whose
+// first/last name words start with the words of search, or whose ID card equals search. Names
+// and ID cards are encrypted, so they are matched through their blind indexes.
+func userSearchCondition(piiIndex *security.BlindIndex, table, search string) clause.Expr {
+	pattern := "%" + search + "%"
+	sql := table + ".email ILIKE ? OR " + table + ".fursona_name ILIKE ?"
+	vars := []any{pattern, pattern}
+	if idCard := piiIndex.IDCard(search); idCard != "" {
+		sql += " OR " + table + ".id_card_index = ?"
+		vars = append(vars, idCard)
+	}
+	if tokens := piiIndex.NameSearchTokens(search); len(tokens) > 0 {
+		sql += " OR " + table + ".name_index @> ?"
+		vars = append(vars, models.BlindIndexTokens(tokens))
+	}
+	return gorm.Expr("("+sql+")", vars...)
+}
+
 // FindByIDForAdmin finds a user by ID (includes deleted users for admin)
 func (r *UserRepository) FindByIDForAdmin(id string) (*models.User, error) {
 	var user models.User
@@ -141,6 +154,16 @@ func (r *UserRepository) UpdateUser(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// FindByRole returns all non-deleted users with the given role.
+func (r *UserRepository) FindByRole(userRole role.UserRole) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.Where("role = ? AND is_deleted = ?", userRole, false).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // CountByCountryResult holds country code and count for aggregation
 type CountByCountryResult struct {
 	Country string `gorm:"column:country"`
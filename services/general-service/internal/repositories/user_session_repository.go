@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewUserSessionRepository(db *gorm.DB) *UserSessionRepository {
+	return &UserSessionRepository{db: db}
+}
+
+func (r *UserSessionRepository) Create(ctx context.Context, session *models.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// FindByID returns a session, including revoked and expired ones.
+func (r *UserSessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUser returns the user's unrevoked, unexpired sessions, most recently used first.
+func (r *UserSessionRepository) FindActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records a new access token for the session and extends its expiry.
+func (r *UserSessionRepository) Touch(ctx context.Context, id uuid.UUID, jti, userAgent, ipAddress string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"current_jti":  jti,
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// Revoke marks the given sessions revoked and returns the ones that were still active.
+func (r *UserSessionRepository) Revoke(ctx context.Context, ids []uuid.UUID, reason string) ([]models.UserSession, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var revoked []models.UserSession
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ? AND revoked_at IS NULL", ids).Find(&revoked).Error; err != nil {
+			return err
+		}
+		if len(revoked) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.UserSession{}).
+			Where("id IN ? AND revoked_at IS NULL", ids).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id IN ? AND revoked_at IS NULL", ids).
+			Update("revoked_at", time.Now()).Error
+	})
+	return revoked, err
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"general-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{db: db}
+}
+
+func (r *WebAuthnCredentialRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(credential).Error
+}
+
+// FindByCredentialID returns the passkey with the given base64url credential ID (gorm.ErrRecordNotFound if none).
+func (r *WebAuthnCredentialRepository) FindByCredentialID(ctx context.Context, credentialID string) (*models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	if err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// FindByUser returns the user's passkeys, newest first.
+func (r *WebAuthnCredentialRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+// CountByUser returns how many passkeys the user has.
+func (r *WebAuthnCredentialRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// RecordUse stores the sign counter and backup state of a successful assertion. The counter only
+// moves forward; it returns false if another assertion already stored a counter at or above
+// signCount (counters of 0 are not compared).
+func (r *WebAuthnCredentialRepository) RecordUse(ctx context.Context, id uuid.UUID, signCount int64, backedUp bool) (bool, error) {
+	q := r.db.WithContext(ctx).Model(&models.WebAuthnCredential{}).Where("id = ?", id)
+	if signCount > 0 {
+		q = q.Where("sign_count < ?", signCount)
+	}
+	res := q.Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"backed_up":    backedUp,
+		"last_used_at": time.Now(),
+	})
+	return res.RowsAffected == 1, res.Error
+}
+
+// Delete removes one of the user's passkeys. It returns false if the user has no passkey with that ID.
+func (r *WebAuthnCredentialRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebAuthnCredential{})
+	return res.RowsAffected == 1, res.Error
+}
@@ -0,0 +1,175 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keyRingPrefix marks values encrypted by AESKeyRing: "v2:<kid>:" + base64(nonce || ciphertext).
+const keyRingPrefix = "v2:"
+
+// LegacyAESKeyID stands for the key of "v1:" values, which were written before key IDs existed.
+const LegacyAESKeyID = "legacy"
+
+var ErrAESKeyUnknown = errors.New("AES key ID unknown")
+
+var aesKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// AESKeyRing encrypts with one active key and decrypts with any key it holds, so a key can be
+// rotated by adding a new active key and re-encrypting the stored values before removing the old
+// one. The legacy key (if any) decrypts "v1:" values and is the active key when no named keys
+// are configured, in which case the ring writes "v1:" values like AESCipher.
+type AESKeyRing struct {
+	activeID string
+	ciphers  map[string]*AESCipher
+	order    []string
+}
+
+// NamedKey is a key with its ID, as listed in a "kid:key,kid:key" setting.
+type NamedKey struct {
+	ID  string
+	Key []byte
+}
+
+// ParseKeySpec parses "kid:key,kid:key" with base64 keys. envName prefixes error messages.
+func ParseKeySpec(envName, keySpec string) ([]NamedKey, error) {
+	var keys []NamedKey
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(keySpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%s: entry must be kid:key", envName)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%s: duplicate key ID %q", envName, id)
+		}
+		seen[id] = true
+		key, err := DecodeBase64Key(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", envName, id, err)
+		}
+		keys = append(keys, NamedKey{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// NewAESKeyRing creates a ring of the named keys (see ParseKeySpec) plus the optional legacy key,
+// encrypting with activeID (default: the first named key, else legacy). envName, the setting the
+// named keys come from, prefixes error messages.
+func NewAESKeyRing(envName string, keys []NamedKey, activeID string, legacyKey []byte) (*AESKeyRing, error) {
+	r := &AESKeyRing{ciphers: make(map[string]*AESCipher)}
+	for _, key := range keys {
+		if !aesKeyIDPattern.MatchString(key.ID) || key.ID == LegacyAESKeyID {
+			return nil, fmt.Errorf("%s: invalid key ID %q (letters, digits, _ and -, not %q)", envName, key.ID, LegacyAESKeyID)
+		}
+		if _, dup := r.ciphers[key.ID]; dup {
+			return nil, fmt.Errorf("%s: duplicate key ID %q", envName, key.ID)
+		}
+		c, err := NewAESCipher(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", envName, key.ID, err)
+		}
+		r.ciphers[key.ID] = c
+		r.order = append(r.order, key.ID)
+	}
+	if legacyKey != nil {
+		c, err := NewAESCipher(legacyKey)
+		if err != nil {
+			return nil, fmt.Errorf("legacy key: %w", err)
+		}
+		r.ciphers[LegacyAESKeyID] = c
+		r.order = append(r.order, LegacyAESKeyID)
+	}
+	if len(r.order) == 0 {
+		return nil, fmt.Errorf("%s is not set", envName)
+	}
+
+	r.activeID = strings.TrimSpace(activeID)
+	if r.activeID == "" {
+		r.activeID = r.order[0]
+	}
+	if _, ok := r.ciphers[r.activeID]; !ok {
+		return nil, fmt.Errorf("active key ID %q not found in %s", r.activeID, envName)
+	}
+	return r, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with.
+func (r *AESKeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+// HasKey reports whether the ring can decrypt values encrypted with key id.
+func (r *AESKeyRing) HasKey(id string) bool {
+	_, ok := r.ciphers[id]
+	return ok
+}
+
+// KeyIDs returns the IDs of all keys in the ring.
+func (r *AESKeyRing) KeyIDs() []string {
+	return append([]string(nil), r.order...)
+}
+
+// EncryptString encrypts plaintext with the active key. Empty strings stay empty.
+func (r *AESKeyRing) EncryptString(plaintext string) (string, error) {
+	c := r.ciphers[r.activeID]
+	if plaintext == "" || r.activeID == LegacyAESKeyID {
+		return c.EncryptString(plaintext)
+	}
+	sealed, err := c.seal(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return keyRingPrefix + r.activeID + ":" + sealed, nil
+}
+
+// DecryptString decrypts a value written by any key of the ring. Values that don't look encrypted
+// are returned as-is, like AESCipher.DecryptString.
+func (r *AESKeyRing) DecryptString(value string) (string, error) {
+	id, ok := EncryptionKeyID(value)
+	if !ok {
+		return value, nil
+	}
+	c, found := r.ciphers[id]
+	if !found {
+		return "", fmt.Errorf("%w: %q", ErrAESKeyUnknown, id)
+	}
+	if id == LegacyAESKeyID {
+		return c.DecryptString(value)
+	}
+	return c.open(strings.TrimPrefix(value, keyRingPrefix+id+":"))
+}
+
+// IsCurrent reports whether value needs no re-encryption: it is empty, plaintext, or encrypted
+// with the active key.
+func (r *AESKeyRing) IsCurrent(value string) bool {
+	id, ok := EncryptionKeyID(value)
+	return !ok || id == r.activeID
+}
+
+// EncryptionKeyID returns the ID of the key value was encrypted with (LegacyAESKeyID for "v1:"
+// values), or false if value is not encrypted.
+func EncryptionKeyID(value string) (string, bool) {
+	if strings.HasPrefix(value, encryptedPrefix) {
+		return LegacyAESKeyID, true
+	}
+	if rest, ok := strings.CutPrefix(value, keyRingPrefix); ok {
+		if id, _, found := strings.Cut(rest, ":"); found && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// IsEncrypted reports whether value was written by AESCipher or AESKeyRing.
+func IsEncrypted(value string) bool {
+	_, ok := EncryptionKeyID(value)
+	return ok
+}
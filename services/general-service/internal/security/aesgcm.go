@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	// Prefix lets us rotate formats later without breaking old rows.
+	// Prefix lets us rotate formats later without breaking old rows. "v1:" values carry no key ID;
+	// AESKeyRing writes "v2:<kid>:" (see aes_key_ring.go).
 	encryptedPrefix = "v1:"
 	nonceSize       = 12
 )
@@ -66,15 +67,11 @@ func (c *AESCipher) EncryptString(plaintext string) (string, error) {
 	if plaintext == "" {
 		return "", nil
 	}
-	nonce := make([]byte, nonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+	sealed, err := c.seal(plaintext)
+	if err != nil {
 		return "", err
 	}
-	ct := c.aead.Seal(nil, nonce, []byte(plaintext), nil)
-	buf := make([]byte, 0, len(nonce)+len(ct))
-	buf = append(buf, nonce...)
-	buf = append(buf, ct...)
-	return encryptedPrefix + base64.StdEncoding.EncodeToString(buf), nil
+	return encryptedPrefix + sealed, nil
 }
 
 // DecryptString returns plaintext.
@@ -86,19 +83,42 @@ func (c *AESCipher) DecryptString(value string) (string, error) {
 	if !strings.HasPrefix(value, encryptedPrefix) {
 		return value, nil
 	}
-	rawB64 := strings.TrimPrefix(value, encryptedPrefix)
-	raw, err := base64.StdEncoding.DecodeString(rawB64)
+	return c.open(strings.TrimPrefix(value, encryptedPrefix))
+}
+
+// seal returns base64(nonce || ciphertext).
+func (c *AESCipher) seal(plaintext string) (string, error) {
+	raw, err := c.sealBytes([]byte(plaintext))
 	if err != nil {
 		return "", err
 	}
-	if len(raw) < nonceSize {
-		return "", errors.New("ciphertext too short")
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (c *AESCipher) open(rawB64 string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(rawB64)
+	if err != nil {
+		return "", err
 	}
-	nonce := raw[:nonceSize]
-	ct := raw[nonceSize:]
-	pt, err := c.aead.Open(nil, nonce, ct, nil)
+	pt, err := c.openBytes(raw)
 	if err != nil {
 		return "", err
 	}
 	return string(pt), nil
 }
+
+// sealBytes returns nonce || ciphertext.
+func (c *AESCipher) sealBytes(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *AESCipher) openBytes(raw []byte) ([]byte, error) {
+	if len(raw) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	return c.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], nil)
+}
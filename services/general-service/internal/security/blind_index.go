@@ -0,0 +1,138 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	blindIndexDomainIDCard = "id_card"
+	blindIndexDomainName   = "name"
+	// Fingerprints have one domain per kind ("fingerprint/ip", ...)
+	blindIndexDomainFingerprint = "fingerprint/"
+
+	// Name words are indexed by every prefix of at least nameIndexMinPrefix runes (shorter words
+	// only as a whole), up to nameIndexMaxRunes.
+	nameIndexMinPrefix = 2
+	nameIndexMaxRunes  = 32
+
+	// blindIndexDigestSize is the number of HMAC bytes kept (hex encoded in the column).
+	blindIndexDigestSize = 16
+)
+
+// BlindIndex computes keyed HMAC-SHA256 digests of normalised PII values, so encrypted columns can
+// be searched by equality without the database ever seeing the plaintext. Digests of different
+// kinds of values never collide (each kind has its own domain).
+type BlindIndex struct {
+	key []byte
+}
+
+func NewBlindIndex(key []byte) (*BlindIndex, error) {
+	if len(key) < 16 {
+		return nil, fmt.Errorf("invalid blind index key length %d (expected at least 16)", len(key))
+	}
+	return &BlindIndex{key: key}, nil
+}
+
+func (b *BlindIndex) digest(domain, value string) string {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(domain))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:blindIndexDigestSize])
+}
+
+// IDCard returns the exact-match index of an ID card or passport number, ignoring case, spaces,
+// dashes and dots. Empty values index as "".
+func (b *BlindIndex) IDCard(value string) string {
+	normalized := NormalizeIDCard(value)
+	if normalized == "" {
+		return ""
+	}
+	return b.digest(blindIndexDomainIDCard, normalized)
+}
+
+// Fingerprint returns the exact-match index of a sign-in fingerprint (IP address, user agent,
+// identity provider subject), ignoring case and surrounding spaces. Empty values index as "".
+func (b *BlindIndex) Fingerprint(kind, value string) string {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if normalized == "" {
+		return ""
+	}
+	return b.digest(blindIndexDomainFingerprint+kind, normalized)
+}
+
+// NameTokens returns the index of the words in names: one digest per word prefix, sorted and
+// without duplicates.
+func (b *BlindIndex) NameTokens(names ...string) []string {
+	seen := make(map[string]bool)
+	for _, word := range NameWords(strings.Join(names, " ")) {
+		runes := []rune(word)
+		for n := min(nameIndexMinPrefix, len(runes)); n <= len(runes); n++ {
+			seen[b.digest(blindIndexDomainName, string(runes[:n]))] = true
+		}
+	}
+	tokens := make([]string, 0, len(seen))
+	for token := range seen {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// NameSearchTokens returns the digests a name must have in its NameTokens to match the search
+// term: each word of the term has to start a word of the name.
+func (b *BlindIndex) NameSearchTokens(term string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, word := range NameWords(term) {
+		token := b.digest(blindIndexDomainName, word)
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// NormalizeIDCard upper-cases an ID document number and drops spaces, dashes and dots.
+func NormalizeIDCard(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '.' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, value)
+}
+
+// NameWords splits a name into lower-case words without diacritics ("Nguyễn Đức" becomes
+// ["nguyen", "duc"]), each cut to nameIndexMaxRunes.
+func NameWords(name string) []string {
+	var folded strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ' || r == 'Đ':
+			folded.WriteRune('d')
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			folded.WriteRune(unicode.ToLower(r))
+		default:
+			folded.WriteRune(' ')
+		}
+	}
+	words := strings.Fields(folded.String())
+	for i, word := range words {
+		if runes := []rune(word); len(runes) > nameIndexMaxRunes {
+			words[i] = string(runes[:nameIndexMaxRunes])
+		}
+	}
+	return words
+}
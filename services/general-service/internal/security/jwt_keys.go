@@ -0,0 +1,177 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// JWT signature algorithms (JOSE "alg" values) supported by the key ring.
+const (
+	JWTAlgEdDSA = "EdDSA"
+	JWTAlgRS256 = "RS256"
+)
+
+// minJWTRSAKeyBits is the smallest accepted RSA modulus.
+const minJWTRSAKeyBits = 2048
+
+var ErrJWTKeyUnknown = errors.New("JWT key ID unknown")
+
+// JWTSigningKey is one asymmetric token signing key. Its algorithm follows from the key type:
+// Ed25519 keys sign EdDSA, RSA keys sign RS256.
+type JWTSigningKey struct {
+	ID        string
+	Algorithm string
+	Signer    crypto.Signer
+}
+
+// PublicKey returns the verification key.
+func (k *JWTSigningKey) PublicKey() crypto.PublicKey {
+	return k.Signer.Public()
+}
+
+// JWTKeyRing holds the keys used to sign and verify JWTs. Tokens carry the ID of their key in
+// the "kid" header; other services verify them with the public keys from JWKS.
+type JWTKeyRing struct {
+	activeID string
+	keys     map[string]*JWTSigningKey
+	order    []string
+}
+
+// NewJWTKeyRingFromEnv loads keys from JWT_SIGNING_KEYS ("kid:key,kid:key", each key a base64
+// PKCS#8 private key, DER or PEM) and signs with JWT_SIGNING_KEY_ID (default: the first key).
+// It returns nil without error if JWT_SIGNING_KEYS is not set. Every listed key stays in JWKS and
+// is accepted for verification, so rotate by adding the new key, waiting for verifiers to refresh
+// JWKS, switching JWT_SIGNING_KEY_ID, then removing the old key once its tokens have expired.
+func NewJWTKeyRingFromEnv() (*JWTKeyRing, error) {
+	keySpec := os.Getenv("JWT_SIGNING_KEYS")
+	if strings.TrimSpace(keySpec) == "" {
+		return nil, nil
+	}
+	return NewJWTKeyRing(keySpec, os.Getenv("JWT_SIGNING_KEY_ID"))
+}
+
+func NewJWTKeyRing(keySpec, activeID string) (*JWTKeyRing, error) {
+	r := &JWTKeyRing{keys: make(map[string]*JWTSigningKey)}
+	for _, entry := range strings.Split(keySpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("JWT_SIGNING_KEYS: entry must be kid:key")
+		}
+		if _, dup := r.keys[id]; dup {
+			return nil, fmt.Errorf("JWT_SIGNING_KEYS: duplicate key ID %q", id)
+		}
+		key, err := parseJWTSigningKey(id, strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEYS: key %q: %w", id, err)
+		}
+		r.keys[id] = key
+		r.order = append(r.order, id)
+	}
+	if len(r.keys) == 0 {
+		return nil, errors.New("JWT_SIGNING_KEYS is not set")
+	}
+	r.activeID = strings.TrimSpace(activeID)
+	if r.activeID == "" {
+		r.activeID = r.order[0]
+	}
+	if _, ok := r.keys[r.activeID]; !ok {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_ID %q not found in JWT_SIGNING_KEYS", r.activeID)
+	}
+	return r, nil
+}
+
+func parseJWTSigningKey(id, encoded string) (*JWTSigningKey, error) {
+	der, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("not base64: %w", err)
+	}
+	if block, _ := pem.Decode(der); block != nil {
+		der = block.Bytes
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("not a PKCS#8 private key: %w", err)
+	}
+	switch key := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &JWTSigningKey{ID: id, Algorithm: JWTAlgEdDSA, Signer: key}, nil
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minJWTRSAKeyBits {
+			return nil, fmt.Errorf("RSA key shorter than %d bits", minJWTRSAKeyBits)
+		}
+		return &JWTSigningKey{ID: id, Algorithm: JWTAlgRS256, Signer: key}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T (use Ed25519 or RSA)", parsed)
+}
+
+// Active returns the key new tokens are signed with.
+func (r *JWTKeyRing) Active() *JWTSigningKey {
+	return r.keys[r.activeID]
+}
+
+// Key returns the key with the given ID.
+func (r *JWTKeyRing) Key(kid string) (*JWTSigningKey, error) {
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrJWTKeyUnknown, kid)
+	}
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key (RFC 7517) form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // OKP
+	X         string `json:"x,omitempty"`   // OKP
+	N         string `json:"n,omitempty"`   // RSA
+	E         string `json:"e,omitempty"`   // RSA
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the ring, active key first.
+func (r *JWTKeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(r.order))}
+	ids := append([]string{r.activeID}, r.order...)
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		key := r.keys[id]
+		jwk := JWK{KeyID: id, Use: "sig", Algorithm: key.Algorithm}
+		switch pub := key.PublicKey().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
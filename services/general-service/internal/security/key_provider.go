@@ -0,0 +1,165 @@
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Data key providers selectable with DATA_KEY_PROVIDER.
+const (
+	KeyProviderEnv  = "env"  // keys in configuration are plaintext (default)
+	KeyProviderKMS  = "kms"  // keys in configuration are wrapped by an AWS KMS key
+	KeyProviderFile = "file" // keys in configuration are wrapped by a local key file (development)
+)
+
+// KeyProvider wraps and unwraps data keys with a key encryption key held elsewhere (envelope
+// encryption), so configuration only carries wrapped data keys.
+type KeyProvider interface {
+	Name() string
+	// WrapKey encrypts a data key for storage in configuration.
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key returned by WrapKey.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyProviderFromEnv returns the provider selected by DATA_KEY_PROVIDER: "env" (default),
+// "kms" (DATA_KEY_KMS_KEY_ID; LocalStack with USE_LOCALSTACK=true) or "file" (DATA_KEY_FILE, a
+// base64 AES key). Unwrapped keys are cached for DATA_KEY_CACHE_TTL (default 1h).
+func NewKeyProviderFromEnv(ctx context.Context) (KeyProvider, error) {
+	var provider KeyProvider
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("DATA_KEY_PROVIDER"))); name {
+	case "", KeyProviderEnv:
+		return plaintextKeyProvider{}, nil
+	case KeyProviderKMS:
+		p, err := NewKMSKeyProvider(ctx, os.Getenv("DATA_KEY_KMS_KEY_ID"))
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	case KeyProviderFile:
+		p, err := NewFileKeyProvider(os.Getenv("DATA_KEY_FILE"))
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	default:
+		return nil, fmt.Errorf("DATA_KEY_PROVIDER %q is not one of env, kms, file", name)
+	}
+
+	ttl := time.Hour
+	if value := strings.TrimSpace(os.Getenv("DATA_KEY_CACHE_TTL")); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("DATA_KEY_CACHE_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+	return NewCachingKeyProvider(provider, ttl), nil
+}
+
+// plaintextKeyProvider is used when configuration holds the data keys themselves.
+type plaintextKeyProvider struct{}
+
+func (plaintextKeyProvider) Name() string {
+	return KeyProviderEnv
+}
+
+func (plaintextKeyProvider) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	return dataKey, nil
+}
+
+func (plaintextKeyProvider) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	return wrapped, nil
+}
+
+// FileKeyProvider wraps data keys with AES-GCM under a key read from a local file. It keeps
+// development setups free of plaintext data keys without needing KMS.
+type FileKeyProvider struct {
+	cipher *AESCipher
+}
+
+func NewFileKeyProvider(path string) (*FileKeyProvider, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("DATA_KEY_FILE is not set")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("DATA_KEY_FILE: %w", err)
+	}
+	key, err := DecodeBase64Key(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("DATA_KEY_FILE: %w", err)
+	}
+	c, err := NewAESCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("DATA_KEY_FILE: %w", err)
+	}
+	return &FileKeyProvider{cipher: c}, nil
+}
+
+func (p *FileKeyProvider) Name() string {
+	return KeyProviderFile
+}
+
+func (p *FileKeyProvider) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	return p.cipher.sealBytes(dataKey)
+}
+
+func (p *FileKeyProvider) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	key, err := p.cipher.openBytes(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	return key, nil
+}
+
+// CachingKeyProvider remembers unwrapped keys for ttl, so loading the same wrapped key again
+// (e.g. from several migrations at startup) does not call the underlying provider.
+type CachingKeyProvider struct {
+	provider KeyProvider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedDataKey
+}
+
+type cachedDataKey struct {
+	key     []byte
+	expires time.Time
+}
+
+func NewCachingKeyProvider(provider KeyProvider, ttl time.Duration) *CachingKeyProvider {
+	return &CachingKeyProvider{provider: provider, ttl: ttl, entries: make(map[[sha256.Size]byte]cachedDataKey)}
+}
+
+func (p *CachingKeyProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *CachingKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	return p.provider.WrapKey(ctx, dataKey)
+}
+
+func (p *CachingKeyProvider) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	id := sha256.Sum256(wrapped)
+	p.mu.Lock()
+	entry, ok := p.entries[id]
+	p.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.key, nil
+	}
+
+	key, err := p.provider.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.entries[id] = cachedDataKey{key: key, expires: time.Now().Add(p.ttl)}
+	p.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,84 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// KMSKeyProvider wraps data keys with an AWS KMS symmetric key. Unwrapping needs kms:Decrypt on
+// that key only, so the data keys are useless to anyone who copies the configuration.
+type KMSKeyProvider struct {
+	client *kms.Client
+	keyID  string
+}
+
+// NewKMSKeyProvider creates a provider for keyID (key ID, ARN or alias). With USE_LOCALSTACK=true
+// it talks to LOCALSTACK_ENDPOINT (default http://localhost:4566) with test credentials.
+func NewKMSKeyProvider(ctx context.Context, keyID string) (*KMSKeyProvider, error) {
+	keyID = strings.TrimSpace(keyID)
+	if keyID == "" {
+		return nil, fmt.Errorf("DATA_KEY_KMS_KEY_ID is not set")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "ap-southeast-1"
+	}
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if os.Getenv("USE_LOCALSTACK") == "true" {
+		accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if accessKey == "" {
+			accessKey = "test"
+		}
+		if secretKey == "" {
+			secretKey = "test"
+		}
+		localEndpoint := os.Getenv("LOCALSTACK_ENDPOINT")
+		if localEndpoint == "" {
+			localEndpoint = "http://localhost:4566"
+		}
+		opts = append(opts,
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+			config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+				return aws.Endpoint{
+					URL:           localEndpoint,
+					SigningRegion: region,
+				}, nil
+			})),
+		)
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config for KMS: %w", err)
+	}
+	return &KMSKeyProvider{client: kms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (p *KMSKeyProvider) Name() string {
+	return KeyProviderKMS
+}
+
+func (p *KMSKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	out, err := p.client.Encrypt(ctx, &kms.EncryptInput{KeyId: aws.String(p.keyID), Plaintext: dataKey})
+	if err != nil {
+		return nil, fmt.Errorf("KMS encrypt: %w", err)
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (p *KMSKeyProvider) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	// Pinning KeyId makes KMS refuse blobs wrapped by any other key.
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{KeyId: aws.String(p.keyID), CiphertextBlob: wrapped})
+	if err != nil {
+		return nil, fmt.Errorf("KMS decrypt: %w", err)
+	}
+	return out.Plaintext, nil
+}
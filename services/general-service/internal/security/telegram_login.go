@@ -0,0 +1,56 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTelegramLoginInvalid = errors.New("telegram login data invalid")
+	ErrTelegramLoginExpired = errors.New("telegram login data expired")
+)
+
+// VerifyTelegramLogin checks data sent by the Telegram Login Widget: hash must be the hex
+// HMAC-SHA256, keyed with SHA-256(botToken), of the other fields as sorted "key=value" lines, and
+// auth_date must be within maxAge of now. fields must not include hash; empty values are skipped,
+// as the widget omits them.
+func VerifyTelegramLogin(botToken string, fields map[string]string, hash string, now time.Time, maxAge time.Duration) error {
+	if botToken == "" || hash == "" {
+		return ErrTelegramLoginInvalid
+	}
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = k + "=" + fields[k]
+	}
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	got, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrTelegramLoginInvalid
+	}
+
+	authDate, err := strconv.ParseInt(fields["auth_date"], 10, 64)
+	if err != nil {
+		return ErrTelegramLoginInvalid
+	}
+	age := now.Sub(time.Unix(authDate, 0))
+	if age > maxAge || age < -time.Minute {
+		return ErrTelegramLoginExpired
+	}
+	return nil
+}
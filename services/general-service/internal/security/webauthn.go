@@ -0,0 +1,88 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// WebAuthn ceremonies are verified with go-webauthn (see services.PasskeyService); this file holds
+// the algorithms passkeys may use and the encodings the service stores.
+
+// COSE algorithm identifiers accepted for passkeys, in order of preference.
+const (
+	COSEAlgES256 = -7
+	COSEAlgEdDSA = -8
+	COSEAlgRS256 = -257
+)
+
+// SupportedCOSEAlgorithms is advertised as pubKeyCredParams in registration options.
+var SupportedCOSEAlgorithms = []int{COSEAlgES256, COSEAlgEdDSA, COSEAlgRS256}
+
+var ErrWebAuthnUnsupported = errors.New("unsupported WebAuthn credential algorithm")
+
+// DecodeWebAuthnBase64 decodes the base64url values browsers send; padding is optional.
+func DecodeWebAuthnBase64(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// EncodeWebAuthnBase64 encodes bytes as unpadded base64url.
+func EncodeWebAuthnBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// COSEKeyAlgorithm returns the COSE algorithm of a COSE_Key.
+func COSEKeyAlgorithm(coseKey []byte) (int, error) {
+	var key webauthncose.PublicKeyData
+	if err := webauthncbor.Unmarshal(coseKey, &key); err != nil {
+		return 0, fmt.Errorf("invalid COSE key: %w", err)
+	}
+	return int(key.Algorithm), nil
+}
+
+// COSEKeyFromPKIX converts a PKIX DER public key, as passkeys were stored before they were
+// verified with go-webauthn, into the COSE_Key for alg.
+func COSEKeyFromPKIX(der []byte, alg int) ([]byte, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	// COSE_Key labels: 1 kty, 3 alg; -1 crv / n, -2 x / e, -3 y.
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if alg != COSEAlgES256 || key.Curve != elliptic.P256() {
+			return nil, ErrWebAuthnUnsupported
+		}
+		return webauthncbor.Marshal(map[int]any{
+			1: int(webauthncose.EllipticKey), 3: alg, -1: int(webauthncose.P256),
+			-2: key.X.FillBytes(make([]byte, 32)), -3: key.Y.FillBytes(make([]byte, 32)),
+		})
+	case ed25519.PublicKey:
+		if alg != COSEAlgEdDSA {
+			return nil, ErrWebAuthnUnsupported
+		}
+		return webauthncbor.Marshal(map[int]any{
+			1: int(webauthncose.OctetKey), 3: alg, -1: int(webauthncose.Ed25519), -2: []byte(key),
+		})
+	case *rsa.PublicKey:
+		if alg != COSEAlgRS256 {
+			return nil, ErrWebAuthnUnsupported
+		}
+		return webauthncbor.Marshal(map[int]any{
+			1: int(webauthncose.RSAKey), 3: alg, -1: key.N.Bytes(), -2: big.NewInt(int64(key.E)).Bytes(),
+		})
+	}
+	return nil, ErrWebAuthnUnsupported
+}
@@ -0,0 +1,84 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// TestCOSEKeyFromPKIX checks converted keys verify signatures through go-webauthn, as passkey
+// assertions are.
+func TestCOSEKeyFromPKIX(t *testing.T) {
+	data := []byte("authenticatorData || SHA-256(clientDataJSON)")
+	digest := sha256.Sum256(data)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pub  crypto.PublicKey
+		alg  int
+		sig  []byte
+	}{
+		{"ES256", &ecKey.PublicKey, COSEAlgES256, ecSig},
+		{"EdDSA", edPub, COSEAlgEdDSA, ed25519.Sign(edKey, data)},
+		{"RS256", &rsaKey.PublicKey, COSEAlgRS256, rsaSig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, err := x509.MarshalPKIXPublicKey(tt.pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			coseKey, err := COSEKeyFromPKIX(der, tt.alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alg, err := COSEKeyAlgorithm(coseKey); err != nil || alg != tt.alg {
+				t.Errorf("COSEKeyAlgorithm = %d, %v; want %d", alg, err, tt.alg)
+			}
+			parsed, err := webauthncose.ParsePublicKey(coseKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := webauthncose.VerifySignature(parsed, data, tt.sig); !ok || err != nil {
+				t.Errorf("signature not verified with the converted key: %v", err)
+			}
+		})
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := COSEKeyFromPKIX(der, COSEAlgES256); err == nil {
+		t.Error("Ed25519 key converted as ES256")
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"general-service/internal/audit"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/common"
+	"general-service/internal/dto/user/requests"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// accountDeletionBatchSize caps the users anonymised per purge run so one run fits in the job timeout.
+const accountDeletionBatchSize = 25
+
+// AccountDeletionService handles users deleting their own account: the request starts a grace
+// period during which it can be cancelled (or held by an admin), after which the purge job
+// anonymises the user.
+type AccountDeletionService struct {
+	repos    *repositories.Repositories
+	sessions *SessionService
+	mail     *MailService
+}
+
+func NewAccountDeletionService(repos *repositories.Repositories, sessions *SessionService, mail *MailService) *AccountDeletionService {
+	return &AccountDeletionService{repos: repos, sessions: sessions, mail: mail}
+}
+
+// RequestDeletion schedules the deletion of the user's account after ACCOUNT_DELETION_GRACE_DAYS.
+// Accounts with a password must confirm it.
+func (s *AccountDeletionService) RequestDeletion(ctx context.Context, userID string, req *requests.RequestAccountDeletionRequest) (*responses.AccountDeletionResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !user.PasswordUnset {
+		if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+			return nil, constants.ErrCurrentPasswordIncorrect
+		}
+	}
+	if _, err := s.repos.Deletion.FindActiveByUser(ctx, uid); err == nil {
+		return nil, constants.ErrAccountDeletionScheduled
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	deletion := &models.AccountDeletion{
+		Id:           uuid.New(),
+		UserId:       uid,
+		Status:       models.AccountDeletionStatusPending,
+		Reason:       strings.TrimSpace(req.Reason),
+		ScheduledFor: time.Now().Add(utils.GetAccountDeletionGracePeriod()),
+	}
+	if err := s.repos.Deletion.Create(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to create account deletion: %w", err)
+	}
+
+	if s.mail != nil {
+		lang := LangFromCountry(user.Country)
+		subject, notice := accountDeletionScheduledNotice(deletion.ScheduledFor, os.Getenv("FRONTEND_URL"), lang)
+		if err := s.mail.SendNoticeEmail(ctx, os.Getenv("SES_EMAIL_IDENTITY"), user.Email, subject, notice, lang); err != nil {
+			log.Printf("[WARN] Failed to send account deletion notice to user %s: %v", user.Id, err)
+		}
+	}
+	return mappers.MapAccountDeletionToResponse(deletion), nil
+}
+
+// GetMyDeletion returns the user's most recent deletion request.
+func (s *AccountDeletionService) GetMyDeletion(ctx context.Context, userID string) (*responses.AccountDeletionResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	deletion, err := s.repos.Deletion.FindLatestByUser(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrAccountDeletionNotFound
+		}
+		return nil, err
+	}
+	return mappers.MapAccountDeletionToResponse(deletion), nil
+}
+
+// CancelDeletion cancels the user's pending (or held) deletion request.
+func (s *AccountDeletionService) CancelDeletion(ctx context.Context, userID string) (*responses.AccountDeletionResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	deletion, err := s.repos.Deletion.FindActiveByUser(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrAccountDeletionNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	deletion.Status = models.AccountDeletionStatusCancelled
+	deletion.CancelledAt = &now
+	if err := s.repos.Deletion.Save(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	return mappers.MapAccountDeletionToResponse(deletion), nil
+}
+
+// GetDeletionsForAdmin returns deletion requests, soonest first, optionally filtered by status.
+func (s *AccountDeletionService) GetDeletionsForAdmin(ctx context.Context, page, pageSize int, status string) ([]*responses.AdminAccountDeletionResponse, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	deletions, total, err := s.repos.Deletion.FindAll(ctx, page, pageSize, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*responses.AdminAccountDeletionResponse, len(deletions))
+	for i, deletion := range deletions {
+		result[i] = mappers.MapAccountDeletionToAdminResponse(deletion)
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return result, meta, nil
+}
+
+// HoldDeletion stops a pending deletion from running (e.g. during a payment dispute) until it is released.
+func (s *AccountDeletionService) HoldDeletion(ctx context.Context, deletionID string, adminID string, req *requests.HoldAccountDeletionRequest) (*responses.AdminAccountDeletionResponse, error) {
+	deletion, err := s.findForAdmin(ctx, deletionID)
+	if err != nil {
+		return nil, err
+	}
+	if deletion.Status != models.AccountDeletionStatusPending {
+		return nil, constants.ErrAccountDeletionInvalidStatus
+	}
+	heldBy, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	before := audit.Snapshot(ctx, deletion)
+	now := time.Now()
+	deletion.Status = models.AccountDeletionStatusHeld
+	deletion.HeldBy = &heldBy
+	deletion.HeldAt = &now
+	deletion.HoldReason = strings.TrimSpace(req.Reason)
+	after := audit.Snapshot(ctx, deletion)
+	if err := s.repos.Deletion.Save(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to hold account deletion: %w", err)
+	}
+	audit.Record(ctx, "users.deletions", deletion.Id.String(), before, after)
+	return mappers.MapAccountDeletionToAdminResponse(deletion), nil
+}
+
+// ReleaseDeletion puts a held deletion back to pending. If its grace period has already ended
+// the user is anonymised by the next purge run.
+func (s *AccountDeletionService) ReleaseDeletion(ctx context.Context, deletionID string) (*responses.AdminAccountDeletionResponse, error) {
+	deletion, err := s.findForAdmin(ctx, deletionID)
+	if err != nil {
+		return nil, err
+	}
+	if deletion.Status != models.AccountDeletionStatusHeld {
+		return nil, constants.ErrAccountDeletionInvalidStatus
+	}
+	before := audit.Snapshot(ctx, deletion)
+	deletion.Status = models.AccountDeletionStatusPending
+	after := audit.Snapshot(ctx, deletion)
+	if err := s.repos.Deletion.Save(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to release account deletion: %w", err)
+	}
+	audit.Record(ctx, "users.deletions", deletion.Id.String(), before, after)
+	return mappers.MapAccountDeletionToAdminResponse(deletion), nil
+}
+
+func (s *AccountDeletionService) findForAdmin(ctx context.Context, deletionID string) (*models.AccountDeletion, error) {
+	id, err := uuid.Parse(deletionID)
+	if err != nil {
+		return nil, constants.ErrAccountDeletionNotFound
+	}
+	deletion, err := s.repos.Deletion.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrAccountDeletionNotFound
+		}
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// ProcessDueDeletions anonymises the users whose deletion grace period has ended, signs them
+// out everywhere and tells them it is done. A failed user is reported and retried next run.
+func (s *AccountDeletionService) ProcessDueDeletions(ctx context.Context) (*responses.ProcessAccountDeletionsResponse, error) {
+	due, err := s.repos.Deletion.FindDue(ctx, time.Now(), accountDeletionBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	opts := repositories.AnonymiseOptions{
+		ConbookPolicy:     submissionPolicyFromEnv("ACCOUNT_DELETION_CONBOOK_POLICY"),
+		PerformancePolicy: submissionPolicyFromEnv("ACCOUNT_DELETION_PERFORMANCE_POLICY"),
+	}
+
+	result := &responses.ProcessAccountDeletionsResponse{}
+	for _, pending := range due {
+		deletion, err := s.repos.Deletion.AnonymiseUser(ctx, pending.Id, opts)
+		if err != nil {
+			if errors.Is(err, repositories.ErrAccountDeletionNotDue) {
+				continue
+			}
+			log.Printf("[ERROR] Failed to anonymise user %s (deletion %s): %v", pending.UserId, pending.Id, err)
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", pending.Id, err))
+			continue
+		}
+		result.Completed++
+
+		// Session rows are gone; this still denies every access token issued so far.
+		if _, err := s.sessions.RevokeAllForUser(ctx, deletion.UserId, uuid.Nil, SessionRevokedDeleted); err != nil {
+			log.Printf("[WARN] Failed to revoke tokens of deleted user %s: %v", deletion.UserId, err)
+		}
+		s.sendDeletionCompleted(ctx, &deletion.User)
+	}
+	return result, nil
+}
+
+// sendDeletionCompleted emails the address the user had before anonymisation, then forgets
+// that the email was sent.
+func (s *AccountDeletionService) sendDeletionCompleted(ctx context.Context, user *models.User) {
+	if s.mail == nil || user.Email == "" {
+		return
+	}
+	lang := LangFromCountry(user.Country)
+	subject, notice := accountDeletionCompletedNotice(lang)
+	if err := s.mail.SendNoticeEmail(ctx, os.Getenv("SES_EMAIL_IDENTITY"), user.Email, subject, notice, lang); err != nil {
+		log.Printf("[WARN] Failed to send account deletion confirmation for user %s: %v", user.Id, err)
+	}
+	if err := s.repos.EmailLog.DeleteByRecipient(ctx, user.Email); err != nil {
+		log.Printf("[WARN] Failed to clear email log of deleted user %s: %v", user.Id, err)
+	}
+}
+
+// submissionPolicyFromEnv reads a models.SubmissionPolicy from env (default keep_approved).
+func submissionPolicyFromEnv(env string) models.SubmissionPolicy {
+	switch policy := models.SubmissionPolicy(strings.ToLower(strings.TrimSpace(os.Getenv(env)))); policy {
+	case models.SubmissionPolicyAnonymise, models.SubmissionPolicyDelete:
+		return policy
+	default:
+		return models.SubmissionPolicyKeepApproved
+	}
+}
+
+// accountDeletionScheduledNotice confirms a deletion request and says until when it can be cancelled.
+func accountDeletionScheduledNotice(scheduledFor time.Time, frontendURL, lang string) (string, NoticeEmail) {
+	var link string
+	if frontendURL != "" {
+		link = strings.TrimRight(frontendURL, "/") + "/account/deletion"
+	}
+	when := scheduledFor.UTC().Format("2006-01-02 15:04 UTC")
+	if lang == "vi" {
+		return "Tài khoản FUVE của bạn sẽ bị xóa", NoticeEmail{
+			Title: "Yêu cầu xóa tài khoản đã được ghi nhận",
+			Paragraphs: []string{
+				fmt.Sprintf("Tài khoản của bạn sẽ bị xóa vào %s. Khi đó thông tin cá nhân của bạn sẽ bị xóa vĩnh viễn và không thể khôi phục.", when),
+				"Vé và thanh toán được giữ lại ở dạng ẩn danh cho mục đích kế toán. Nếu bạn là chủ gian hàng, quyền chủ gian hàng sẽ được chuyển cho thành viên khác.",
+				"Trước thời điểm đó bạn vẫn có thể đăng nhập và hủy yêu cầu.",
+			},
+			ActionURL:   link,
+			ActionLabel: "Hủy yêu cầu xóa",
+			Footnote:    "Nếu bạn không yêu cầu xóa tài khoản, hãy đăng nhập để hủy yêu cầu và đổi mật khẩu ngay.",
+		}
+	}
+	return "Your FUVE account is scheduled for deletion", NoticeEmail{
+		Title: "We received your account deletion request",
+		Paragraphs: []string{
+			fmt.Sprintf("Your account will be deleted on %s. Your personal details will then be erased permanently and cannot be recovered.", when),
+			"Tickets and payments are kept in anonymised form for accounting. If you own a dealer booth, ownership passes to another member of its staff.",
+			"Until then you can still sign in and cancel the request.",
+		},
+		ActionURL:   link,
+		ActionLabel: "Cancel deletion",
+		Footnote:    "If you did not request this, sign in to cancel it and change your password right away.",
+	}
+}
+
+// accountDeletionCompletedNotice confirms the user's personal data has been erased.
+func accountDeletionCompletedNotice(lang string) (string, NoticeEmail) {
+	if lang == "vi" {
+		return "Tài khoản FUVE của bạn đã được xóa", NoticeEmail{
+			Title: "Tài khoản của bạn đã được xóa",
+			Paragraphs: []string{
+				"Thông tin cá nhân của bạn đã bị xóa khỏi hệ thống FUVE. Đây là email cuối cùng chúng tôi gửi đến địa chỉ này.",
+			},
+		}
+	}
+	return "Your FUVE account has been deleted", NoticeEmail{
+		Title: "Your account has been deleted",
+		Paragraphs: []string{
+			"Your personal details have been erased from FUVE. This is the last email we will send to this address.",
+		},
+	}
+}
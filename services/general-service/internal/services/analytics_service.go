@@ -2,9 +2,15 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"general-service/internal/common/constants"
 	"general-service/internal/dto/analytics"
+	"general-service/internal/dto/common"
+	ticketresponses "general-service/internal/dto/ticket/responses"
 	"general-service/internal/dto/user/responses"
 	"general-service/internal/repositories"
+	"log"
+	"os"
 	"sync"
 )
 
@@ -12,11 +18,12 @@ import (
 type AnalyticsService struct {
 	repos  *repositories.Repositories
 	ticket *TicketService
+	mail   *MailService
 }
 
-// NewAnalyticsService creates an analytics service (depends on ticket service and repos).
-func NewAnalyticsService(repos *repositories.Repositories, ticket *TicketService) *AnalyticsService {
-	return &AnalyticsService{repos: repos, ticket: ticket}
+// NewAnalyticsService creates an analytics service (depends on ticket service, mail service and repos).
+func NewAnalyticsService(repos *repositories.Repositories, ticket *TicketService, mail *MailService) *AnalyticsService {
+	return &AnalyticsService{repos: repos, ticket: ticket, mail: mail}
 }
 
 // GetDashboard returns all dashboard analytics in one call. Independent operations run in parallel so response time is roughly the slowest of them, not the sum.
@@ -141,3 +148,77 @@ func (s *AnalyticsService) GetDashboard(ctx context.Context, timelineDays, reven
 	}
 	return out, nil
 }
+
+// SendWeeklyStatsEmail emails a summary of the last 7 days (and overall ticket totals) to every admin.
+func (s *AnalyticsService) SendWeeklyStatsEmail(ctx context.Context) (*common.EmailJobResult, error) {
+	dashboard, err := s.GetDashboard(ctx, 7, 7)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := s.repos.User.FindByRole(constants.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &common.EmailJobResult{Candidates: len(admins)}
+	fromEmail := os.Getenv("SES_EMAIL_IDENTITY")
+	if s.mail == nil || fromEmail == "" {
+		log.Printf("Weekly stats email skipped: mail not configured (%d admins)", len(admins))
+		return result, nil
+	}
+
+	for _, admin := range admins {
+		if admin.Email == "" {
+			continue
+		}
+		lang := LangFromCountry(admin.Country)
+		subject, notice := weeklyStatsNotice(dashboard, lang)
+		if err := s.mail.SendNoticeEmail(ctx, fromEmail, admin.Email, subject, notice, lang); err != nil {
+			log.Printf("Failed to send weekly stats email to admin %s: %v", admin.Id, err)
+			result.Failed++
+			continue
+		}
+		result.Sent++
+	}
+	return result, nil
+}
+
+func weeklyStatsNotice(d *analytics.DashboardResponse, lang string) (string, NoticeEmail) {
+	var soldThisWeek int64
+	for _, day := range d.SalesTimeline {
+		soldThisWeek += day.Count
+	}
+	var revenueThisWeek float64
+	if d.Revenue != nil {
+		for _, day := range d.Revenue.ByDay {
+			revenueThisWeek += day.Revenue
+		}
+	}
+	stats := d.TicketStats
+	if stats == nil {
+		stats = &ticketresponses.TicketStatisticsResponse{}
+	}
+
+	if lang == "vi" {
+		return "Báo cáo tuần FUVE", NoticeEmail{
+			Title: "Báo cáo tuần",
+			Paragraphs: []string{
+				fmt.Sprintf("Vé bán trong 7 ngày qua: %d (doanh thu %.2f).", soldThisWeek, revenueThisWeek),
+				fmt.Sprintf("Tổng số vé: %d — đã duyệt %d, chờ thanh toán %d, chờ xác nhận %d, bị từ chối %d.",
+					stats.TotalTickets, stats.ApprovedCount, stats.PendingCount, stats.SelfConfirmedCount, stats.DeniedCount),
+				fmt.Sprintf("Vé chờ quá 24 giờ: %d.", stats.PendingOver24Hours),
+				fmt.Sprintf("Người dùng: %d. Gian hàng: %d.", d.UserCount, d.DealerCount),
+			},
+		}
+	}
+	return "FUVE weekly report", NoticeEmail{
+		Title: "Weekly report",
+		Paragraphs: []string{
+			fmt.Sprintf("Tickets sold in the last 7 days: %d (revenue %.2f).", soldThisWeek, revenueThisWeek),
+			fmt.Sprintf("Total tickets: %d — %d approved, %d awaiting payment, %d awaiting confirmation, %d denied.",
+				stats.TotalTickets, stats.ApprovedCount, stats.PendingCount, stats.SelfConfirmedCount, stats.DeniedCount),
+			fmt.Sprintf("Tickets pending for over 24 hours: %d.", stats.PendingOver24Hours),
+			fmt.Sprintf("Users: %d. Dealer booths: %d.", d.UserCount, d.DealerCount),
+		},
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"general-service/internal/audit"
+	"general-service/internal/dto/audit/responses"
+	"general-service/internal/dto/common"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// auditBatchSize is how many entries the CSV export and the chain check read at a time.
+const auditBatchSize = 1000
+
+// auditRedacted replaces PII values in audit diffs; the diff still shows that the field changed.
+const auditRedacted = "[redacted]"
+
+// auditRedactedFields are the PII fields never written to the audit log, normalised (lower case,
+// no underscores) so both json tags and Go field names of untagged models match.
+var auditRedactedFields = map[string]bool{
+	"email":                     true,
+	"firstname":                 true,
+	"lastname":                  true,
+	"idcard":                    true,
+	"dateofbirth":               true,
+	"password":                  true,
+	"membersinfo":               true,
+	"representativefacebookurl": true,
+	"representativeurl":         true,
+}
+
+// auditIgnoredFields change on every write and would only add noise to diffs.
+var auditIgnoredFields = map[string]bool{
+	"modifiedat": true,
+}
+
+func normaliseAuditField(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
+
+// auditFieldChange is one changed field in an audit diff.
+type auditFieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditService writes and reads the admin audit log.
+type AuditService struct {
+	repos *repositories.Repositories
+}
+
+func NewAuditService(repos *repositories.Repositories) *AuditService {
+	return &AuditService{repos: repos}
+}
+
+// RecordAdminAction appends an audited action to the log: one entry per changed entity, or a
+// single entry for the request's target when no service reported a change.
+func (s *AuditService) RecordAdminAction(ctx context.Context, action *audit.Action) error {
+	var actorID *uuid.UUID
+	if id, err := uuid.Parse(action.ActorID); err == nil {
+		actorID = &id
+	}
+	newEntry := func(entityType, entityID, changes string) *models.AdminAuditLog {
+		return &models.AdminAuditLog{
+			ActorId:    actorID,
+			ActorRole:  action.ActorRole,
+			Action:     action.Name,
+			EntityType: entityType,
+			EntityId:   entityID,
+			Changes:    changes,
+			Status:     action.Status,
+			IpAddress:  truncate(action.IPAddress, 64),
+			UserAgent:  truncate(action.UserAgent, 500),
+			RequestId:  truncate(action.RequestID, 64),
+		}
+	}
+
+	var entries []*models.AdminAuditLog
+	for _, change := range action.Changes {
+		diff := auditDiff(change.Before, change.After)
+		changes := ""
+		if len(diff) > 0 {
+			data, err := json.Marshal(diff)
+			if err != nil {
+				return err
+			}
+			changes = string(data)
+		}
+		entries = append(entries, newEntry(change.EntityType, change.EntityID, changes))
+	}
+	if len(entries) == 0 {
+		entries = append(entries, newEntry(action.EntityType, action.EntityID, ""))
+	}
+	return s.repos.AuditLog.Append(ctx, entries)
+}
+
+// auditDiff returns the fields that differ between two JSON object snapshots, with PII redacted.
+// Nested objects are diffed field by field; an association loaded on only one side is skipped.
+func auditDiff(before, after json.RawMessage) map[string]interface{} {
+	var b, a map[string]interface{}
+	if len(before) > 0 {
+		_ = json.Unmarshal(before, &b)
+	}
+	if len(after) > 0 {
+		_ = json.Unmarshal(after, &a)
+	}
+	return diffAuditObjects(b, a, b == nil || a == nil)
+}
+
+func diffAuditObjects(before, after map[string]interface{}, wholeEntity bool) map[string]interface{} {
+	diff := make(map[string]interface{})
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	for key := range keys {
+		normalised := normaliseAuditField(key)
+		if auditIgnoredFields[normalised] {
+			continue
+		}
+		bv, av := before[key], after[key]
+		if reflect.DeepEqual(bv, av) {
+			continue
+		}
+		bm, bIsObject := bv.(map[string]interface{})
+		am, aIsObject := av.(map[string]interface{})
+		if !wholeEntity && (bIsObject || aIsObject) {
+			if bIsObject && aIsObject {
+				if nested := diffAuditObjects(bm, am, false); len(nested) > 0 {
+					diff[key] = nested
+				}
+			}
+			continue
+		}
+		diff[key] = auditFieldChange{Before: redactAuditValue(normalised, bv), After: redactAuditValue(normalised, av)}
+	}
+	return diff
+}
+
+// redactAuditValue hides the value of a PII field (and of PII fields inside objects).
+func redactAuditValue(field string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			out[k] = redactAuditValue(normaliseAuditField(k), nested)
+		}
+		return out
+	case []interface{}:
+		if auditRedactedFields[field] {
+			return auditRedacted
+		}
+		out := make([]interface{}, len(v))
+		for i, nested := range v {
+			out[i] = redactAuditValue(field, nested)
+		}
+		return out
+	case string:
+		if auditRedactedFields[field] && v != "" {
+			return auditRedacted
+		}
+		return v
+	default:
+		if auditRedactedFields[field] {
+			return auditRedacted
+		}
+		return v
+	}
+}
+
+// auditedUser loads a user for the audit log of an admin change; nil outside an audited action.
+func auditedUser(ctx context.Context, repos *repositories.Repositories, userID string) any {
+	if audit.FromContext(ctx) == nil {
+		return nil
+	}
+	user, err := repos.User.FindByIDForAdmin(userID)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// GetLogs returns audit log entries matching filter, newest first.
+func (s *AuditService) GetLogs(ctx context.Context, filter repositories.AdminAuditLogFilter, page, pageSize int) ([]*responses.AuditLogResponse, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	entries, total, err := s.repos.AuditLog.FindAll(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*responses.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		result[i] = mappers.MapAuditLogToResponse(entry)
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return result, meta, nil
+}
+
+// ExportCSV writes the entries matching filter to w as CSV, oldest first, with their hashes so
+// the export can be checked against the chain.
+func (s *AuditService) ExportCSV(ctx context.Context, filter repositories.AdminAuditLogFilter, w io.Writer) error {
+	out := csv.NewWriter(w)
+	header := []string{"seq", "created_at", "actor_id", "actor_role", "action", "entity_type", "entity_id",
+		"status", "ip_address", "user_agent", "request_id", "changes", "prev_hash", "hash"}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	var after int64
+	for {
+		entries, err := s.repos.AuditLog.FindAfter(ctx, filter, after, auditBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			actorID := ""
+			if entry.ActorId != nil {
+				actorID = entry.ActorId.String()
+			}
+			record := []string{
+				strconv.FormatInt(entry.Seq, 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				actorID,
+				strconv.Itoa(entry.ActorRole),
+				entry.Action,
+				entry.EntityType,
+				entry.EntityId,
+				strconv.Itoa(entry.Status),
+				entry.IpAddress,
+				csvSafe(entry.UserAgent),
+				entry.RequestId,
+				entry.Changes,
+				entry.PrevHash,
+				entry.Hash,
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+			after = entry.Seq
+		}
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return err
+		}
+		if len(entries) < auditBatchSize {
+			return nil
+		}
+	}
+}
+
+// csvSafe stops spreadsheet apps from running a client-supplied value as a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// VerifyChain walks the whole log in order and recomputes every hash. It reports the first entry
+// whose sequence, link to the previous entry or content does not verify.
+func (s *AuditService) VerifyChain(ctx context.Context) (*responses.AuditChainVerificationResponse, error) {
+	result := &responses.AuditChainVerificationResponse{Valid: true}
+	var previous *models.AdminAuditLog
+	for {
+		after := int64(0)
+		if previous != nil {
+			after = previous.Seq
+		}
+		entries, err := s.repos.AuditLog.FindAfter(ctx, repositories.AdminAuditLogFilter{}, after, auditBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			expectedSeq, expectedPrev := int64(1), ""
+			if previous != nil {
+				expectedSeq, expectedPrev = previous.Seq+1, previous.Hash
+			}
+			problem := ""
+			switch {
+			case entry.Seq != expectedSeq:
+				problem = fmt.Sprintf("expected entry %d, found %d (entries removed)", expectedSeq, entry.Seq)
+			case entry.PrevHash != expectedPrev:
+				problem = "prev_hash does not match the previous entry"
+			case entry.ComputeHash() != entry.Hash:
+				problem = "content does not match its hash (entry modified)"
+			}
+			if problem != "" {
+				seq := entry.Seq
+				result.Valid = false
+				result.BrokenAtSeq = &seq
+				result.Problem = problem
+				return result, nil
+			}
+			result.Checked++
+			result.HeadSeq = entry.Seq
+			result.HeadHash = entry.Hash
+			previous = entry
+		}
+		if len(entries) < auditBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -17,21 +17,28 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
-	"google.golang.org/api/idtoken"
 	"gorm.io/gorm"
 )
 
 type AuthService struct {
 	repos                 *repositories.Repositories
 	redisClient           *redis.Client
+	sessions              *SessionService
+	mfa                   *MFAService
+	passkeys              *PasskeyService
+	impersonations        *ImpersonationService
 	loginMaxFail          int
 	loginFailBlockMinutes int
 }
 
-func NewAuthService(repos *repositories.Repositories, redisClient *redis.Client, loginMaxFail int, loginFailBlockMinutes int) *AuthService {
+func NewAuthService(repos *repositories.Repositories, redisClient *redis.Client, sessions *SessionService, mfa *MFAService, passkeys *PasskeyService, impersonations *ImpersonationService, loginMaxFail int, loginFailBlockMinutes int) *AuthService {
 	return &AuthService{
 		repos:                 repos,
 		redisClient:           redisClient,
+		sessions:              sessions,
+		mfa:                   mfa,
+		passkeys:              passkeys,
+		impersonations:        impersonations,
 		loginMaxFail:          loginMaxFail,
 		loginFailBlockMinutes: loginFailBlockMinutes,
 	}
@@ -173,113 +180,10 @@ func splitName(name string) []string {
 	return parts
 }
 
-// GoogleLoginOrRegister verifies the Google ID token, finds or creates the user, and returns access token.
-// One endpoint handles both login (existing user) and register (new user created and logged in).
-func (s *AuthService) GoogleLoginOrRegister(ctx context.Context, req *requests.GoogleLoginRequest, googleClientID string) (*responses.LoginResponse, error) {
-	if googleClientID == "" {
-		return nil, fmt.Errorf("google client ID not configured")
-	}
-	payload, err := idtoken.Validate(ctx, req.Credential, googleClientID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid google token: %w", err)
-	}
-	googleSub := payload.Subject
-	email, _ := payload.Claims["email"].(string)
-	email = strings.ToLower(strings.TrimSpace(email))
-	if email == "" {
-		return nil, fmt.Errorf("google token missing email")
-	}
-	emailVerified, _ := payload.Claims["email_verified"].(bool)
+// Login authenticates a user and returns tokens. When the user has an authenticator, or their role
+// requires one, the response carries a short-lived MFA challenge instead (see MFAService).
 
-	// Find by Google ID first, then by email (to link existing account)
-	user, err := s.repos.User.FindByGoogleId(googleSub)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to find user: %w", err)
-	}
-	if user == nil {
-		user, err = s.repos.User.FindByEmail(email)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("failed to find user: %w", err)
-		}
-		if user != nil {
-			// Link Google to existing account
-			user.GoogleId = &googleSub
-			if emailVerified {
-				user.IsVerified = true
-			}
-			if err := s.repos.User.UpdateUserProfile(user); err != nil {
-				return nil, fmt.Errorf("failed to link google account: %w", err)
-			}
-		}
-	}
-	if user == nil {
-		// New user: require same body as normal register (fullName, nickname, dateOfBirth, country)
-		fullName := strings.TrimSpace(req.FullName)
-		nickname := strings.TrimSpace(req.Nickname)
-		country := strings.TrimSpace(req.Country)
-		if fullName == "" || nickname == "" || country == "" || strings.TrimSpace(req.DateOfBirth) == "" {
-			return nil, constants.ErrGoogleRegistrationDetailsRequired
-		}
-		dob, err := utils.ParseAndValidateDateOfBirth(req.DateOfBirth)
-		if err != nil {
-			return nil, err
-		}
-		idCard := strings.TrimSpace(req.IdCard)
-		firstName, lastName := parseFullName(fullName)
-		var hashedPassword string
-		if req.Password != "" && req.ConfirmPassword != "" && req.Password == req.ConfirmPassword {
-			if len(req.Password) < 6 {
-				return nil, fmt.Errorf("password must be at least 6 characters")
-			}
-			var errPwd error
-			hashedPassword, errPwd = utils.HashPassword(req.Password)
-			if errPwd != nil {
-				return nil, fmt.Errorf("failed to hash password: %w", errPwd)
-			}
-		} else {
-			if req.Password != "" || req.ConfirmPassword != "" {
-				return nil, constants.ErrPasswordMismatch
-			}
-			// No password: unguessable hash so email/password login is disabled
-			hashedPassword, _ = utils.HashPassword(uuid.New().String() + string(rune(time.Now().UnixNano()%256)))
-		}
-		newUser := &models.User{
-			Id:          uuid.New(),
-			FursonaName: nickname,
-			FirstName:   firstName,
-			LastName:    lastName,
-			Email:       email,
-			Password:    hashedPassword,
-			Country:     country,
-			IdCard:      idCard,
-			DateOfBirth: dob,
-			GoogleId:    &googleSub,
-			IsVerified:  true,
-			Role:        constants.RoleUser,
-			CreatedAt:   time.Now(),
-			ModifiedAt:  time.Now(),
-		}
-		if err := s.repos.User.Create(newUser); err != nil {
-			return nil, fmt.Errorf("failed to create user: %w", err)
-		}
-		user = newUser
-	}
-
-	// Banned users cannot log in
-	if user.IsBlacklisted {
-		return nil, constants.ErrAccountBanned
-	}
-
-	accessToken, err := utils.CreateAccessToken(user.Id, user.Email, user.FursonaName, user.Role.String())
-	if err != nil {
-		return nil, err
-	}
-	return &responses.LoginResponse{AccessToken: accessToken}, nil
-}
-
-// Login authenticates a user and returns tokens
-
-func (s *AuthService) Login(ctx context.Context, req *requests.LoginRequest) (response *responses.LoginResponse, err error) {
+func (s *AuthService) Login(ctx context.Context, req *requests.LoginRequest, client ClientInfo) (response *responses.LoginResponse, err error) {
 	// Recover from unexpected panics and convert to internal server error
 	defer func() {
 		if r := recover(); r != nil {
@@ -353,22 +257,41 @@ func (s *AuthService) Login(ctx context.Context, req *requests.LoginRequest) (re
 		}
 	}
 
-	// Create tokens (convert int role to string for JWT)
-	AccessToken, err := utils.CreateAccessToken(user.Id, user.Email, user.FursonaName, user.Role.String())
+	// Create tokens for a new session, or an MFA challenge if a second factor is needed
+	return s.mfa.BeginLogin(ctx, user, client)
+}
+
+// BeginPasskeyLogin starts a passwordless login with a passkey.
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context) (*responses.PasskeyRequestOptions, error) {
+	return s.passkeys.BeginLogin(ctx)
+}
+
+// FinishPasskeyLogin verifies the passkey assertion and starts a session. Passkeys always require
+// user verification (PIN or biometric), so no further MFA challenge is issued.
+func (s *AuthService) FinishPasskeyLogin(ctx context.Context, req *requests.PasskeyLoginRequest, client ClientInfo) (*responses.LoginResponse, error) {
+	credential, err := s.passkeys.Authenticate(ctx, &req.Credential)
 	if err != nil {
 		return nil, err
 	}
+	user, err := s.repos.User.FindByID(credential.UserId.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
 
-	// Build response
-	response = &responses.LoginResponse{
-		AccessToken: AccessToken,
+	// Banned users cannot log in
+	if user.IsBlacklisted {
+		return nil, constants.ErrAccountBanned
 	}
 
-	return response, nil
+	return s.sessions.Start(ctx, user, client)
 }
 
-// ResetPassword allows a logged-in user to change their password
-func (s *AuthService) ResetPassword(userID string, req *requests.ResetPasswordRequest) error {
+// ResetPassword allows a logged-in user to change their password. Every other session of the
+// user is signed out; currentSessionID (from the access token, may be empty) stays signed in.
+func (s *AuthService) ResetPassword(ctx context.Context, userID, currentSessionID string, req *requests.ResetPasswordRequest) error {
 	if req.NewPassword != req.ConfirmedPassword {
 		return constants.ErrPasswordMismatch
 	}
@@ -400,6 +323,42 @@ func (s *AuthService) ResetPassword(userID string, req *requests.ResetPasswordRe
 		return errors.New("failed to update password")
 	}
 
+	keep, _ := uuid.Parse(currentSessionID)
+	if _, err := s.sessions.RevokeAllForUser(ctx, user.Id, keep, SessionRevokedPasswordReset); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions after password change for user %s: %v\n", user.Id, err)
+	}
+
+	return nil
+}
+
+// SetInitialPassword sets the first password of an account created through a provider login,
+// adding email/password as a login method. Accounts that already have one use ResetPassword.
+func (s *AuthService) SetInitialPassword(ctx context.Context, userID string, req *requests.SetPasswordRequest) error {
+	if req.Password != req.ConfirmPassword {
+		return constants.ErrPasswordMismatch
+	}
+
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constants.ErrUserNotFound
+		}
+		return err
+	}
+	if !user.PasswordUnset {
+		return constants.ErrPasswordAlreadySet
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	user.Password = hashedPassword
+	user.PasswordUnset = false
+	if err := s.repos.User.UpdateUserProfile(user); err != nil {
+		return errors.New("failed to update password")
+	}
 	return nil
 }
 
@@ -571,7 +530,7 @@ func (s *AuthService) ForgotPassword(ctx context.Context, email string, mailServ
 }
 
 // ResetPasswordWithToken validates reset token and updates the user's password
-func (s *AuthService) ResetPasswordWithToken(token string, req *requests.ResetPasswordTokenRequest) error {
+func (s *AuthService) ResetPasswordWithToken(ctx context.Context, token string, req *requests.ResetPasswordTokenRequest) error {
 	if req.NewPassword != req.ConfirmedPassword {
 		return constants.ErrPasswordMismatch
 	}
@@ -596,10 +555,349 @@ func (s *AuthService) ResetPasswordWithToken(token string, req *requests.ResetPa
 	}
 
 	user.Password = hashed
+	user.PasswordUnset = false
 
 	if err := s.repos.User.UpdateUserProfile(user); err != nil {
 		return constants.ErrInternalServer
 	}
 
+	// Whoever knew the old password is signed out everywhere
+	if _, err := s.sessions.RevokeAllForUser(ctx, user.Id, uuid.Nil, SessionRevokedPasswordReset); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions after password reset for user %s: %v\n", user.Id, err)
+	}
+
+	return nil
+}
+
+// ========== Magic link login ==========
+
+// RequestMagicLink emails a single-use sign-in link to the account with this email. Like
+// ForgotPassword it does not reveal whether the account exists. Requests for an address within
+// MAGIC_LINK_COOLDOWN_SECONDS of the previous one are silently dropped, so the route limiter
+// (per IP) cannot be sidestepped to flood one inbox.
+func (s *AuthService) RequestMagicLink(ctx context.Context, email string, mailService *MailService, frontendURL, fromEmail string) error {
+	emailNorm := strings.ToLower(strings.TrimSpace(email))
+	send, err := utils.StartMagicLinkCooldown(ctx, s.redisClient, emailNorm, utils.GetMagicLinkCooldown())
+	if err != nil {
+		return fmt.Errorf("failed to check magic link cooldown: %w", err)
+	}
+	if !send {
+		return nil
+	}
+
+	user, err := s.repos.User.FindByEmail(emailNorm)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsBlacklisted {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	expiry := utils.GetMagicLinkExpiry()
+	if err := utils.StoreMagicLink(ctx, s.redisClient, token, user.Id.String(), expiry); err != nil {
+		return fmt.Errorf("failed to store magic link: %w", err)
+	}
+
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	link := strings.TrimRight(frontendURL, "/") + "/auth/magic-link?token=" + url.QueryEscape(token)
+	if mailService == nil {
+		return fmt.Errorf("mail service not available")
+	}
+	if err := mailService.SendMagicLinkEmail(ctx, fromEmail, user.Email, link, expiry, LangFromCountry(user.Country)); err != nil {
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
 	return nil
 }
+
+// LoginWithMagicLink signs in with a token from RequestMagicLink; each link works once. Opening
+// the link proves the email address, so an unverified account is verified, as after
+// VerifyOtpAndCompleteRegistration. Accounts with two-factor authentication get an MFA challenge.
+func (s *AuthService) LoginWithMagicLink(ctx context.Context, token string, client ClientInfo) (*responses.LoginResponse, error) {
+	userID, err := utils.TakeMagicLink(ctx, s.redisClient, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read magic link: %w", err)
+	}
+	if userID == "" {
+		return nil, constants.ErrInvalidMagicLink
+	}
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	if user.IsBlacklisted {
+		return nil, constants.ErrAccountBanned
+	}
+
+	if !user.IsVerified {
+		if err := s.repos.User.SetVerified(user.Id.String(), true); err != nil {
+			return nil, fmt.Errorf("failed to verify user: %w", err)
+		}
+		user.IsVerified = true
+	}
+
+	return s.mfa.BeginLogin(ctx, user, client)
+}
+
+// ========== Email change ==========
+
+// RequestEmailChange starts changing the user's email: after checking the current password, a
+// code is sent to the new address. The email only changes once ConfirmEmailChange proves it
+// receives mail.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID string, req *requests.ChangeEmailRequest, mailService *MailService, fromEmail string) error {
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constants.ErrUserNotFound
+		}
+		return err
+	}
+	if err := utils.ComparePassword(user.Password, req.CurrentPassword); err != nil {
+		return constants.ErrCurrentPasswordIncorrect
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if strings.EqualFold(newEmail, user.Email) {
+		return constants.ErrSameEmail
+	}
+	existingUser, err := s.repos.User.FindByEmail(newEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existingUser != nil {
+		return constants.ErrEmailAlreadyInUse
+	}
+
+	otp, err := utils.GenerateOtp()
+	if err != nil {
+		return fmt.Errorf("failed to generate OTP: %w", err)
+	}
+	if err := utils.StoreOTP(ctx, s.redisClient, utils.EmailChangeOTPKey(user.Id, newEmail), otp, timeConstants.GetOTPExpiryDuration()); err != nil {
+		return fmt.Errorf("failed to store OTP: %w", err)
+	}
+	if mailService == nil {
+		return fmt.Errorf("mail service not available")
+	}
+	if err := mailService.SendOtpEmail(ctx, fromEmail, newEmail, otp, LangFromCountry(user.Country)); err != nil {
+		return fmt.Errorf("failed to send OTP email: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange changes the user's email to a new address that received the code from
+// RequestEmailChange. The previous address is told about the change and gets a link to undo it
+// (RevertEmailChange); other sessions are signed out.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, userID, currentSessionID string, req *requests.ConfirmEmailChangeRequest, mailService *MailService, frontendURL, fromEmail string) error {
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constants.ErrUserNotFound
+		}
+		return err
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	valid, err := utils.VerifyAndDeleteOTP(ctx, s.redisClient, utils.EmailChangeOTPKey(user.Id, newEmail), req.Otp)
+	if err != nil {
+		return fmt.Errorf("an error occurred while verifying the OTP: %w", err)
+	}
+	if !valid {
+		return constants.ErrInvalidEmailChangeCode
+	}
+
+	oldEmail := user.Email
+	if err := s.repos.User.UpdateEmail(user.Id, newEmail); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constants.ErrEmailAlreadyInUse
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	keep, _ := uuid.Parse(currentSessionID)
+	if _, err := s.sessions.RevokeAllForUser(ctx, user.Id, keep, SessionRevokedEmailChanged); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions after email change for user %s: %v\n", user.Id, err)
+	}
+
+	if mailService == nil || oldEmail == "" {
+		return nil
+	}
+	revertToken, err := utils.CreateEmailRevertToken(user.Id, oldEmail, newEmail)
+	if err != nil {
+		fmt.Printf("[ERROR] Failed to create email revert token for user %s: %v\n", user.Id, err)
+		return nil
+	}
+	lang := LangFromCountry(user.Country)
+	subject, notice := emailChangedNotice(newEmail, revertToken, frontendURL, lang)
+	if err := mailService.SendNoticeEmail(ctx, fromEmail, oldEmail, subject, notice, lang); err != nil {
+		fmt.Printf("[ERROR] Failed to send email change notice for user %s: %v\n", user.Id, err)
+	}
+	return nil
+}
+
+// RevertEmailChange restores the previous email with the token sent to it by ConfirmEmailChange,
+// as long as the account still uses the address it was changed to. Everyone is signed out, since
+// the change may have been made by someone else.
+func (s *AuthService) RevertEmailChange(ctx context.Context, token string) error {
+	claims, err := utils.ValidateEmailRevertToken(token)
+	if err != nil {
+		return constants.ErrInvalidEmailRevertToken
+	}
+	user, err := s.repos.User.FindByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constants.ErrInvalidEmailRevertToken
+		}
+		return err
+	}
+	if !strings.EqualFold(user.Email, claims.NewEmail) {
+		// Already reverted, or changed again since
+		return constants.ErrInvalidEmailRevertToken
+	}
+
+	if err := s.repos.User.UpdateEmail(user.Id, claims.Email); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constants.ErrEmailAlreadyInUse
+		}
+		return fmt.Errorf("failed to restore email: %w", err)
+	}
+
+	if _, err := s.sessions.RevokeAllForUser(ctx, user.Id, uuid.Nil, SessionRevokedEmailChanged); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions after email revert for user %s: %v\n", user.Id, err)
+	}
+	return nil
+}
+
+// emailChangedNotice tells the previous address about an email change, with the link to undo it
+// (or the token itself when no frontend URL is configured).
+func emailChangedNotice(newEmail, revertToken, frontendURL, lang string) (string, NoticeEmail) {
+	var link string
+	if frontendURL != "" {
+		link = strings.TrimRight(frontendURL, "/") + "/account/email/revert?token=" + url.QueryEscape(revertToken)
+	}
+	validDays := int(utils.GetEmailRevertTokenExpiry().Hours() / 24)
+	if lang == "vi" {
+		n := NoticeEmail{
+			Title: "Email tài khoản đã được thay đổi",
+			Paragraphs: []string{
+				fmt.Sprintf("Email đăng nhập của tài khoản FUVE của bạn vừa được đổi thành %s. Từ nay vé và thông báo sẽ được gửi đến địa chỉ mới.", newEmail),
+				"Nếu bạn không thực hiện thay đổi này, hãy khôi phục email cũ bằng liên kết bên dưới và đổi mật khẩu ngay.",
+			},
+			ActionURL:   link,
+			ActionLabel: "Khôi phục email cũ",
+			Footnote:    fmt.Sprintf("Liên kết khôi phục có hiệu lực trong %d ngày.", validDays),
+		}
+		if link == "" {
+			n.Paragraphs = append(n.Paragraphs, "Mã khôi phục: "+revertToken)
+		}
+		return "Email tài khoản FUVE của bạn đã được thay đổi", n
+	}
+	n := NoticeEmail{
+		Title: "Your account email was changed",
+		Paragraphs: []string{
+			fmt.Sprintf("The sign-in email of your FUVE account was just changed to %s. Tickets and notifications will be sent to the new address from now on.", newEmail),
+			"If you did not make this change, restore your previous email with the link below and change your password right away.",
+		},
+		ActionURL:   link,
+		ActionLabel: "Restore previous email",
+		Footnote:    fmt.Sprintf("The restore link is valid for %d days.", validDays),
+	}
+	if link == "" {
+		n.Paragraphs = append(n.Paragraphs, "Restore token: "+revertToken)
+	}
+	return "Your FUVE account email was changed", n
+}
+
+// ========== Refresh tokens ==========
+
+// RefreshTokens rotates a refresh token: the presented token is marked used and a new access and
+// refresh token are issued for the same session. Presenting an already rotated token means it was
+// copied, so the whole session is revoked and both holders have to log in again.
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string, client ClientInfo) (*responses.LoginResponse, error) {
+	if refreshToken == "" {
+		return nil, constants.ErrInvalidRefreshToken
+	}
+	stored, err := s.repos.RefreshToken.FindByHash(ctx, utils.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, constants.ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedSession(ctx, stored)
+	}
+	rotated, err := s.repos.RefreshToken.MarkUsed(ctx, stored.Id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Lost the race against another request presenting the same token
+		return nil, s.revokeReusedSession(ctx, stored)
+	}
+
+	user, err := s.repos.User.FindByID(stored.UserId.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = s.sessions.Revoke(ctx, SessionRevokedDeleted, stored.FamilyId)
+			return nil, constants.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.IsBlacklisted {
+		_ = s.sessions.Revoke(ctx, SessionRevokedBlacklisted, stored.FamilyId)
+		return nil, constants.ErrAccountBanned
+	}
+	return s.sessions.issue(ctx, user, stored.FamilyId, client)
+}
+
+func (s *AuthService) revokeReusedSession(ctx context.Context, stored *models.RefreshToken) error {
+	fmt.Printf("[SECURITY] Refresh token reuse for user %s (session %s); revoking session\n", stored.UserId, stored.FamilyId)
+	if err := s.sessions.Revoke(ctx, SessionRevokedTokenReuse, stored.FamilyId); err != nil {
+		return err
+	}
+	return constants.ErrRefreshTokenReused
+}
+
+// Logout ends the current session, identified by the access token's session ID or, for tokens
+// issued before sessions existed, by the refresh token. Signing out with an impersonation token
+// ends the impersonation instead (its sid is the impersonation, not a session).
+func (s *AuthService) Logout(ctx context.Context, claims *utils.JWTClaims, refreshToken string) error {
+	if claims != nil && claims.Actor != nil {
+		return s.impersonations.EndForToken(ctx, claims)
+	}
+	if claims != nil && claims.SessionID != "" {
+		if sid, err := uuid.Parse(claims.SessionID); err == nil {
+			return s.sessions.Revoke(ctx, SessionRevokedLogout, sid)
+		}
+	}
+	if claims != nil {
+		if err := utils.DenyAccessToken(ctx, s.redisClient, claims.ID); err != nil {
+			return err
+		}
+	}
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.repos.RefreshToken.FindByHash(ctx, utils.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.sessions.Revoke(ctx, SessionRevokedLogout, stored.FamilyId)
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"general-service/internal/common/utils"
+	"general-service/internal/middlewares"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+func TestLogoutWithImpersonationTokenEndsImpersonation(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-for-impersonation-logout")
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FindByID preloads the admin and user; an empty users table is enough.
+	for _, stmt := range []string{
+		"CREATE TABLE users (id TEXT PRIMARY KEY)",
+		`CREATE TABLE impersonations (id TEXT PRIMARY KEY, admin_id TEXT NOT NULL, target_user_id TEXT NOT NULL,
+			reason TEXT NOT NULL, token_id TEXT, expires_at DATETIME NOT NULL, ended_at DATETIME,
+			notified_at DATETIME, created_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	repos := repositories.NewRepositories(db, nil)
+	sessions := services.NewSessionService(repos, redisClient)
+	impersonations := services.NewImpersonationService(repos, redisClient, nil)
+	auth := services.NewAuthService(repos, redisClient, sessions, nil, nil, impersonations, 5, 15)
+	middlewares.SetTokenRevocationChecker(sessions)
+	t.Cleanup(func() { middlewares.SetTokenRevocationChecker(nil) })
+
+	ctx := context.Background()
+	adminID, userID, impersonationID := uuid.New(), uuid.New(), uuid.New()
+	token, jti, expiresAt, err := utils.CreateImpersonationToken(userID, "user@example.com", "User", "user", impersonationID.String(), adminID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Impersonation.Create(ctx, &models.Impersonation{
+		Id:           impersonationID,
+		AdminId:      adminID,
+		TargetUserId: userID,
+		Reason:       "Support case",
+		TokenId:      jti,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/me", middlewares.JWTAuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/auth/logout", middlewares.JWTAuthMiddleware(), func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		if err := auth.Logout(c.Request.Context(), claims.(*utils.JWTClaims), ""); err != nil {
+			t.Errorf("Logout: %v", err)
+		}
+		c.Status(http.StatusOK)
+	})
+	request := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := request(http.MethodGet, "/me"); code != http.StatusOK {
+		t.Fatalf("before logout: status %d, want %d", code, http.StatusOK)
+	}
+	if code := request(http.MethodPost, "/auth/logout"); code != http.StatusOK {
+		t.Fatalf("logout: status %d, want %d", code, http.StatusOK)
+	}
+	if code := request(http.MethodGet, "/me"); code != http.StatusUnauthorized {
+		t.Errorf("after logout: status %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	impersonation, err := repos.Impersonation.FindByID(ctx, impersonationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impersonation.EndedAt == nil {
+		t.Error("impersonation not marked ended")
+	}
+	if impersonation.IsActive(time.Now()) {
+		t.Error("impersonation still active after logout")
+	}
+}
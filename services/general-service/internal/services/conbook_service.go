@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"general-service/internal/audit"
 	"general-service/internal/dto/conbook/requests"
 	"general-service/internal/dto/conbook/responses"
 	"general-service/internal/mappers"
@@ -235,6 +236,7 @@ func (s *ConbookService) setConbookStatus(ctx context.Context, conbookIDStr stri
 		return nil, ErrStatusUnchanged
 	}
 
+	before := audit.Snapshot(ctx, conbook)
 	if err := s.repos.Conbook.SetConbookStatus(ctx, conbookID, status); err != nil {
 		log.Printf("Error setting conbook status: %v", err)
 		return nil, err
@@ -245,6 +247,7 @@ func (s *ConbookService) setConbookStatus(ctx context.Context, conbookIDStr stri
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "conbooks", conbookID.String(), before, updated)
 
 	response := mappers.MapConbookToResponse(updated)
 	return &response, nil
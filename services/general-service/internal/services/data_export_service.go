@@ -0,0 +1,625 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	htemplate "html/template"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+//go:embed export/*.html
+var exportHTML embed.FS
+
+var exportTemplates = htemplate.Must(htemplate.New("").Funcs(htemplate.FuncMap{
+	"datetime": func(value any) string {
+		var t time.Time
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v != nil {
+				t = *v
+			}
+		}
+		if t.IsZero() {
+			return "—"
+		}
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+}).ParseFS(exportHTML, "export/*.html"))
+
+// dataExportStaleAfter is how long a pending or processing export blocks a new request. Older
+// ones are assumed lost (e.g. the job went to the DLQ).
+const dataExportStaleAfter = time.Hour
+
+type DataExportService struct {
+	repos *repositories.Repositories
+	mail  *MailService
+}
+
+func NewDataExportService(repos *repositories.Repositories, mail *MailService) *DataExportService {
+	return &DataExportService{repos: repos, mail: mail}
+}
+
+// RequestExport records a new personal data export for the user, to be built by BuildExport.
+// Only one export can be in progress at a time.
+func (s *DataExportService) RequestExport(ctx context.Context, userID string) (*responses.DataExportResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	_, err = s.repos.DataExport.FindInProgressByUser(ctx, uid, time.Now().Add(-dataExportStaleAfter))
+	if err == nil {
+		return nil, constants.ErrDataExportInProgress
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	export := &models.DataExport{Id: uuid.New(), UserId: uid, Status: models.DataExportStatusPending}
+	if err := s.repos.DataExport.Create(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+	return mappers.MapDataExportToResponse(export), nil
+}
+
+// FailExport marks an export as failed, e.g. when its job could not be queued.
+func (s *DataExportService) FailExport(ctx context.Context, exportID uuid.UUID, reason string) error {
+	_, err := s.repos.DataExport.SetStatus(ctx, exportID, models.DataExportStatusFailed, truncateExportError(reason),
+		models.DataExportStatusPending, models.DataExportStatusProcessing)
+	return err
+}
+
+// GetLatestExport returns the user's most recent export request.
+func (s *DataExportService) GetLatestExport(ctx context.Context, userID string) (*responses.DataExportResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	export, err := s.repos.DataExport.FindLatestByUser(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	return mappers.MapDataExportToResponse(export), nil
+}
+
+// BuildExport assembles the archive of a requested export and emails the user a download link
+// valid for DATA_EXPORT_LINK_EXPIRY_HOURS. It is safe to retry: a ready export is left alone,
+// and a failed one is rebuilt with a new link.
+func (s *DataExportService) BuildExport(ctx context.Context, exportID, userID string) (*responses.DataExportResponse, error) {
+	eid, err := uuid.Parse(exportID)
+	if err != nil {
+		return nil, constants.ErrDataExportNotFound
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	export, err := s.repos.DataExport.FindByID(ctx, eid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	if export.UserId != uid {
+		return nil, constants.ErrDataExportNotFound
+	}
+	if export.Status == models.DataExportStatusReady {
+		return mappers.MapDataExportToResponse(export), nil
+	}
+	moved, err := s.repos.DataExport.SetStatus(ctx, eid, models.DataExportStatusProcessing, "",
+		models.DataExportStatusPending, models.DataExportStatusProcessing, models.DataExportStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		// Finished by a concurrent delivery of the same job
+		return mappers.MapDataExportToResponse(export), nil
+	}
+
+	if err := s.buildExport(ctx, export); err != nil {
+		if failErr := s.FailExport(ctx, eid, err.Error()); failErr != nil {
+			log.Printf("[WARN] Failed to mark data export %s as failed: %v", eid, failErr)
+		}
+		return nil, err
+	}
+	return mappers.MapDataExportToResponse(export), nil
+}
+
+func (s *DataExportService) buildExport(ctx context.Context, export *models.DataExport) error {
+	records, err := s.repos.DataExport.CollectUserData(ctx, export.UserId)
+	if err != nil {
+		return fmt.Errorf("failed to collect user data: %w", err)
+	}
+	lang := LangFromCountry(records.User.Country)
+	now := time.Now()
+	archive, err := buildDataExportArchive(newUserDataExport(records, now), lang)
+	if err != nil {
+		return err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(utils.GetDataExportLinkExpiry())
+	// Save encrypts the archive with the user PII keys (see database.RegisterUserPIIEncryption).
+	export.Archive = base64.StdEncoding.EncodeToString(archive)
+	export.ArchiveSize = int64(len(archive))
+	export.DownloadTokenHash = hashDataExportToken(token)
+	export.ExpiresAt = &expiresAt
+	export.CompletedAt = &now
+	export.Status = models.DataExportStatusReady
+	export.Error = ""
+	if err := s.repos.DataExport.Save(ctx, export); err != nil {
+		return fmt.Errorf("failed to save data export: %w", err)
+	}
+	export.Archive = ""
+
+	if s.mail == nil {
+		return fmt.Errorf("mail service not available")
+	}
+	subject, notice := dataExportReadyNotice(token, os.Getenv("FRONTEND_URL"), expiresAt, lang)
+	if err := s.mail.SendNoticeEmail(ctx, os.Getenv("SES_EMAIL_IDENTITY"), records.User.Email, subject, notice, lang); err != nil {
+		return fmt.Errorf("failed to send data export email: %w", err)
+	}
+	return nil
+}
+
+// DownloadExport returns the archive of the ready export a download link token belongs to.
+func (s *DataExportService) DownloadExport(ctx context.Context, token string) (string, []byte, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", nil, constants.ErrInvalidDataExportLink
+	}
+	export, err := s.repos.DataExport.FindReadyByTokenHash(ctx, hashDataExportToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, constants.ErrInvalidDataExportLink
+		}
+		return "", nil, err
+	}
+	archive, err := base64.StdEncoding.DecodeString(export.Archive)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode data export %s: %w", export.Id, err)
+	}
+	return "fuve-data-export-" + export.CreatedAt.UTC().Format("2006-01-02") + ".zip", archive, nil
+}
+
+func hashDataExportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncateExportError(msg string) string {
+	if len(msg) > 500 {
+		return msg[:500]
+	}
+	return msg
+}
+
+// dataExportReadyNotice tells the user their export is ready, with the download link (or the
+// token itself when no frontend URL is configured).
+func dataExportReadyNotice(token, frontendURL string, expiresAt time.Time, lang string) (string, NoticeEmail) {
+	var link string
+	if frontendURL != "" {
+		link = strings.TrimRight(frontendURL, "/") + "/account/data-export?token=" + url.QueryEscape(token)
+	}
+	expires := expiresAt.UTC().Format("2006-01-02 15:04 UTC")
+	if lang == "vi" {
+		n := NoticeEmail{
+			Title: "Bản sao dữ liệu cá nhân của bạn đã sẵn sàng",
+			Paragraphs: []string{
+				"Chúng tôi đã tổng hợp toàn bộ dữ liệu cá nhân FUVE đang lưu về bạn: hồ sơ, vé và lịch sử trạng thái, gian hàng, các bài đăng conbook, panel và tiết mục, lệnh cấm và email đã gửi.",
+				"Tệp nén gồm data.json (để máy đọc) và index.html (để bạn xem trên trình duyệt).",
+			},
+			ActionURL:   link,
+			ActionLabel: "Tải xuống dữ liệu",
+			Footnote:    fmt.Sprintf("Liên kết có hiệu lực đến %s. Nếu bạn không yêu cầu bản sao này, hãy đổi mật khẩu ngay.", expires),
+		}
+		if link == "" {
+			n.Paragraphs = append(n.Paragraphs, "Mã tải xuống: "+token)
+		}
+		return "Dữ liệu FUVE của bạn đã sẵn sàng để tải xuống", n
+	}
+	n := NoticeEmail{
+		Title: "Your personal data export is ready",
+		Paragraphs: []string{
+			"We have gathered all personal data FUVE holds about you: your profile, tickets and their status history, dealer booths, conbook, panel and talent submissions, bans and the emails we sent you.",
+			"The archive contains data.json (machine-readable) and index.html (to read in your browser).",
+		},
+		ActionURL:   link,
+		ActionLabel: "Download your data",
+		Footnote:    fmt.Sprintf("The link is valid until %s. If you did not request this export, change your password right away.", expires),
+	}
+	if link == "" {
+		n.Paragraphs = append(n.Paragraphs, "Download token: "+token)
+	}
+	return "Your FUVE data is ready to download", n
+}
+
+// ========== Archive ==========
+
+// userDataExport is data.json of the export archive. Fields are named for readers outside FUVE,
+// so they do not follow the models.
+type userDataExport struct {
+	GeneratedAt  time.Time                `json:"generated_at"`
+	Notes        []string                 `json:"notes"`
+	Profile      exportProfile            `json:"profile"`
+	LinkedLogins []exportLinkedLogin      `json:"linked_logins"`
+	Tickets      []exportTicket           `json:"tickets"`
+	DealerBooths []exportDealerMembership `json:"dealer_booths"`
+	Conbooks     []exportConbook          `json:"conbook_submissions"`
+	Panels       []exportPerformance      `json:"panel_submissions"`
+	Talents      []exportPerformance      `json:"talent_submissions"`
+	Ban          exportBan                `json:"ban"`
+	EmailsSent   []exportEmail            `json:"emails_sent"`
+}
+
+type exportProfile struct {
+	Id          uuid.UUID  `json:"id"`
+	Email       string     `json:"email"`
+	FursonaName string     `json:"fursona_name"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	IdCard      string     `json:"id_card"`
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	Country     string     `json:"country"`
+	Avatar      string     `json:"avatar,omitempty"`
+	Role        string     `json:"role"`
+	IsVerified  bool       `json:"is_verified"`
+	HasPassword bool       `json:"has_password"`
+	Status      string     `json:"status"` // active, deleted
+	CreatedAt   time.Time  `json:"created_at"`
+	ModifiedAt  time.Time  `json:"modified_at"`
+}
+
+type exportLinkedLogin struct {
+	Provider    string     `json:"provider"`
+	AccountID   string     `json:"account_id"`
+	Email       string     `json:"email,omitempty"`
+	LinkedAt    time.Time  `json:"linked_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+type exportTicket struct {
+	Id                    uuid.UUID            `json:"id"`
+	ReferenceCode         string               `json:"reference_code"`
+	PreviousReferenceCode string               `json:"previous_reference_code,omitempty"`
+	Tier                  string               `json:"tier"`
+	Status                string               `json:"status"`
+	BadgeName             string               `json:"badge_name,omitempty"`
+	BadgeImage            string               `json:"badge_image,omitempty"`
+	NamecardUrl           string               `json:"namecard_url,omitempty"`
+	IsFursuiter           bool                 `json:"is_fursuiter"`
+	IsFursuitStaff        bool                 `json:"is_fursuit_staff"`
+	IsCheckedIn           bool                 `json:"is_checked_in"`
+	DenialReason          string               `json:"denial_reason,omitempty"`
+	UpgradeDenialReason   string               `json:"upgrade_denial_reason,omitempty"`
+	Payment               *exportPayment       `json:"payment,omitempty"`
+	History               []exportTicketChange `json:"status_history"`
+	CreatedAt             time.Time            `json:"created_at"`
+	ModifiedAt            time.Time            `json:"modified_at"`
+	DeletedAt             *time.Time           `json:"deleted_at,omitempty"`
+}
+
+type exportTicketChange struct {
+	Status string     `json:"status"`
+	At     *time.Time `json:"at,omitempty"` // nil when the time was not recorded
+	Note   string     `json:"note,omitempty"`
+}
+
+type exportPayment struct {
+	Method        string `json:"method"`
+	Status        string `json:"status"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	Provider      string `json:"provider,omitempty"`
+	TransactionID string `json:"transaction_id,omitempty"`
+}
+
+type exportDealerMembership struct {
+	BoothName   string     `json:"booth_name"`
+	BoothNumber string     `json:"booth_number,omitempty"`
+	Description string     `json:"description,omitempty"`
+	IsVerified  bool       `json:"is_verified"`
+	IsOwner     bool       `json:"is_owner"`
+	JoinedAt    time.Time  `json:"joined_at"`
+	LeftAt      *time.Time `json:"left_at,omitempty"`
+}
+
+type exportConbook struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Handle      string     `json:"handle,omitempty"`
+	ImageUrl    string     `json:"image_url"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type exportPerformance struct {
+	Title             string                         `json:"title"`
+	Nickname          string                         `json:"nickname,omitempty"`
+	RepresentativeUrl string                         `json:"representative_url,omitempty"`
+	ParticipantCount  int                            `json:"participant_count"`
+	Genre             string                         `json:"genre,omitempty"`
+	Introduction      string                         `json:"introduction,omitempty"`
+	DurationMinutes   int                            `json:"duration_minutes"`
+	MaterialsUrl      string                         `json:"materials_url,omitempty"`
+	EquipmentNotes    string                         `json:"equipment_notes,omitempty"`
+	Members           []models.PerformanceMemberInfo `json:"members,omitempty"`
+	SlotLabel         string                         `json:"slot_label,omitempty"`
+	ScheduledStartAt  *time.Time                     `json:"scheduled_start_at,omitempty"`
+	Status            string                         `json:"status"`
+	CreatedAt         time.Time                      `json:"created_at"`
+	DeletedAt         *time.Time                     `json:"deleted_at,omitempty"`
+}
+
+type exportBan struct {
+	IsBanned      bool       `json:"is_banned"`
+	BannedAt      *time.Time `json:"banned_at,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	TicketDenials int        `json:"ticket_denials"`
+}
+
+type exportEmail struct {
+	Subject string    `json:"subject"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func newUserDataExport(r *repositories.UserDataRecords, now time.Time) *userDataExport {
+	u := &r.User
+	out := &userDataExport{
+		GeneratedAt: now.UTC(),
+		Notes: []string{
+			"Ticket status history is rebuilt from the times recorded on each ticket; steps whose time was not recorded have no \"at\".",
+			"emails_sent lists emails sent to your current address since sent emails were first recorded; email contents are not kept.",
+		},
+		Profile: exportProfile{
+			Id:          u.Id,
+			Email:       u.Email,
+			FursonaName: u.FursonaName,
+			FirstName:   u.FirstName,
+			LastName:    u.LastName,
+			IdCard:      u.IdCard,
+			DateOfBirth: u.DateOfBirth,
+			Country:     u.Country,
+			Avatar:      u.Avatar,
+			Role:        u.Role.String(),
+			IsVerified:  u.IsVerified,
+			HasPassword: !u.PasswordUnset,
+			Status:      "active",
+			CreatedAt:   u.CreatedAt,
+			ModifiedAt:  u.ModifiedAt,
+		},
+		Ban: exportBan{
+			IsBanned:      u.IsBlacklisted,
+			BannedAt:      u.BlacklistedAt,
+			Reason:        u.BlacklistReason,
+			TicketDenials: u.DenialCount,
+		},
+		LinkedLogins: []exportLinkedLogin{},
+		Tickets:      []exportTicket{},
+		DealerBooths: []exportDealerMembership{},
+		Conbooks:     []exportConbook{},
+		Panels:       []exportPerformance{},
+		Talents:      []exportPerformance{},
+		EmailsSent:   []exportEmail{},
+	}
+	if isUserDeleted(u) {
+		out.Profile.Status = "deleted"
+	}
+
+	for _, identity := range r.Identities {
+		out.LinkedLogins = append(out.LinkedLogins, exportLinkedLogin{
+			Provider:    identity.Provider,
+			AccountID:   identity.Subject,
+			Email:       identity.Email,
+			LinkedAt:    identity.LinkedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+	for i := range r.Tickets {
+		out.Tickets = append(out.Tickets, newExportTicket(&r.Tickets[i]))
+	}
+	for _, staff := range r.DealerStaff {
+		out.DealerBooths = append(out.DealerBooths, exportDealerMembership{
+			BoothName:   staff.Booth.BoothName,
+			BoothNumber: staff.Booth.BoothNumber,
+			Description: staff.Booth.Description,
+			IsVerified:  staff.Booth.IsVerified,
+			IsOwner:     staff.IsOwner,
+			JoinedAt:    staff.CreatedAt,
+			LeftAt:      staff.DeletedAt,
+		})
+	}
+	for _, c := range r.Conbooks {
+		out.Conbooks = append(out.Conbooks, exportConbook{
+			Title:       c.Title,
+			Description: c.Description,
+			Handle:      c.Handle,
+			ImageUrl:    c.ImageUrl,
+			Status:      string(c.ConBookArtStatus),
+			CreatedAt:   c.CreatedAt,
+			DeletedAt:   c.DeletedAt,
+		})
+	}
+	for _, p := range r.Panels {
+		out.Panels = append(out.Panels, exportPerformance{
+			Title:             p.Title,
+			Nickname:          p.Nickname,
+			RepresentativeUrl: p.RepresentativeUrl,
+			ParticipantCount:  p.ParticipantCount,
+			Genre:             p.PerformanceGenre,
+			Introduction:      p.Introduction,
+			DurationMinutes:   p.DurationMinutes,
+			MaterialsUrl:      p.MaterialsDriveUrl,
+			EquipmentNotes:    p.EquipmentNotes,
+			Members:           p.MembersInfo,
+			SlotLabel:         p.SlotLabel,
+			ScheduledStartAt:  p.ScheduledStartAt,
+			Status:            string(p.PanelStatus),
+			CreatedAt:         p.CreatedAt,
+			DeletedAt:         p.DeletedAt,
+		})
+	}
+	for _, t := range r.Talents {
+		out.Talents = append(out.Talents, exportPerformance{
+			Title:             t.Title,
+			Nickname:          t.Nickname,
+			RepresentativeUrl: t.RepresentativeUrl,
+			ParticipantCount:  t.ParticipantCount,
+			Genre:             t.PerformanceGenre,
+			Introduction:      t.Introduction,
+			DurationMinutes:   t.DurationMinutes,
+			MaterialsUrl:      t.MaterialsDriveUrl,
+			EquipmentNotes:    t.EquipmentNotes,
+			Members:           t.MembersInfo,
+			SlotLabel:         t.SlotLabel,
+			ScheduledStartAt:  t.ScheduledStartAt,
+			Status:            string(t.TalentStatus),
+			CreatedAt:         t.CreatedAt,
+			DeletedAt:         t.DeletedAt,
+		})
+	}
+	for _, e := range r.Emails {
+		out.EmailsSent = append(out.EmailsSent, exportEmail{Subject: e.Subject, SentAt: e.SentAt})
+	}
+	return out
+}
+
+func newExportTicket(t *models.UserTicket) exportTicket {
+	tier := t.Ticket.TicketName
+	if t.Ticket.TierCode != "" {
+		tier = t.Ticket.TierCode + " " + tier
+	}
+	out := exportTicket{
+		Id:                    t.Id,
+		ReferenceCode:         t.ReferenceCode,
+		PreviousReferenceCode: t.PreviousReferenceCode,
+		Tier:                  strings.TrimSpace(tier),
+		Status:                string(t.Status),
+		BadgeName:             t.ConBadgeName,
+		BadgeImage:            t.BadgeImage,
+		NamecardUrl:           t.NamecardUrl,
+		IsFursuiter:           t.IsFursuiter,
+		IsFursuitStaff:        t.IsFursuitStaff,
+		IsCheckedIn:           t.IsCheckedIn,
+		DenialReason:          t.DenialReason,
+		UpgradeDenialReason:   t.UpgradeDenialReason,
+		History:               ticketStatusHistory(t),
+		CreatedAt:             t.CreatedAt,
+		ModifiedAt:            t.ModifiedAt,
+		DeletedAt:             t.DeletedAt,
+	}
+	if t.Payment.Id != uuid.Nil {
+		out.Payment = &exportPayment{
+			Method:        t.Payment.PaymentMethod,
+			Status:        t.Payment.Status,
+			Amount:        t.Payment.InvoicedPrice.StringFixed(2),
+			Currency:      t.Payment.Currency,
+			Provider:      t.Payment.Provider,
+			TransactionID: t.Payment.GatewayTransactionId,
+		}
+	}
+	return out
+}
+
+// ticketStatusHistory rebuilds the status changes of a ticket from its recorded times. Tickets do
+// not keep a change log, so steps without a recorded time (payment confirmation, upgrade, check-in)
+// are listed without one, after the timed steps.
+func ticketStatusHistory(t *models.UserTicket) []exportTicketChange {
+	created := t.CreatedAt
+	first := string(models.TicketStatusPending)
+	if t.Status == models.TicketStatusAdminGranted {
+		first = string(models.TicketStatusAdminGranted)
+	}
+	timed := []exportTicketChange{{Status: first, At: &created}}
+	if t.ApprovedAt != nil {
+		timed = append(timed, exportTicketChange{Status: string(models.TicketStatusApproved), At: t.ApprovedAt})
+	}
+	if t.DeniedAt != nil {
+		timed = append(timed, exportTicketChange{Status: string(models.TicketStatusDenied), At: t.DeniedAt, Note: t.DenialReason})
+	}
+	if t.DeletedAt != nil {
+		timed = append(timed, exportTicketChange{Status: "cancelled", At: t.DeletedAt})
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].At.Before(*timed[j].At) })
+
+	history := timed
+	if t.Status == models.TicketStatusSelfConfirmed {
+		history = append(history, exportTicketChange{Status: string(models.TicketStatusSelfConfirmed), Note: "payment marked as sent"})
+	}
+	if t.UpgradedFromTierID != nil {
+		history = append(history, exportTicketChange{Status: "upgraded", Note: "previous reference " + t.PreviousReferenceCode})
+	}
+	if t.IsCheckedIn {
+		history = append(history, exportTicketChange{Status: "checked_in"})
+	}
+	return history
+}
+
+// buildDataExportArchive zips data.json and an index.html rendering of it in lang.
+func buildDataExportArchive(data *userDataExport, lang string) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode data export: %w", err)
+	}
+	tpl := "data_export_en.html"
+	if lang == "vi" {
+		tpl = "data_export_vi.html"
+	}
+	var page bytes.Buffer
+	if err := exportTemplates.ExecuteTemplate(&page, tpl, data); err != nil {
+		return nil, fmt.Errorf("render data export: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		body []byte
+	}{
+		{"data.json", jsonData},
+		{"index.html", page.Bytes()},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: data.GeneratedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"general-service/internal/audit"
 	"general-service/internal/common/utils"
 	"general-service/internal/dto/common"
 	"general-service/internal/dto/dealer/requests"
@@ -223,10 +224,12 @@ func (s *DealerService) VerifyDealer(ctx context.Context, boothID string, fromEm
 	}
 
 	// Verify booth with generated code
+	before := audit.Snapshot(ctx, booth)
 	verifiedBooth, err := s.repos.Dealer.VerifyBooth(boothID, boothNumber)
 	if err != nil {
 		return nil, errors.New("failed to verify dealer booth")
 	}
+	audit.Record(ctx, "dealers", boothID, before, verifiedBooth)
 
 	// Reload booth with staff information
 	boothWithStaffs, err := s.repos.Dealer.FindBoothByIDWithStaffs(boothID)
@@ -253,7 +256,7 @@ func (s *DealerService) VerifyDealer(ctx context.Context, boothID string, fromEm
 
 // DenyDealerBooth rejects a dealer registration by soft-deleting the booth and its staff records.
 // Only allowed before verification.
-func (s *DealerService) DenyDealerBooth(boothID string) (*responses.DealerBoothDetailResponse, error) {
+func (s *DealerService) DenyDealerBooth(ctx context.Context, boothID string) (*responses.DealerBoothDetailResponse, error) {
 	booth, err := s.repos.Dealer.FindBoothByID(boothID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -266,10 +269,12 @@ func (s *DealerService) DenyDealerBooth(boothID string) (*responses.DealerBoothD
 		return nil, errors.New("dealer booth is already verified")
 	}
 
+	before := audit.Snapshot(ctx, booth)
 	deniedBooth, err := s.repos.Dealer.DenyBooth(boothID)
 	if err != nil {
 		return nil, errors.New("failed to deny dealer booth")
 	}
+	audit.Record(ctx, "dealers", boothID, before, deniedBooth)
 
 	return mappers.MapDealerBoothToDetailResponse(deniedBooth), nil
 }
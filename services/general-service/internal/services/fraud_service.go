@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"general-service/internal/audit"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/common"
+	"general-service/internal/dto/user/requests"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/security"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// fraudScreeningBatchSize caps the tickets screened per screening job run.
+	fraudScreeningBatchSize = 100
+	// fraudBirthDateCandidates caps the accounts born on the same day whose names are compared.
+	fraudBirthDateCandidates = 200
+	// fraudNameSimilarity is how alike two names must be (0-1) to count with the same birth date.
+	fraudNameSimilarity = 0.8
+	// fraudMemoMinLength ignores payment memos too short to identify anyone.
+	fraudMemoMinLength = 6
+	// fraudBlacklistedBonus is added to a signal whose matched account is blacklisted.
+	fraudBlacklistedBonus = 20
+)
+
+// fraudSignalWeights is how much each kind of signal adds to the risk score. Only the strongest
+// signal of each kind counts, and the score is capped at 100.
+var fraudSignalWeights = map[string]int{
+	models.FraudSignalIDCard:      70,
+	models.FraudSignalIdentity:    60,
+	models.FraudSignalBirthName:   45,
+	models.FraudSignalPaymentMemo: 40,
+	models.FraudSignalDevice:      15,
+	models.FraudSignalIP:          10,
+}
+
+// fraudFingerprintMaxShared ignores fingerprints shared by more accounts than this, which point
+// at a shared network or a common browser rather than one person.
+var fraudFingerprintMaxShared = map[string]int{
+	models.FingerprintKindIdentity: math.MaxInt32,
+	models.FingerprintKindDevice:   3,
+	models.FingerprintKindIP:       5,
+}
+
+// FraudService screens ticket purchases for duplicate accounts, most often a blacklisted user
+// registering again with a new email. Purchases are scored from signals shared with other
+// accounts; high scores go to the admin review queue and can hold the ticket until reviewed.
+type FraudService struct {
+	repos    *repositories.Repositories
+	sessions *SessionService
+}
+
+func NewFraudService(repos *repositories.Repositories, sessions *SessionService) *FraudService {
+	return &FraudService{repos: repos, sessions: sessions}
+}
+
+// ScreenTicket scores the ticket's purchase (User preloaded), stores the score on the ticket and,
+// at FRAUD_REVIEW_SCORE or more, adds it to the review queue, holding the ticket at
+// FRAUD_HOLD_SCORE or more. It returns the assessment, or nil when the purchase was not flagged.
+func (s *FraudService) ScreenTicket(ctx context.Context, ticket *models.UserTicket) (*models.FraudAssessment, error) {
+	signals, err := s.collectSignals(ctx, &ticket.User)
+	if err != nil {
+		return nil, err
+	}
+	score := fraudScore(signals)
+
+	var assessment *models.FraudAssessment
+	hold := false
+	if score >= utils.GetFraudReviewScore() {
+		holdScore := utils.GetFraudHoldScore()
+		hold = holdScore > 0 && score >= holdScore
+		assessment = &models.FraudAssessment{
+			Id:           uuid.New(),
+			UserId:       ticket.UserId,
+			UserTicketId: &ticket.Id,
+			Score:        score,
+			Signals:      signals,
+			Status:       models.FraudReviewOpen,
+			TicketHeld:   hold,
+		}
+	}
+	if err := s.repos.Fraud.SaveScreening(ctx, ticket.Id, score, hold, assessment); err != nil {
+		return nil, fmt.Errorf("failed to save fraud screening: %w", err)
+	}
+	ticket.RiskScore = &score
+	ticket.FraudHold = ticket.FraudHold || hold
+	if assessment != nil {
+		log.Printf("[INFO] Ticket %s flagged for fraud review (score %d, held %t)", ticket.ReferenceCode, score, hold)
+	}
+	return assessment, nil
+}
+
+// ScreenPurchases is the screening job: it scores tickets bought through the queue, which were
+// not screened at purchase. A failed ticket is retried on the next run.
+func (s *FraudService) ScreenPurchases(ctx context.Context) (*responses.ScreenPurchasesResponse, error) {
+	tickets, err := s.repos.Fraud.FindUnscreenedTickets(ctx, fraudScreeningBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &responses.ScreenPurchasesResponse{}
+	for _, ticket := range tickets {
+		assessment, err := s.ScreenTicket(ctx, ticket)
+		if err != nil {
+			log.Printf("[ERROR] Failed to screen ticket %s: %v", ticket.ReferenceCode, err)
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ticket.ReferenceCode, err))
+			continue
+		}
+		result.Screened++
+		if assessment != nil {
+			result.Flagged++
+			if assessment.TicketHeld {
+				result.Held++
+			}
+		}
+	}
+	return result, nil
+}
+
+// ScreenPurchase screens one ticket unless it already has a risk score or is no longer awaiting
+// approval. Approval screens first, and the sqs-worker calls it right after a queued purchase.
+func (s *FraudService) ScreenPurchase(ctx context.Context, ticketID uuid.UUID) (*responses.ScreenPurchasesResponse, error) {
+	ticket, err := s.repos.Fraud.FindTicketForScreening(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrTicketNotFound
+		}
+		return nil, err
+	}
+	result := &responses.ScreenPurchasesResponse{}
+	if ticket.RiskScore != nil || (ticket.Status != models.TicketStatusPending && ticket.Status != models.TicketStatusSelfConfirmed) {
+		return result, nil
+	}
+	assessment, err := s.ScreenTicket(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	result.Screened = 1
+	if assessment != nil {
+		result.Flagged = 1
+		if assessment.TicketHeld {
+			result.Held = 1
+		}
+	}
+	return result, nil
+}
+
+// GetAssessments returns the review queue, highest score first, optionally only assessments with
+// status and at least minScore.
+func (s *FraudService) GetAssessments(ctx context.Context, page, pageSize int, status string, minScore int) ([]*responses.FraudAssessmentResponse, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	assessments, total, err := s.repos.Fraud.FindAssessments(ctx, page, pageSize, status, minScore)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*responses.FraudAssessmentResponse, len(assessments))
+	for i, assessment := range assessments {
+		result[i] = mappers.MapFraudAssessmentToResponse(assessment)
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return result, meta, nil
+}
+
+// GetAssessment returns one assessment of the review queue.
+func (s *FraudService) GetAssessment(ctx context.Context, assessmentID string) (*responses.FraudAssessmentResponse, error) {
+	assessment, err := s.findAssessment(ctx, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	return mappers.MapFraudAssessmentToResponse(assessment), nil
+}
+
+// ClearAssessment closes an open assessment as not a duplicate and releases the ticket's hold.
+func (s *FraudService) ClearAssessment(ctx context.Context, adminID, assessmentID string, req *requests.ClearFraudAssessmentRequest) (*responses.FraudAssessmentResponse, error) {
+	return s.review(ctx, adminID, assessmentID, models.FraudReviewCleared, req.Note, false)
+}
+
+// ConfirmAssessment closes an open assessment as a duplicate account. The ticket stays held (to be
+// denied); with Blacklist the account is banned as well.
+func (s *FraudService) ConfirmAssessment(ctx context.Context, adminID, assessmentID string, req *requests.ConfirmFraudAssessmentRequest) (*responses.FraudAssessmentResponse, error) {
+	return s.review(ctx, adminID, assessmentID, models.FraudReviewConfirmed, req.Note, req.Blacklist)
+}
+
+func (s *FraudService) review(ctx context.Context, adminID, assessmentID string, status models.FraudReviewStatus, note string, blacklist bool) (*responses.FraudAssessmentResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	assessment, err := s.findAssessment(ctx, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Status != models.FraudReviewOpen {
+		return nil, constants.ErrFraudAssessmentReviewed
+	}
+
+	before := audit.Snapshot(ctx, assessment)
+	now := time.Now()
+	assessment.Status = status
+	assessment.ReviewedBy = &adminUUID
+	assessment.ReviewedAt = &now
+	assessment.ReviewNote = strings.TrimSpace(note)
+	release := status == models.FraudReviewCleared
+	if err := s.repos.Fraud.SaveReview(ctx, assessment, release); err != nil {
+		return nil, fmt.Errorf("failed to save fraud review: %w", err)
+	}
+	if release && assessment.UserTicket != nil {
+		assessment.UserTicket.FraudHold = false
+	}
+	audit.Record(ctx, "fraud.assessments", assessment.Id.String(), before, assessment)
+
+	if blacklist && !assessment.User.IsBlacklisted {
+		reason := "Duplicate account (fraud review)"
+		if assessment.ReviewNote != "" {
+			reason += ": " + assessment.ReviewNote
+		}
+		if err := blacklistUser(ctx, s.repos, s.sessions, assessment.UserId, truncate(reason, 500)); err != nil {
+			return nil, fmt.Errorf("failed to blacklist user: %w", err)
+		}
+		assessment.User.IsBlacklisted = true
+	}
+	return mappers.MapFraudAssessmentToResponse(assessment), nil
+}
+
+func (s *FraudService) findAssessment(ctx context.Context, assessmentID string) (*models.FraudAssessment, error) {
+	id, err := uuid.Parse(assessmentID)
+	if err != nil {
+		return nil, constants.ErrFraudAssessmentNotFound
+	}
+	assessment, err := s.repos.Fraud.FindAssessmentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrFraudAssessmentNotFound
+		}
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// collectSignals finds the other accounts user looks like a duplicate of, strongest first.
+func (s *FraudService) collectSignals(ctx context.Context, user *models.User) ([]models.FraudSignal, error) {
+	var signals []models.FraudSignal
+	add := func(kind string, match repositories.FraudMatch, detail string) {
+		weight := fraudSignalWeights[kind]
+		if match.IsBlacklisted {
+			weight += fraudBlacklistedBonus
+		}
+		signals = append(signals, models.FraudSignal{
+			Kind:               kind,
+			MatchedUserId:      match.UserId,
+			MatchedBlacklisted: match.IsBlacklisted,
+			Weight:             weight,
+			Detail:             detail,
+		})
+	}
+
+	matches, err := s.repos.Fraud.FindIDCardMatches(ctx, user.Id, user.IdCardIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match ID card: %w", err)
+	}
+	for _, match := range matches {
+		add(models.FraudSignalIDCard, match, "Same ID card number")
+	}
+
+	if user.DateOfBirth != nil {
+		candidates, err := s.repos.Fraud.FindBirthDateMatches(ctx, user.Id, *user.DateOfBirth, fraudBirthDateCandidates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to match date of birth: %w", err)
+		}
+		name := security.NameWords(user.FirstName + " " + user.LastName)
+		for _, candidate := range candidates {
+			similarity := nameSimilarity(name, security.NameWords(candidate.FirstName+" "+candidate.LastName))
+			if similarity >= fraudNameSimilarity {
+				match := repositories.FraudMatch{UserId: candidate.Id, IsBlacklisted: candidate.IsBlacklisted}
+				add(models.FraudSignalBirthName, match, fmt.Sprintf("Same date of birth, name %d%% similar", int(similarity*100)))
+			}
+		}
+	}
+
+	matches, err = s.repos.Fraud.FindFingerprintMatches(ctx, user.Id, fraudFingerprintMaxShared)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match sign-in fingerprints: %w", err)
+	}
+	for _, match := range matches {
+		switch match.Kind {
+		case models.FingerprintKindIdentity:
+			add(models.FraudSignalIdentity, match, "Signed in with the same Google (or other provider) account")
+		case models.FingerprintKindDevice:
+			add(models.FraudSignalDevice, match, fmt.Sprintf("Signed in from the same device (%d accounts)", match.SharedBy))
+		case models.FingerprintKindIP:
+			add(models.FraudSignalIP, match, fmt.Sprintf("Signed in from the same IP address (%d accounts)", match.SharedBy))
+		}
+	}
+
+	matches, err = s.repos.Fraud.FindPaymentMemoMatches(ctx, user.Id, fraudMemoMinLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match payment memos: %w", err)
+	}
+	for _, match := range matches {
+		add(models.FraudSignalPaymentMemo, match, "Payment memo matches another account's payment or ticket")
+	}
+
+	sort.SliceStable(signals, func(i, j int) bool { return signals[i].Weight > signals[j].Weight })
+	return signals, nil
+}
+
+// fraudScore adds up the strongest signal of each kind, capped at 100.
+func fraudScore(signals []models.FraudSignal) int {
+	strongest := make(map[string]int)
+	for _, signal := range signals {
+		strongest[signal.Kind] = max(strongest[signal.Kind], signal.Weight)
+	}
+	score := 0
+	for _, weight := range strongest {
+		score += weight
+	}
+	return min(score, 100)
+}
+
+// nameSimilarity compares two names given as words (see security.NameWords), 0 to 1: the better
+// of the share of words in common (names written in another order) and the edit distance of the
+// whole names (typos, missing diacritics already folded).
+func nameSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	words := make(map[string]bool, len(a))
+	for _, word := range a {
+		words[word] = true
+	}
+	common, union := 0, len(words)
+	seen := make(map[string]bool, len(b))
+	for _, word := range b {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		if words[word] {
+			common++
+		} else {
+			union++
+		}
+	}
+	overlap := float64(common) / float64(union)
+
+	x, y := []rune(strings.Join(a, " ")), []rune(strings.Join(b, " "))
+	edits := float64(levenshtein(x, y)) / float64(max(len(x), len(y)))
+	return max(overlap, 1-edits)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"general-service/internal/audit"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/common"
+	"general-service/internal/dto/user/requests"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// impersonationNoticeBatchSize caps the users emailed per notice job run.
+const impersonationNoticeBatchSize = 50
+
+// ImpersonationService lets admins act as an attendee for support. The token is short-lived and
+// marked with an act claim; the middlewares log every request made with it to the admin audit log
+// and refuse sensitive actions. The user is emailed once the impersonation is over.
+type ImpersonationService struct {
+	repos       *repositories.Repositories
+	redisClient *redis.Client
+	mail        *MailService
+}
+
+func NewImpersonationService(repos *repositories.Repositories, redisClient *redis.Client, mail *MailService) *ImpersonationService {
+	return &ImpersonationService{repos: repos, redisClient: redisClient, mail: mail}
+}
+
+// Start issues an impersonation token for the user. Only attendee accounts can be impersonated,
+// and an admin can have one active impersonation at a time.
+func (s *ImpersonationService) Start(ctx context.Context, adminID string, req *requests.StartImpersonationRequest) (*responses.ImpersonationTokenResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	if targetID == adminUUID {
+		return nil, constants.ErrImpersonationNotAllowed
+	}
+	user, err := s.repos.User.FindByID(targetID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role != constants.RoleUser || user.IsDeleted {
+		return nil, constants.ErrImpersonationNotAllowed
+	}
+	now := time.Now()
+	if _, err := s.repos.Impersonation.FindActiveByAdmin(ctx, adminUUID, now); err == nil {
+		return nil, constants.ErrImpersonationActive
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	id := uuid.New()
+	token, jti, expiresAt, err := utils.CreateImpersonationToken(user.Id, user.Email, user.FursonaName, user.Role.String(), id.String(), adminUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	impersonation := &models.Impersonation{
+		Id:           id,
+		AdminId:      adminUUID,
+		TargetUserId: user.Id,
+		Reason:       strings.TrimSpace(req.Reason),
+		TokenId:      jti,
+		ExpiresAt:    expiresAt,
+	}
+	if err := s.repos.Impersonation.Create(ctx, impersonation); err != nil {
+		return nil, fmt.Errorf("failed to create impersonation: %w", err)
+	}
+	audit.Record(ctx, "users.impersonations", id.String(), nil, impersonation)
+
+	return &responses.ImpersonationTokenResponse{
+		Id:           id,
+		TargetUserId: user.Id,
+		AccessToken:  token,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// End stops an active impersonation: its token is denied and the user is emailed straight away.
+func (s *ImpersonationService) End(ctx context.Context, impersonationID string) (*responses.AdminImpersonationResponse, error) {
+	id, err := uuid.Parse(impersonationID)
+	if err != nil {
+		return nil, constants.ErrImpersonationNotFound
+	}
+	impersonation, err := s.repos.Impersonation.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrImpersonationNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !impersonation.IsActive(now) {
+		return nil, constants.ErrImpersonationEnded
+	}
+	if err := s.end(ctx, impersonation, now); err != nil {
+		return nil, err
+	}
+	return mappers.MapImpersonationToAdminResponse(impersonation), nil
+}
+
+// EndForToken ends the impersonation an impersonation token belongs to, when the admin signs out
+// with it. The token itself is denied even if the impersonation has already ended.
+func (s *ImpersonationService) EndForToken(ctx context.Context, claims *utils.JWTClaims) error {
+	if err := utils.DenyAccessToken(ctx, s.redisClient, claims.ID); err != nil {
+		return fmt.Errorf("failed to deny impersonation token: %w", err)
+	}
+	id, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil
+	}
+	impersonation, err := s.repos.Impersonation.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	now := time.Now()
+	if !impersonation.IsActive(now) || impersonation.TokenId != claims.ID {
+		return nil
+	}
+	return s.end(ctx, impersonation, now)
+}
+
+// end marks an active impersonation ended, denies its token and emails the user.
+func (s *ImpersonationService) end(ctx context.Context, impersonation *models.Impersonation, now time.Time) error {
+	before := audit.Snapshot(ctx, impersonation)
+	impersonation.EndedAt = &now
+	if err := s.repos.Impersonation.Save(ctx, impersonation); err != nil {
+		return fmt.Errorf("failed to end impersonation: %w", err)
+	}
+	audit.Record(ctx, "users.impersonations", impersonation.Id.String(), before, impersonation)
+	if err := utils.DenySessions(ctx, s.redisClient, impersonation.Id.String()); err != nil {
+		return fmt.Errorf("failed to deny impersonation token: %w", err)
+	}
+
+	if err := s.notify(ctx, impersonation); err != nil {
+		log.Printf("[WARN] Failed to notify user %s of impersonation %s: %v", impersonation.TargetUserId, impersonation.Id, err)
+	}
+	return nil
+}
+
+// GetImpersonations returns impersonations newest first, optionally only those of one user.
+func (s *ImpersonationService) GetImpersonations(ctx context.Context, page, pageSize int, userID string) ([]*responses.AdminImpersonationResponse, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	var filter *uuid.UUID
+	if userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, nil, constants.ErrInvalidUserID
+		}
+		filter = &id
+	}
+
+	impersonations, total, err := s.repos.Impersonation.FindAll(ctx, page, pageSize, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*responses.AdminImpersonationResponse, len(impersonations))
+	for i, impersonation := range impersonations {
+		result[i] = mappers.MapImpersonationToAdminResponse(impersonation)
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return result, meta, nil
+}
+
+// ProcessNotices emails the users of impersonations that expired without being ended. A failed
+// email is retried on the next run.
+func (s *ImpersonationService) ProcessNotices(ctx context.Context) (*responses.ProcessImpersonationNoticesResponse, error) {
+	due, err := s.repos.Impersonation.FindUnnotified(ctx, time.Now(), impersonationNoticeBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &responses.ProcessImpersonationNoticesResponse{}
+	for _, impersonation := range due {
+		if err := s.notify(ctx, impersonation); err != nil {
+			log.Printf("[ERROR] Failed to notify user %s of impersonation %s: %v", impersonation.TargetUserId, impersonation.Id, err)
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", impersonation.Id, err))
+			continue
+		}
+		result.Notified++
+	}
+	return result, nil
+}
+
+// notify emails the user (TargetUser preloaded) that they were impersonated, once. If sending
+// fails the impersonation is left unnotified so the notice job retries it.
+func (s *ImpersonationService) notify(ctx context.Context, impersonation *models.Impersonation) error {
+	now := time.Now()
+	claimed, err := s.repos.Impersonation.MarkNotified(ctx, impersonation.Id, now)
+	if err != nil || !claimed {
+		return err
+	}
+	impersonation.NotifiedAt = &now
+	user := &impersonation.TargetUser
+	if s.mail == nil || user.Email == "" || user.IsDeleted {
+		return nil
+	}
+
+	endedAt := impersonation.ExpiresAt
+	if impersonation.EndedAt != nil {
+		endedAt = *impersonation.EndedAt
+	}
+	lang := LangFromCountry(user.Country)
+	subject, notice := impersonationNotice(impersonation.CreatedAt, endedAt, lang)
+	if err := s.mail.SendNoticeEmail(ctx, os.Getenv("SES_EMAIL_IDENTITY"), user.Email, subject, notice, lang); err != nil {
+		impersonation.NotifiedAt = nil
+		if saveErr := s.repos.Impersonation.Save(ctx, impersonation); saveErr != nil {
+			log.Printf("[ERROR] Failed to reset notice of impersonation %s: %v", impersonation.Id, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// impersonationNotice tells a user that support staff used their account, when, and what they
+// could not do.
+func impersonationNotice(startedAt, endedAt time.Time, lang string) (string, NoticeEmail) {
+	from := startedAt.UTC().Format("2006-01-02 15:04 UTC")
+	until := endedAt.UTC().Format("15:04 UTC")
+	if lang == "vi" {
+		return "Đội hỗ trợ FUVE đã truy cập tài khoản của bạn", NoticeEmail{
+			Title: "Đội hỗ trợ đã xem tài khoản của bạn",
+			Paragraphs: []string{
+				fmt.Sprintf("Từ %s đến %s, một thành viên đội hỗ trợ FUVE đã đăng nhập vào tài khoản của bạn để xử lý yêu cầu hỗ trợ.", from, until),
+				"Họ có thể xem những gì bạn thấy, nhưng không thể đổi mật khẩu, thanh toán hay xóa tài khoản của bạn. Mọi thao tác đều được ghi lại.",
+				"Nếu bạn không liên hệ với chúng tôi để được hỗ trợ, vui lòng trả lời email này.",
+			},
+		}
+	}
+	return "FUVE support accessed your account", NoticeEmail{
+		Title: "Our support team viewed your account",
+		Paragraphs: []string{
+			fmt.Sprintf("From %s to %s, a member of the FUVE support team was signed in to your account to help with a support request.", from, until),
+			"They could see what you see, but could not change your password, make payments or delete your account. Everything they did was recorded.",
+			"If you did not ask us for help, please reply to this email.",
+		},
+	}
+}
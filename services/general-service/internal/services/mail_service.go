@@ -6,11 +6,13 @@ import (
 	"embed"
 	"encoding/base64"
 	"fmt"
+	timeConstants "general-service/internal/constants"
 	"general-service/internal/repositories"
 	htemplate "html/template"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -164,14 +166,26 @@ func (s *MailService) SendEmail(
 	bcc []string,
 ) error {
 	if s.sendgridClient != nil {
-		return s.sendEmailSendGrid(ctx, fromEmail, toEmail, subject, body, cc, bcc)
+		return s.logSent(ctx, toEmail, subject, mailProviderSendGrid, s.sendEmailSendGrid(ctx, fromEmail, toEmail, subject, body, cc, bcc))
 	}
 	if s.sesClient != nil {
-		return s.sendEmailSES(ctx, fromEmail, toEmail, subject, body, cc, bcc)
+		return s.logSent(ctx, toEmail, subject, mailProviderSES, s.sendEmailSES(ctx, fromEmail, toEmail, subject, body, cc, bcc))
 	}
 	return fmt.Errorf("no mail provider configured")
 }
 
+// logSent records a successfully sent email in the email log and returns sendErr. Failing to
+// record it does not fail the send.
+func (s *MailService) logSent(ctx context.Context, toEmail, subject, provider string, sendErr error) error {
+	if sendErr != nil || s.repos == nil {
+		return sendErr
+	}
+	if err := s.repos.EmailLog.Record(ctx, toEmail, subject, provider); err != nil {
+		log.Printf("[WARN] Failed to record sent email (to=%s subject=%s): %v", toEmail, subject, err)
+	}
+	return nil
+}
+
 func (s *MailService) sendEmailSES(
 	ctx context.Context,
 	fromEmail, toEmail, subject, body string,
@@ -369,13 +383,37 @@ func (s *MailService) SendOtpEmail(ctx context.Context, fromEmail, toEmail, otp,
 		subject = "Verify your email for FUVE"
 		tpl = "otp_en.html"
 	}
-	body, err := renderMailTemplate(tpl, struct{ Otp string }{Otp: otp})
+	body, err := renderMailTemplate(tpl, otpEmailData{Otp: otp, ExpiryMinutes: timeConstants.OTPExpiryMinutes})
 	if err != nil {
 		return fmt.Errorf("render otp email: %w", err)
 	}
 	return s.SendEmail(ctx, fromEmail, toEmail, subject, body, nil, nil)
 }
 
+// otpEmailData fills the otp_*.html templates: a verification code, or a sign-in button when Link is set.
+type otpEmailData struct {
+	Otp           string
+	Link          string
+	ExpiryMinutes int
+}
+
+// SendMagicLinkEmail sends a passwordless sign-in link, using the OTP email templates. lang: "vi" for Vietnamese, else English.
+func (s *MailService) SendMagicLinkEmail(ctx context.Context, fromEmail, toEmail, link string, expiry time.Duration, lang string) error {
+	var subject, tpl string
+	if lang == "vi" {
+		subject = "Liên kết đăng nhập FUVE của bạn"
+		tpl = "otp_vi.html"
+	} else {
+		subject = "Your FUVE sign-in link"
+		tpl = "otp_en.html"
+	}
+	body, err := renderMailTemplate(tpl, otpEmailData{Link: link, ExpiryMinutes: int(expiry.Minutes())})
+	if err != nil {
+		return fmt.Errorf("render magic link email: %w", err)
+	}
+	return s.SendEmail(ctx, fromEmail, toEmail, subject, body, nil, nil)
+}
+
 // SendDealerApprovedEmail sends an email to the dealer (booth owner) when their registration is approved. lang: "vi" for Vietnamese, else English.
 func (s *MailService) SendDealerApprovedEmail(ctx context.Context, fromEmail, toEmail, boothName, boothNumber, lang string) error {
 	var subject, tpl string
@@ -439,10 +477,10 @@ func (s *MailService) SendTicketApprovedWithQREmail(ctx context.Context, fromEma
 	}
 
 	if s.sendgridClient != nil {
-		return s.sendEmailSendGridWithInlineImage(ctx, fromEmail, toEmail, subject, htmlBody, contentID, qrPNG)
+		return s.logSent(ctx, toEmail, subject, mailProviderSendGrid, s.sendEmailSendGridWithInlineImage(ctx, fromEmail, toEmail, subject, htmlBody, contentID, qrPNG))
 	}
 	if s.sesClient != nil {
-		return s.sendEmailSESRawWithInlineImage(ctx, fromEmail, toEmail, subject, htmlBody, contentID, qrPNG)
+		return s.logSent(ctx, toEmail, subject, mailProviderSES, s.sendEmailSESRawWithInlineImage(ctx, fromEmail, toEmail, subject, htmlBody, contentID, qrPNG))
 	}
 	return fmt.Errorf("no mail provider configured")
 }
@@ -481,3 +519,38 @@ func (s *MailService) SendTicketDeniedEmail(ctx context.Context, fromEmail, toEm
 	}
 	return s.SendEmail(ctx, fromEmail, toEmail, subject, body, nil, nil)
 }
+
+// NoticeEmail is the content of a generic notice rendered with notice_{en,vi}.html. Callers pass text
+// already in the recipient's language; ActionURL is optional and shown as a button.
+type NoticeEmail struct {
+	Title       string
+	Paragraphs  []string
+	ActionURL   string
+	ActionLabel string
+	Footnote    string
+}
+
+// SendNoticeEmail renders a generic notice email. lang: "vi" for Vietnamese, else English.
+func (s *MailService) SendNoticeEmail(ctx context.Context, fromEmail, toEmail, subject string, notice NoticeEmail, lang string) error {
+	tpl := "notice_en.html"
+	if lang == "vi" {
+		tpl = "notice_vi.html"
+	}
+	body, err := renderMailTemplate(tpl, struct {
+		Title       string
+		Paragraphs  []string
+		ActionURL   htemplate.URL
+		ActionLabel string
+		Footnote    string
+	}{
+		Title:       notice.Title,
+		Paragraphs:  notice.Paragraphs,
+		ActionURL:   htemplate.URL(notice.ActionURL),
+		ActionLabel: notice.ActionLabel,
+		Footnote:    notice.Footnote,
+	})
+	if err != nil {
+		return fmt.Errorf("render notice email: %w", err)
+	}
+	return s.SendEmail(ctx, fromEmail, toEmail, subject, body, nil, nil)
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	authrequests "general-service/internal/dto/auth/requests"
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/security"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// Second-factor methods listed in MFA challenges
+const (
+	MFAMethodTOTP    = "totp"
+	MFAMethodPasskey = "passkey"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O, 1/I
+	mfaFailedKeyPrefix   = "mfa:"                             // shares the login failure counter, keyed by user ID
+)
+
+// MFAService handles TOTP two-factor authentication: enrolment, the second login step and
+// recovery codes. Roles in requiredRoles must set up an authenticator before they can sign in.
+type MFAService struct {
+	repos                 *repositories.Repositories
+	redisClient           *redis.Client
+	sessions              *SessionService
+	passkeys              *PasskeyService
+	requiredRoles         map[constants.UserRole]bool
+	loginMaxFail          int
+	loginFailBlockMinutes int
+}
+
+func NewMFAService(repos *repositories.Repositories, redisClient *redis.Client, sessions *SessionService, passkeys *PasskeyService, requiredRoles []constants.UserRole, loginMaxFail int, loginFailBlockMinutes int) *MFAService {
+	required := make(map[constants.UserRole]bool, len(requiredRoles))
+	for _, role := range requiredRoles {
+		required[role] = true
+	}
+	return &MFAService{
+		repos:                 repos,
+		redisClient:           redisClient,
+		sessions:              sessions,
+		passkeys:              passkeys,
+		requiredRoles:         required,
+		loginMaxFail:          loginMaxFail,
+		loginFailBlockMinutes: loginFailBlockMinutes,
+	}
+}
+
+// IsRequired reports whether role must use two-factor authentication.
+func (s *MFAService) IsRequired(role constants.UserRole) bool {
+	return s.requiredRoles[role]
+}
+
+// ========== Login ==========
+
+// BeginLogin is called once the user's first factor (password or Google) checks out. Without an
+// authenticator or passkey it starts the session right away; otherwise it returns an MFA challenge.
+func (s *MFAService) BeginLogin(ctx context.Context, user *models.User, client ClientInfo) (*responses.LoginResponse, error) {
+	methods, err := s.methods(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	tokenType := ""
+	switch {
+	case len(methods) > 0:
+		tokenType = utils.MFAChallengeTokenType
+	case s.IsRequired(user.Role):
+		tokenType = utils.MFAEnrollmentTokenType
+	default:
+		return s.sessions.Start(ctx, user, client)
+	}
+
+	token, err := utils.CreateMFAChallengeToken(user.Id, user.Email, user.FursonaName, user.Role.String(), tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return &responses.LoginResponse{MFA: &responses.MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: tokenType == utils.MFAEnrollmentTokenType,
+		Methods:            methods,
+		MFAToken:           token,
+		ExpiresIn:          int(utils.GetMFAChallengeTokenExpiry() / time.Second),
+	}}, nil
+}
+
+// methods lists the second factors the user can answer a challenge with.
+func (s *MFAService) methods(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	methods := []string{}
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.ConfirmedAt != nil {
+		methods = append(methods, MFAMethodTOTP)
+	}
+	hasPasskeys, err := s.passkeys.HasPasskeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load passkeys: %w", err)
+	}
+	if hasPasskeys {
+		methods = append(methods, MFAMethodPasskey)
+	}
+	return methods, nil
+}
+
+// CompleteLogin checks the TOTP code or recovery code for an MFA challenge and starts the session.
+func (s *MFAService) CompleteLogin(ctx context.Context, mfaToken, code, recoveryCode string, client ClientInfo) (*responses.LoginResponse, error) {
+	user, err := s.userFromChallenge(mfaToken, utils.MFAChallengeTokenType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNotBlocked(ctx, user.Id); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code != "":
+		err = s.verifyCode(ctx, user.Id, code)
+	case recoveryCode != "":
+		err = s.useRecoveryCode(ctx, user.Id, recoveryCode)
+	default:
+		err = constants.ErrInvalidMFACode
+	}
+	if err != nil {
+		return nil, s.recordFailure(ctx, user.Id, err)
+	}
+	s.resetFailures(ctx, user.Id)
+	return s.sessions.Start(ctx, user, client)
+}
+
+// BeginPasskeyChallenge returns passkey request options for answering an MFA challenge.
+func (s *MFAService) BeginPasskeyChallenge(ctx context.Context, mfaToken string) (*responses.PasskeyRequestOptions, error) {
+	user, err := s.userFromChallenge(mfaToken, utils.MFAChallengeTokenType)
+	if err != nil {
+		return nil, err
+	}
+	return s.passkeys.BeginSecondFactor(ctx, user.Id)
+}
+
+// CompletePasskeyChallenge verifies a passkey assertion for an MFA challenge and starts the session.
+func (s *MFAService) CompletePasskeyChallenge(ctx context.Context, mfaToken string, credential *authrequests.PasskeyCredential, client ClientInfo) (*responses.LoginResponse, error) {
+	user, err := s.userFromChallenge(mfaToken, utils.MFAChallengeTokenType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.passkeys.VerifySecondFactor(ctx, user.Id, credential); err != nil {
+		return nil, err
+	}
+	return s.sessions.Start(ctx, user, client)
+}
+
+// BeginEnrollmentForLogin starts authenticator setup for a user whose role requires 2FA, using
+// the enrolment challenge returned by login.
+func (s *MFAService) BeginEnrollmentForLogin(ctx context.Context, mfaToken string) (*responses.TOTPSetupResponse, error) {
+	user, err := s.userFromChallenge(mfaToken, utils.MFAEnrollmentTokenType)
+	if err != nil {
+		return nil, err
+	}
+	return s.BeginEnrollment(ctx, user.Id)
+}
+
+// ConfirmEnrollmentForLogin confirms the authenticator set up during login and starts the
+// session. The recovery codes are returned alongside the tokens.
+func (s *MFAService) ConfirmEnrollmentForLogin(ctx context.Context, mfaToken, code string, client ClientInfo) (*responses.LoginResponse, []string, error) {
+	user, err := s.userFromChallenge(mfaToken, utils.MFAEnrollmentTokenType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkNotBlocked(ctx, user.Id); err != nil {
+		return nil, nil, err
+	}
+	codes, err := s.ConfirmEnrollment(ctx, user.Id, code)
+	if err != nil {
+		return nil, nil, s.recordFailure(ctx, user.Id, err)
+	}
+	s.resetFailures(ctx, user.Id)
+	login, err := s.sessions.Start(ctx, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return login, codes, nil
+}
+
+// userFromChallenge validates an MFA challenge token and reloads its user, who may have been
+// banned since the password step.
+func (s *MFAService) userFromChallenge(mfaToken, tokenType string) (*models.User, error) {
+	claims, err := utils.ValidateMFAChallengeToken(mfaToken, tokenType)
+	if err != nil {
+		return nil, constants.ErrInvalidMFAToken
+	}
+	user, err := s.repos.User.FindByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if user.IsBlacklisted {
+		return nil, constants.ErrAccountBanned
+	}
+	return user, nil
+}
+
+// ========== Enrolment ==========
+
+// Status describes the user's two-factor setup.
+func (s *MFAService) Status(ctx context.Context, userID uuid.UUID, role constants.UserRole) (*responses.MFAStatusResponse, error) {
+	status := &responses.MFAStatusResponse{Required: s.IsRequired(role)}
+	passkeys, err := s.repos.Passkey.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Passkeys = passkeys
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return status, nil
+	}
+	if mfa.ConfirmedAt == nil {
+		status.Pending = true
+		return status, nil
+	}
+	status.Enabled = true
+	if status.RecoveryCodesRemaining, err = s.repos.MFA.CountUnusedRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// BeginEnrollment generates a new authenticator secret. It stays pending, and is not asked for at
+// login, until ConfirmEnrollment receives a valid code for it.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*responses.TOTPSetupResponse, error) {
+	user, err := s.repos.User.FindByID(userID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, err
+	}
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	saved, err := s.repos.MFA.SavePending(ctx, &models.UserMFA{UserId: userID, Secret: secret})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+	if !saved {
+		return nil, constants.ErrMFAAlreadyEnabled
+	}
+
+	uri := security.TOTPProvisioningURI(secret, totpIssuer(), user.Email)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	return &responses.TOTPSetupResponse{
+		Secret:     secret,
+		OtpauthURL: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// ConfirmEnrollment enables the pending authenticator if code matches it and returns a fresh set
+// of recovery codes.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, constants.ErrMFASetupNotStarted
+	}
+	if mfa.ConfirmedAt != nil {
+		return nil, constants.ErrMFAAlreadyEnabled
+	}
+	step, ok := security.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+	if !ok {
+		return nil, constants.ErrInvalidMFACode
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := s.repos.MFA.Confirm(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	if !confirmed {
+		return nil, constants.ErrInvalidMFACode
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current TOTP code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.verifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.MFA.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// Disable removes the user's own authenticator after checking a current TOTP code. Users whose
+// role requires 2FA cannot turn it off; an admin can reset it instead.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, role constants.UserRole, code string) error {
+	if s.IsRequired(role) {
+		return constants.ErrMFARequiredForRole
+	}
+	if err := s.verifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+	_, err := s.repos.MFA.Delete(ctx, userID)
+	return err
+}
+
+// Reset removes a user's authenticator (admin action, e.g. a lost phone) and signs them out
+// everywhere. If their role requires 2FA they set up a new authenticator at their next login.
+func (s *MFAService) Reset(ctx context.Context, userID uuid.UUID) error {
+	deleted, err := s.repos.MFA.Delete(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return constants.ErrMFANotEnabled
+	}
+	s.resetFailures(ctx, userID)
+	if _, err := s.sessions.RevokeAllForUser(ctx, userID, uuid.Nil, SessionRevokedMFAReset); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions after MFA reset for user %s: %v\n", userID, err)
+	}
+	return nil
+}
+
+// ========== Helpers ==========
+
+// findMFA returns the user's authenticator, or nil if there is none.
+func (s *MFAService) findMFA(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	mfa, err := s.repos.MFA.FindByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	return mfa, nil
+}
+
+// verifyCode checks a TOTP code against the user's confirmed authenticator and consumes its time
+// step so the same code cannot be used twice.
+func (s *MFAService) verifyCode(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || mfa.ConfirmedAt == nil {
+		return constants.ErrMFANotEnabled
+	}
+	step, ok := security.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+	if !ok {
+		return constants.ErrInvalidMFACode
+	}
+	used, err := s.repos.MFA.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return constants.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAService) useRecoveryCode(ctx context.Context, userID uuid.UUID, code string) error {
+	used, err := s.repos.MFA.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return constants.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// checkNotBlocked applies the login lockout to second-factor attempts, so codes cannot be guessed
+// by requesting new challenges.
+func (s *MFAService) checkNotBlocked(ctx context.Context, userID uuid.UUID) error {
+	isBlocked, remainingMinutes, err := utils.IsLoginBlocked(ctx, s.redisClient, mfaFailedKeyPrefix+userID.String(), s.loginMaxFail)
+	if err != nil {
+		return err
+	}
+	if isBlocked {
+		return fmt.Errorf("%w: please try again in %d minutes", constants.ErrAccountLocked, remainingMinutes+1)
+	}
+	return nil
+}
+
+// recordFailure counts a wrong code towards the lockout and returns err.
+func (s *MFAService) recordFailure(ctx context.Context, userID uuid.UUID, err error) error {
+	if !errors.Is(err, constants.ErrInvalidMFACode) {
+		return err
+	}
+	if incErr := utils.IncrementLoginFailedAttempts(ctx, s.redisClient, mfaFailedKeyPrefix+userID.String(), s.loginFailBlockMinutes); incErr != nil {
+		fmt.Printf("[ERROR] Failed to increment MFA attempts for user %s: %v\n", userID, incErr)
+		return constants.ErrInternalServer
+	}
+	return err
+}
+
+func (s *MFAService) resetFailures(ctx context.Context, userID uuid.UUID) {
+	if err := utils.ResetLoginFailedAttempts(ctx, s.redisClient, mfaFailedKeyPrefix+userID.String()); err != nil {
+		fmt.Printf("[ERROR] Failed to reset MFA attempts for user %s: %v\n", userID, err)
+	}
+}
+
+// generateRecoveryCodes returns new recovery codes formatted for display and their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	buf := make([]byte, recoveryCodeLength)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		var b strings.Builder
+		for j, v := range buf {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+		}
+		codes[i] = b.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normalises a recovery code as typed by the user and returns its hex SHA-256.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpIssuer is the account issuer shown in authenticator apps.
+func totpIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("MFA_TOTP_ISSUER")); issuer != "" {
+		return issuer
+	}
+	return "Fuvekon"
+}
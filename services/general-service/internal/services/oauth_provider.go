@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"general-service/internal/models"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+)
+
+// OAuthProfile is what an identity provider tells us about a user, mapped onto our registration
+// fields. Email is only trusted for linking and sign-up when EmailVerified is set.
+type OAuthProfile struct {
+	Provider      string `json:"provider"`
+	Subject       string `json:"subject"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	FullName      string `json:"full_name"`
+	Nickname      string `json:"nickname"`
+	AvatarURL     string `json:"avatar_url"`
+}
+
+// OAuthProvider is an identity provider that signs users in with the authorization code flow.
+// PKCE is always used: AuthCodeURL sends the S256 challenge of verifier and Exchange proves it.
+type OAuthProvider interface {
+	Name() string
+	AuthCodeURL(state, verifier string) string
+	Exchange(ctx context.Context, code, verifier string) (*OAuthProfile, error)
+}
+
+// oauthCodeProvider implements OAuthProvider on top of oauth2.Config; profile maps the provider's
+// user info onto an OAuthProfile.
+type oauthCodeProvider struct {
+	name    string
+	config  *oauth2.Config
+	profile func(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*OAuthProfile, error)
+}
+
+func (p *oauthCodeProvider) Name() string {
+	return p.name
+}
+
+func (p *oauthCodeProvider) AuthCodeURL(state, verifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *oauthCodeProvider) Exchange(ctx context.Context, code, verifier string) (*OAuthProfile, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%s code exchange failed: %w", p.name, err)
+	}
+	profile, err := p.profile(ctx, p.config, token)
+	if err != nil {
+		return nil, fmt.Errorf("%s profile lookup failed: %w", p.name, err)
+	}
+	if profile.Subject == "" {
+		return nil, fmt.Errorf("%s profile has no user ID", p.name)
+	}
+	profile.Provider = p.name
+	profile.Email = strings.ToLower(strings.TrimSpace(profile.Email))
+	return profile, nil
+}
+
+// loadOAuthProvidersFromEnv returns the providers that have OAUTH_<NAME>_CLIENT_ID and
+// OAUTH_<NAME>_CLIENT_SECRET set. The redirect URI is OAUTH_REDIRECT_BASE_URL/<name> (default
+// FRONTEND_URL/auth/callback/<name>); it must be registered with the provider.
+func loadOAuthProvidersFromEnv() map[string]OAuthProvider {
+	redirectBase := strings.TrimRight(strings.TrimSpace(os.Getenv("OAUTH_REDIRECT_BASE_URL")), "/")
+	if redirectBase == "" {
+		frontendURL := strings.TrimRight(strings.TrimSpace(os.Getenv("FRONTEND_URL")), "/")
+		if frontendURL == "" {
+			frontendURL = "http://localhost:3000"
+		}
+		redirectBase = frontendURL + "/auth/callback"
+	}
+
+	providers := make(map[string]OAuthProvider)
+	add := func(name string, endpoint oauth2.Endpoint, scopes []string, profile func(context.Context, *oauth2.Config, *oauth2.Token) (*OAuthProfile, error)) {
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		clientID := strings.TrimSpace(os.Getenv(prefix + "CLIENT_ID"))
+		if clientID == "" && name == models.IdentityProviderGoogle {
+			clientID = strings.TrimSpace(os.Getenv("GOOGLE_CLIENT_ID"))
+		}
+		clientSecret := strings.TrimSpace(os.Getenv(prefix + "CLIENT_SECRET"))
+		if clientID == "" || clientSecret == "" {
+			return
+		}
+		providers[name] = &oauthCodeProvider{
+			name: name,
+			config: &oauth2.Config{
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				Endpoint:     endpoint,
+				RedirectURL:  redirectBase + "/" + name,
+				Scopes:       scopes,
+			},
+			profile: profile,
+		}
+	}
+
+	add(models.IdentityProviderGoogle, oauth2.Endpoint{
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+	}, []string{"openid", "email", "profile"}, googleCodeProfile)
+
+	add(models.IdentityProviderDiscord, oauth2.Endpoint{
+		AuthURL:  "https://discord.com/oauth2/authorize",
+		TokenURL: "https://discord.com/api/oauth2/token",
+	}, []string{"identify", "email"}, discordProfile)
+
+	add(models.IdentityProviderFacebook, oauth2.Endpoint{
+		AuthURL:  "https://www.facebook.com/" + facebookGraphVersion + "/dialog/oauth",
+		TokenURL: "https://graph.facebook.com/" + facebookGraphVersion + "/oauth/access_token",
+	}, []string{"email", "public_profile"}, facebookProfile)
+
+	return providers
+}
+
+// ========== Provider profile mapping ==========
+
+// googleIDTokenProfile verifies a Google ID token (from Sign-In or the code flow) for clientID.
+func googleIDTokenProfile(ctx context.Context, credential, clientID string) (*OAuthProfile, error) {
+	payload, err := idtoken.Validate(ctx, credential, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid google token: %w", err)
+	}
+	claim := func(name string) string {
+		v, _ := payload.Claims[name].(string)
+		return strings.TrimSpace(v)
+	}
+	verified, _ := payload.Claims["email_verified"].(bool)
+	nickname := claim("given_name")
+	if nickname == "" {
+		nickname = claim("name")
+	}
+	return &OAuthProfile{
+		Provider:      models.IdentityProviderGoogle,
+		Subject:       payload.Subject,
+		Email:         strings.ToLower(claim("email")),
+		EmailVerified: verified,
+		FullName:      claim("name"),
+		Nickname:      nickname,
+		AvatarURL:     claim("picture"),
+	}, nil
+}
+
+func googleCodeProfile(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*OAuthProfile, error) {
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	return googleIDTokenProfile(ctx, idToken, config.ClientID)
+}
+
+func discordProfile(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*OAuthProfile, error) {
+	var me struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+		Email      string `json:"email"`
+		Verified   bool   `json:"verified"`
+		Avatar     string `json:"avatar"`
+	}
+	if err := getProviderJSON(ctx, config.Client(ctx, token), "https://discord.com/api/users/@me", &me); err != nil {
+		return nil, err
+	}
+	profile := &OAuthProfile{
+		Subject:       me.ID,
+		Email:         me.Email,
+		EmailVerified: me.Verified,
+		Nickname:      me.GlobalName,
+	}
+	if profile.Nickname == "" {
+		profile.Nickname = me.Username
+	}
+	if me.Avatar != "" {
+		profile.AvatarURL = fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", me.ID, me.Avatar)
+	}
+	return profile, nil
+}
+
+const facebookGraphVersion = "v19.0"
+
+func facebookProfile(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*OAuthProfile, error) {
+	var me struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		ShortName string `json:"short_name"`
+		Email     string `json:"email"`
+		Picture   struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	url := "https://graph.facebook.com/" + facebookGraphVersion + "/me?fields=id,name,short_name,email,picture"
+	if err := getProviderJSON(ctx, config.Client(ctx, token), url, &me); err != nil {
+		return nil, err
+	}
+	return &OAuthProfile{
+		Subject: me.ID,
+		Email:   me.Email,
+		// Facebook only returns confirmed email addresses
+		EmailVerified: me.Email != "",
+		FullName:      me.Name,
+		Nickname:      me.ShortName,
+		AvatarURL:     me.Picture.Data.URL,
+	}, nil
+}
+
+// telegramProfile maps Telegram Login Widget data. Telegram never shares an email address.
+func telegramProfile(firstName, lastName, username, photoURL string, id int64) *OAuthProfile {
+	nickname := username
+	if nickname == "" {
+		nickname = firstName
+	}
+	return &OAuthProfile{
+		Provider:  models.IdentityProviderTelegram,
+		Subject:   strconv.FormatInt(id, 10),
+		FullName:  strings.TrimSpace(firstName + " " + lastName),
+		Nickname:  nickname,
+		AvatarURL: photoURL,
+	}
+}
+
+func getProviderJSON(ctx context.Context, client *http.Client, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.Unmarshal(body, dest)
+}
@@ -0,0 +1,642 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	authrequests "general-service/internal/dto/auth/requests"
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/security"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// oauthStateTimeout bounds the time between starting a provider login and its callback.
+	oauthStateTimeout = 10 * time.Minute
+	// oauthRegistrationTimeout bounds how long a verified provider profile waits for sign-up details.
+	oauthRegistrationTimeout = 30 * time.Minute
+	// telegramLoginMaxAge bounds how old Telegram Login Widget data may be.
+	telegramLoginMaxAge = 10 * time.Minute
+)
+
+// oauthPendingLogin is the state stored in Redis between starting a provider login and its
+// callback. UserID is set when the provider account is being linked to that user instead.
+type oauthPendingLogin struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	UserID   string `json:"user_id,omitempty"`
+}
+
+// OAuthService signs users in with external identity providers (Google, Discord, Facebook,
+// Telegram). Provider accounts are linked to users through user_identities; a provider login
+// finds the user by identity, or creates a new account once the registration details are
+// complete. Existing accounts are only linked explicitly by their signed-in owner.
+type OAuthService struct {
+	repos          *repositories.Repositories
+	redisClient    *redis.Client
+	mfa            *MFAService
+	mail           *MailService
+	providers      map[string]OAuthProvider
+	googleClientID string
+	telegramToken  string
+}
+
+func NewOAuthService(repos *repositories.Repositories, redisClient *redis.Client, mfa *MFAService, mail *MailService) *OAuthService {
+	googleClientID := strings.TrimSpace(os.Getenv("GOOGLE_CLIENT_ID"))
+	if googleClientID == "" {
+		googleClientID = strings.TrimSpace(os.Getenv("OAUTH_GOOGLE_CLIENT_ID"))
+	}
+	return &OAuthService{
+		repos:          repos,
+		redisClient:    redisClient,
+		mfa:            mfa,
+		mail:           mail,
+		providers:      loadOAuthProvidersFromEnv(),
+		googleClientID: googleClientID,
+		telegramToken:  strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN")),
+	}
+}
+
+// Providers lists the configured providers, sorted by name.
+func (s *OAuthService) Providers() *responses.OAuthProvidersResponse {
+	names := make([]string, 0, len(s.providers)+2)
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	if _, ok := s.providers[models.IdentityProviderGoogle]; !ok && s.googleClientID != "" {
+		names = append(names, models.IdentityProviderGoogle)
+	}
+	if s.telegramToken != "" {
+		names = append(names, models.IdentityProviderTelegram)
+	}
+	sort.Strings(names)
+	return &responses.OAuthProvidersResponse{Providers: names}
+}
+
+// ========== Authorization code flow ==========
+
+// BeginLogin starts an authorization code login with PKCE. The state is single-use and the
+// verifier never leaves the server.
+func (s *OAuthService) BeginLogin(ctx context.Context, providerName string) (*responses.OAuthAuthorizationResponse, error) {
+	return s.beginAuthorization(ctx, providerName, "")
+}
+
+// beginAuthorization starts the authorization code flow, for a login or, when userID is set, for
+// linking the provider account to that user.
+func (s *OAuthService) beginAuthorization(ctx context.Context, providerName, userID string) (*responses.OAuthAuthorizationResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, constants.ErrOAuthProviderNotConfigured
+	}
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+	pending, err := json.Marshal(oauthPendingLogin{Provider: providerName, Verifier: verifier, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.StoreOAuthState(ctx, s.redisClient, utils.OAuthStateKeyPrefix+state, pending, oauthStateTimeout); err != nil {
+		return nil, fmt.Errorf("failed to store sign-in state: %w", err)
+	}
+	return &responses.OAuthAuthorizationResponse{
+		Provider:         providerName,
+		AuthorizationURL: provider.AuthCodeURL(state, verifier),
+		State:            state,
+		ExpiresIn:        int(oauthStateTimeout.Seconds()),
+	}, nil
+}
+
+// CompleteLogin exchanges the code the provider redirected back with and signs the user in (or
+// returns an MFA challenge or a registration request, see LoginResponse).
+func (s *OAuthService) CompleteLogin(ctx context.Context, providerName string, req *authrequests.OAuthCallbackRequest, client ClientInfo) (*responses.LoginResponse, error) {
+	profile, err := s.exchangeCode(ctx, providerName, req.Code, req.State, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.loginOrRequestRegistration(ctx, profile, &req.OAuthRegistrationDetails, client)
+}
+
+// exchangeCode consumes the state of an authorization started for userID ("" for a login) and
+// exchanges the code for the provider profile.
+func (s *OAuthService) exchangeCode(ctx context.Context, providerName, code, state, userID string) (*OAuthProfile, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, constants.ErrOAuthProviderNotConfigured
+	}
+	raw, err := utils.TakeOAuthState(ctx, s.redisClient, utils.OAuthStateKeyPrefix+state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sign-in state: %w", err)
+	}
+	var pending oauthPendingLogin
+	if raw == nil || json.Unmarshal(raw, &pending) != nil || pending.Provider != providerName || pending.UserID != userID {
+		return nil, constants.ErrInvalidOAuthState
+	}
+	profile, err := provider.Exchange(ctx, code, pending.Verifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidOAuthLogin, err)
+	}
+	return profile, nil
+}
+
+// CompleteRegistration finishes sign-up for a profile saved by a callback that answered with
+// registration_required.
+func (s *OAuthService) CompleteRegistration(ctx context.Context, req *authrequests.OAuthRegisterRequest, client ClientInfo) (*responses.LoginResponse, error) {
+	key := utils.OAuthRegistrationKeyPrefix + req.RegistrationToken
+	raw, err := utils.GetOAuthState(ctx, s.redisClient, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sign-up state: %w", err)
+	}
+	var profile OAuthProfile
+	if raw == nil || json.Unmarshal(raw, &profile) != nil {
+		return nil, constants.ErrOAuthRegistrationExpired
+	}
+	response, err := s.LoginWithProfile(ctx, &profile, &req.OAuthRegistrationDetails, client)
+	if err != nil {
+		// Keep the token on validation errors so the user can correct the details
+		return nil, err
+	}
+	if err := utils.DeleteOAuthState(ctx, s.redisClient, key); err != nil {
+		fmt.Printf("[WARN] Failed to delete sign-up state: %v\n", err)
+	}
+	return response, nil
+}
+
+// loginOrRequestRegistration signs the profile in. When a new account still needs details, the
+// verified profile is kept for CompleteRegistration instead of failing, since the code it came
+// from cannot be exchanged again.
+func (s *OAuthService) loginOrRequestRegistration(ctx context.Context, profile *OAuthProfile, details *authrequests.OAuthRegistrationDetails, client ClientInfo) (*responses.LoginResponse, error) {
+	response, err := s.LoginWithProfile(ctx, profile, details, client)
+	if !errors.Is(err, constants.ErrOAuthRegistrationDetailsRequired) {
+		return response, err
+	}
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.StoreOAuthState(ctx, s.redisClient, utils.OAuthRegistrationKeyPrefix+token, raw, oauthRegistrationTimeout); err != nil {
+		return nil, fmt.Errorf("failed to store sign-up state: %w", err)
+	}
+	merged := mergeRegistrationDetails(profile, details)
+	return &responses.LoginResponse{Registration: &responses.OAuthRegistrationResponse{
+		RegistrationRequired: true,
+		RegistrationToken:    token,
+		Provider:             profile.Provider,
+		Email:                profile.Email,
+		FullName:             merged.FullName,
+		Nickname:             merged.Nickname,
+		AvatarURL:            profile.AvatarURL,
+		MissingFields:        missingRegistrationFields(&merged),
+		ExpiresIn:            int(oauthRegistrationTimeout.Seconds()),
+	}}, nil
+}
+
+// ========== Token and widget logins ==========
+
+// LoginWithGoogleIDToken signs in with a Google Sign-In credential (ID token). Missing
+// registration details are reported as ErrGoogleRegistrationDetailsRequired; the client resubmits
+// the same credential with them.
+func (s *OAuthService) LoginWithGoogleIDToken(ctx context.Context, req *authrequests.GoogleLoginRequest, client ClientInfo) (*responses.LoginResponse, error) {
+	profile, err := s.verifyGoogleIDToken(ctx, req.Credential)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.LoginWithProfile(ctx, profile, &req.OAuthRegistrationDetails, client)
+	if errors.Is(err, constants.ErrOAuthRegistrationDetailsRequired) {
+		return nil, constants.ErrGoogleRegistrationDetailsRequired
+	}
+	return response, err
+}
+
+// LoginWithTelegram signs in with data from the Telegram Login Widget, signed with the bot token.
+// Telegram shares no email address, so only accounts that already have a Telegram identity can
+// use it.
+func (s *OAuthService) LoginWithTelegram(ctx context.Context, req *authrequests.TelegramLoginRequest, client ClientInfo) (*responses.LoginResponse, error) {
+	profile, err := s.verifyTelegram(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.LoginWithProfile(ctx, profile, nil, client)
+}
+
+func (s *OAuthService) verifyGoogleIDToken(ctx context.Context, credential string) (*OAuthProfile, error) {
+	if s.googleClientID == "" {
+		return nil, constants.ErrOAuthProviderNotConfigured
+	}
+	profile, err := googleIDTokenProfile(ctx, credential, s.googleClientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidOAuthLogin, err)
+	}
+	return profile, nil
+}
+
+func (s *OAuthService) verifyTelegram(req *authrequests.TelegramLoginRequest) (*OAuthProfile, error) {
+	if s.telegramToken == "" {
+		return nil, constants.ErrOAuthProviderNotConfigured
+	}
+	fields := map[string]string{
+		"id":         strconv.FormatInt(req.ID, 10),
+		"first_name": req.FirstName,
+		"last_name":  req.LastName,
+		"username":   req.Username,
+		"photo_url":  req.PhotoURL,
+		"auth_date":  strconv.FormatInt(req.AuthDate, 10),
+	}
+	if err := security.VerifyTelegramLogin(s.telegramToken, fields, req.Hash, time.Now(), telegramLoginMaxAge); err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidOAuthLogin, err)
+	}
+	return telegramProfile(req.FirstName, req.LastName, req.Username, req.PhotoURL, req.ID), nil
+}
+
+// ========== Account resolution ==========
+
+// LoginWithProfile finds the user linked to a verified provider profile and starts their session
+// (or MFA challenge). Without a linked identity a new account is created from the profile and
+// details. An existing account with the same email is not linked implicitly: its owner signs in
+// and links the provider (CompleteLink etc.), proving control of both. Unverified emails are never
+// used to create accounts.
+func (s *OAuthService) LoginWithProfile(ctx context.Context, profile *OAuthProfile, details *authrequests.OAuthRegistrationDetails, client ClientInfo) (*responses.LoginResponse, error) {
+	user, err := s.findLinkedUser(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if profile.Email == "" || !profile.EmailVerified {
+			return nil, constants.ErrOAuthVerifiedEmailRequired
+		}
+		user, err = s.repos.User.FindByEmail(profile.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find user: %w", err)
+		}
+		if user != nil {
+			return nil, constants.ErrOAuthAccountExists
+		}
+		if user, err = s.register(ctx, profile, details); err != nil {
+			return nil, err
+		}
+	}
+
+	// Banned users cannot log in
+	if user.IsBlacklisted {
+		return nil, constants.ErrAccountBanned
+	}
+
+	// Second factor, when the user has one or their role requires it
+	return s.mfa.BeginLogin(ctx, user, client)
+}
+
+// findLinkedUser returns the user linked to the profile's provider account, or nil if none.
+func (s *OAuthService) findLinkedUser(ctx context.Context, profile *OAuthProfile) (*models.User, error) {
+	identity, err := s.repos.Identity.FindByProviderSubject(ctx, profile.Provider, profile.Subject)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find identity: %w", err)
+	}
+	user, err := s.repos.User.FindByID(identity.UserId.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := s.repos.Identity.RecordLogin(ctx, identity.Id, profile.Email); err != nil {
+		fmt.Printf("[WARN] Failed to record %s login for user %s: %v\n", profile.Provider, user.Id, err)
+	}
+	s.recordIdentityFingerprint(ctx, user.Id, profile)
+	return user, nil
+}
+
+// recordIdentityFingerprint keeps the provider account for duplicate account detection, so it is
+// still recognised on another account after this one unlinks it.
+func (s *OAuthService) recordIdentityFingerprint(ctx context.Context, userID uuid.UUID, profile *OAuthProfile) {
+	if err := s.repos.Fraud.RecordFingerprint(ctx, userID, models.FingerprintKindIdentity, profile.Provider+":"+profile.Subject, time.Now()); err != nil {
+		log.Printf("[WARN] Failed to record %s fingerprint for user %s: %v", profile.Provider, userID, err)
+	}
+}
+
+// register creates a verified account for the profile. Details left empty fall back to the
+// profile; the same fields as a normal registration are required.
+func (s *OAuthService) register(ctx context.Context, profile *OAuthProfile, details *authrequests.OAuthRegistrationDetails) (*models.User, error) {
+	merged := mergeRegistrationDetails(profile, details)
+	if len(missingRegistrationFields(&merged)) > 0 {
+		return nil, constants.ErrOAuthRegistrationDetailsRequired
+	}
+	dob, err := utils.ParseAndValidateDateOfBirth(merged.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
+	var hashedPassword string
+	if merged.Password != "" && merged.ConfirmPassword != "" && merged.Password == merged.ConfirmPassword {
+		if len(merged.Password) < 6 {
+			return nil, fmt.Errorf("password must be at least 6 characters")
+		}
+		if hashedPassword, err = utils.HashPassword(merged.Password); err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+	} else {
+		if merged.Password != "" || merged.ConfirmPassword != "" {
+			return nil, constants.ErrPasswordMismatch
+		}
+		// No password: unguessable hash so email/password login is disabled
+		hashedPassword, _ = utils.HashPassword(uuid.New().String() + uuid.New().String())
+	}
+	passwordUnset := merged.Password == ""
+
+	firstName, lastName := parseFullName(merged.FullName)
+	now := time.Now()
+	user := &models.User{
+		Id:            uuid.New(),
+		FursonaName:   merged.Nickname,
+		FirstName:     firstName,
+		LastName:      lastName,
+		Email:         profile.Email,
+		Password:      hashedPassword,
+		PasswordUnset: passwordUnset,
+		Country:       merged.Country,
+		IdCard:        merged.IdCard,
+		DateOfBirth:   dob,
+		IsVerified:    true,
+		Role:          constants.RoleUser,
+		CreatedAt:     now,
+		ModifiedAt:    now,
+	}
+	identity := &models.UserIdentity{
+		Id:          uuid.New(),
+		UserId:      user.Id,
+		Provider:    profile.Provider,
+		Subject:     profile.Subject,
+		Email:       profile.Email,
+		LinkedAt:    now,
+		LastLoginAt: &now,
+	}
+	if err := s.repos.Identity.CreateWithUser(ctx, user, identity); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	s.recordIdentityFingerprint(ctx, user.Id, profile)
+	return user, nil
+}
+
+// ========== Account linking ==========
+
+// BeginLink starts the authorization code flow to link a provider account to the signed-in user.
+// The state is bound to the user, so it cannot finish a login or another user's link.
+func (s *OAuthService) BeginLink(ctx context.Context, userID uuid.UUID, providerName string) (*responses.OAuthAuthorizationResponse, error) {
+	return s.beginAuthorization(ctx, providerName, userID.String())
+}
+
+// CompleteLink exchanges the code from a BeginLink redirect and links the provider account.
+func (s *OAuthService) CompleteLink(ctx context.Context, userID uuid.UUID, providerName string, req *authrequests.OAuthLinkRequest) (*responses.LinkedIdentityResponse, error) {
+	profile, err := s.exchangeCode(ctx, providerName, req.Code, req.State, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.link(ctx, userID, profile)
+}
+
+// LinkGoogleIDToken links the Google account of a Sign-In credential to the signed-in user.
+func (s *OAuthService) LinkGoogleIDToken(ctx context.Context, userID uuid.UUID, req *authrequests.GoogleLinkRequest) (*responses.LinkedIdentityResponse, error) {
+	profile, err := s.verifyGoogleIDToken(ctx, req.Credential)
+	if err != nil {
+		return nil, err
+	}
+	return s.link(ctx, userID, profile)
+}
+
+// LinkTelegram links the Telegram account of Login Widget data to the signed-in user.
+func (s *OAuthService) LinkTelegram(ctx context.Context, userID uuid.UUID, req *authrequests.TelegramLoginRequest) (*responses.LinkedIdentityResponse, error) {
+	profile, err := s.verifyTelegram(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.link(ctx, userID, profile)
+}
+
+// link attaches a verified provider profile to the user. A provider account belongs to one user,
+// and a user links at most one account per provider; linking the same account again is a no-op.
+func (s *OAuthService) link(ctx context.Context, userID uuid.UUID, profile *OAuthProfile) (*responses.LinkedIdentityResponse, error) {
+	user, err := s.repos.User.FindByID(userID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	existing, err := s.repos.Identity.FindByProviderSubject(ctx, profile.Provider, profile.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find identity: %w", err)
+	}
+	if existing != nil {
+		if existing.UserId != user.Id {
+			return nil, constants.ErrIdentityAlreadyLinked
+		}
+		return mappers.MapIdentityToResponse(existing), nil
+	}
+
+	identities, err := s.repos.Identity.FindByUser(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked identities: %w", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == profile.Provider {
+			return nil, constants.ErrProviderAlreadyLinked
+		}
+	}
+
+	identity := &models.UserIdentity{
+		UserId:   user.Id,
+		Provider: profile.Provider,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+		LinkedAt: time.Now(),
+	}
+	if err := s.repos.Identity.Create(ctx, identity); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, constants.ErrIdentityAlreadyLinked
+		}
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	s.recordIdentityFingerprint(ctx, user.Id, profile)
+	s.notifyLoginMethodChange(ctx, user, profile.Provider, true)
+	return mappers.MapIdentityToResponse(identity), nil
+}
+
+// LoginMethods lists how the user can sign in: password, passkeys and linked provider accounts.
+func (s *OAuthService) LoginMethods(ctx context.Context, userID uuid.UUID) (*responses.LoginMethodsResponse, error) {
+	user, err := s.repos.User.FindByID(userID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	passkeys, err := s.repos.Passkey.CountByUser(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count passkeys: %w", err)
+	}
+	identities, err := s.repos.Identity.FindByUser(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked identities: %w", err)
+	}
+	return &responses.LoginMethodsResponse{
+		HasPassword: !user.PasswordUnset,
+		Passkeys:    passkeys,
+		Identities:  mappers.MapIdentitiesToResponse(identities),
+	}, nil
+}
+
+// Unlink removes a linked provider account, as long as the user keeps another way to sign in
+// (password, passkey or another provider account).
+func (s *OAuthService) Unlink(ctx context.Context, userID, identityID uuid.UUID) error {
+	methods, err := s.LoginMethods(ctx, userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range methods.Identities {
+		found = found || identity.Id == identityID
+	}
+	if !found {
+		return constants.ErrIdentityNotFound
+	}
+	others := methods.Passkeys + int64(len(methods.Identities)-1)
+	if methods.HasPassword {
+		others++
+	}
+	if others == 0 {
+		return constants.ErrLastLoginMethod
+	}
+
+	identity, err := s.repos.Identity.Delete(ctx, userID, identityID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	if identity == nil {
+		return constants.ErrIdentityNotFound
+	}
+	if user, err := s.repos.User.FindByID(userID.String()); err == nil {
+		s.notifyLoginMethodChange(ctx, user, identity.Provider, false)
+	}
+	return nil
+}
+
+// notifyLoginMethodChange emails the user that a provider account was linked or unlinked, so an
+// unexpected change is noticed. Failures are logged only.
+func (s *OAuthService) notifyLoginMethodChange(ctx context.Context, user *models.User, provider string, linked bool) {
+	if s.mail == nil || user.Email == "" {
+		return
+	}
+	lang := LangFromCountry(user.Country)
+	subject, notice := loginMethodNotice(provider, linked, lang)
+	if err := s.mail.SendNoticeEmail(ctx, os.Getenv("SES_EMAIL_IDENTITY"), user.Email, subject, notice, lang); err != nil {
+		log.Printf("Failed to send login method notice to user %s: %v", user.Id, err)
+	}
+}
+
+func loginMethodNotice(provider string, linked bool, lang string) (string, NoticeEmail) {
+	name := strings.ToUpper(provider[:1]) + provider[1:]
+	if lang == "vi" {
+		if linked {
+			return "Đã liên kết tài khoản " + name, NoticeEmail{
+				Title: "Đã liên kết phương thức đăng nhập",
+				Paragraphs: []string{
+					fmt.Sprintf("Tài khoản %s vừa được liên kết với tài khoản FUVE của bạn và có thể dùng để đăng nhập.", name),
+				},
+				Footnote: "Nếu bạn không thực hiện thay đổi này, vui lòng gỡ liên kết trong phần cài đặt tài khoản và đổi mật khẩu ngay.",
+			}
+		}
+		return "Đã gỡ liên kết tài khoản " + name, NoticeEmail{
+			Title: "Đã gỡ phương thức đăng nhập",
+			Paragraphs: []string{
+				fmt.Sprintf("Tài khoản %s đã được gỡ khỏi tài khoản FUVE của bạn và không còn dùng để đăng nhập được nữa.", name),
+			},
+			Footnote: "Nếu bạn không thực hiện thay đổi này, vui lòng đổi mật khẩu và liên hệ ban tổ chức.",
+		}
+	}
+	if linked {
+		return "Your " + name + " account was linked", NoticeEmail{
+			Title: "Login method linked",
+			Paragraphs: []string{
+				fmt.Sprintf("A %s account was just linked to your FUVE account and can now be used to sign in.", name),
+			},
+			Footnote: "If you did not make this change, unlink it in your account settings and change your password right away.",
+		}
+	}
+	return "Your " + name + " account was unlinked", NoticeEmail{
+		Title: "Login method removed",
+		Paragraphs: []string{
+			fmt.Sprintf("A %s account was removed from your FUVE account and can no longer be used to sign in.", name),
+		},
+		Footnote: "If you did not make this change, change your password and contact the organizers.",
+	}
+}
+
+// mergeRegistrationDetails fills empty name fields from the provider profile.
+func mergeRegistrationDetails(profile *OAuthProfile, details *authrequests.OAuthRegistrationDetails) authrequests.OAuthRegistrationDetails {
+	merged := authrequests.OAuthRegistrationDetails{}
+	if details != nil {
+		merged = *details
+	}
+	merged.FullName = strings.TrimSpace(merged.FullName)
+	merged.Nickname = strings.TrimSpace(merged.Nickname)
+	merged.Country = strings.TrimSpace(merged.Country)
+	merged.DateOfBirth = strings.TrimSpace(merged.DateOfBirth)
+	merged.IdCard = strings.TrimSpace(merged.IdCard)
+	if merged.FullName == "" {
+		merged.FullName = profile.FullName
+	}
+	if merged.Nickname == "" {
+		merged.Nickname = profile.Nickname
+	}
+	return merged
+}
+
+// missingRegistrationFields lists the required sign-up fields that are still empty.
+func missingRegistrationFields(details *authrequests.OAuthRegistrationDetails) []string {
+	missing := []string{}
+	for _, field := range []struct{ name, value string }{
+		{"fullName", details.FullName},
+		{"nickname", details.Nickname},
+		{"dateOfBirth", details.DateOfBirth},
+		{"country", details.Country},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
+}
+
+// randomToken returns 256 random bits, URL-safe base64 encoded, for single-use sign-in tokens.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate sign-in token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"general-service/internal/audit"
 	"general-service/internal/dto/panel/requests"
 	"general-service/internal/dto/panel/responses"
 	"general-service/internal/mappers"
@@ -263,6 +264,7 @@ func (s *PanelService) setPanelStatus(ctx context.Context, panelIDStr string, st
 		return nil, ErrStatusUnchanged
 	}
 
+	before := audit.Snapshot(ctx, panel)
 	if err := s.repos.Panel.SetPanelStatus(ctx, panelID, status); err != nil {
 		log.Printf("Error setting panel status: %v", err)
 		return nil, err
@@ -272,6 +274,7 @@ func (s *PanelService) setPanelStatus(ctx context.Context, panelIDStr string, st
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "panels", panelID.String(), before, updated)
 
 	resp := mappers.MapPanelToResponse(updated)
 	return &resp, nil
@@ -305,6 +308,7 @@ func (s *PanelService) AssignPanelSchedule(ctx context.Context, panelIDStr strin
 		return nil, ErrPanelNotSchedulable
 	}
 
+	before := audit.Snapshot(ctx, panel)
 	if err := s.repos.Panel.SetPanelSchedule(ctx, panelID, req.SlotLabel, req.ScheduledStartAt); err != nil {
 		log.Printf("Error assigning panel schedule: %v", err)
 		return nil, err
@@ -314,6 +318,7 @@ func (s *PanelService) AssignPanelSchedule(ctx context.Context, panelIDStr strin
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "panels", panelID.String(), before, updated)
 
 	resp := mappers.MapPanelToResponse(updated)
 	return &resp, nil
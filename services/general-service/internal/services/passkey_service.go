@@ -0,0 +1,512 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	authrequests "general-service/internal/dto/auth/requests"
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/security"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	passkeyCeremonyTimeout = 5 * time.Minute
+	passkeyChallengeSize   = 32
+
+	passkeyCeremonyRegister = "register"
+	passkeyCeremonyLogin    = "login"
+	passkeyCeremonyMFA      = "mfa"
+)
+
+// passkeyCeremony is the state stored in Redis between the begin and finish requests.
+type passkeyCeremony struct {
+	Kind   string `json:"kind"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// PasskeyService runs WebAuthn ceremonies: registering passkeys and verifying them for a
+// passwordless login or as a second factor. Responses are verified with go-webauthn; the service
+// keeps the ceremonies in Redis and the passkeys in the database. Only "none" attestation is
+// requested, and user verification is always required, so a passkey login counts as two factors.
+type PasskeyService struct {
+	repos       *repositories.Repositories
+	redisClient *redis.Client
+	webAuthn    *webauthn.WebAuthn
+	rpID        string
+	rpName      string
+}
+
+func NewPasskeyService(repos *repositories.Repositories, redisClient *redis.Client) *PasskeyService {
+	rpID := strings.TrimSpace(os.Getenv("WEBAUTHN_RP_ID"))
+	if rpID == "" {
+		rpID = "localhost"
+	}
+	rpName := strings.TrimSpace(os.Getenv("WEBAUTHN_RP_NAME"))
+	if rpName == "" {
+		rpName = "Fuvekon"
+	}
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		origins = []string{"http://localhost:3000"}
+	}
+	webAuthn, err := webauthn.New(&webauthn.Config{RPID: rpID, RPDisplayName: rpName, RPOrigins: origins})
+	if err != nil {
+		log.Fatalf("WebAuthn configuration (WEBAUTHN_RP_ID, WEBAUTHN_ORIGINS): %v", err)
+	}
+	return &PasskeyService{repos: repos, redisClient: redisClient, webAuthn: webAuthn, rpID: rpID, rpName: rpName}
+}
+
+// ========== Registration ==========
+
+// BeginRegistration returns the options for navigator.credentials.create() to add a passkey.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID uuid.UUID) (*responses.PasskeyCreationOptions, error) {
+	user, err := s.repos.User.FindByID(userID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrUserNotFound
+		}
+		return nil, err
+	}
+	existing, err := s.repos.Passkey.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := s.newCeremony(ctx, passkeyCeremony{Kind: passkeyCeremonyRegister, UserID: userID.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]responses.PasskeyCredentialParameter, len(security.SupportedCOSEAlgorithms))
+	for i, alg := range security.SupportedCOSEAlgorithms {
+		params[i] = responses.PasskeyCredentialParameter{Type: "public-key", Alg: alg}
+	}
+	displayName := user.FursonaName
+	if displayName == "" {
+		displayName = user.Email
+	}
+	return &responses.PasskeyCreationOptions{
+		Challenge: challenge,
+		RP:        responses.PasskeyRelyingParty{ID: s.rpID, Name: s.rpName},
+		User: responses.PasskeyUser{
+			ID:          passkeyUserHandle(userID),
+			Name:        user.Email,
+			DisplayName: displayName,
+		},
+		PubKeyCredParams: params,
+		Timeout:          int(passkeyCeremonyTimeout / time.Millisecond),
+		Attestation:      "none",
+		AuthenticatorSelection: responses.PasskeyAuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		ExcludeCredentials: credentialDescriptors(existing),
+	}, nil
+}
+
+// FinishRegistration verifies the attestation response and stores the new passkey.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID uuid.UUID, req *authrequests.PasskeyRegisterRequest) (*responses.PasskeyResponse, error) {
+	parsed, err := parseCredentialCreation(&req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidPasskey, err)
+	}
+	session, err := s.takeCeremony(ctx, parsed.Response.CollectedClientData.Challenge, passkeyCeremonyRegister, userID)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.webAuthn.CreateCredential(passkeyUser{id: userID}, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidPasskey, err)
+	}
+	alg, err := security.COSEKeyAlgorithm(created.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidPasskey, err)
+	}
+
+	credentialID := security.EncodeWebAuthnBase64(created.ID)
+	if _, err := s.repos.Passkey.FindByCredentialID(ctx, credentialID); err == nil {
+		return nil, constants.ErrPasskeyAlreadyRegistered
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	credential := &models.WebAuthnCredential{
+		Id:             uuid.New(),
+		UserId:         userID,
+		CredentialId:   credentialID,
+		PublicKey:      created.PublicKey,
+		Algorithm:      alg,
+		SignCount:      int64(created.Authenticator.SignCount),
+		Aaguid:         formatAAGUID(created.Authenticator.AAGUID),
+		Transports:     truncate(strings.Join(req.Credential.Response.Transports, ","), 100),
+		Name:           name,
+		BackupEligible: created.Flags.BackupEligible,
+		BackedUp:       created.Flags.BackupState,
+	}
+	if err := s.repos.Passkey.Create(ctx, credential); err != nil {
+		return nil, fmt.Errorf("failed to store passkey: %w", err)
+	}
+	return mappers.MapPasskeyToResponse(credential), nil
+}
+
+// ========== Authentication ==========
+
+// BeginLogin returns the options for navigator.credentials.get() for a passwordless login. No
+// credentials are listed: the browser offers the passkeys it holds for this site.
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*responses.PasskeyRequestOptions, error) {
+	return s.requestOptions(ctx, passkeyCeremony{Kind: passkeyCeremonyLogin}, nil)
+}
+
+// Authenticate verifies a passwordless login assertion and returns the passkey used.
+func (s *PasskeyService) Authenticate(ctx context.Context, credential *authrequests.PasskeyCredential) (*models.WebAuthnCredential, error) {
+	return s.verifyAssertion(ctx, credential, passkeyCeremonyLogin, uuid.Nil)
+}
+
+// BeginSecondFactor returns request options limited to the user's passkeys, for an MFA challenge.
+func (s *PasskeyService) BeginSecondFactor(ctx context.Context, userID uuid.UUID) (*responses.PasskeyRequestOptions, error) {
+	existing, err := s.repos.Passkey.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, constants.ErrPasskeyNotFound
+	}
+	return s.requestOptions(ctx, passkeyCeremony{Kind: passkeyCeremonyMFA, UserID: userID.String()}, existing)
+}
+
+// VerifySecondFactor verifies an assertion made with one of the user's passkeys.
+func (s *PasskeyService) VerifySecondFactor(ctx context.Context, userID uuid.UUID, credential *authrequests.PasskeyCredential) error {
+	_, err := s.verifyAssertion(ctx, credential, passkeyCeremonyMFA, userID)
+	return err
+}
+
+func (s *PasskeyService) requestOptions(ctx context.Context, ceremony passkeyCeremony, allowed []models.WebAuthnCredential) (*responses.PasskeyRequestOptions, error) {
+	challenge, err := s.newCeremony(ctx, ceremony)
+	if err != nil {
+		return nil, err
+	}
+	return &responses.PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rpID,
+		Timeout:          int(passkeyCeremonyTimeout / time.Millisecond),
+		UserVerification: "required",
+		AllowCredentials: credentialDescriptors(allowed),
+	}, nil
+}
+
+// verifyAssertion checks an assertion for a ceremony of the given kind. For second-factor
+// ceremonies userID is the user the passkey must belong to.
+func (s *PasskeyService) verifyAssertion(ctx context.Context, credential *authrequests.PasskeyCredential, kind string, userID uuid.UUID) (*models.WebAuthnCredential, error) {
+	parsed, err := parseCredentialAssertion(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidPasskey, err)
+	}
+	session, err := s.takeCeremony(ctx, parsed.Response.CollectedClientData.Challenge, kind, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored *models.WebAuthnCredential
+	var validated *webauthn.Credential
+	if kind == passkeyCeremonyMFA {
+		var user passkeyUser
+		if user, err = s.loadUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		validated, err = s.webAuthn.ValidateLogin(user, *session, parsed)
+		stored = user.find(parsed.RawID)
+	} else {
+		// Discoverable login: the passkey names its user in the user handle.
+		validated, err = s.webAuthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			owner, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, constants.ErrPasskeyNotFound
+			}
+			user, err := s.loadUser(ctx, owner)
+			if err != nil {
+				return nil, err
+			}
+			stored = user.find(rawID)
+			return user, nil
+		}, *session, parsed)
+	}
+	if stored == nil {
+		return nil, constants.ErrPasskeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidPasskey, err)
+	}
+
+	// A counter that does not move forward means the authenticator may have been cloned
+	if validated.Authenticator.CloneWarning {
+		log.Printf("[WARN] Passkey %s of user %s: sign counter %d did not exceed %d, possible clone", stored.Id, stored.UserId, parsed.Response.AuthenticatorData.Counter, stored.SignCount)
+		return nil, constants.ErrInvalidPasskey
+	}
+	updated, err := s.repos.Passkey.RecordUse(ctx, stored.Id, int64(validated.Authenticator.SignCount), validated.Flags.BackupState)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, constants.ErrInvalidPasskey
+	}
+	return stored, nil
+}
+
+// ========== Management ==========
+
+// List returns the user's passkeys.
+func (s *PasskeyService) List(ctx context.Context, userID uuid.UUID) ([]responses.PasskeyResponse, error) {
+	credentials, err := s.repos.Passkey.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return mappers.MapPasskeysToResponse(credentials), nil
+}
+
+// Delete removes one of the user's passkeys.
+func (s *PasskeyService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	deleted, err := s.repos.Passkey.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return constants.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// HasPasskeys reports whether the user has registered a passkey.
+func (s *PasskeyService) HasPasskeys(ctx context.Context, userID uuid.UUID) (bool, error) {
+	count, err := s.repos.Passkey.CountByUser(ctx, userID)
+	return count > 0, err
+}
+
+// ========== Helpers ==========
+
+// newCeremony stores a ceremony under a fresh random challenge and returns the challenge.
+func (s *PasskeyService) newCeremony(ctx context.Context, ceremony passkeyCeremony) (string, error) {
+	b := make([]byte, passkeyChallengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := security.EncodeWebAuthnBase64(b)
+	state, err := json.Marshal(ceremony)
+	if err != nil {
+		return "", err
+	}
+	if err := utils.StoreWebAuthnChallenge(ctx, s.redisClient, challenge, state, passkeyCeremonyTimeout); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// takeCeremony consumes the ceremony a response's challenge belongs to and returns it as the
+// session go-webauthn verifies the response against.
+func (s *PasskeyService) takeCeremony(ctx context.Context, challenge, kind string, userID uuid.UUID) (*webauthn.SessionData, error) {
+	if challenge == "" {
+		return nil, constants.ErrPasskeyChallengeExpired
+	}
+	state, err := utils.TakeWebAuthnChallenge(ctx, s.redisClient, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, constants.ErrPasskeyChallengeExpired
+	}
+	var ceremony passkeyCeremony
+	if err := json.Unmarshal(state, &ceremony); err != nil {
+		return nil, fmt.Errorf("failed to read passkey ceremony: %w", err)
+	}
+	if ceremony.Kind != kind {
+		return nil, constants.ErrPasskeyChallengeExpired
+	}
+	if userID != uuid.Nil && ceremony.UserID != userID.String() {
+		return nil, constants.ErrPasskeyChallengeExpired
+	}
+
+	session := &webauthn.SessionData{
+		Challenge:        challenge,
+		RelyingPartyID:   s.rpID,
+		UserVerification: protocol.VerificationRequired,
+	}
+	if kind == passkeyCeremonyRegister {
+		session.CredParams = make([]protocol.CredentialParameter, len(security.SupportedCOSEAlgorithms))
+		for i, alg := range security.SupportedCOSEAlgorithms {
+			session.CredParams[i] = protocol.CredentialParameter{
+				Type:      protocol.PublicKeyCredentialType,
+				Algorithm: webauthncose.COSEAlgorithmIdentifier(alg),
+			}
+		}
+	}
+	if userID != uuid.Nil {
+		session.UserID = userID[:]
+	}
+	return session, nil
+}
+
+// loadUser returns the user's passkeys for go-webauthn.
+func (s *PasskeyService) loadUser(ctx context.Context, userID uuid.UUID) (passkeyUser, error) {
+	credentials, err := s.repos.Passkey.FindByUser(ctx, userID)
+	if err != nil {
+		return passkeyUser{}, err
+	}
+	return passkeyUser{id: userID, credentials: credentials}, nil
+}
+
+// passkeyUser is a user and their stored passkeys as go-webauthn sees them.
+type passkeyUser struct {
+	id          uuid.UUID
+	credentials []models.WebAuthnCredential
+}
+
+func (u passkeyUser) WebAuthnID() []byte          { return u.id[:] }
+func (u passkeyUser) WebAuthnName() string        { return u.id.String() }
+func (u passkeyUser) WebAuthnDisplayName() string { return u.id.String() }
+
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		id, err := security.DecodeWebAuthnBase64(c.CredentialId)
+		if err != nil {
+			continue
+		}
+		credentials = append(credentials, webauthn.Credential{
+			ID:        id,
+			PublicKey: c.PublicKey,
+			Flags:     webauthn.CredentialFlags{BackupEligible: c.BackupEligible, BackupState: c.BackedUp},
+			Authenticator: webauthn.Authenticator{
+				SignCount: uint32(c.SignCount),
+			},
+		})
+	}
+	return credentials
+}
+
+// find returns the stored passkey with the raw credential ID, or nil.
+func (u passkeyUser) find(rawID []byte) *models.WebAuthnCredential {
+	id := security.EncodeWebAuthnBase64(rawID)
+	for i := range u.credentials {
+		if u.credentials[i].CredentialId == id {
+			return &u.credentials[i]
+		}
+	}
+	return nil
+}
+
+// parseCredentialCreation decodes a registration response for go-webauthn.
+func parseCredentialCreation(credential *authrequests.PasskeyCredential) (*protocol.ParsedCredentialCreationData, error) {
+	rawID, clientDataJSON, err := decodePasskeyCredential(credential)
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := security.DecodeWebAuthnBase64(credential.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("attestationObject: %w", err)
+	}
+	return protocol.CredentialCreationResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: security.EncodeWebAuthnBase64(rawID), Type: credential.Type},
+			RawID:      rawID,
+		},
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AttestationObject:     attestation,
+			Transports:            credential.Response.Transports,
+		},
+	}.Parse()
+}
+
+// parseCredentialAssertion decodes a login response for go-webauthn.
+func parseCredentialAssertion(credential *authrequests.PasskeyCredential) (*protocol.ParsedCredentialAssertionData, error) {
+	rawID, clientDataJSON, err := decodePasskeyCredential(credential)
+	if err != nil {
+		return nil, err
+	}
+	resp := credential.Response
+	authenticatorData, err := security.DecodeWebAuthnBase64(resp.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("authenticatorData: %w", err)
+	}
+	signature, err := security.DecodeWebAuthnBase64(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+	userHandle, err := security.DecodeWebAuthnBase64(resp.UserHandle)
+	if err != nil {
+		return nil, fmt.Errorf("userHandle: %w", err)
+	}
+	return protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: security.EncodeWebAuthnBase64(rawID), Type: credential.Type},
+			RawID:      rawID,
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AuthenticatorData:     authenticatorData,
+			Signature:             signature,
+			UserHandle:            userHandle,
+		},
+	}.Parse()
+}
+
+func decodePasskeyCredential(credential *authrequests.PasskeyCredential) (rawID, clientDataJSON []byte, err error) {
+	if rawID, err = security.DecodeWebAuthnBase64(credential.ID); err != nil || len(rawID) == 0 {
+		return nil, nil, errors.New("invalid credential ID")
+	}
+	if clientDataJSON, err = security.DecodeWebAuthnBase64(credential.Response.ClientDataJSON); err != nil {
+		return nil, nil, fmt.Errorf("clientDataJSON: %w", err)
+	}
+	return rawID, clientDataJSON, nil
+}
+
+// passkeyUserHandle is the WebAuthn user.id: the 16 bytes of the user's UUID, base64url.
+func passkeyUserHandle(userID uuid.UUID) string {
+	return security.EncodeWebAuthnBase64(userID[:])
+}
+
+func credentialDescriptors(credentials []models.WebAuthnCredential) []responses.PasskeyCredentialDescriptor {
+	descriptors := make([]responses.PasskeyCredentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		descriptors[i] = responses.PasskeyCredentialDescriptor{
+			Type:       "public-key",
+			ID:         credential.CredentialId,
+			Transports: credential.TransportList(),
+		}
+	}
+	return descriptors
+}
+
+func formatAAGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
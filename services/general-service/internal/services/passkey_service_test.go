@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"general-service/internal/common/constants"
+	authrequests "general-service/internal/dto/auth/requests"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"general-service/internal/security"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const testPasskeyOrigin = "http://localhost:3000"
+
+// testAuthenticator is a software passkey with an ES256 key.
+type testAuthenticator struct {
+	t       *testing.T
+	key     *ecdsa.PrivateKey
+	id      []byte
+	counter uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{t: t, key: key, id: id}
+}
+
+func (a *testAuthenticator) clientData(ceremonyType, challenge string) []byte {
+	b, err := json.Marshal(map[string]any{"type": ceremonyType, "challenge": challenge, "origin": testPasskeyOrigin})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return b
+}
+
+func (a *testAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	return append(data, attested...)
+}
+
+func (a *testAuthenticator) create(challenge string) authrequests.PasskeyCredential {
+	coseKey, err := webauthncbor.Marshal(map[int]any{
+		1: 2, 3: security.COSEAlgES256, -1: 1,
+		-2: a.key.X.FillBytes(make([]byte, 32)), -3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	attested := append(make([]byte, 16), byte(len(a.id)>>8), byte(len(a.id)))
+	attested = append(append(attested, a.id...), coseKey...)
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(0x45, attested), // UP, UV, AT
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return authrequests.PasskeyCredential{
+		ID:   security.EncodeWebAuthnBase64(a.id),
+		Type: "public-key",
+		Response: authrequests.PasskeyCredentialResponse{
+			ClientDataJSON:    security.EncodeWebAuthnBase64(a.clientData("webauthn.create", challenge)),
+			AttestationObject: security.EncodeWebAuthnBase64(attestation),
+		},
+	}
+}
+
+func (a *testAuthenticator) get(challenge string, userID uuid.UUID) authrequests.PasskeyCredential {
+	a.counter++
+	clientData := a.clientData("webauthn.get", challenge)
+	authData := a.authData(0x05, nil) // UP, UV
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return authrequests.PasskeyCredential{
+		ID:   security.EncodeWebAuthnBase64(a.id),
+		Type: "public-key",
+		Response: authrequests.PasskeyCredentialResponse{
+			ClientDataJSON:    security.EncodeWebAuthnBase64(clientData),
+			AuthenticatorData: security.EncodeWebAuthnBase64(authData),
+			Signature:         security.EncodeWebAuthnBase64(signature),
+			UserHandle:        security.EncodeWebAuthnBase64(userID[:]),
+		},
+	}
+}
+
+func TestPasskeyCeremonies(t *testing.T) {
+	t.Setenv("WEBAUTHN_RP_ID", "")
+	t.Setenv("WEBAUTHN_ORIGINS", "")
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.WebAuthnCredential{}); err != nil {
+		t.Fatal(err)
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	s := NewPasskeyService(repositories.NewRepositories(db, nil), redisClient)
+
+	ctx := context.Background()
+	userID, otherUserID := uuid.New(), uuid.New()
+	authenticator := newTestAuthenticator(t)
+	challenge := func(kind string, userID uuid.UUID) string {
+		ceremony := passkeyCeremony{Kind: kind}
+		if userID != uuid.Nil {
+			ceremony.UserID = userID.String()
+		}
+		c, err := s.newCeremony(ctx, ceremony)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	registration := authenticator.create(challenge(passkeyCeremonyRegister, userID))
+	if _, err := s.FinishRegistration(ctx, userID, &authrequests.PasskeyRegisterRequest{Credential: registration}); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if _, err := s.FinishRegistration(ctx, userID, &authrequests.PasskeyRegisterRequest{Credential: registration}); !errors.Is(err, constants.ErrPasskeyChallengeExpired) {
+		t.Errorf("reused registration: %v, want %v", err, constants.ErrPasskeyChallengeExpired)
+	}
+
+	stored, err := s.Authenticate(ctx, ptr(authenticator.get(challenge(passkeyCeremonyLogin, uuid.Nil), userID)))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if stored.UserId != userID {
+		t.Errorf("Authenticate returned a passkey of user %s, want %s", stored.UserId, userID)
+	}
+	if err := s.VerifySecondFactor(ctx, userID, ptr(authenticator.get(challenge(passkeyCeremonyMFA, userID), userID))); err != nil {
+		t.Errorf("VerifySecondFactor: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		credential func() authrequests.PasskeyCredential
+		verify     func(*authrequests.PasskeyCredential) error
+		want       error
+	}{
+		{"counter did not move forward", func() authrequests.PasskeyCredential {
+			authenticator.counter--
+			return authenticator.get(challenge(passkeyCeremonyLogin, uuid.Nil), userID)
+		}, func(c *authrequests.PasskeyCredential) error { _, err := s.Authenticate(ctx, c); return err }, constants.ErrInvalidPasskey},
+		{"bad signature", func() authrequests.PasskeyCredential {
+			c := authenticator.get(challenge(passkeyCeremonyLogin, uuid.Nil), userID)
+			c.Response.Signature = security.EncodeWebAuthnBase64([]byte("not a signature"))
+			return c
+		}, func(c *authrequests.PasskeyCredential) error { _, err := s.Authenticate(ctx, c); return err }, constants.ErrInvalidPasskey},
+		{"wrong origin", func() authrequests.PasskeyCredential {
+			c := authenticator.get(challenge(passkeyCeremonyLogin, uuid.Nil), userID)
+			clientData, _ := security.DecodeWebAuthnBase64(c.Response.ClientDataJSON)
+			c.Response.ClientDataJSON = security.EncodeWebAuthnBase64(bytes.Replace(clientData, []byte(testPasskeyOrigin), []byte("https://evil.example"), 1))
+			return c
+		}, func(c *authrequests.PasskeyCredential) error { _, err := s.Authenticate(ctx, c); return err }, constants.ErrInvalidPasskey},
+		{"second factor for another user", func() authrequests.PasskeyCredential {
+			return authenticator.get(challenge(passkeyCeremonyMFA, otherUserID), userID)
+		}, func(c *authrequests.PasskeyCredential) error { return s.VerifySecondFactor(ctx, otherUserID, c) }, constants.ErrPasskeyNotFound},
+	}
+	for _, tt := range tests {
+		credential := tt.credential()
+		if err := tt.verify(&credential); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
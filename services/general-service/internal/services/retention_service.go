@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/common"
+	"general-service/internal/dto/user/responses"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// retentionAnchorEventEnd marks rules whose period counts from the end of the convention
+// (EVENT_END_DATE); they cover the users who registered up to that day.
+const retentionAnchorEventEnd = "event_end"
+
+const (
+	// retentionBatchSize is how many rows one transaction purges.
+	retentionBatchSize = 500
+	// retentionMaxBatches caps the batches per rule and run so one run fits in the job timeout;
+	// the rest is purged by the next run.
+	retentionMaxBatches = 10
+)
+
+// RetentionTriggeredBySchedule is recorded as TriggeredBy for runs of the scheduled job.
+const RetentionTriggeredBySchedule = "schedule"
+
+// retentionRule keeps one kind of personal data for Days after its anchor. The period can be
+// changed, or the rule switched off, with RETENTION_<NAME>.
+type retentionRule struct {
+	Target      repositories.RetentionTarget
+	Description string
+	Anchor      string // retentionAnchorEventEnd or the row's timestamp column
+	DefaultDays int
+}
+
+// retentionRules is the data retention policy. Rules run in this order.
+var retentionRules = []retentionRule{
+	{repositories.RetentionTargetUserIdCard, "ID card numbers of attendees", retentionAnchorEventEnd, 60},
+	{repositories.RetentionTargetUserDateOfBirth, "Dates of birth of attendees", retentionAnchorEventEnd, 60},
+	{repositories.RetentionTargetTicketDenialReason, "Ticket denial reasons", "denied_at", 365},
+	{repositories.RetentionTargetTicketUpgradeDenialReason, "Ticket upgrade denial reasons", "modified_at", 365},
+	{repositories.RetentionTargetUnverifiedUser, "Accounts never verified, with no ticket, booth or submission", "created_at", 30},
+	{repositories.RetentionTargetSession, "Expired login sessions", "expires_at", 90},
+	{repositories.RetentionTargetRefreshToken, "Expired refresh tokens", "expires_at", 90},
+	{repositories.RetentionTargetDataExportArchive, "Personal data export archives past their download link", "expires_at", 0},
+	{repositories.RetentionTargetEmailLog, "Log of emails sent", "sent_at", 730},
+	{repositories.RetentionTargetUserFingerprint, "Sign-in IP and device fingerprints for duplicate account detection", "last_seen_at", 365},
+}
+
+// schedule returns the rule's effective period and the cutoff at or before which rows are due.
+// cutoff is nil, with the reason in skipped, when the rule does not run now.
+func (r retentionRule) schedule(now time.Time) (days int, enabled bool, cutoff *time.Time, skipped string) {
+	days, enabled = utils.GetRetentionPeriod(string(r.Target), r.DefaultDays)
+	if !enabled {
+		return days, false, nil, "disabled"
+	}
+	if r.Anchor != retentionAnchorEventEnd {
+		before := now.AddDate(0, 0, -days)
+		return days, true, &before, ""
+	}
+	end, ok := utils.GetEventEndDate()
+	if !ok {
+		return days, true, nil, "EVENT_END_DATE is not set"
+	}
+	if now.Before(end.AddDate(0, 0, days)) {
+		return days, true, nil, fmt.Sprintf("kept until %s", end.AddDate(0, 0, days).Format("2006-01-02"))
+	}
+	return days, true, &end, ""
+}
+
+// RetentionService applies the data retention policy: the scheduled purge job removes personal
+// data once its retention period is over and records what it removed.
+type RetentionService struct {
+	repos *repositories.Repositories
+}
+
+func NewRetentionService(repos *repositories.Repositories) *RetentionService {
+	return &RetentionService{repos: repos}
+}
+
+// GetRules returns the retention rules with their configuration as of now.
+func (s *RetentionService) GetRules() []*responses.RetentionRuleResponse {
+	now := time.Now()
+	result := make([]*responses.RetentionRuleResponse, len(retentionRules))
+	for i, rule := range retentionRules {
+		days, enabled, cutoff, skipped := rule.schedule(now)
+		result[i] = &responses.RetentionRuleResponse{
+			Name:        string(rule.Target),
+			Description: rule.Description,
+			Action:      string(repositories.RetentionTargetAction(rule.Target)),
+			Anchor:      rule.Anchor,
+			Days:        days,
+			Enabled:     enabled,
+			Cutoff:      cutoff,
+			Skipped:     skipped,
+		}
+	}
+	return result
+}
+
+// RunScheduledPurge is the scheduled purge job. It is a dry run until RETENTION_PURGE_ENABLED is set.
+func (s *RetentionService) RunScheduledPurge(ctx context.Context) (*responses.RetentionRunResponse, error) {
+	return s.RunPurge(ctx, !utils.IsRetentionPurgeEnabled(), RetentionTriggeredBySchedule)
+}
+
+// RunPurge applies every rule that can run now and writes one purge log entry per rule. A dry run
+// only counts the rows that are due. A failing rule is reported and the run goes on; failing to
+// write the log stops the run, so nothing is purged without a record.
+func (s *RetentionService) RunPurge(ctx context.Context, dryRun bool, triggeredBy string) (*responses.RetentionRunResponse, error) {
+	now := time.Now()
+	run := &responses.RetentionRunResponse{RunId: uuid.New(), DryRun: dryRun}
+	for _, rule := range retentionRules {
+		action := repositories.RetentionTargetAction(rule.Target)
+		_, _, cutoff, skipped := rule.schedule(now)
+		result := &responses.RetentionRuleResult{Rule: string(rule.Target), Action: string(action), Cutoff: cutoff, Skipped: skipped}
+		run.Rules = append(run.Rules, result)
+		if cutoff == nil {
+			continue
+		}
+
+		entry := &models.RetentionPurgeLog{
+			Id:          uuid.New(),
+			RunId:       run.RunId,
+			Rule:        string(rule.Target),
+			Action:      action,
+			Cutoff:      *cutoff,
+			DryRun:      dryRun,
+			TriggeredBy: triggeredBy,
+		}
+		var err error
+		if dryRun {
+			entry.Affected, err = s.repos.Retention.CountDue(ctx, rule.Target, *cutoff)
+		} else {
+			entry.RecordIds, err = s.purgeRule(ctx, rule.Target, *cutoff)
+			entry.Affected = int64(len(entry.RecordIds))
+		}
+		result.Affected = entry.Affected
+		if err != nil {
+			log.Printf("[ERROR] Retention rule %s failed: %v", rule.Target, err)
+			entry.Error = err.Error()
+			result.Error = err.Error()
+			run.Failed++
+		}
+		// Rows already purged must be logged even if the caller gave up waiting.
+		if err := s.repos.Retention.CreateLog(context.WithoutCancel(ctx), entry); err != nil {
+			return nil, fmt.Errorf("record retention purge of %s: %w", rule.Target, err)
+		}
+	}
+	return run, nil
+}
+
+// purgeRule purges the due rows of a rule batch by batch and returns the IDs purged, including
+// the batches done before an error.
+func (s *RetentionService) purgeRule(ctx context.Context, target repositories.RetentionTarget, cutoff time.Time) ([]uuid.UUID, error) {
+	var purged []uuid.UUID
+	for i := 0; i < retentionMaxBatches; i++ {
+		ids, err := s.repos.Retention.PurgeBatch(ctx, target, cutoff, retentionBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged = append(purged, ids...)
+		if len(ids) < retentionBatchSize {
+			break
+		}
+	}
+	return purged, nil
+}
+
+// GetLogs returns purge log entries, newest first, optionally filtered by rule and run.
+func (s *RetentionService) GetLogs(ctx context.Context, page, pageSize int, rule string, runID *uuid.UUID) ([]*models.RetentionPurgeLog, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	logs, total, err := s.repos.Retention.FindLogs(ctx, page, pageSize, rule, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return logs, meta, nil
+}
+
+// IsRetentionRule reports whether name is one of the retention rules.
+func IsRetentionRule(name string) bool {
+	for _, rule := range retentionRules {
+		if string(rule.Target) == name {
+			return true
+		}
+	}
+	return false
+}
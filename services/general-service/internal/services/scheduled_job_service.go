@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+)
+
+// ScheduledJobService exposes the run status the sqs-worker scheduler records for each job.
+type ScheduledJobService struct {
+	repos *repositories.Repositories
+}
+
+func NewScheduledJobService(repos *repositories.Repositories) *ScheduledJobService {
+	return &ScheduledJobService{repos: repos}
+}
+
+// GetRuns returns the latest run of every scheduled job.
+func (s *ScheduledJobService) GetRuns(ctx context.Context) ([]models.ScheduledJobRun, error) {
+	return s.repos.ScheduledJob.FindAll(ctx)
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"general-service/internal/audit"
+	"general-service/internal/common/constants"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/auth/requests"
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/dto/common"
+	"general-service/internal/mappers"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyTouchInterval is how often an API key's last-used time and IP are written while it is in use.
+const apiKeyTouchInterval = time.Minute
+
+// ServiceAccountService manages the service accounts partner integrations use to call the admin
+// API, and authenticates their API keys.
+type ServiceAccountService struct {
+	repos *repositories.Repositories
+}
+
+func NewServiceAccountService(repos *repositories.Repositories) *ServiceAccountService {
+	return &ServiceAccountService{repos: repos}
+}
+
+// CreateServiceAccount registers a partner integration. It has no keys yet.
+func (s *ServiceAccountService) CreateServiceAccount(ctx context.Context, adminID string, req *requests.CreateServiceAccountRequest) (*responses.ServiceAccountResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	name := strings.TrimSpace(req.Name)
+	exists, err := s.repos.ServiceAccount.ExistsByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, constants.ErrServiceAccountExists
+	}
+	account := &models.ServiceAccount{
+		Id:          uuid.New(),
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		CreatedBy:   adminUUID,
+	}
+	if err := s.repos.ServiceAccount.Create(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+	audit.Record(ctx, "service_accounts", account.Id.String(), nil, account)
+	return mappers.MapServiceAccountToResponse(account), nil
+}
+
+// GetServiceAccounts returns service accounts and their keys, by name.
+func (s *ServiceAccountService) GetServiceAccounts(ctx context.Context, page, pageSize int) ([]*responses.ServiceAccountResponse, *common.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	accounts, total, err := s.repos.ServiceAccount.FindAll(ctx, page, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*responses.ServiceAccountResponse, len(accounts))
+	for i, account := range accounts {
+		result[i] = mappers.MapServiceAccountToResponse(account)
+	}
+	meta := &common.PaginationMeta{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		TotalPages:  int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalItems:  total,
+	}
+	return result, meta, nil
+}
+
+// GetServiceAccount returns a service account and its keys.
+func (s *ServiceAccountService) GetServiceAccount(ctx context.Context, accountID string) (*responses.ServiceAccountResponse, error) {
+	account, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return mappers.MapServiceAccountToResponse(account), nil
+}
+
+// DisableServiceAccount disables the service account and revokes all its keys.
+func (s *ServiceAccountService) DisableServiceAccount(ctx context.Context, adminID, accountID string) (*responses.ServiceAccountResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	account, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.DisabledAt != nil {
+		return nil, constants.ErrServiceAccountDisabled
+	}
+	before := audit.Snapshot(ctx, account)
+	if err := s.repos.ServiceAccount.Disable(ctx, account, time.Now(), adminUUID); err != nil {
+		return nil, fmt.Errorf("failed to disable service account: %w", err)
+	}
+	audit.Record(ctx, "service_accounts", account.Id.String(), before, account)
+	return s.GetServiceAccount(ctx, accountID)
+}
+
+// CreateAPIKey issues a key to the service account. The response holds the key, which is not
+// stored and cannot be shown again.
+func (s *ServiceAccountService) CreateAPIKey(ctx context.Context, adminID, accountID string, req *requests.CreateAPIKeyRequest) (*responses.IssuedAPIKeyResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	account, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.DisabledAt != nil {
+		return nil, constants.ErrServiceAccountDisabled
+	}
+	scopes, err := parseAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	key, secret, err := newAPIKey(account.Id, strings.TrimSpace(req.Name), scopes, expiresAt, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.ServiceAccount.CreateKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	audit.Record(ctx, "service_accounts.keys", key.Id.String(), nil, key)
+	return &responses.IssuedAPIKeyResponse{APIKeyResponse: *mappers.MapAPIKeyToResponse(key), Key: secret}, nil
+}
+
+// RotateAPIKey replaces an active key with a new one of the same name, scopes and lifetime. The
+// old key is revoked straight away, or expires after the grace period.
+func (s *ServiceAccountService) RotateAPIKey(ctx context.Context, adminID, accountID, keyID string, req *requests.RotateAPIKeyRequest) (*responses.IssuedAPIKeyResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	account, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.DisabledAt != nil {
+		return nil, constants.ErrServiceAccountDisabled
+	}
+	old, err := s.findKey(ctx, account.Id, keyID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !old.IsActive(now) {
+		return nil, constants.ErrAPIKeyRevoked
+	}
+
+	var expiresAt *time.Time
+	if old.ExpiresAt != nil {
+		t := now.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		expiresAt = &t
+	}
+	next, secret, err := newAPIKey(account.Id, old.Name, old.Scopes, expiresAt, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+	next.RotatedFromId = &old.Id
+
+	before := audit.Snapshot(ctx, old)
+	oldExpiresAt := now.Add(time.Duration(req.GracePeriodMinutes) * time.Minute)
+	if err := s.repos.ServiceAccount.RotateKey(ctx, old, next, oldExpiresAt, now, adminUUID); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+	audit.Record(ctx, "service_accounts.keys", old.Id.String(), before, old)
+	audit.Record(ctx, "service_accounts.keys", next.Id.String(), nil, next)
+	return &responses.IssuedAPIKeyResponse{APIKeyResponse: *mappers.MapAPIKeyToResponse(next), Key: secret}, nil
+}
+
+// RevokeAPIKey stops a key from working at once.
+func (s *ServiceAccountService) RevokeAPIKey(ctx context.Context, adminID, accountID, keyID string) (*responses.APIKeyResponse, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, constants.ErrInvalidUserID
+	}
+	account, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.findKey(ctx, account.Id, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, constants.ErrAPIKeyRevoked
+	}
+	before := audit.Snapshot(ctx, key)
+	now := time.Now()
+	key.RevokedAt = &now
+	key.RevokedBy = &adminUUID
+	if err := s.repos.ServiceAccount.SaveKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	audit.Record(ctx, "service_accounts.keys", key.Id.String(), before, key)
+	return mappers.MapAPIKeyToResponse(key), nil
+}
+
+// AuthenticateAPIKey returns the service account of an active key of an enabled account, or
+// ErrInvalidAPIKey. Its last-used time and IP are recorded, at most once a minute.
+func (s *ServiceAccountService) AuthenticateAPIKey(ctx context.Context, rawKey, ip string) (*utils.APIKeyPrincipal, error) {
+	prefix, ok := utils.APIKeyPrefix(rawKey)
+	if !ok {
+		return nil, constants.ErrInvalidAPIKey
+	}
+	key, err := s.repos.ServiceAccount.FindKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !utils.APIKeyMatches(rawKey, key.SecretHash) || !key.IsActive(now) || key.ServiceAccount.DisabledAt != nil {
+		return nil, constants.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repos.ServiceAccount.TouchKey(ctx, key.Id, now, truncate(ip, 64), apiKeyTouchInterval); err != nil {
+			log.Printf("[WARN] Failed to record use of API key %s: %v", key.Prefix, err)
+		}
+	}
+	return &utils.APIKeyPrincipal{
+		ServiceAccountID: key.ServiceAccountId.String(),
+		ServiceAccount:   key.ServiceAccount.Name,
+		KeyID:            key.Id.String(),
+		Scopes:           key.Scopes,
+	}, nil
+}
+
+func (s *ServiceAccountService) findAccount(ctx context.Context, accountID string) (*models.ServiceAccount, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, constants.ErrServiceAccountNotFound
+	}
+	account, err := s.repos.ServiceAccount.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *ServiceAccountService) findKey(ctx context.Context, accountID uuid.UUID, keyID string) (*models.APIKey, error) {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, constants.ErrAPIKeyNotFound
+	}
+	key, err := s.repos.ServiceAccount.FindKey(ctx, accountID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// newAPIKey generates a key for the service account, returning the row to store and the key to
+// hand out once.
+func newAPIKey(accountID uuid.UUID, name string, scopes []constants.APIKeyScope, expiresAt *time.Time, createdBy uuid.UUID) (*models.APIKey, string, error) {
+	secret, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return &models.APIKey{
+		Id:               uuid.New(),
+		ServiceAccountId: accountID,
+		Name:             name,
+		Prefix:           prefix,
+		SecretHash:       utils.HashAPIKey(secret),
+		Scopes:           scopes,
+		ExpiresAt:        expiresAt,
+		CreatedBy:        createdBy,
+	}, secret, nil
+}
+
+// parseAPIKeyScopes validates and de-duplicates requested scopes.
+func parseAPIKeyScopes(values []string) ([]constants.APIKeyScope, error) {
+	var scopes []constants.APIKeyScope
+	for _, value := range values {
+		scope := constants.APIKeyScope(value)
+		if !scope.IsValid() {
+			return nil, constants.ErrInvalidAPIKeyScope
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, constants.ErrInvalidAPIKeyScope
+	}
+	return scopes, nil
+}
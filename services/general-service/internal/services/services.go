@@ -1,35 +1,65 @@
 package services
 
 import (
+	"general-service/internal/common/constants"
 	"general-service/internal/repositories"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Services struct {
-	Auth      *AuthService
-	User      *UserService
-	Mail      *MailService
-	Ticket    *TicketService
-	Dealer    *DealerService
-	Conbook   *ConbookService
-	Panel     *PanelService
-	Talent    *TalentService
-	Analytics *AnalyticsService
+	Auth           *AuthService
+	Session        *SessionService
+	MFA            *MFAService
+	Passkey        *PasskeyService
+	OAuth          *OAuthService
+	User           *UserService
+	Mail           *MailService
+	Ticket         *TicketService
+	Dealer         *DealerService
+	Conbook        *ConbookService
+	Panel          *PanelService
+	Talent         *TalentService
+	Analytics      *AnalyticsService
+	ScheduledJob   *ScheduledJobService
+	DataExport     *DataExportService
+	Deletion       *AccountDeletionService
+	Retention      *RetentionService
+	Audit          *AuditService
+	Impersonation  *ImpersonationService
+	ServiceAccount *ServiceAccountService
+	Fraud          *FraudService
 }
 
-func NewServices(repos *repositories.Repositories, redisClient *redis.Client, loginMaxFail int, loginFailBlockMinutes int) *Services {
+func NewServices(repos *repositories.Repositories, redisClient *redis.Client, loginMaxFail int, loginFailBlockMinutes int, mfaRequiredRoles []constants.UserRole) *Services {
 	mail := NewMailService(repos)
-	ticket := NewTicketService(repos, mail)
+	session := NewSessionService(repos, redisClient)
+	fraud := NewFraudService(repos, session)
+	ticket := NewTicketService(repos, mail, fraud, session)
+	passkey := NewPasskeyService(repos, redisClient)
+	mfa := NewMFAService(repos, redisClient, session, passkey, mfaRequiredRoles, loginMaxFail, loginFailBlockMinutes)
+	impersonation := NewImpersonationService(repos, redisClient, mail)
 	return &Services{
-		Auth:      NewAuthService(repos, redisClient, loginMaxFail, loginFailBlockMinutes),
-		User:      NewUserService(repos),
-		Mail:      mail,
-		Ticket:    ticket,
-		Dealer:    NewDealerService(repos, mail),
-		Conbook:   NewConbookService(repos),
-		Panel:     NewPanelService(repos),
-		Talent:    NewTalentService(repos),
-		Analytics: NewAnalyticsService(repos, ticket),
+		Auth:           NewAuthService(repos, redisClient, session, mfa, passkey, impersonation, loginMaxFail, loginFailBlockMinutes),
+		Session:        session,
+		MFA:            mfa,
+		Passkey:        passkey,
+		OAuth:          NewOAuthService(repos, redisClient, mfa, mail),
+		User:           NewUserService(repos, session),
+		Mail:           mail,
+		Ticket:         ticket,
+		Dealer:         NewDealerService(repos, mail),
+		Conbook:        NewConbookService(repos),
+		Panel:          NewPanelService(repos),
+		Talent:         NewTalentService(repos),
+		Analytics:      NewAnalyticsService(repos, ticket, mail),
+		ScheduledJob:   NewScheduledJobService(repos),
+		DataExport:     NewDataExportService(repos, mail),
+		Deletion:       NewAccountDeletionService(repos, session, mail),
+		Retention:      NewRetentionService(repos),
+		Audit:          NewAuditService(repos),
+		Impersonation:  impersonation,
+		ServiceAccount: NewServiceAccountService(repos),
+		Fraud:          fraud,
 	}
 }
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"general-service/internal/common/utils"
+	"general-service/internal/dto/auth/responses"
+	"general-service/internal/models"
+	"general-service/internal/repositories"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Reasons recorded on revoked sessions
+const (
+	SessionRevokedLogout        = "logout"
+	SessionRevokedByUser        = "revoked_by_user"
+	SessionRevokedByAdmin       = "revoked_by_admin"
+	SessionRevokedTokenReuse    = "refresh_token_reuse"
+	SessionRevokedBlacklisted   = "blacklisted"
+	SessionRevokedDeleted       = "deleted"
+	SessionRevokedRoleChanged   = "role_changed"
+	SessionRevokedPasswordReset = "password_reset"
+	SessionRevokedMFAReset      = "mfa_reset"
+	SessionRevokedEmailChanged  = "email_changed"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ClientInfo identifies the device a session was started from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionService keeps the registry of signed-in devices (UserSession) and revokes them. Revoked
+// sessions lose their refresh tokens in Postgres, and their live access tokens are denied in
+// Redis until they expire (checked by JWTAuthMiddleware).
+type SessionService struct {
+	repos       *repositories.Repositories
+	redisClient *redis.Client
+}
+
+func NewSessionService(repos *repositories.Repositories, redisClient *redis.Client) *SessionService {
+	return &SessionService{repos: repos, redisClient: redisClient}
+}
+
+// Start creates a session for user and issues its first token pair.
+func (s *SessionService) Start(ctx context.Context, user *models.User, client ClientInfo) (*responses.LoginResponse, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		Id:         uuid.New(),
+		UserId:     user.Id,
+		UserAgent:  truncate(client.UserAgent, 500),
+		IpAddress:  client.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.GetRefreshTokenExpiry()),
+	}
+	if err := s.repos.Session.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	// Sign-in fingerprints feed duplicate account detection; failing to keep them does not fail the login.
+	for kind, value := range map[string]string{models.FingerprintKindIP: client.IPAddress, models.FingerprintKindDevice: client.UserAgent} {
+		if err := s.repos.Fraud.RecordFingerprint(ctx, user.Id, kind, value, now); err != nil {
+			log.Printf("[WARN] Failed to record %s fingerprint for user %s: %v", kind, user.Id, err)
+		}
+	}
+	return s.issue(ctx, user, session.Id, client)
+}
+
+// issue mints an access token and a refresh token for an existing session.
+func (s *SessionService) issue(ctx context.Context, user *models.User, sessionID uuid.UUID, client ClientInfo) (*responses.LoginResponse, error) {
+	pair, err := utils.CreateTokenPair(user.Id, user.Email, user.FursonaName, user.Role.String(), sessionID.String())
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(utils.GetRefreshTokenExpiry())
+	token := &models.RefreshToken{
+		Id:        uuid.New(),
+		UserId:    user.Id,
+		FamilyId:  sessionID,
+		TokenHash: utils.HashRefreshToken(pair.RefreshToken),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repos.RefreshToken.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	if err := s.repos.Session.Touch(ctx, sessionID, pair.AccessTokenID, truncate(client.UserAgent, 500), client.IPAddress, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+	return &responses.LoginResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken}, nil
+}
+
+// ListActive returns the user's active sessions.
+func (s *SessionService) ListActive(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	return s.repos.Session.FindActiveByUser(ctx, userID)
+}
+
+// RevokeForUser revokes one of the user's own sessions.
+func (s *SessionService) RevokeForUser(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.repos.Session.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserId != userID {
+		return ErrSessionNotFound
+	}
+	return s.Revoke(ctx, SessionRevokedByUser, sessionID)
+}
+
+// Revoke revokes the given sessions.
+func (s *SessionService) Revoke(ctx context.Context, reason string, sessionIDs ...uuid.UUID) error {
+	revoked, err := s.repos.Session.Revoke(ctx, sessionIDs, reason)
+	if err != nil {
+		return err
+	}
+	return s.deny(ctx, revoked)
+}
+
+// RevokeAllForUser signs the user out everywhere except keepSessionID (uuid.Nil keeps none). When
+// no session is kept, access tokens issued before sessions existed are denied too.
+func (s *SessionService) RevokeAllForUser(ctx context.Context, userID, keepSessionID uuid.UUID, reason string) (int, error) {
+	active, err := s.repos.Session.FindActiveByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]uuid.UUID, 0, len(active))
+	for _, session := range active {
+		if session.Id != keepSessionID {
+			ids = append(ids, session.Id)
+		}
+	}
+	revoked, err := s.repos.Session.Revoke(ctx, ids, reason)
+	if err != nil {
+		return 0, err
+	}
+	if keepSessionID == uuid.Nil {
+		if err := utils.DenyUserTokensIssuedBefore(ctx, s.redisClient, userID.String(), time.Now()); err != nil {
+			return len(revoked), err
+		}
+	}
+	return len(revoked), s.deny(ctx, revoked)
+}
+
+// deny blocks the live access tokens of revoked sessions.
+func (s *SessionService) deny(ctx context.Context, sessions []models.UserSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.Id.String()
+	}
+	if err := utils.DenySessions(ctx, s.redisClient, ids...); err != nil {
+		return fmt.Errorf("failed to deny session tokens: %w", err)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether a validated access token belongs to a revoked session
+// or user. Used by JWTAuthMiddleware.
+func (s *SessionService) IsAccessTokenRevoked(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	return utils.IsAccessTokenDenied(ctx, s.redisClient, claims)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"general-service/internal/audit"
 	"general-service/internal/dto/talent/requests"
 	"general-service/internal/dto/talent/responses"
 	"general-service/internal/mappers"
@@ -263,6 +264,7 @@ func (s *TalentService) setTalentStatus(ctx context.Context, talentIDStr string,
 		return nil, ErrStatusUnchanged
 	}
 
+	before := audit.Snapshot(ctx, talent)
 	if err := s.repos.Talent.SetTalentStatus(ctx, talentID, status); err != nil {
 		log.Printf("Error setting talent status: %v", err)
 		return nil, err
@@ -272,6 +274,7 @@ func (s *TalentService) setTalentStatus(ctx context.Context, talentIDStr string,
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "talents", talentID.String(), before, updated)
 
 	resp := mappers.MapTalentToResponse(updated)
 	return &resp, nil
@@ -304,6 +307,7 @@ func (s *TalentService) AssignTalentSchedule(ctx context.Context, talentIDStr st
 		return nil, ErrTalentNotSchedulable
 	}
 
+	before := audit.Snapshot(ctx, talent)
 	if err := s.repos.Talent.SetTalentSchedule(ctx, talentID, req.SlotLabel, req.ScheduledStartAt); err != nil {
 		log.Printf("Error assigning talent schedule: %v", err)
 		return nil, err
@@ -313,6 +317,7 @@ func (s *TalentService) AssignTalentSchedule(ctx context.Context, talentIDStr st
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "talents", talentID.String(), before, updated)
 
 	resp := mappers.MapTalentToResponse(updated)
 	return &resp, nil
@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"general-service/internal/audit"
 	"general-service/internal/common/constants"
 	"general-service/internal/dto/common"
 	"general-service/internal/dto/ticket/requests"
@@ -13,9 +16,11 @@ import (
 	"log"
 	"math"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // Re-export sentinel errors from constants for backward compatibility
@@ -28,12 +33,14 @@ var (
 )
 
 type TicketService struct {
-	repos *repositories.Repositories
-	mail  *MailService
+	repos    *repositories.Repositories
+	mail     *MailService
+	fraud    *FraudService
+	sessions *SessionService
 }
 
-func NewTicketService(repos *repositories.Repositories, mail *MailService) *TicketService {
-	return &TicketService{repos: repos, mail: mail}
+func NewTicketService(repos *repositories.Repositories, mail *MailService, fraud *FraudService, sessions *SessionService) *TicketService {
+	return &TicketService{repos: repos, mail: mail, fraud: fraud, sessions: sessions}
 }
 
 // ========== Public User Endpoints ==========
@@ -121,6 +128,7 @@ func (s *TicketService) CreateTierForAdmin(ctx context.Context, req *requests.Cr
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets.tiers", created.Id.String(), nil, created)
 	return mappers.MapTicketTierToResponse(created), nil
 }
 
@@ -168,10 +176,12 @@ func (s *TicketService) UpdateTierForAdmin(ctx context.Context, tierID string, r
 		}
 		return mappers.MapTicketTierToResponse(tier), nil
 	}
+	before := audit.Snapshot(ctx, s.auditedTier(ctx, id))
 	tier, err := s.repos.Ticket.UpdateTier(ctx, id, updates)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets.tiers", tier.Id.String(), before, tier)
 	return mappers.MapTicketTierToResponse(tier), nil
 }
 
@@ -181,7 +191,12 @@ func (s *TicketService) DeleteTierForAdmin(ctx context.Context, tierID string) e
 	if err != nil {
 		return ErrInvalidTierID
 	}
-	return s.repos.Ticket.DeleteTier(ctx, id)
+	before := audit.Snapshot(ctx, s.auditedTier(ctx, id))
+	if err := s.repos.Ticket.DeleteTier(ctx, id); err != nil {
+		return err
+	}
+	audit.Record(ctx, "tickets.tiers", id.String(), before, nil)
+	return nil
 }
 
 // SetTierActiveForAdmin sets is_active for a ticket tier (admin only).
@@ -190,10 +205,12 @@ func (s *TicketService) SetTierActiveForAdmin(ctx context.Context, tierID string
 	if err != nil {
 		return nil, ErrInvalidTierID
 	}
+	before := audit.Snapshot(ctx, s.auditedTier(ctx, id))
 	tier, err := s.repos.Ticket.SetTierActive(ctx, id, active)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets.tiers", tier.Id.String(), before, tier)
 	return mappers.MapTicketTierToResponse(tier), nil
 }
 
@@ -203,10 +220,12 @@ func (s *TicketService) SetTierVisibleForAdmin(ctx context.Context, tierID strin
 	if err != nil {
 		return nil, ErrInvalidTierID
 	}
+	before := audit.Snapshot(ctx, s.auditedTier(ctx, id))
 	tier, err := s.repos.Ticket.SetTierVisible(ctx, id, visible)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets.tiers", tier.Id.String(), before, tier)
 	return mappers.MapTicketTierToResponse(tier), nil
 }
 
@@ -245,6 +264,11 @@ func (s *TicketService) PurchaseTicket(ctx context.Context, userID string, req *
 		return nil, err
 	}
 
+	// Screen for duplicate accounts; a ticket left unscreened is picked up by the screening job.
+	if _, err := s.fraud.ScreenTicket(ctx, ticket); err != nil {
+		log.Printf("[WARN] Failed to screen ticket %s for fraud: %v", ticket.ReferenceCode, err)
+	}
+
 	return mappers.MapUserTicketToResponse(ticket, false), nil
 }
 
@@ -440,10 +464,12 @@ func (s *TicketService) ConfirmCheckIn(ctx context.Context, ticketIDOrRef, staff
 	if err != nil {
 		return nil, ErrInvalidUserID
 	}
+	before := audit.Snapshot(ctx, ticket)
 	updated, err := s.repos.Ticket.UpdateTicketForAdmin(ctx, ticket.Id, map[string]interface{}{"is_checked_in": true}, sid)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", updated.Id.String(), before, updated)
 	return mappers.MapUserTicketToResponse(updated, true), nil
 }
 
@@ -459,10 +485,17 @@ func (s *TicketService) ApproveTicket(ctx context.Context, ticketID string, staf
 		return nil, ErrInvalidUserID
 	}
 
+	// Purchases made through the queue may not have been screened yet; approval refuses those
+	if _, err := s.fraud.ScreenPurchase(ctx, tid); err != nil && !errors.Is(err, repositories.ErrTicketNotFound) {
+		log.Printf("[WARN] Failed to screen ticket %s before approval: %v", tid, err)
+	}
+
+	before := audit.Snapshot(ctx, s.auditedTicket(ctx, tid))
 	ticket, err := s.repos.Ticket.ApproveTicket(ctx, tid, sid)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", ticket.Id.String(), before, ticket)
 
 	// Send ticket approved email with QR code to the user
 	if s.mail != nil && ticket.User.Email != "" {
@@ -493,10 +526,15 @@ func (s *TicketService) DenyTicket(ctx context.Context, ticketID string, staffID
 		return nil, ErrInvalidUserID
 	}
 
+	before := audit.Snapshot(ctx, s.auditedTicket(ctx, tid))
 	ticket, err := s.repos.Ticket.DenyTicket(ctx, tid, sid, req.Reason)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", ticket.Id.String(), before, ticket)
+	if blacklistedByDenial(ticket) {
+		signOutBlacklisted(ctx, s.sessions, ticket.UserId)
+	}
 
 	// Send ticket denied email to the user (best-effort)
 	if s.mail != nil && ticket.User.Email != "" && ticket.Status == models.TicketStatusDenied {
@@ -592,6 +630,7 @@ func (s *TicketService) CreateTicketForAdmin(ctx context.Context, staffID string
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", ticket.Id.String(), nil, ticket)
 
 	return mappers.MapUserTicketToResponse(ticket, true), nil
 }
@@ -644,10 +683,12 @@ func (s *TicketService) UpdateTicketForAdmin(ctx context.Context, ticketID, staf
 		return s.GetTicketByID(ctx, ticketID)
 	}
 
+	before := audit.Snapshot(ctx, s.auditedTicket(ctx, tid))
 	ticket, err := s.repos.Ticket.UpdateTicketForAdmin(ctx, tid, updates, sid)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", ticket.Id.String(), before, ticket)
 
 	return mappers.MapUserTicketToResponse(ticket, true), nil
 }
@@ -659,10 +700,12 @@ func (s *TicketService) DeleteTicketForAdmin(ctx context.Context, ticketID strin
 		return nil, ErrInvalidTicketID
 	}
 
+	before := audit.Snapshot(ctx, s.auditedTicket(ctx, tid))
 	ticket, err := s.repos.Ticket.DeleteTicketForAdmin(ctx, tid)
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(ctx, "tickets", ticket.Id.String(), before, ticket)
 
 	return mappers.MapUserTicketToResponse(ticket, true), nil
 }
@@ -701,7 +744,71 @@ func (s *TicketService) BlacklistUser(ctx context.Context, userID string, req *r
 		return ErrInvalidUserID
 	}
 
-	return s.repos.Ticket.BlacklistUser(ctx, id, req.Reason)
+	return blacklistUser(ctx, s.repos, s.sessions, id, req.Reason)
+}
+
+// SignOutBlacklistedUser revokes the sessions of a user the sqs-worker blacklisted automatically
+// while denying a ticket. Users who are not blacklisted are left signed in.
+func (s *TicketService) SignOutBlacklistedUser(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+	user, err := s.repos.User.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Deleted accounts have no sessions left
+		}
+		return err
+	}
+	if user.IsBlacklisted {
+		signOutBlacklisted(ctx, s.sessions, id)
+	}
+	return nil
+}
+
+// blacklistUser blacklists the user and signs them out everywhere, recording the change in the
+// audit log. Shared by the admin blacklist and the fraud review.
+func blacklistUser(ctx context.Context, repos *repositories.Repositories, sessions *SessionService, userID uuid.UUID, reason string) error {
+	before := audit.Snapshot(ctx, auditedUser(ctx, repos, userID.String()))
+	if err := repos.Ticket.BlacklistUser(ctx, userID, reason); err != nil {
+		return err
+	}
+	audit.Record(ctx, "users", userID.String(), before, auditedUser(ctx, repos, userID.String()))
+	signOutBlacklisted(ctx, sessions, userID)
+	return nil
+}
+
+// signOutBlacklisted revokes every session of a blacklisted user. Best-effort: the blacklist is
+// already saved and still blocks purchases.
+func signOutBlacklisted(ctx context.Context, sessions *SessionService, userID uuid.UUID) {
+	if _, err := sessions.RevokeAllForUser(ctx, userID, uuid.Nil, SessionRevokedBlacklisted); err != nil {
+		log.Printf("[ERROR] Failed to revoke sessions of blacklisted user %s: %v", userID, err)
+	}
+}
+
+// blacklistedByDenial reports whether denying the ticket was the denial that blacklisted its user
+// (the repository stamps both with the same time).
+func blacklistedByDenial(ticket *models.UserTicket) bool {
+	return ticket.User.IsBlacklisted && ticket.User.BlacklistedAt != nil && ticket.DeniedAt != nil &&
+		ticket.User.BlacklistedAt.Equal(*ticket.DeniedAt)
+}
+
+// BulkApproveTickets approves each ticket in turn through ApproveTicket (so approval emails are sent)
+// and reports per-ticket failures instead of stopping at the first one.
+func (s *TicketService) BulkApproveTickets(ctx context.Context, ticketIDs []string, staffID string) *responses.BulkApproveResponse {
+	result := &responses.BulkApproveResponse{
+		Approved: make([]string, 0, len(ticketIDs)),
+		Failed:   []responses.BulkApproveFailure{},
+	}
+	for _, ticketID := range ticketIDs {
+		if _, err := s.ApproveTicket(ctx, ticketID, staffID); err != nil {
+			result.Failed = append(result.Failed, responses.BulkApproveFailure{TicketID: ticketID, Error: err.Error()})
+			continue
+		}
+		result.Approved = append(result.Approved, ticketID)
+	}
+	return result
 }
 
 // UnblacklistUser removes a user from blacklist
@@ -711,11 +818,40 @@ func (s *TicketService) UnblacklistUser(ctx context.Context, userID string) erro
 		return ErrInvalidUserID
 	}
 
-	return s.repos.Ticket.UnblacklistUser(ctx, id)
+	before := audit.Snapshot(ctx, auditedUser(ctx, s.repos, userID))
+	if err := s.repos.Ticket.UnblacklistUser(ctx, id); err != nil {
+		return err
+	}
+	audit.Record(ctx, "users", userID, before, auditedUser(ctx, s.repos, userID))
+	return nil
 }
 
 // ========== Helper Functions ==========
 
+// auditedTicket loads a ticket for the audit log of an admin change; nil outside an audited action.
+func (s *TicketService) auditedTicket(ctx context.Context, id uuid.UUID) any {
+	if audit.FromContext(ctx) == nil {
+		return nil
+	}
+	ticket, err := s.repos.Ticket.GetUserTicketByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return ticket
+}
+
+// auditedTier loads a ticket tier for the audit log of an admin change; nil outside an audited action.
+func (s *TicketService) auditedTier(ctx context.Context, id uuid.UUID) any {
+	if audit.FromContext(ctx) == nil {
+		return nil
+	}
+	tier, err := s.repos.Ticket.GetTierByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return tier
+}
+
 func isValidTicketStatus(status models.TicketStatus) bool {
 	switch status {
 	case models.TicketStatusPending,
@@ -728,3 +864,70 @@ func isValidTicketStatus(status models.TicketStatus) bool {
 		return false
 	}
 }
+
+// ========== Scheduled Jobs ==========
+
+// SendPaymentReminders emails users whose ticket has been pending (payment not confirmed) for longer
+// than req.OlderThanHours, limited to tickets that entered that state within the last req.WindowHours.
+func (s *TicketService) SendPaymentReminders(ctx context.Context, req *requests.PaymentReminderJobRequest) (*common.EmailJobResult, error) {
+	window := req.WindowHours
+	if window <= 0 {
+		window = 24
+	}
+	to := time.Now().Add(-time.Duration(req.OlderThanHours) * time.Hour)
+	from := to.Add(-time.Duration(window) * time.Hour)
+
+	tickets, err := s.repos.Ticket.GetPendingTicketsLastModifiedBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &common.EmailJobResult{Candidates: len(tickets)}
+	fromEmail := os.Getenv("SES_EMAIL_IDENTITY")
+	if s.mail == nil || fromEmail == "" {
+		log.Printf("Payment reminders skipped: mail not configured (%d candidates)", len(tickets))
+		return result, nil
+	}
+
+	for _, t := range tickets {
+		if t.User.Email == "" {
+			continue
+		}
+		lang := LangFromCountry(t.User.Country)
+		subject, notice := paymentReminderNotice(t.ReferenceCode, t.Ticket.TicketName, req.ExpireAfterHours, lang)
+		if err := s.mail.SendNoticeEmail(ctx, fromEmail, t.User.Email, subject, notice, lang); err != nil {
+			log.Printf("Failed to send payment reminder for ticket %s: %v", t.Id, err)
+			result.Failed++
+			continue
+		}
+		result.Sent++
+	}
+	return result, nil
+}
+
+func paymentReminderNotice(referenceCode, tierName string, expireAfterHours int, lang string) (string, NoticeEmail) {
+	if lang == "vi" {
+		n := NoticeEmail{
+			Title: "Nhắc nhở thanh toán",
+			Paragraphs: []string{
+				fmt.Sprintf("Vé %s (%s) của bạn vẫn đang chờ thanh toán.", referenceCode, tierName),
+				"Sau khi chuyển khoản, vui lòng bấm \"Tôi đã thanh toán\" trên trang vé để ban tổ chức xác nhận.",
+			},
+		}
+		if expireAfterHours > 0 {
+			n.Footnote = fmt.Sprintf("Vé chưa thanh toán sẽ tự động bị huỷ sau %d giờ kể từ khi đặt.", expireAfterHours)
+		}
+		return "Vé FUVE của bạn đang chờ thanh toán", n
+	}
+	n := NoticeEmail{
+		Title: "Payment reminder",
+		Paragraphs: []string{
+			fmt.Sprintf("Your ticket %s (%s) is still waiting for payment.", referenceCode, tierName),
+			"Once you have transferred the payment, please press \"I have paid\" on your ticket page so our team can confirm it.",
+		},
+	}
+	if expireAfterHours > 0 {
+		n.Footnote = fmt.Sprintf("Unpaid tickets are released automatically %d hours after booking.", expireAfterHours)
+	}
+	return "Your FUVE ticket is awaiting payment", n
+}
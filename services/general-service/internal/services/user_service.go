@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"general-service/internal/audit"
 	"general-service/internal/common/constants"
 	"general-service/internal/common/utils"
 	"general-service/internal/dto/common"
@@ -15,15 +16,17 @@ import (
 	"math"
 	"strings"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
-	repos *repositories.Repositories
+	repos    *repositories.Repositories
+	sessions *SessionService
 }
 
-func NewUserService(repos *repositories.Repositories) *UserService {
-	return &UserService{repos: repos}
+func NewUserService(repos *repositories.Repositories, sessions *SessionService) *UserService {
+	return &UserService{repos: repos, sessions: sessions}
 }
 
 // isUserDeleted checks if a user is soft-deleted by examining both IsDeleted flag and DeletedAt timestamp
@@ -215,7 +218,7 @@ func (s *UserService) GetUserByIDForAdmin(userID string) (*responses.UserDetaile
 }
 
 // UpdateUserByAdmin updates user information by admin
-func (s *UserService) UpdateUserByAdmin(userID string, req *requests.AdminUpdateUserRequest) (*responses.UserDetailedResponse, error) {
+func (s *UserService) UpdateUserByAdmin(ctx context.Context, userID string, req *requests.AdminUpdateUserRequest) (*responses.UserDetailedResponse, error) {
 	// Fetch user (admin can see deleted users)
 	user, err := s.repos.User.FindByIDForAdmin(userID)
 	if err != nil {
@@ -225,6 +228,8 @@ func (s *UserService) UpdateUserByAdmin(userID string, req *requests.AdminUpdate
 		return nil, err
 	}
 
+	before := audit.Snapshot(ctx, user)
+
 	// Update only provided fields (nil = not provided, *string = explicitly set, even if empty)
 	// Note: Email cannot be changed by admin for security reasons
 	if req.FursonaName != nil {
@@ -242,6 +247,7 @@ func (s *UserService) UpdateUserByAdmin(userID string, req *requests.AdminUpdate
 	if req.Avatar != nil {
 		user.Avatar = *req.Avatar
 	}
+	roleChanged := req.Role != nil && *req.Role != user.Role
 	if req.Role != nil {
 		user.Role = *req.Role
 	}
@@ -252,10 +258,19 @@ func (s *UserService) UpdateUserByAdmin(userID string, req *requests.AdminUpdate
 		user.IsVerified = *req.IsVerified
 	}
 
-	// Save updated user
+	// Save updated user (snapshot first: saving encrypts the PII fields in place)
+	after := audit.Snapshot(ctx, user)
 	if err := s.repos.User.UpdateUser(user); err != nil {
 		return nil, errors.New("failed to update user")
 	}
+	audit.Record(ctx, "users", user.Id.String(), before, after)
+
+	// Tokens carry the role, so sign the user out to pick up the new one
+	if roleChanged {
+		if _, err := s.sessions.RevokeAllForUser(context.Background(), user.Id, uuid.Nil, SessionRevokedRoleChanged); err != nil {
+			fmt.Printf("[ERROR] Failed to revoke sessions after role change for user %s: %v\n", user.Id, err)
+		}
+	}
 
 	return mappers.MapUserToDetailedResponse(user), nil
 }
@@ -333,7 +348,7 @@ func (s *UserService) GetUserCountByAgeRange() (*responses.CountByAgeRangeRespon
 }
 
 // DeleteUser soft deletes a user (admin only)
-func (s *UserService) DeleteUser(userID string) error {
+func (s *UserService) DeleteUser(ctx context.Context, userID string) error {
 	// Fetch user (admin can see deleted users)
 	user, err := s.repos.User.FindByIDForAdmin(userID)
 	if err != nil {
@@ -349,15 +364,21 @@ func (s *UserService) DeleteUser(userID string) error {
 	}
 
 	// Soft delete
+	before := audit.Snapshot(ctx, user)
 	if err := s.repos.User.DeleteUser(user); err != nil {
 		return errors.New("failed to delete user")
 	}
+	audit.Record(ctx, "users", user.Id.String(), before, auditedUser(ctx, s.repos, user.Id.String()))
+
+	if _, err := s.sessions.RevokeAllForUser(context.Background(), user.Id, uuid.Nil, SessionRevokedDeleted); err != nil {
+		fmt.Printf("[ERROR] Failed to revoke sessions of deleted user %s: %v\n", user.Id, err)
+	}
 
 	return nil
 }
 
 // VerifyUser verifies a user account (admin only)
-func (s *UserService) VerifyUser(userID string) (*responses.UserDetailedResponse, error) {
+func (s *UserService) VerifyUser(ctx context.Context, userID string) (*responses.UserDetailedResponse, error) {
 	// Fetch user
 	user, err := s.repos.User.FindByIDForAdmin(userID)
 	if err != nil {
@@ -368,12 +389,15 @@ func (s *UserService) VerifyUser(userID string) (*responses.UserDetailedResponse
 	}
 
 	// Verify user
+	before := audit.Snapshot(ctx, user)
 	user.IsVerified = true
+	after := audit.Snapshot(ctx, user)
 
 	// Save updated user
 	if err := s.repos.User.UpdateUser(user); err != nil {
 		return nil, errors.New("failed to verify user")
 	}
+	audit.Record(ctx, "users", user.Id.String(), before, after)
 
 	return mappers.MapUserToDetailedResponse(user), nil
 }
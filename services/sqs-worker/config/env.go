@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -75,3 +77,29 @@ func DatabaseDSN() string {
 func IsLambdaEnv() bool {
 	return GetEnvOr("AWS_LAMBDA_FUNCTION_NAME", "") != ""
 }
+
+// GetEnvIntOr returns the integer value of key, or defaultValue when unset or invalid.
+func GetEnvIntOr(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+// JobSchedule returns the cron expression (five fields, UTC) for a scheduled job from
+// SCHEDULE_<NAME> (e.g. SCHEDULE_EXPIRE_STALE_TICKETS), falling back to defaultExpr.
+// "off" disables the job in local mode; in AWS the EventBridge rules decide when jobs run.
+func JobSchedule(name, defaultExpr string) string {
+	return GetEnvOr("SCHEDULE_"+strings.ToUpper(name), defaultExpr)
+}
+
+// SchedulerEnabled reports whether Local() should run scheduled jobs on its internal ticker.
+func SchedulerEnabled() bool {
+	return GetEnvOr("SCHEDULER_ENABLED", "true") == "true"
+}
+
+// GeneralServiceURL is the base URL of general-service (including any path prefix) used for
+// internal job endpoints.
+func GeneralServiceURL() string {
+	return strings.TrimRight(GetEnvOr("GENERAL_SERVICE_URL", "http://localhost:8085"), "/")
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"fuvekonse/sqs-worker/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ConnectRedis returns a client for REDIS_URL (the same Redis as general-service), with TLS in
+// Lambda or when REDIS_TLS is "true". It returns nil if REDIS_URL is not set. The client connects
+// on first use.
+func ConnectRedis() (*redis.Client, error) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return nil, nil
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" || config.GetEnvOr("REDIS_TLS", "false") == "true" {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return redis.NewClient(opts), nil
+}
@@ -17,6 +17,7 @@ func AutoMigrate(gormDB *gorm.DB) error {
 		&models.User{},
 		&models.TicketTier{},
 		&models.UserTicket{},
+		&models.ScheduledJobRun{},
 	)
 }
 
@@ -26,7 +27,7 @@ func ValidateSchema(ctx context.Context, gormDB *gorm.DB) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// Touch tables and columns we use (same as general-service: users, ticket_tiers, user_tickets).
+	// Touch tables and columns we use (same as general-service: users, ticket_tiers, user_tickets, scheduled_job_runs).
 	var n int64
 	if err := gormDB.WithContext(ctx).Model(&models.UserTicket{}).Limit(1).Count(&n).Error; err != nil {
 		return fmt.Errorf("schema check user_tickets: %w (ensure worker schema matches general-service)", err)
@@ -37,5 +38,8 @@ func ValidateSchema(ctx context.Context, gormDB *gorm.DB) error {
 	if err := gormDB.WithContext(ctx).Model(&models.User{}).Limit(1).Count(&n).Error; err != nil {
 		return fmt.Errorf("schema check users: %w (ensure worker schema matches general-service)", err)
 	}
+	if err := gormDB.WithContext(ctx).Model(&models.ScheduledJobRun{}).Limit(1).Count(&n).Error; err != nil {
+		return fmt.Errorf("schema check scheduled_job_runs: %w (ensure worker schema matches general-service)", err)
+	}
 	return nil
 }
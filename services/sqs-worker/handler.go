@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"strconv"
 	"sync"
 
 	"fuvekonse/sqs-worker/config"
 	"fuvekonse/sqs-worker/db"
 	"fuvekonse/sqs-worker/processor"
+	"fuvekonse/sqs-worker/scheduler"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"gorm.io/gorm"
 )
 
@@ -17,8 +23,31 @@ var (
 	dbOnce sync.Once
 	gormDB *gorm.DB
 	dbErr  error
+
+	jobRegistry = processor.DefaultRegistry()
+
+	settlerOnce   sync.Once
+	lambdaSettler *settler
+	settlerErr    error
 )
 
+// getSettler creates the SQS client used to delay retries and dead-letter messages.
+func getSettler() (*settler, error) {
+	settlerOnce.Do(func() {
+		queueURL := queueURLFromEnv()
+		if queueURL == "" {
+			settlerErr = errors.New("SQS_QUEUE is not set")
+			return
+		}
+		var client *sqs.Client
+		client, settlerErr = newSQSClient(queueURL)
+		if settlerErr == nil {
+			lambdaSettler = newSettler(client, queueURL)
+		}
+	})
+	return lambdaSettler, settlerErr
+}
+
 func getDB() (*gorm.DB, error) {
 	dbOnce.Do(func() {
 		if dbErr = config.ValidateDBEnv(); dbErr != nil {
@@ -33,12 +62,39 @@ func getDB() (*gorm.DB, error) {
 	return gormDB, dbErr
 }
 
+// dispatch routes a Lambda invocation: EventBridge schedule rules send a scheduler.Event, the
+// SQS event source mapping sends an SQSEvent.
+func dispatch(ctx context.Context, payload json.RawMessage) (any, error) {
+	var ev scheduler.Event
+	if err := json.Unmarshal(payload, &ev); err == nil && ev.ScheduledJob != "" {
+		return nil, runScheduledJob(ctx, ev.ScheduledJob)
+	}
+	var request events.SQSEvent
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, err
+	}
+	return handler(request)
+}
+
+func runScheduledJob(ctx context.Context, name string) error {
+	g, err := getDB()
+	if err != nil {
+		log.Printf("Database init failed: %v", err)
+		return err
+	}
+	return scheduler.New(g, scheduler.DefaultJobs()).RunByName(ctx, name)
+}
+
 func handler(request events.SQSEvent) (events.SQSEventResponse, error) {
 	log.Printf("Received %d SQS messages", len(request.Records))
 
 	g, err := getDB()
+	var s *settler
+	if err == nil {
+		s, err = getSettler()
+	}
 	if err != nil {
-		log.Printf("Database init failed: %v", err)
+		log.Printf("Worker init failed: %v", err)
 		var batchItemFailures []events.SQSBatchItemFailure
 		for _, record := range request.Records {
 			batchItemFailures = append(batchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
@@ -50,18 +106,23 @@ func handler(request events.SQSEvent) (events.SQSEventResponse, error) {
 	var batchItemFailures []events.SQSBatchItemFailure
 
 	for _, record := range request.Records {
-		body := []byte(record.Body)
-		err := processor.ProcessTicketJob(ctx, g, body)
-		if err != nil {
-			log.Printf("Message %s: %v", record.MessageId, err)
-			if processor.IsPermanentError(err) {
-				log.Printf("Message %s: permanent error, not retrying", record.MessageId)
-				continue
-			}
+		receiveCount, _ := strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+		msg := received{
+			MessageID:     record.MessageId,
+			ReceiptHandle: record.ReceiptHandle,
+			Body:          record.Body,
+			Signature:     aws.ToString(record.MessageAttributes[processor.SignatureAttribute].StringValue),
+		}
+		dec := jobRegistry.Process(ctx, g, processor.Delivery{
+			MessageID:    msg.MessageID,
+			Body:         []byte(msg.Body),
+			ReceiveCount: receiveCount,
+			Signature:    msg.Signature,
+		})
+		// Messages not reported as failures are deleted by the event source mapping.
+		if s.settle(ctx, msg, dec) {
 			batchItemFailures = append(batchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
-			continue
 		}
-		log.Printf("Processed ticket job message %s successfully", record.MessageId)
 	}
 
 	return events.SQSEventResponse{BatchItemFailures: batchItemFailures}, nil
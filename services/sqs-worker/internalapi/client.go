@@ -0,0 +1,95 @@
+// Package internalapi calls general-service internal job endpoints (for work that needs
+// general-service features such as mail) using the shared INTERNAL_API_KEY and an HMAC signature
+// over the method, path and body (JOB_SIGNING_KEYS).
+package internalapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"fuvekonse/sqs-worker/config"
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+)
+
+const (
+	apiKeyHeader    = "X-Internal-Api-Key"
+	signatureHeader = "X-Job-Signature"
+)
+
+var (
+	httpClient = &http.Client{Timeout: 45 * time.Second}
+
+	signerOnce sync.Once
+	signer     *jobmsg.Signer
+	signerErr  error
+)
+
+// response mirrors general-service common.ApiResponse.
+type response struct {
+	IsSuccess bool            `json:"isSuccess"`
+	ErrorCode string          `json:"errorCode"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Post sends body as JSON to GENERAL_SERVICE_URL+path and returns the response "data" field.
+// Non-2xx responses are returned as errors including the API error code and message, classified
+// for the retry policy: 429 is rate-limited, 409 a conflict (including a signature general-service
+// has already seen, which a retry signs afresh), 5xx transient, other 4xx permanent.
+func Post(ctx context.Context, path string, body any) (json.RawMessage, error) {
+	signerOnce.Do(func() { signer, signerErr = jobmsg.NewSignerFromEnv() })
+	if signerErr != nil {
+		return nil, fmt.Errorf("job signing keys: %w", signerErr)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.GeneralServiceURL()+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiKeyHeader, os.Getenv("INTERNAL_API_KEY"))
+	// Sign the path as sent, including any prefix in GENERAL_SERVICE_URL, since that is the path
+	// general-service routes on.
+	req.Header.Set(signatureHeader, signer.Sign(jobmsg.HTTPScope(req.Method, req.URL.Path), payload, time.Now()))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("POST %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("POST %s: read response: %w", path, err)
+	}
+	var out response
+	_ = json.Unmarshal(raw, &out)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("POST %s: status %d %s: %s", path, resp.StatusCode, out.ErrorCode, out.Message)
+		return nil, joberr.Wrap(statusClass(resp.StatusCode), fmt.Sprintf("HTTP_%d", resp.StatusCode), err)
+	}
+	return out.Data, nil
+}
+
+func statusClass(status int) joberr.Class {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return joberr.RateLimited
+	case status == http.StatusConflict:
+		return joberr.Conflict
+	case status >= 500:
+		return joberr.Transient
+	}
+	return joberr.Permanent
+}
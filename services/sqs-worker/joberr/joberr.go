@@ -0,0 +1,135 @@
+// Package joberr gives worker errors a machine-readable class and code so the retry policy can
+// decide what to do with a failed message without a hand-maintained errors.Is chain.
+package joberr
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Class tells the retry policy how to treat a failure.
+type Class string
+
+const (
+	// Permanent failures never succeed on retry (bad input, business rule violations).
+	Permanent Class = "permanent"
+	// Transient failures (timeouts, lost connections) are retried with exponential backoff.
+	Transient Class = "transient"
+	// Conflict failures lost a race with a concurrent write (deadlock, serialization failure) and
+	// are retried quickly.
+	Conflict Class = "conflict"
+	// RateLimited failures hit a downstream limit and are retried with a longer backoff.
+	RateLimited Class = "rate_limited"
+)
+
+// Error is a classified error. Code is a stable identifier (e.g. "OUT_OF_STOCK") for logs and DLQ
+// attributes. DeadLetter marks permanent failures that should be kept in the DLQ for inspection
+// instead of being dropped.
+type Error struct {
+	Class      Class
+	Code       string
+	Msg        string
+	DeadLetter bool
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		if e.Msg == "" {
+			return e.Err.Error()
+		}
+		return e.Msg + ": " + e.Err.Error()
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches another *Error with the same code, so sentinel errors work with errors.Is after
+// being wrapped with fmt.Errorf("%w").
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// New returns a sentinel error of the given class.
+func New(class Class, code, msg string) *Error {
+	return &Error{Class: class, Code: code, Msg: msg}
+}
+
+// NewDeadLetter returns a permanent sentinel error whose messages are moved to the DLQ.
+func NewDeadLetter(code, msg string) *Error {
+	return &Error{Class: Permanent, Code: code, Msg: msg, DeadLetter: true}
+}
+
+// Wrap classifies err, keeping it in the chain.
+func Wrap(class Class, code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Class: class, Code: code, Err: err}
+}
+
+// Postgres SQLSTATE codes with a retry class.
+var pgClasses = map[string]Class{
+	"40001": Conflict,    // serialization_failure
+	"40P01": Conflict,    // deadlock_detected
+	"55P03": Conflict,    // lock_not_available
+	"53300": RateLimited, // too_many_connections
+	"57014": Transient,   // query_canceled
+}
+
+// ClassOf returns the class of err. Unclassified errors are Transient, so an unexpected failure
+// is retried (and eventually dead-lettered) rather than silently dropped.
+func ClassOf(err error) Class {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Class
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if class, ok := pgClasses[pgErr.Code]; ok {
+			return class
+		}
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return Permanent
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return Transient
+	}
+	return Transient
+}
+
+// CodeOf returns the code of a classified error, or the SQLSTATE / "UNCLASSIFIED".
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Code != "" {
+		return e.Code
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return "PG_" + pgErr.Code
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "NOT_FOUND"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "TIMEOUT"
+	}
+	return "UNCLASSIFIED"
+}
+
+// IsDeadLetter reports whether err asks for its message to be kept in the DLQ.
+func IsDeadLetter(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.DeadLetter
+}
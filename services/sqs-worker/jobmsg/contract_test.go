@@ -0,0 +1,146 @@
+package jobmsg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"fuvekonse/sqs-worker/jobmsg"
+)
+
+// contractDir holds the job message contract fixtures. Current-version fixtures (generated by
+// general-service) and compat/v* fixtures must decode strictly and re-encode unchanged;
+// compat/reject_* fixtures must be rejected as an unsupported version so they go to the DLQ.
+const contractDir = "../../contracts/jobs"
+
+func TestJobContract(t *testing.T) {
+	current, err := filepath.Glob(filepath.Join(contractDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compat, err := filepath.Glob(filepath.Join(contractDir, "compat", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(current) == 0 {
+		t.Fatalf("no fixtures found in %s", contractDir)
+	}
+
+	type fixture struct {
+		path    string
+		current bool
+	}
+	var tests []fixture
+	for _, path := range current {
+		tests = append(tests, fixture{path, true})
+	}
+	for _, path := range compat {
+		tests = append(tests, fixture{path, false})
+	}
+
+	seen := make(map[jobmsg.Action]bool)
+	for _, tt := range tests {
+		name := filepath.Base(tt.path)
+		if !tt.current {
+			name = "compat/" + name
+		}
+		t.Run(name, func(t *testing.T) {
+			action, err := checkFixture(tt.path, strings.HasPrefix(filepath.Base(tt.path), "reject_"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.current && action != "" {
+				seen[action] = true
+			}
+		})
+	}
+	for _, action := range jobmsg.KnownActions {
+		if !seen[action] {
+			t.Errorf("no current-version fixture for ticket action %q", action)
+		}
+	}
+}
+
+// checkFixture decodes one fixture and returns its ticket action, if any. Reject fixtures pass
+// when the worker rejects the message as a future version.
+func checkFixture(path string, expectReject bool) (jobmsg.Action, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	env, err := jobmsg.DecodeEnvelope(body)
+	if err != nil {
+		return "", fmt.Errorf("decode envelope: %w", err)
+	}
+	err = jobmsg.Upgrade(env)
+	if expectReject {
+		if errors.Is(err, jobmsg.ErrUnsupportedVersion) {
+			return "", nil
+		}
+		return "", fmt.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch env.Type {
+	case jobmsg.JobTypeTicket:
+		var msg jobmsg.TicketJobMessage
+		if err := roundTrip(env.Payload, &msg); err != nil {
+			return "", err
+		}
+		for _, a := range jobmsg.KnownActions {
+			if a == msg.Action {
+				return msg.Action, nil
+			}
+		}
+		return "", fmt.Errorf("unknown ticket action %q", msg.Action)
+	case jobmsg.JobTypeBulkApprove:
+		var msg jobmsg.BulkApproveJobMessage
+		return "", roundTrip(env.Payload, &msg)
+	case jobmsg.JobTypeDataExport:
+		var msg jobmsg.DataExportJobMessage
+		return "", roundTrip(env.Payload, &msg)
+	default:
+		return "", fmt.Errorf("unknown job type %q", env.Type)
+	}
+}
+
+// roundTrip decodes payload into v rejecting unknown fields, re-encodes it and checks nothing was
+// lost or added.
+func roundTrip(payload json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	again, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var want, got any
+	if err := json.Unmarshal(payload, &want); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(again, &got); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("payload changed on round trip:\n    sent %s\n    read %s", compact(payload), again)
+	}
+	return nil
+}
+
+func compact(b []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return string(b)
+	}
+	return buf.String()
+}
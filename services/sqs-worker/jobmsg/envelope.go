@@ -0,0 +1,65 @@
+package jobmsg
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobType identifies which registered handler processes a job (must match general-service queue.JobType).
+type JobType string
+
+const (
+	JobTypeTicket      JobType = "ticket"
+	JobTypeBulkApprove JobType = "bulk_approve"
+	JobTypeDataExport  JobType = "data_export"
+)
+
+// Envelope is the typed wrapper around every job on the queue. Payload is decoded by the
+// handler registered for Type, so new job kinds never touch the ticket action switch.
+type Envelope struct {
+	Type       JobType         `json:"type"`
+	Version    int             `json:"version"`
+	Payload    json.RawMessage `json:"payload"`
+	TraceID    string          `json:"trace_id,omitempty"`
+	EnqueuedAt *time.Time      `json:"enqueued_at,omitempty"`
+}
+
+// DecodeEnvelope parses an SQS body. Bodies without a "type" field are bare TicketJobMessage
+// payloads published before envelopes existed and are wrapped as a version 1 ticket job.
+// Callers must pass the result through Upgrade before dispatching it.
+func DecodeEnvelope(body []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, err
+	}
+	if env.Type == "" {
+		return &Envelope{
+			Type:    JobTypeTicket,
+			Version: 1,
+			Payload: append(json.RawMessage(nil), body...),
+		}, nil
+	}
+	return &env, nil
+}
+
+// BulkApproveJobMessage approves many tickets on behalf of one staff member.
+type BulkApproveJobMessage struct {
+	TicketIDs []string `json:"ticket_ids"`
+	StaffID   string   `json:"staff_id"`
+}
+
+// DataExportJobMessage builds the personal data export a user requested and emails its link.
+type DataExportJobMessage struct {
+	ExportID string `json:"export_id"`
+	UserID   string `json:"user_id"`
+}
+
+// Action returns the "action" field of the payload (ticket jobs), or "" for job types without one.
+// It is used to pick a per-action retry budget before the payload is fully decoded.
+func (e *Envelope) Action() string {
+	var peek struct {
+		Action string `json:"action"`
+	}
+	_ = json.Unmarshal(e.Payload, &peek)
+	return peek.Action
+}
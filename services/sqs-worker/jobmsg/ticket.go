@@ -15,6 +15,19 @@ const (
 	ActionUnblacklistUser Action = "unblacklist_user"
 )
 
+// KnownActions lists every action the worker handles (must match general-service queue.TicketJobActions).
+var KnownActions = []Action{
+	ActionPurchaseTicket,
+	ActionConfirmPayment,
+	ActionCancelTicket,
+	ActionUpdateBadge,
+	ActionApproveTicket,
+	ActionDenyTicket,
+	ActionUpgradeTicket,
+	ActionBlacklistUser,
+	ActionUnblacklistUser,
+}
+
 // TicketJobMessage is the SQS body (same shape as general-service queue.TicketJobMessage).
 type TicketJobMessage struct {
 	Action        Action `json:"action"`
@@ -0,0 +1,63 @@
+package jobmsg
+
+import (
+	"fmt"
+
+	"fuvekonse/sqs-worker/joberr"
+)
+
+// Schema versions of the job message contract (must match general-service queue.JobSchemaVersion).
+//
+//	1: bare TicketJobMessage bodies and the first envelopes (no version checks).
+//	2: every message is an Envelope carrying an explicit version; payloads unchanged from 1.
+//	3: adds the data_export job type; existing payloads unchanged from 2.
+//
+// During a rolling deploy the worker accepts CurrentVersion and the one before it, so producers can
+// be upgraded before or after the worker. Bump CurrentVersion when a payload changes shape or a new
+// job type or ticket action is added, and add an upgrader below for the previous version.
+const (
+	CurrentVersion      = 3
+	MinSupportedVersion = CurrentVersion - 1
+)
+
+var (
+	// ErrUnsupportedVersion marks a message from a newer producer. It is moved to the DLQ, to be
+	// replayed once a newer worker is deployed.
+	ErrUnsupportedVersion = joberr.NewDeadLetter("UNSUPPORTED_VERSION", "unsupported job message version")
+	// ErrObsoleteVersion marks a message older than MinSupportedVersion; no worker can process it.
+	ErrObsoleteVersion = joberr.New(joberr.Permanent, "OBSOLETE_VERSION", "obsolete job message version")
+)
+
+// upgraders[v] rewrites a version v envelope into version v+1.
+var upgraders = map[int]func(*Envelope) error{
+	1: func(env *Envelope) error {
+		// Payload fields are identical between 1 and 2.
+		return nil
+	},
+	2: func(env *Envelope) error {
+		// Only a new job type was added in 3.
+		return nil
+	},
+}
+
+// Upgrade checks env.Version against the supported range and rewrites older payloads up to
+// CurrentVersion, so handlers only ever see the current shape.
+func Upgrade(env *Envelope) error {
+	switch {
+	case env.Version > CurrentVersion:
+		return fmt.Errorf("%w: %d (worker supports %d-%d)", ErrUnsupportedVersion, env.Version, MinSupportedVersion, CurrentVersion)
+	case env.Version < MinSupportedVersion:
+		return fmt.Errorf("%w: %d (worker supports %d-%d)", ErrObsoleteVersion, env.Version, MinSupportedVersion, CurrentVersion)
+	}
+	for env.Version < CurrentVersion {
+		upgrade, ok := upgraders[env.Version]
+		if !ok {
+			return fmt.Errorf("%w: no upgrader from version %d", ErrObsoleteVersion, env.Version)
+		}
+		if err := upgrade(env); err != nil {
+			return fmt.Errorf("upgrade job message from version %d: %w", env.Version, err)
+		}
+		env.Version++
+	}
+	return nil
+}
@@ -3,23 +3,25 @@ package main
 import (
 	"context"
 	"log"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"fuvekonse/sqs-worker/config"
 	"fuvekonse/sqs-worker/processor"
+	"fuvekonse/sqs-worker/scheduler"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 const pollInterval = 5 * time.Second
 
 func Local() {
-	queueURL := config.GetEnvOr("SQS_QUEUE_URL", os.Getenv("SQS_QUEUE"))
+	queueURL := queueURLFromEnv()
 	if queueURL == "" {
 		log.Fatal("SQS_QUEUE_URL or SQS_QUEUE is required for local mode")
 	}
@@ -34,18 +36,29 @@ func Local() {
 	}
 	log.Printf("Local SQS worker started. Queue: %s (writing to database directly)", queueURL)
 
-	client, err := newSQSClientForLocal(queueURL)
+	client, err := newSQSClient(queueURL)
 	if err != nil {
 		log.Fatalf("Failed to create SQS client: %v", err)
 	}
 
+	if config.SchedulerEnabled() {
+		scheduler.New(g, scheduler.DefaultJobs()).Start(context.Background())
+	}
+
+	registry := processor.DefaultRegistry()
+	s := newSettler(client, queueURL)
+	// Keep messages invisible for longer than the slowest handler may run.
+	visibilityTimeout := int32((registry.MaxTimeout() + 5*time.Second) / time.Second)
+
 	for {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		output, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-			QueueUrl:            aws.String(queueURL),
-			MaxNumberOfMessages: 10,
-			WaitTimeSeconds:     5,
-			VisibilityTimeout:   30,
+			QueueUrl:                    aws.String(queueURL),
+			MaxNumberOfMessages:         10,
+			WaitTimeSeconds:             5,
+			VisibilityTimeout:           visibilityTimeout,
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameApproximateReceiveCount},
+			MessageAttributeNames:       []string{processor.SignatureAttribute},
 		})
 		cancel()
 
@@ -59,40 +72,27 @@ func Local() {
 			if msg.MessageId == nil || msg.Body == nil || msg.ReceiptHandle == nil {
 				continue
 			}
-			body := []byte(*msg.Body)
-			processCtx, processCancel := context.WithTimeout(context.Background(), 25*time.Second)
-			err := processor.ProcessTicketJob(processCtx, g, body)
-			processCancel()
-
-			if err != nil {
-				log.Printf("[%s] Process failed: %v", *msg.MessageId, err)
-				if processor.IsPermanentError(err) {
-					log.Printf("[%s] Permanent error, deleting message to avoid infinite retry", *msg.MessageId)
-					delCtx, delCancel := context.WithTimeout(context.Background(), 10*time.Second)
-					_, _ = client.DeleteMessage(delCtx, &sqs.DeleteMessageInput{
-						QueueUrl:      aws.String(queueURL),
-						ReceiptHandle: msg.ReceiptHandle,
-					})
-					delCancel()
-				}
-				continue
+			receiveCount, _ := strconv.Atoi(msg.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+			m := received{
+				MessageID:     *msg.MessageId,
+				ReceiptHandle: *msg.ReceiptHandle,
+				Body:          *msg.Body,
+				Signature:     aws.ToString(msg.MessageAttributes[processor.SignatureAttribute].StringValue),
 			}
-
-			log.Printf("[%s] Processed ticket job successfully", *msg.MessageId)
-			delCtx, delCancel := context.WithTimeout(context.Background(), 10*time.Second)
-			_, err = client.DeleteMessage(delCtx, &sqs.DeleteMessageInput{
-				QueueUrl:      aws.String(queueURL),
-				ReceiptHandle: msg.ReceiptHandle,
+			dec := registry.Process(context.Background(), g, processor.Delivery{
+				MessageID:    m.MessageID,
+				Body:         []byte(m.Body),
+				ReceiveCount: receiveCount,
+				Signature:    m.Signature,
 			})
-			delCancel()
-			if err != nil {
-				log.Printf("DeleteMessage %s failed: %v (message may be processed again)", *msg.MessageId, err)
+			if !s.settle(context.Background(), m, dec) {
+				s.delete(context.Background(), m)
 			}
 		}
 	}
 }
 
-func newSQSClientForLocal(queueURL string) (*sqs.Client, error) {
+func newSQSClient(queueURL string) (*sqs.Client, error) {
 	region := config.GetEnvOr("AWS_REGION", "ap-southeast-1")
 	useLocalStack := config.GetEnvOr("USE_LOCALSTACK", "") == "true" ||
 		strings.Contains(queueURL, "localhost") || strings.Contains(queueURL, "localstack")
@@ -10,7 +10,7 @@ func main() {
 	config.LoadEnv()
 
 	if config.IsLambdaEnv() {
-		lambda.Start(handler)
+		lambda.Start(dispatch)
 	} else {
 		Local()
 	}
@@ -60,7 +60,32 @@ type UserTicket struct {
 	UpgradedFromTierID    *uuid.UUID   `gorm:"type:uuid"`
 	PreviousReferenceCode string       `gorm:"type:varchar(50)"`
 	UpgradeDenialReason   string       `gorm:"type:varchar(500)"`
+	RiskScore             *int         `gorm:"type:int"`
+	FraudHold             bool         `gorm:"default:false"`
 	IsDeleted             bool         `gorm:"default:false"`
 	CreatedAt             time.Time    `gorm:"autoCreateTime"`
 	ModifiedAt            time.Time    `gorm:"autoUpdateTime"`
 }
+
+// ScheduledJobStatus matches general-service models.ScheduledJobStatus.
+type ScheduledJobStatus string
+
+const (
+	ScheduledJobStatusRunning   ScheduledJobStatus = "running"
+	ScheduledJobStatusSucceeded ScheduledJobStatus = "succeeded"
+	ScheduledJobStatusFailed    ScheduledJobStatus = "failed"
+)
+
+// ScheduledJobRun latest run per scheduled job (table: scheduled_job_runs, owned by general-service).
+type ScheduledJobRun struct {
+	Name           string             `gorm:"type:varchar(100);primaryKey"`
+	Schedule       string             `gorm:"type:varchar(100)"`
+	LastStatus     ScheduledJobStatus `gorm:"type:varchar(20)"`
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastDurationMs int64  `gorm:"type:bigint;default:0"`
+	LastError      string `gorm:"type:text"`
+	LastResult     string `gorm:"type:text"`
+	LastSuccessAt  *time.Time
+	ModifiedAt     time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"fuvekonse/sqs-worker/internalapi"
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// bulkApproveChunkSize caps the tickets approved per general-service call, so each call (one
+// approval and one email per ticket) stays well inside the request timeout.
+const bulkApproveChunkSize = 20
+
+// bulkApproveResult mirrors general-service responses.BulkApproveResponse.
+type bulkApproveResult struct {
+	Approved []string `json:"approved"`
+	Failed   []struct {
+		TicketID string `json:"ticket_id"`
+		Error    string `json:"error"`
+	} `json:"failed"`
+}
+
+// ProcessBulkApproveJob has general-service approve the tickets in the payload in chunks, through
+// the same path as a synchronous bulk approval so attendees get their approval email. Tickets that
+// cannot be approved (not found, wrong status, held) are reported by general-service and logged;
+// a failed call fails the job so it is retried. Tickets approved by an earlier attempt then come
+// back as wrong status, so nobody is emailed twice.
+func ProcessBulkApproveJob(ctx context.Context, _ *gorm.DB, env *jobmsg.Envelope) error {
+	var msg jobmsg.BulkApproveJobMessage
+	if err := json.Unmarshal(env.Payload, &msg); err != nil {
+		return joberr.Wrap(joberr.Permanent, "INVALID_PAYLOAD", err)
+	}
+	if _, err := uuid.Parse(msg.StaffID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUUID, err)
+	}
+
+	var approved, skipped int
+	for start := 0; start < len(msg.TicketIDs); start += bulkApproveChunkSize {
+		chunk := msg.TicketIDs[start:min(start+bulkApproveChunkSize, len(msg.TicketIDs))]
+		data, err := internalapi.Post(ctx, "/internal/jobs/bulk-approve", &jobmsg.BulkApproveJobMessage{
+			TicketIDs: chunk,
+			StaffID:   msg.StaffID,
+		})
+		if err != nil {
+			return fmt.Errorf("bulk approve tickets %d-%d: %w", start+1, start+len(chunk), err)
+		}
+		var result bulkApproveResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return joberr.Wrap(joberr.Transient, "INVALID_RESPONSE", err)
+		}
+		for _, failure := range result.Failed {
+			log.Printf("Bulk approve: skipping ticket %s: %s", failure.TicketID, failure.Error)
+		}
+		approved += len(result.Approved)
+		skipped += len(result.Failed)
+	}
+	log.Printf("Bulk approve by staff %s: %d approved, %d skipped", msg.StaffID, approved, skipped)
+	return nil
+}
@@ -0,0 +1,28 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"fuvekonse/sqs-worker/internalapi"
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+
+	"gorm.io/gorm"
+)
+
+// ProcessDataExportJob asks general-service to build a personal data export, since decrypting the
+// user's PII and sending the download link both live there. Building is idempotent: a ready export
+// is left alone and a failed one is rebuilt, so the job can simply be retried.
+func ProcessDataExportJob(ctx context.Context, _ *gorm.DB, env *jobmsg.Envelope) error {
+	var msg jobmsg.DataExportJobMessage
+	if err := json.Unmarshal(env.Payload, &msg); err != nil {
+		return joberr.Wrap(joberr.Permanent, "INVALID_PAYLOAD", err)
+	}
+	if _, err := internalapi.Post(ctx, "/internal/jobs/data-export", &msg); err != nil {
+		return err
+	}
+	log.Printf("Data export %s built for user %s", msg.ExportID, msg.UserID)
+	return nil
+}
@@ -5,18 +5,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"fuvekonse/sqs-worker/internalapi"
+	"fuvekonse/sqs-worker/joberr"
 	"fuvekonse/sqs-worker/jobmsg"
+	"fuvekonse/sqs-worker/models"
 	"fuvekonse/sqs-worker/repo"
+	"log"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// ProcessTicketJob processes one ticket job message and writes to the database.
+// ProcessTicketJob processes one ticket job payload and writes to the database.
 func ProcessTicketJob(ctx context.Context, db *gorm.DB, body []byte) error {
 	var msg jobmsg.TicketJobMessage
 	if err := json.Unmarshal(body, &msg); err != nil {
-		return err
+		return joberr.Wrap(joberr.Permanent, "INVALID_PAYLOAD", err)
 	}
 
 	tr := repo.NewTicketRepo(db)
@@ -31,8 +35,15 @@ func ProcessTicketJob(ctx context.Context, db *gorm.DB, body []byte) error {
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrInvalidUUID, err)
 		}
-		_, err = tr.PurchaseTicket(ctx, uid, tid, msg.AdminBypass)
-		return err
+		t, err := tr.PurchaseTicket(ctx, uid, tid, msg.AdminBypass)
+		if err != nil {
+			return err
+		}
+		// Best-effort: the screening job and approval screen it otherwise
+		if err := screenPurchase(ctx, t.Id); err != nil {
+			log.Printf("Fraud screening of ticket %s failed: %v", t.Id, err)
+		}
+		return nil
 	case jobmsg.ActionConfirmPayment:
 		uid, err := uuid.Parse(msg.UserID)
 		if err != nil {
@@ -83,8 +94,7 @@ func ProcessTicketJob(ctx context.Context, db *gorm.DB, body []byte) error {
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrInvalidUUID, err)
 		}
-		_, err = tr.ApproveTicket(ctx, tid, sid)
-		return err
+		return approveTicket(ctx, tr, tid, sid)
 	case jobmsg.ActionDenyTicket:
 		tid, err := uuid.Parse(msg.TicketID)
 		if err != nil {
@@ -94,8 +104,11 @@ func ProcessTicketJob(ctx context.Context, db *gorm.DB, body []byte) error {
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrInvalidUUID, err)
 		}
-		_, err = tr.DenyTicket(ctx, tid, sid, msg.Reason)
-		return err
+		t, err := tr.DenyTicket(ctx, tid, sid, msg.Reason)
+		if err != nil {
+			return err
+		}
+		return signOutIfBlacklisted(ctx, tr, t)
 	case jobmsg.ActionUpgradeTicket:
 		uid, err := uuid.Parse(msg.UserID)
 		if err != nil {
@@ -124,27 +137,45 @@ func ProcessTicketJob(ctx context.Context, db *gorm.DB, body []byte) error {
 	}
 }
 
+// screenPurchase has general-service score a queued purchase for duplicate accounts, holding the
+// ticket when the score is high enough.
+func screenPurchase(ctx context.Context, ticketID uuid.UUID) error {
+	_, err := internalapi.Post(ctx, "/internal/jobs/fraud-screening", map[string]string{"ticket_id": ticketID.String()})
+	return err
+}
+
+// approveTicket approves the ticket, screening it first when it has no risk score yet so a
+// high-risk purchase is held instead of approved.
+func approveTicket(ctx context.Context, tr *repo.TicketRepo, ticketID, staffID uuid.UUID) error {
+	_, err := tr.ApproveTicket(ctx, ticketID, staffID)
+	if !errors.Is(err, repo.ErrTicketNotScreened) {
+		return err
+	}
+	if err := screenPurchase(ctx, ticketID); err != nil {
+		return err
+	}
+	_, err = tr.ApproveTicket(ctx, ticketID, staffID)
+	return err
+}
+
+// signOutIfBlacklisted has general-service revoke the sessions of a user the denial blacklisted
+// automatically. A failed call fails the job; the retried denial is idempotent and tries again.
+func signOutIfBlacklisted(ctx context.Context, tr *repo.TicketRepo, t *models.UserTicket) error {
+	blacklisted, err := tr.BlacklistedByDenial(ctx, t.Id)
+	if err != nil || !blacklisted {
+		return err
+	}
+	_, err = internalapi.Post(ctx, "/internal/jobs/blacklist-sign-out", map[string]string{"user_id": t.UserId.String()})
+	return err
+}
+
 var (
-	ErrNoTicketFound = errors.New("no ticket found for this user")
-	ErrUnknownAction = errors.New("unknown ticket job action")
-	ErrInvalidUUID   = errors.New("invalid UUID format")
+	ErrNoTicketFound = joberr.New(joberr.Permanent, "NO_TICKET_FOUND", "no ticket found for this user")
+	ErrUnknownAction = joberr.New(joberr.Permanent, "UNKNOWN_ACTION", "unknown ticket job action")
+	ErrInvalidUUID   = joberr.New(joberr.Permanent, "INVALID_UUID", "invalid UUID format")
 )
 
 // IsPermanentError returns true if retrying the message won't fix the error.
 func IsPermanentError(err error) bool {
-	if err == nil {
-		return false
-	}
-	return errors.Is(err, repo.ErrTicketTierNotFound) ||
-		errors.Is(err, repo.ErrTicketNotFound) ||
-		errors.Is(err, repo.ErrOutOfStock) ||
-		errors.Is(err, repo.ErrUserAlreadyHasTicket) ||
-		errors.Is(err, repo.ErrUserBlacklisted) ||
-		errors.Is(err, repo.ErrInvalidTicketStatus) ||
-		errors.Is(err, repo.ErrCannotDowngrade) ||
-		errors.Is(err, repo.ErrTicketNotApproved) ||
-		errors.Is(err, ErrInvalidUUID) ||
-		errors.Is(err, ErrNoTicketFound) ||
-		errors.Is(err, ErrUnknownAction) ||
-		errors.Is(err, gorm.ErrRecordNotFound)
+	return err != nil && joberr.ClassOf(err) == joberr.Permanent
 }
@@ -0,0 +1,193 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"fuvekonse/sqs-worker/config"
+	"fuvekonse/sqs-worker/db"
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// HandlerFunc processes one decoded job envelope.
+type HandlerFunc func(ctx context.Context, db *gorm.DB, env *jobmsg.Envelope) error
+
+// Delivery is one received queue message plus the SQS metadata the registry needs.
+type Delivery struct {
+	MessageID    string
+	Body         []byte
+	ReceiveCount int
+	Signature    string // JobSignature message attribute
+}
+
+// SignatureAttribute is the SQS message attribute carrying the job signature (must match
+// general-service queue.SignatureAttribute).
+const SignatureAttribute = "JobSignature"
+
+type registration struct {
+	handler HandlerFunc
+	policy  Policy
+}
+
+// Registry maps job types to handlers. Register every type at startup; it is not safe for
+// concurrent registration.
+type Registry struct {
+	handlers  map[jobmsg.JobType]registration
+	signer    *jobmsg.Signer
+	signerErr error
+	maxAge    time.Duration
+	replays   *redis.Client
+}
+
+// NewRegistry creates an empty registry that verifies message signatures with signer, rejecting
+// messages signed more than maxAge ago. A nil signer rejects every message with signerErr.
+func NewRegistry(signer *jobmsg.Signer, signerErr error, maxAge time.Duration) *Registry {
+	if signer == nil && signerErr == nil {
+		signerErr = errors.New("no job signer configured")
+	}
+	return &Registry{
+		handlers:  make(map[jobmsg.JobType]registration),
+		signer:    signer,
+		signerErr: signerErr,
+		maxAge:    maxAge,
+	}
+}
+
+// SetReplayStore sets the Redis client used to remember delivered message signatures, so a
+// captured message body cannot be enqueued again while its signature is still valid. Without it
+// signatures are only checked for age.
+func (r *Registry) SetReplayStore(store *redis.Client) {
+	r.replays = store
+}
+
+// Register adds a handler for jobType. Zero policy fields fall back to DefaultPolicy.
+func (r *Registry) Register(jobType jobmsg.JobType, handler HandlerFunc, policy Policy) {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultPolicy.Timeout
+	}
+	r.handlers[jobType] = registration{handler: handler, policy: policy}
+}
+
+// MaxTimeout returns the longest handler timeout, used to size the queue visibility timeout.
+func (r *Registry) MaxTimeout() time.Duration {
+	longest := DefaultPolicy.Timeout
+	for _, reg := range r.handlers {
+		if reg.policy.Timeout > longest {
+			longest = reg.policy.Timeout
+		}
+	}
+	return longest
+}
+
+// Process verifies the signature, decodes and upgrades the envelope, runs the registered handler
+// under its policy and decides what to do with the message. Signature failures and messages from
+// a newer producer are dead-lettered for inspection (forged, replayed, or signed with a key /
+// written in a version this worker does not have yet).
+func (r *Registry) Process(ctx context.Context, db *gorm.DB, d Delivery) Decision {
+	maxAttempts := DefaultPolicy.MaxAttempts
+	claimed := false
+	err := r.process(ctx, db, d, &maxAttempts, &claimed)
+	dec := decide(err, d.ReceiveCount, maxAttempts)
+	if dec.Outcome == OutcomeDeadLetter && !joberr.IsDeadLetter(err) {
+		dec.Err = fmt.Errorf("%w: attempt %d of %d: %w", ErrAttemptsExhausted, d.ReceiveCount, maxAttempts, err)
+	}
+	if dec.Outcome == OutcomeDeadLetter && claimed {
+		// A redrive from the DLQ arrives under a new message ID with the same signature.
+		if err := releaseSignature(ctx, r.replays, d.Signature, d.MessageID); err != nil {
+			log.Printf("[%s] Failed to release job signature for redrive: %v", d.MessageID, err)
+		}
+	}
+	return dec
+}
+
+// process runs the job and reports the attempts budget that applies to it through maxAttempts,
+// and whether this message now holds its signature, through claimed.
+func (r *Registry) process(ctx context.Context, db *gorm.DB, d Delivery, maxAttempts *int, claimed *bool) error {
+	if r.signer == nil {
+		return fmt.Errorf("%w: %v", ErrSignatureRejected, r.signerErr)
+	}
+	if err := r.signer.Verify(d.Signature, jobmsg.ScopeQueue, d.Body, time.Now(), r.maxAge); err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureRejected, err)
+	}
+	if r.replays != nil {
+		// A signature stays valid for maxAge either side of its timestamp, so remember it for the
+		// whole window.
+		fresh, err := claimSignature(ctx, r.replays, d.Signature, d.MessageID, 2*r.maxAge)
+		if err != nil {
+			return joberr.Wrap(joberr.Transient, "REPLAY_CHECK_FAILED", err)
+		}
+		if !fresh {
+			return ErrReplayedMessage
+		}
+		*claimed = true
+	}
+
+	env, err := jobmsg.DecodeEnvelope(d.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if err := jobmsg.Upgrade(env); err != nil {
+		return err
+	}
+
+	reg, ok := r.handlers[env.Type]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownJobType, env.Type)
+	}
+	action := env.Action()
+	*maxAttempts = reg.policy.maxAttemptsFor(action)
+
+	// Only reached when an earlier attempt could not be settled (e.g. the DLQ send failed).
+	if d.ReceiveCount > *maxAttempts {
+		return fmt.Errorf("%w: %s job received %d times (max %d)", ErrAttemptsExhausted, env.Type, d.ReceiveCount, *maxAttempts)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reg.policy.Timeout)
+	defer cancel()
+
+	log.Printf("[%s] Processing %s job v%d (action=%s, trace=%s, attempt=%d/%d)", d.MessageID, env.Type, env.Version, action, env.TraceID, d.ReceiveCount, *maxAttempts)
+	return reg.handler(ctx, db, env)
+}
+
+// DefaultRegistry returns a registry with every job type the worker understands, verifying
+// signatures with the keys in JOB_SIGNING_KEYS and remembering them in REDIS_URL.
+func DefaultRegistry() *Registry {
+	signer, err := jobmsg.NewSignerFromEnv()
+	if err != nil {
+		log.Printf("Job signing keys: %v (all messages will be rejected)", err)
+	}
+	maxAge := time.Duration(config.GetEnvIntOr("JOB_MESSAGE_MAX_AGE_HOURS", 24)) * time.Hour
+	r := NewRegistry(signer, err, maxAge)
+	switch store, err := db.ConnectRedis(); {
+	case err != nil:
+		log.Printf("WARNING: job replay check disabled: %v", err)
+	case store == nil:
+		log.Printf("WARNING: REDIS_URL not set, job replay check disabled")
+	default:
+		r.SetReplayStore(store)
+	}
+	r.Register(jobmsg.JobTypeTicket, func(ctx context.Context, db *gorm.DB, env *jobmsg.Envelope) error {
+		return ProcessTicketJob(ctx, db, env.Payload)
+	}, ticketPolicy)
+	r.Register(jobmsg.JobTypeBulkApprove, ProcessBulkApproveJob, Policy{MaxAttempts: 5, Timeout: 50 * time.Second})
+	r.Register(jobmsg.JobTypeDataExport, ProcessDataExportJob, Policy{MaxAttempts: 3, Timeout: 50 * time.Second})
+	return r
+}
+
+var (
+	ErrInvalidEnvelope   = joberr.New(joberr.Permanent, "INVALID_ENVELOPE", "invalid job envelope")
+	ErrUnknownJobType    = joberr.New(joberr.Permanent, "UNKNOWN_JOB_TYPE", "unknown job type")
+	ErrAttemptsExhausted = joberr.NewDeadLetter("ATTEMPTS_EXHAUSTED", "job exceeded its max attempts")
+	ErrSignatureRejected = joberr.NewDeadLetter("SIGNATURE_REJECTED", "job message signature rejected")
+	ErrReplayedMessage   = joberr.NewDeadLetter("JOB_REPLAYED", "job message signature already delivered by another message")
+)
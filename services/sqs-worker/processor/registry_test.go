@@ -0,0 +1,63 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+	"fuvekonse/sqs-worker/processor"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+func TestProcessRejectsReplayedSignature(t *testing.T) {
+	body := []byte(fmt.Sprintf(`{"type":%q,"version":%d,"payload":{"export_id":"77777777-7777-4777-8777-777777777777","user_id":"11111111-1111-4111-8111-111111111111"}}`,
+		jobmsg.JobTypeDataExport, jobmsg.CurrentVersion))
+	signer, err := jobmsg.NewSigner("test:test-job-signing-secret-0123456789abcdef", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := signer.Sign(jobmsg.ScopeQueue, body, time.Now())
+
+	var handlerErr error
+	registry := processor.NewRegistry(signer, nil, time.Hour)
+	registry.SetReplayStore(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}))
+	registry.Register(jobmsg.JobTypeDataExport, func(context.Context, *gorm.DB, *jobmsg.Envelope) error {
+		return handlerErr
+	}, processor.Policy{MaxAttempts: 2})
+
+	tests := []struct {
+		name         string
+		messageID    string
+		receiveCount int
+		handlerErr   error
+		want         processor.Outcome
+		wantErr      error
+	}{
+		{"first delivery fails", "msg-1", 1, joberr.New(joberr.Transient, "TEST", "try again"), processor.OutcomeRetry, nil},
+		{"same message is redelivered", "msg-1", 2, joberr.New(joberr.Transient, "TEST", "try again"), processor.OutcomeDeadLetter, nil},
+		{"redrive from the DLQ", "msg-2", 1, nil, processor.OutcomeDone, nil},
+		{"body enqueued again", "msg-3", 1, nil, processor.OutcomeDeadLetter, processor.ErrReplayedMessage},
+	}
+	for _, tt := range tests {
+		handlerErr = tt.handlerErr
+		dec := registry.Process(context.Background(), nil, processor.Delivery{
+			MessageID:    tt.messageID,
+			Body:         body,
+			ReceiveCount: tt.receiveCount,
+			Signature:    signature,
+		})
+		if dec.Outcome != tt.want {
+			t.Errorf("%s: outcome %v, want %v (%v)", tt.name, dec.Outcome, tt.want, dec.Err)
+		}
+		if tt.wantErr != nil && !errors.Is(dec.Err, tt.wantErr) {
+			t.Errorf("%s: error %v, want %v", tt.name, dec.Err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fuvekonse/sqs-worker/jobmsg"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// queueSignatureKeyPrefix namespaces queue message signatures apart from the internal job
+// request signatures general-service stores under "jobsig:".
+const queueSignatureKeyPrefix = "jobsig:queue:"
+
+// releaseSignatureScript deletes a signature claim only if this message still holds it.
+var releaseSignatureScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// claimSignature records that messageID delivered signature, for ttl. It reports false if another
+// message already delivered the same signature; SQS redeliveries of the same message (retries keep
+// the message ID) still pass.
+func claimSignature(ctx context.Context, store *redis.Client, signature, messageID string, ttl time.Duration) (bool, error) {
+	key := queueSignatureKeyPrefix + jobmsg.SignatureMAC(signature)
+	fresh, err := store.SetNX(ctx, key, messageID, ttl).Result()
+	if err != nil || fresh {
+		return fresh, err
+	}
+	holder, err := store.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Expired between the two calls: the signature is outside its window anyway.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return holder == messageID, nil
+}
+
+// releaseSignature forgets a claim made by messageID, so the message can be redriven from the DLQ
+// (which gives it a new message ID).
+func releaseSignature(ctx context.Context, store *redis.Client, signature, messageID string) error {
+	key := queueSignatureKeyPrefix + jobmsg.SignatureMAC(signature)
+	return releaseSignatureScript.Run(ctx, store, []string{key}, messageID).Err()
+}
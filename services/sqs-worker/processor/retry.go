@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"fuvekonse/sqs-worker/joberr"
+	"fuvekonse/sqs-worker/jobmsg"
+)
+
+// Outcome tells the caller what to do with a processed message.
+type Outcome int
+
+const (
+	// OutcomeDone: the job succeeded; delete the message.
+	OutcomeDone Outcome = iota
+	// OutcomeDrop: the job failed permanently; delete the message without retrying.
+	OutcomeDrop
+	// OutcomeRetry: make the message visible again after Decision.Delay.
+	OutcomeRetry
+	// OutcomeDeadLetter: move the message to the DLQ for inspection and delete it.
+	OutcomeDeadLetter
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeDone:
+		return "done"
+	case OutcomeDrop:
+		return "drop"
+	case OutcomeRetry:
+		return "retry"
+	case OutcomeDeadLetter:
+		return "dead_letter"
+	}
+	return "unknown"
+}
+
+// Decision is the result of Registry.Process. Class, Code and Err are empty for OutcomeDone.
+type Decision struct {
+	Outcome     Outcome
+	Delay       time.Duration
+	Class       joberr.Class
+	Code        string
+	Attempt     int
+	MaxAttempts int
+	Err         error
+}
+
+// Backoff is an exponential backoff: attempt n waits Base*2^(n-1), capped at Max, with "equal
+// jitter" (a random value between half and all of that) so retries of a failed batch spread out.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// maxVisibilityDelay is the SQS limit for ChangeMessageVisibility.
+const maxVisibilityDelay = 12 * time.Hour
+
+// Delay returns the wait before retry number attempt (1-based).
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := b.Max
+	if attempt <= 30 && b.Base<<(attempt-1) < b.Max {
+		d = b.Base << (attempt - 1)
+	}
+	d = min(d, maxVisibilityDelay)
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half+1)
+}
+
+// Backoffs per retryable error class. Conflicts (deadlocks, lost row-lock races) clear quickly;
+// rate limits need the longest pause.
+var Backoffs = map[joberr.Class]Backoff{
+	joberr.Transient:   {Base: 10 * time.Second, Max: 15 * time.Minute},
+	joberr.Conflict:    {Base: 1 * time.Second, Max: 30 * time.Second},
+	joberr.RateLimited: {Base: time.Minute, Max: time.Hour},
+}
+
+// Policy bounds how long a job may run and how many deliveries it gets before it is moved to the
+// DLQ. ActionMaxAttempts overrides MaxAttempts per payload action (see jobmsg.Envelope.Action).
+type Policy struct {
+	MaxAttempts       int
+	ActionMaxAttempts map[string]int
+	Timeout           time.Duration
+}
+
+// maxAttemptsFor returns the attempts budget for action.
+func (p Policy) maxAttemptsFor(action string) int {
+	if n, ok := p.ActionMaxAttempts[action]; ok && n > 0 {
+		return n
+	}
+	return p.MaxAttempts
+}
+
+// DefaultPolicy applies to job types registered without an explicit policy. Timeout stays under the
+// 60s Lambda timeout. Budgets must stay below the queue redrive maxReceiveCount (10), which is only
+// a safety net for messages the worker could not settle itself.
+var DefaultPolicy = Policy{MaxAttempts: 5, Timeout: 25 * time.Second}
+
+// ticketPolicy gives stock-contended actions a larger budget: a purchase losing a row-lock race
+// should not be dead-lettered while seats are still available.
+var ticketPolicy = Policy{
+	MaxAttempts: 5,
+	ActionMaxAttempts: map[string]int{
+		string(jobmsg.ActionPurchaseTicket): 8,
+		string(jobmsg.ActionUpgradeTicket):  8,
+		string(jobmsg.ActionConfirmPayment): 8,
+		string(jobmsg.ActionUpdateBadge):    3,
+	},
+	Timeout: 25 * time.Second,
+}
+
+// decide maps a handler result to an Outcome under policy.
+func decide(err error, attempt, maxAttempts int) Decision {
+	if err == nil {
+		return Decision{Outcome: OutcomeDone, Attempt: attempt, MaxAttempts: maxAttempts}
+	}
+	dec := Decision{
+		Class:       joberr.ClassOf(err),
+		Code:        joberr.CodeOf(err),
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		Err:         err,
+	}
+	switch {
+	case joberr.IsDeadLetter(err):
+		dec.Outcome = OutcomeDeadLetter
+	case dec.Class == joberr.Permanent:
+		dec.Outcome = OutcomeDrop
+	case attempt >= maxAttempts:
+		dec.Outcome = OutcomeDeadLetter
+	default:
+		dec.Outcome = OutcomeRetry
+		dec.Delay = Backoffs[dec.Class].Delay(attempt)
+	}
+	return dec
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"fuvekonse/sqs-worker/joberr"
 	"fuvekonse/sqs-worker/models"
 	"strings"
 	"time"
@@ -13,15 +14,18 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// Business rule errors are permanent: retrying the same message cannot change the outcome.
 var (
-	ErrTicketTierNotFound   = errors.New("ticket tier not found")
-	ErrTicketNotFound       = errors.New("ticket not found")
-	ErrOutOfStock           = errors.New("ticket tier is out of stock")
-	ErrUserAlreadyHasTicket = errors.New("user already has a ticket")
-	ErrUserBlacklisted      = errors.New("user is blacklisted from purchasing tickets")
-	ErrInvalidTicketStatus  = errors.New("invalid ticket status for this operation")
-	ErrCannotDowngrade      = errors.New("cannot downgrade: new tier price must be higher than current tier price")
-	ErrTicketNotApproved    = errors.New("only approved tickets can be upgraded")
+	ErrTicketTierNotFound   = joberr.New(joberr.Permanent, "TICKET_TIER_NOT_FOUND", "ticket tier not found")
+	ErrTicketNotFound       = joberr.New(joberr.Permanent, "TICKET_NOT_FOUND", "ticket not found")
+	ErrOutOfStock           = joberr.New(joberr.Permanent, "OUT_OF_STOCK", "ticket tier is out of stock")
+	ErrUserAlreadyHasTicket = joberr.New(joberr.Permanent, "USER_ALREADY_HAS_TICKET", "user already has a ticket")
+	ErrUserBlacklisted      = joberr.New(joberr.Permanent, "USER_BLACKLISTED", "user is blacklisted from purchasing tickets")
+	ErrInvalidTicketStatus  = joberr.New(joberr.Permanent, "INVALID_TICKET_STATUS", "invalid ticket status for this operation")
+	ErrCannotDowngrade      = joberr.New(joberr.Permanent, "CANNOT_DOWNGRADE", "cannot downgrade: new tier price must be higher than current tier price")
+	ErrTicketNotApproved    = joberr.New(joberr.Permanent, "TICKET_NOT_APPROVED", "only approved tickets can be upgraded")
+	ErrTicketOnHold         = joberr.New(joberr.Permanent, "TICKET_ON_HOLD", "ticket is held for fraud review")
+	ErrTicketNotScreened    = joberr.New(joberr.Transient, "TICKET_NOT_SCREENED", "ticket has not been screened for fraud yet")
 )
 
 type TicketRepo struct {
@@ -244,6 +248,13 @@ func (r *TicketRepo) ApproveTicket(ctx context.Context, ticketID, staffID uuid.U
 		if t.Status != models.TicketStatusPending && t.Status != models.TicketStatusSelfConfirmed {
 			return ErrInvalidTicketStatus
 		}
+		// Held until an admin clears the fraud assessment
+		if t.FraudHold {
+			return ErrTicketOnHold
+		}
+		if t.RiskScore == nil {
+			return ErrTicketNotScreened
+		}
 		// If this is an upgrade, free the old tier seat now that admin has confirmed.
 		if t.UpgradedFromTierID != nil {
 			var oldTier models.TicketTier
@@ -330,6 +341,18 @@ func (r *TicketRepo) DenyTicket(ctx context.Context, ticketID, staffID uuid.UUID
 	return &t, nil
 }
 
+// BlacklistedByDenial reports whether denying the ticket blacklisted its user (DenyTicket stamps
+// blacklisted_at with the denial time). Also true when a retried denial finds the ticket already denied.
+func (r *TicketRepo) BlacklistedByDenial(ctx context.Context, ticketID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("user_tickets AS t").
+		Joins("JOIN users AS u ON u.id = t.user_id").
+		Where("t.id = ? AND t.status = ? AND u.is_blacklisted = ? AND u.blacklisted_at = t.denied_at", ticketID, models.TicketStatusDenied, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *TicketRepo) UpgradeTicketTier(ctx context.Context, userID, newTierID uuid.UUID, adminBypass bool) (*models.UserTicket, error) {
 	var ticket models.UserTicket
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -506,3 +529,93 @@ func (r *TicketRepo) UnblacklistUser(ctx context.Context, userID uuid.UUID) erro
 func isDuplicateKey(err error) bool {
 	return err != nil && (strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "23505"))
 }
+
+// ExpireStalePendingTickets releases tickets that have stayed pending (payment never confirmed)
+// since before cutoff: upgrades roll back to the previous tier, new purchases are cancelled and
+// their seat restocked. At most limit tickets are handled per call; each runs in its own
+// transaction so a failure leaves the rest untouched. Returns how many were expired/rolled back.
+func (r *TicketRepo) ExpireStalePendingTickets(ctx context.Context, cutoff time.Time, limit int) (expired, rolledBack int, err error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).
+		Model(&models.UserTicket{}).
+		Where("is_deleted = ? AND status = ? AND modified_at < ?", false, models.TicketStatusPending, cutoff).
+		Order("modified_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, 0, err
+	}
+
+	for _, id := range ids {
+		var upgrade bool
+		txErr := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var t models.UserTicket
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND is_deleted = ?", id, false).
+				First(&t).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			// Re-check under lock: the user may have confirmed payment since the scan.
+			if t.Status != models.TicketStatusPending || !t.ModifiedAt.Before(cutoff) {
+				return nil
+			}
+			if t.UpgradedFromTierID != nil {
+				upgrade = true
+				return rollbackUpgrade(tx, &t, uuid.Nil, "Upgrade expired: payment not received")
+			}
+			var tier models.TicketTier
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", t.TicketId).First(&tier).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&tier).Update("stock", tier.Stock+1).Error; err != nil {
+				return err
+			}
+			return tx.Model(&t).Update("is_deleted", true).Error
+		})
+		if txErr != nil {
+			return expired, rolledBack, fmt.Errorf("expire ticket %s: %w", id, txErr)
+		}
+		if upgrade {
+			rolledBack++
+		} else {
+			expired++
+		}
+	}
+	return expired, rolledBack, nil
+}
+
+// TierStock is one tier's seat accounting: remaining stock plus seats held by tickets.
+type TierStock struct {
+	TierCode string `json:"tier_code"`
+	Stock    int    `json:"stock"`
+	Held     int64  `json:"held"`
+	Total    int64  `json:"total"`
+}
+
+// GetTierStock returns stock and held seats for every non-deleted tier. A seat is held by a live
+// (not deleted, not denied) ticket on the tier, or by a pending upgrade away from it (the old seat
+// is only released on approval). Stock + held only changes when an admin edits the tier stock.
+func (r *TicketRepo) GetTierStock(ctx context.Context) ([]TierStock, error) {
+	var out []TierStock
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.tier_code,
+		       t.stock,
+		       (SELECT COUNT(*) FROM user_tickets ut
+		         WHERE ut.ticket_id = t.id AND ut.is_deleted = false AND ut.status <> ?)
+		     + (SELECT COUNT(*) FROM user_tickets ut
+		         WHERE ut.upgraded_from_tier_id = t.id AND ut.is_deleted = false AND ut.status IN (?, ?)) AS held
+		FROM ticket_tiers t
+		WHERE t.is_deleted = false
+		ORDER BY t.tier_code`,
+		models.TicketStatusDenied, models.TicketStatusPending, models.TicketStatusSelfConfirmed,
+	).Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Total = int64(out[i].Stock) + out[i].Held
+	}
+	return out, nil
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fuvekonse/sqs-worker/config"
+	"fuvekonse/sqs-worker/internalapi"
+	"fuvekonse/sqs-worker/models"
+	"fuvekonse/sqs-worker/repo"
+
+	"gorm.io/gorm"
+)
+
+// Job names; EventBridge rule inputs must use the same values.
+const (
+	JobExpireStaleTickets   = "expire_stale_tickets"
+	JobPaymentReminders     = "payment_reminders"
+	JobReconcileStock       = "reconcile_stock"
+	JobWeeklyStats          = "weekly_stats"
+	JobPurgeDeletedUsers    = "purge_deleted_users"
+	JobRetentionPurge       = "retention_purge"
+	JobImpersonationNotices = "impersonation_notices"
+	JobFraudScreening       = "fraud_screening"
+)
+
+// expireBatchSize caps tickets expired per run so one run fits in the Lambda timeout.
+const expireBatchSize = 200
+
+// DefaultJobs returns every scheduled job with its schedule from config. Default schedules are
+// UTC: hourly expiry, reminders at 09:00 ICT, stock reconcile at 02:30 ICT, stats Monday 08:00 ICT,
+// account deletions at quarter past every hour, retention purge at 03:00 ICT, fraud screening
+// every five minutes.
+func DefaultJobs() []Job {
+	return []Job{
+		newJob(JobExpireStaleTickets, "0 * * * *", expireStaleTickets),
+		newJob(JobPaymentReminders, "0 2 * * *", sendPaymentReminders),
+		newJob(JobReconcileStock, "30 19 * * *", reconcileStock),
+		newJob(JobWeeklyStats, "0 1 * * 1", sendWeeklyStats),
+		newJob(JobPurgeDeletedUsers, "15 * * * *", purgeDeletedUsers),
+		newJob(JobRetentionPurge, "0 20 * * *", purgeExpiredData),
+		newJob(JobImpersonationNotices, "*/10 * * * *", sendImpersonationNotices),
+		newJob(JobFraudScreening, "*/5 * * * *", screenPurchases),
+	}
+}
+
+func newJob(name, defaultSchedule string, run JobFunc) Job {
+	job := Job{Name: name, Timeout: DefaultTimeout, Run: run}
+	expr := config.JobSchedule(name, defaultSchedule)
+	if expr == "off" {
+		return job
+	}
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		log.Printf("Scheduled job %s: %v; falling back to %q", name, err, defaultSchedule)
+		schedule, _ = ParseSchedule(defaultSchedule)
+	}
+	job.Schedule = schedule
+	return job
+}
+
+// pendingExpiryHours is how long a ticket may stay pending before it is released.
+func pendingExpiryHours() int {
+	return config.GetEnvIntOr("TICKET_PENDING_EXPIRY_HOURS", 48)
+}
+
+func expireStaleTickets(ctx context.Context, db *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	hours := pendingExpiryHours()
+	cutoff := time.Now().Add(-time.Duration(hours) * time.Hour)
+	expired, rolledBack, err := repo.NewTicketRepo(db).ExpireStalePendingTickets(ctx, cutoff, expireBatchSize)
+	return map[string]int{
+		"expiry_hours":         hours,
+		"expired":              expired,
+		"upgrades_rolled_back": rolledBack,
+	}, err
+}
+
+func sendPaymentReminders(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/payment-reminders", map[string]int{
+		"older_than_hours":   config.GetEnvIntOr("PAYMENT_REMINDER_AFTER_HOURS", 24),
+		"window_hours":       24, // Matches the daily schedule so each ticket is reminded once
+		"expire_after_hours": pendingExpiryHours(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func sendWeeklyStats(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/weekly-stats", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// purgeDeletedUsers has general-service anonymise the users whose account deletion grace period
+// has ended (it owns the PII encryption and the emails).
+func purgeDeletedUsers(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/account-deletions", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// purgeExpiredData has general-service apply the data retention policy. It only reports what
+// is due until RETENTION_PURGE_ENABLED is set there.
+func purgeExpiredData(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/retention-purge", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// sendImpersonationNotices has general-service email the users of admin impersonations that
+// expired without being ended (ended ones are notified straight away).
+func sendImpersonationNotices(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/impersonation-notices", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// screenPurchases has general-service score ticket purchases that have no risk score yet, mostly
+// the ones bought through the queue, and hold the risky ones for review.
+func screenPurchases(ctx context.Context, _ *gorm.DB, _ *models.ScheduledJobRun) (any, error) {
+	data, err := internalapi.Post(ctx, "/internal/jobs/fraud-screening", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type stockReport struct {
+	Tiers []repo.TierStock `json:"tiers"`
+	Drift []string         `json:"drift,omitempty"`
+}
+
+// reconcileStock snapshots stock + held seats per tier and compares the totals with the previous
+// run. Totals only change when an admin edits tier stock, so any other change means a code path
+// lost or double-counted a seat. Drift is reported (run marked failed), not auto-corrected.
+func reconcileStock(ctx context.Context, db *gorm.DB, last *models.ScheduledJobRun) (any, error) {
+	tiers, err := repo.NewTicketRepo(db).GetTierStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &stockReport{Tiers: tiers}
+
+	var previous stockReport
+	if last == nil || last.LastResult == "" || json.Unmarshal([]byte(last.LastResult), &previous) != nil {
+		return report, nil
+	}
+	before := make(map[string]int64, len(previous.Tiers))
+	for _, t := range previous.Tiers {
+		before[t.TierCode] = t.Total
+	}
+	for _, t := range tiers {
+		if prev, ok := before[t.TierCode]; ok && prev != t.Total {
+			report.Drift = append(report.Drift, fmt.Sprintf("%s: %d -> %d (stock %d, held %d)", t.TierCode, prev, t.Total, t.Stock, t.Held))
+		}
+	}
+	if len(report.Drift) > 0 {
+		return report, fmt.Errorf("stock drift detected (check for admin stock edits): %s", strings.Join(report.Drift, "; "))
+	}
+	return report, nil
+}
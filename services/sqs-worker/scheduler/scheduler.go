@@ -0,0 +1,206 @@
+// Package scheduler runs periodic maintenance jobs. In AWS, EventBridge rules invoke the worker
+// Lambda with an Event naming the job; locally, Start runs due jobs on a minute ticker. Either way
+// a Postgres advisory lock ensures a job never runs twice at once, and every run is recorded in
+// scheduled_job_runs so admins can see the last status from general-service.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"fuvekonse/sqs-worker/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTimeout bounds a job run; it stays under the 60s Lambda timeout.
+const DefaultTimeout = 50 * time.Second
+
+// JobFunc runs one job. last is the previous recorded run (nil on first run); the returned
+// result is stored as JSON in scheduled_job_runs.last_result.
+type JobFunc func(ctx context.Context, db *gorm.DB, last *models.ScheduledJobRun) (any, error)
+
+// Job is a named periodic job. Schedule is nil when the job is disabled for the local ticker.
+type Job struct {
+	Name     string
+	Schedule *Schedule
+	Timeout  time.Duration
+	Run      JobFunc
+}
+
+// Event is the constant input EventBridge rules send to the worker Lambda.
+type Event struct {
+	ScheduledJob string `json:"scheduled_job"`
+}
+
+var ErrUnknownJob = errors.New("unknown scheduled job")
+
+type Scheduler struct {
+	db   *gorm.DB
+	jobs []Job
+}
+
+func New(db *gorm.DB, jobs []Job) *Scheduler {
+	return &Scheduler{db: db, jobs: jobs}
+}
+
+// RunByName runs the named job immediately, regardless of its schedule.
+func (s *Scheduler) RunByName(ctx context.Context, name string) error {
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			return s.run(ctx, &s.jobs[i])
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownJob, name)
+}
+
+// RunDue runs every job whose schedule matches now, concurrently, and waits for them.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) {
+	var wg sync.WaitGroup
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if job.Schedule == nil || !job.Schedule.Matches(now) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.run(ctx, job); err != nil {
+				log.Printf("Scheduled job %s failed: %v", job.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Start runs due jobs at the top of every minute until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.Schedule != nil {
+			log.Printf("Scheduled job %s: %s (UTC)", job.Name, job.Schedule)
+		} else {
+			log.Printf("Scheduled job %s: disabled", job.Name)
+		}
+	}
+	go func() {
+		for {
+			next := time.Now().Truncate(time.Minute).Add(time.Minute)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Until(next)):
+			}
+			s.RunDue(ctx, next)
+		}
+	}()
+}
+
+// run executes job while holding its advisory lock. If another worker holds the lock the run is
+// skipped (not an error) and nothing is recorded.
+func (s *Scheduler) run(ctx context.Context, job *Job) error {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lockKey := "scheduled_job:" + job.Name
+	var runErr error
+	err := s.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(hashtext(?))", lockKey).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			log.Printf("Scheduled job %s: already running elsewhere, skipping", job.Name)
+			return nil
+		}
+		defer func() {
+			// Unlock on the same session even if ctx has expired.
+			if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(hashtext(?))", lockKey).Error; err != nil {
+				log.Printf("Scheduled job %s: release lock: %v", job.Name, err)
+			}
+		}()
+		runErr = s.execute(ctx, job)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return runErr
+}
+
+func (s *Scheduler) execute(ctx context.Context, job *Job) error {
+	var last *models.ScheduledJobRun
+	var prev models.ScheduledJobRun
+	if err := s.db.WithContext(ctx).Where("name = ?", job.Name).Limit(1).Find(&prev).Error; err != nil {
+		return fmt.Errorf("load last run: %w", err)
+	}
+	if prev.Name != "" {
+		last = &prev
+	}
+
+	schedule := ""
+	if job.Schedule != nil {
+		schedule = job.Schedule.String()
+	}
+	started := time.Now().UTC()
+	if err := s.record(ctx, &models.ScheduledJobRun{
+		Name:          job.Name,
+		Schedule:      schedule,
+		LastStatus:    models.ScheduledJobStatusRunning,
+		LastStartedAt: &started,
+	}, "schedule", "last_status", "last_started_at"); err != nil {
+		return fmt.Errorf("record start: %w", err)
+	}
+
+	log.Printf("Scheduled job %s: started", job.Name)
+	result, runErr := job.Run(ctx, s.db, last)
+
+	finished := time.Now().UTC()
+	run := &models.ScheduledJobRun{
+		Name:           job.Name,
+		Schedule:       schedule,
+		LastStatus:     models.ScheduledJobStatusSucceeded,
+		LastStartedAt:  &started,
+		LastFinishedAt: &finished,
+		LastDurationMs: finished.Sub(started).Milliseconds(),
+	}
+	if result != nil {
+		if b, err := json.Marshal(result); err == nil {
+			run.LastResult = string(b)
+		}
+	}
+	columns := []string{"last_status", "last_finished_at", "last_duration_ms", "last_error", "last_result"}
+	if runErr != nil {
+		run.LastStatus = models.ScheduledJobStatusFailed
+		run.LastError = runErr.Error()
+	} else {
+		run.LastSuccessAt = &finished
+		columns = append(columns, "last_success_at")
+	}
+	// Record with a fresh context so a timed-out run is still marked failed.
+	recordCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.record(recordCtx, run, columns...); err != nil {
+		log.Printf("Scheduled job %s: record result: %v", job.Name, err)
+	}
+
+	log.Printf("Scheduled job %s: %s in %dms", job.Name, run.LastStatus, run.LastDurationMs)
+	return runErr
+}
+
+// record upserts the job row, updating only the given columns when it already exists.
+func (s *Scheduler) record(ctx context.Context, run *models.ScheduledJobRun, columns ...string) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "modified_at")),
+	}).Create(run).Error
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"fuvekonse/sqs-worker/config"
+	"fuvekonse/sqs-worker/processor"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// DLQ message attributes describing why a message was dead-lettered.
+const (
+	failureClassAttribute  = "FailureClass"
+	failureCodeAttribute   = "FailureCode"
+	failureReasonAttribute = "FailureReason"
+	attemptsAttribute      = "Attempts"
+	sourceIDAttribute      = "SourceMessageId"
+
+	maxFailureReasonLen = 1024
+	settleTimeout       = 10 * time.Second
+	// deadLetterRetryDelay hides a message whose DLQ send failed. Permanent and dead-letter failures
+	// have no backoff of their own, and a zero delay would redeliver the message straight away.
+	deadLetterRetryDelay = 15 * time.Minute
+)
+
+// settler applies a processor.Decision to the received message. The Lambda handler and the local
+// polling loop share it so both follow the same retry policy.
+type settler struct {
+	client   *sqs.Client
+	queueURL string
+	dlqURL   string
+}
+
+func newSettler(client *sqs.Client, queueURL string) *settler {
+	return &settler{client: client, queueURL: queueURL, dlqURL: os.Getenv("SQS_DLQ_URL")}
+}
+
+// received is the part of an SQS message needed to settle it.
+type received struct {
+	MessageID     string
+	ReceiptHandle string
+	Body          string
+	Signature     string
+}
+
+// settle acts on dec and reports whether the message must stay on the queue. Messages that stay
+// are made visible again after the backoff delay; the rest are deleted by the caller (Local) or by
+// the Lambda event source mapping.
+func (s *settler) settle(ctx context.Context, msg received, dec processor.Decision) (keep bool) {
+	switch dec.Outcome {
+	case processor.OutcomeDone:
+		log.Printf("[%s] Processed job successfully", msg.MessageID)
+		return false
+	case processor.OutcomeDrop:
+		log.Printf("[%s] Permanent error (%s), not retrying: %v", msg.MessageID, dec.Code, dec.Err)
+		return false
+	case processor.OutcomeDeadLetter:
+		log.Printf("[%s] Moving to DLQ (%s/%s, attempt %d/%d): %v", msg.MessageID, dec.Class, dec.Code, dec.Attempt, dec.MaxAttempts, dec.Err)
+		if err := s.deadLetter(ctx, msg, dec); err != nil {
+			// Leave it on the queue; the redrive policy is the fallback.
+			log.Printf("[%s] DLQ send failed, leaving message on the queue: %v", msg.MessageID, err)
+			s.delay(ctx, msg, max(processor.Backoffs[dec.Class].Max, deadLetterRetryDelay))
+			return true
+		}
+		return false
+	}
+
+	log.Printf("[%s] %s error (%s), retry %d/%d in %s: %v", msg.MessageID, dec.Class, dec.Code, dec.Attempt, dec.MaxAttempts, dec.Delay.Round(time.Second), dec.Err)
+	s.delay(ctx, msg, dec.Delay)
+	return true
+}
+
+// delay hides the message for d. On failure the queue visibility timeout applies instead.
+func (s *settler) delay(ctx context.Context, msg received, d time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, settleTimeout)
+	defer cancel()
+	_, err := s.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(s.queueURL),
+		ReceiptHandle:     aws.String(msg.ReceiptHandle),
+		VisibilityTimeout: int32(d / time.Second),
+	})
+	if err != nil {
+		log.Printf("[%s] ChangeMessageVisibility failed: %v", msg.MessageID, err)
+	}
+}
+
+// deadLetter copies the message, its signature and the failure details to SQS_DLQ_URL.
+func (s *settler) deadLetter(ctx context.Context, msg received, dec processor.Decision) error {
+	if s.dlqURL == "" {
+		return fmt.Errorf("SQS_DLQ_URL is not set")
+	}
+	reason := ""
+	if dec.Err != nil {
+		reason = dec.Err.Error()
+	}
+	if len(reason) > maxFailureReasonLen {
+		reason = reason[:maxFailureReasonLen]
+	}
+	attrs := map[string]types.MessageAttributeValue{
+		failureClassAttribute: stringAttribute(string(dec.Class)),
+		failureCodeAttribute:  stringAttribute(dec.Code),
+		attemptsAttribute:     {DataType: aws.String("Number"), StringValue: aws.String(strconv.Itoa(dec.Attempt))},
+		sourceIDAttribute:     stringAttribute(msg.MessageID),
+	}
+	if reason != "" {
+		attrs[failureReasonAttribute] = stringAttribute(reason)
+	}
+	if msg.Signature != "" {
+		attrs[processor.SignatureAttribute] = stringAttribute(msg.Signature)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settleTimeout)
+	defer cancel()
+	_, err := s.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(s.dlqURL),
+		MessageBody:       aws.String(msg.Body),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+// delete removes a settled message from the queue (local mode only).
+func (s *settler) delete(ctx context.Context, msg received) {
+	ctx, cancel := context.WithTimeout(ctx, settleTimeout)
+	defer cancel()
+	_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: aws.String(msg.ReceiptHandle),
+	})
+	if err != nil {
+		log.Printf("DeleteMessage %s failed: %v (message may be processed again)", msg.MessageID, err)
+	}
+}
+
+func stringAttribute(v string) types.MessageAttributeValue {
+	if v == "" {
+		v = "-"
+	}
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
+
+// queueURLFromEnv returns SQS_QUEUE_URL, falling back to SQS_QUEUE (the Lambda env name).
+func queueURLFromEnv() string {
+	return config.GetEnvOr("SQS_QUEUE_URL", os.Getenv("SQS_QUEUE"))
+}